@@ -0,0 +1,146 @@
+package adl
+
+import "github.com/golangee/architecture/arc/token"
+
+const (
+	// EntityComponent is an entity stereotype.
+	EntityComponent = "entity"
+
+	// AggregateRootComponent is the stereotype of the root entity of an aggregate.
+	AggregateRootComponent = "aggregate root"
+
+	// ValueObjectComponent is a value object stereotype.
+	ValueObjectComponent = "value object"
+)
+
+// An Aggregate is a cluster of entities and value objects, which is treated as a single unit. It is only
+// accessed through its root entity and only the root can be managed by a repository.
+type Aggregate struct {
+	Comment      token.String
+	Name         token.String
+	Root         *Entity
+	Entities     []*Entity
+	ValueObjects []*ValueObject
+}
+
+func NewAggregate(name, comment string) *Aggregate {
+	return &Aggregate{
+		Comment: traceStr(comment),
+		Name:    traceStr(name),
+	}
+}
+
+// SetRoot designates the given entity as the aggregate root.
+func (a *Aggregate) SetRoot(e *Entity) *Aggregate {
+	e.Component.Stereotype.Val = AggregateRootComponent
+	a.Root = e
+
+	return a
+}
+
+func (a *Aggregate) AddEntities(e ...*Entity) *Aggregate {
+	a.Entities = append(a.Entities, e...)
+	return a
+}
+
+func (a *Aggregate) AddValueObjects(v ...*ValueObject) *Aggregate {
+	a.ValueObjects = append(a.ValueObjects, v...)
+	return a
+}
+
+func (a *Aggregate) Normalize(ctx Ctx) {
+	if a.Root != nil {
+		a.Root.Normalize(ctx)
+	}
+
+	for _, entity := range a.Entities {
+		entity.Normalize(ctx)
+	}
+
+	for _, value := range a.ValueObjects {
+		value.Normalize(ctx)
+	}
+}
+
+// An Entity is a mutable domain object, which is not distinguished by its attributes but by its identity.
+type Entity struct {
+	Component  *Struct
+	Identity   *Field // the identity field, which is not part of the component fields.
+	Invariants []*Invariant
+}
+
+func NewEntity(name, comment string) *Entity {
+	return &Entity{
+		Component: &Struct{
+			Comment:    traceStr(comment),
+			Name:       traceStr(name),
+			Stereotype: traceStr(EntityComponent),
+		},
+	}
+}
+
+func (e *Entity) SetIdentity(f *Field) *Entity {
+	e.Identity = f
+	return e
+}
+
+func (e *Entity) AddFields(f ...*Field) *Entity {
+	e.Component.Fields = append(e.Component.Fields, f...)
+	return e
+}
+
+func (e *Entity) AddInvariants(i ...*Invariant) *Entity {
+	e.Invariants = append(e.Invariants, i...)
+	return e
+}
+
+func (e *Entity) Normalize(ctx Ctx) {
+	e.Component.Normalize(ctx)
+	if e.Identity != nil {
+		e.Identity.Normalize(ctx)
+	}
+}
+
+// A ValueObject is an immutable domain object without identity, which is only distinguished by its attributes.
+type ValueObject struct {
+	Component  *Struct
+	Invariants []*Invariant
+}
+
+func NewValueObject(name, comment string) *ValueObject {
+	return &ValueObject{
+		Component: &Struct{
+			Comment:    traceStr(comment),
+			Name:       traceStr(name),
+			Stereotype: traceStr(ValueObjectComponent),
+		},
+	}
+}
+
+func (v *ValueObject) AddFields(f ...*Field) *ValueObject {
+	v.Component.Fields = append(v.Component.Fields, f...)
+	return v
+}
+
+func (v *ValueObject) AddInvariants(i ...*Invariant) *ValueObject {
+	v.Invariants = append(v.Invariants, i...)
+	return v
+}
+
+func (v *ValueObject) Normalize(ctx Ctx) {
+	v.Component.Normalize(ctx)
+}
+
+// An Invariant is a condition which must always hold for an entity or value object. It is checked whenever
+// a new instance is created.
+type Invariant struct {
+	Comment token.String
+	Name    token.String
+}
+
+func NewInvariant(name, comment string) *Invariant {
+	return &Invariant{
+		Comment: traceStr(comment),
+		Name:    traceStr(name),
+	}
+}
@@ -0,0 +1,77 @@
+package adl
+
+import (
+	"github.com/golangee/architecture/arc/token"
+	"strconv"
+)
+
+// The available kinds of a Constraint.
+const (
+	ConstraintRequired  = "required"
+	ConstraintMin       = "min"
+	ConstraintMax       = "max"
+	ConstraintMinLength = "minLength"
+	ConstraintMaxLength = "maxLength"
+	ConstraintPattern   = "pattern"
+	ConstraintOneOf     = "oneOf"
+	ConstraintPredicate = "predicate"
+)
+
+// A Constraint restricts the valid values of a field. All constraints of a type are checked by its generated
+// Validate method, which reports all violations at once.
+type Constraint struct {
+	Comment token.String
+	Kind    token.String   // Kind is one of the Constraint* constants.
+	Value   token.String   // Value is the literal argument, like a number, a regular expression or a predicate name.
+	Values  []token.String // Values are the allowed literals of a one-of constraint.
+}
+
+// NewRequired rejects the zero value, e.g. an empty string, a nil pointer or an empty slice.
+func NewRequired() *Constraint {
+	return &Constraint{Kind: traceStr(ConstraintRequired), Value: traceStr("")}
+}
+
+// NewMin rejects numbers or durations which are less than the given literal, like 1, 0.5 or 1s.
+func NewMin(literal string) *Constraint {
+	return &Constraint{Kind: traceStr(ConstraintMin), Value: traceStr(literal)}
+}
+
+// NewMax rejects numbers or durations which are greater than the given literal, like 1, 0.5 or 1s.
+func NewMax(literal string) *Constraint {
+	return &Constraint{Kind: traceStr(ConstraintMax), Value: traceStr(literal)}
+}
+
+// NewMinLength rejects strings with less runes or slices and maps with less elements.
+func NewMinLength(n int) *Constraint {
+	return &Constraint{Kind: traceStr(ConstraintMinLength), Value: traceStr(strconv.Itoa(n))}
+}
+
+// NewMaxLength rejects strings with more runes or slices and maps with more elements.
+func NewMaxLength(n int) *Constraint {
+	return &Constraint{Kind: traceStr(ConstraintMaxLength), Value: traceStr(strconv.Itoa(n))}
+}
+
+// NewPattern rejects strings which do not match the given regular expression.
+func NewPattern(regex string) *Constraint {
+	return &Constraint{Kind: traceStr(ConstraintPattern), Value: traceStr(regex)}
+}
+
+// NewOneOf rejects strings or numbers which are not equal to any of the given literals.
+func NewOneOf(literals ...string) *Constraint {
+	c := &Constraint{Kind: traceStr(ConstraintOneOf), Value: traceStr("")}
+	for _, literal := range literals {
+		c.Values = append(c.Values, traceStr(literal))
+	}
+
+	return c
+}
+
+// NewPredicate declares a custom check. The generator emits a shadowable method stub with the given name,
+// which must return an error to reject the field value.
+func NewPredicate(name, comment string) *Constraint {
+	return &Constraint{
+		Comment: traceStr(comment),
+		Kind:    traceStr(ConstraintPredicate),
+		Value:   traceStr(name),
+	}
+}
@@ -0,0 +1,480 @@
+package adl
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/token"
+	"reflect"
+	"strings"
+)
+
+// ChangeKind classifies a Change.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeRenamed  ChangeKind = "renamed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// A Change is a single difference between two versions of a project.
+type Change struct {
+	Kind     ChangeKind
+	Breaking bool      // Breaking is true, if existing consumers or implementations are affected by the change.
+	Path     string    // Path is the qualified name of the changed declaration, like Tickets/core.Ticket.ID.
+	Message  string    // Message describes the change.
+	Pos      token.Pos // Pos is the position within the new version or within the old one, if it has been removed.
+}
+
+// A DiffReport contains all changes between two versions of a project in declaration order.
+type DiffReport struct {
+	Changes []Change
+}
+
+// Diff compares the modules, bounded contexts, packages and their declarations of two project versions, which
+// are usually loaded by LoadFile from the previous and the current JSON export. Declarations are matched by
+// their names, so that a renamed type is reported as removed and added. Within structs, a removed and an added
+// field with the same type are reported as renamed. The operations of a crud are compared like the declared
+// methods of its repository. Both projects are only inspected as declared, so variables like $BC are not resolved
+// and must be used consistently.
+func Diff(oldPrj, newPrj *Project) *DiffReport {
+	d := &differ{}
+	d.project(oldPrj, newPrj)
+
+	return &DiffReport{Changes: d.changes}
+}
+
+// Breaking returns only the breaking changes.
+func (r *DiffReport) Breaking() []Change {
+	var res []Change
+	for _, change := range r.Changes {
+		if change.Breaking {
+			res = append(res, change)
+		}
+	}
+
+	return res
+}
+
+// IsBreaking returns true, if any change is breaking.
+func (r *DiffReport) IsBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// Text renders the report as plain text with a single line per change.
+func (r *DiffReport) Text() string {
+	if len(r.Changes) == 0 {
+		return "no changes\n"
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%d changes, %d breaking\n\n", len(r.Changes), len(r.Breaking()))
+	for _, change := range r.Changes {
+		severity := "compatible"
+		if change.Breaking {
+			severity = "BREAKING"
+		}
+
+		fmt.Fprintf(sb, "%-10s %-8s %s: %s", severity, change.Kind, change.Path, change.Message)
+		if change.Pos.File != "" {
+			sb.WriteString(" (" + change.Pos.String() + ")")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// Markdown renders the report as a markdown table, e.g. to be posted into a code review.
+func (r *DiffReport) Markdown() string {
+	sb := &strings.Builder{}
+	sb.WriteString("## Architecture changes\n\n")
+	if len(r.Changes) == 0 {
+		sb.WriteString("There are no changes.\n")
+		return sb.String()
+	}
+
+	fmt.Fprintf(sb, "**%d of %d changes are breaking.**\n\n", len(r.Breaking()), len(r.Changes))
+	sb.WriteString("| Severity | Change | Declaration | Description |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, change := range r.Changes {
+		severity := "compatible"
+		if change.Breaking {
+			severity = "**breaking**"
+		}
+
+		fmt.Fprintf(sb, "| %s | %s | `%s` | %s |\n", severity, change.Kind, change.Path, strings.ReplaceAll(change.Message, "|", "\\|"))
+	}
+
+	return sb.String()
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) report(kind ChangeKind, breaking bool, path string, pos token.Node, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Kind:     kind,
+		Breaking: breaking,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Pos:      pos.Begin(),
+	})
+}
+
+// removedOrAdded reports a declaration which only exists in one version. Removals are always breaking,
+// additions only if addBreaks is set.
+func (d *differ) removedOrAdded(what, path string, oldName, newName *token.String, addBreaks bool) {
+	if newName == nil {
+		d.report(ChangeRemoved, true, path, oldName, "%s '%s' has been removed", what, oldName.String())
+		return
+	}
+
+	d.report(ChangeAdded, addBreaks, path, newName, "%s '%s' has been added", what, newName.String())
+}
+
+func (d *differ) project(oldPrj, newPrj *Project) {
+	oldMods := map[string]*Module{}
+	for _, mod := range oldPrj.Modules {
+		oldMods[mod.Name.String()] = mod
+	}
+
+	for _, mod := range newPrj.Modules {
+		if old, ok := oldMods[mod.Name.String()]; ok {
+			d.module(old, mod)
+			delete(oldMods, mod.Name.String())
+			continue
+		}
+
+		d.removedOrAdded("module", mod.Name.String(), nil, &mod.Name, false)
+	}
+
+	for _, mod := range oldPrj.Modules {
+		if _, ok := oldMods[mod.Name.String()]; ok {
+			d.removedOrAdded("module", mod.Name.String(), &mod.Name, nil, false)
+		}
+	}
+}
+
+func (d *differ) module(oldMod, newMod *Module) {
+	oldBCs := map[string]*BoundedContext{}
+	for _, bc := range oldMod.BoundedContexts {
+		oldBCs[bc.Name.String()] = bc
+	}
+
+	for _, bc := range newMod.BoundedContexts {
+		if old, ok := oldBCs[bc.Name.String()]; ok {
+			d.packages(bc.Name.String()+"/"+layerCore, old.Core, bc.Core)
+			d.packages(bc.Name.String()+"/"+layerUsecase, old.Usecase, bc.Usecase)
+			delete(oldBCs, bc.Name.String())
+			continue
+		}
+
+		d.removedOrAdded("bounded context", bc.Name.String(), nil, &bc.Name, false)
+	}
+
+	for _, bc := range oldMod.BoundedContexts {
+		if _, ok := oldBCs[bc.Name.String()]; ok {
+			d.removedOrAdded("bounded context", bc.Name.String(), &bc.Name, nil, false)
+		}
+	}
+}
+
+func (d *differ) packages(path string, oldPkgs, newPkgs []*Package) {
+	pkgPath := func(p *Package) string {
+		if p.Name.String() == "" {
+			return path
+		}
+
+		return path + "/" + p.Name.String()
+	}
+
+	olds := map[string]*Package{}
+	for _, p := range oldPkgs {
+		olds[p.Name.String()] = p
+	}
+
+	for _, p := range newPkgs {
+		if old, ok := olds[p.Name.String()]; ok {
+			d.pkg(pkgPath(p), old, p)
+			delete(olds, p.Name.String())
+			continue
+		}
+
+		d.removedOrAdded("package", pkgPath(p), nil, &p.Name, false)
+	}
+
+	for _, p := range oldPkgs {
+		if _, ok := olds[p.Name.String()]; ok {
+			d.removedOrAdded("package", pkgPath(p), &p.Name, nil, false)
+		}
+	}
+}
+
+// member is a named declaration.
+type member struct {
+	name *token.String
+	decl interface{}
+}
+
+// membersOf returns the declarations of the given slice of struct pointers, which all provide a Name field.
+func membersOf(list interface{}) []member {
+	v := reflect.ValueOf(list)
+	res := make([]member, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		name := elem.Elem().FieldByName("Name").Addr().Interface().(*token.String)
+		res = append(res, member{name: name, decl: elem.Interface()})
+	}
+
+	return res
+}
+
+// members matches the declarations by name and compares the matching pairs. Removals are breaking and additions
+// only if addBreaks is set.
+func (d *differ) members(what, path string, olds, news []member, addBreaks bool, compare func(path string, old, new interface{})) {
+	oldByName := map[string]member{}
+	for _, m := range olds {
+		oldByName[m.name.String()] = m
+	}
+
+	for _, m := range news {
+		if old, ok := oldByName[m.name.String()]; ok {
+			compare(path+"."+m.name.String(), old.decl, m.decl)
+			delete(oldByName, m.name.String())
+			continue
+		}
+
+		d.removedOrAdded(what, path+"."+m.name.String(), nil, m.name, addBreaks)
+	}
+
+	for _, m := range olds {
+		if _, ok := oldByName[m.name.String()]; ok {
+			d.removedOrAdded(what, path+"."+m.name.String(), m.name, nil, false)
+		}
+	}
+}
+
+func (d *differ) pkg(path string, oldPkg, newPkg *Package) {
+	d.members("struct", path, membersOf(oldPkg.DTOs), membersOf(newPkg.DTOs), false, func(p string, old, new interface{}) {
+		d.fields(p, old.(*Struct).Fields, new.(*Struct).Fields, false)
+	})
+
+	d.members("error case", path, membersOf(oldPkg.Errors), membersOf(newPkg.Errors), false, func(p string, old, new interface{}) {
+		d.fields(p, old.(*Error).Fields, new.(*Error).Fields, false)
+	})
+
+	d.members("event", path, membersOf(oldPkg.Events), membersOf(newPkg.Events), false, func(p string, old, new interface{}) {
+		d.fields(p, old.(*Event).Fields, new.(*Event).Fields, false)
+	})
+
+	d.members("enum", path, membersOf(oldPkg.Enums), membersOf(newPkg.Enums), false, func(p string, old, new interface{}) {
+		d.members("enum case", p, membersOf(old.(*Enum).Cases), membersOf(new.(*Enum).Cases), false, func(p string, old, new interface{}) {
+			d.fields(p, old.(*EnumCase).Fields, new.(*EnumCase).Fields, false)
+		})
+	})
+
+	d.members("value object", path, membersOf(valueComponents(oldPkg.ValueObjects)), membersOf(valueComponents(newPkg.ValueObjects)), false, d.ctorFields)
+
+	d.members("aggregate", path, membersOf(oldPkg.Aggregates), membersOf(newPkg.Aggregates), false, func(p string, old, new interface{}) {
+		oldAggregate, newAggregate := old.(*Aggregate), new.(*Aggregate)
+		d.members("entity", p, membersOf(entityComponents(oldAggregate)), membersOf(entityComponents(newAggregate)), false, d.ctorFields)
+		d.members("value object", p, membersOf(valueComponents(oldAggregate.ValueObjects)), membersOf(valueComponents(newAggregate.ValueObjects)), false, d.ctorFields)
+	})
+
+	d.members("repository", path, membersOf(oldPkg.Repositories), membersOf(newPkg.Repositories), false, func(p string, old, new interface{}) {
+		// each implementation of the repository must provide an added method
+		d.members("repository method", p, membersOf(old.(*Interface).Methods), membersOf(new.(*Interface).Methods), true, d.method)
+		d.crudOperations(p, old.(*Interface).CRUDs, new.(*Interface).CRUDs)
+	})
+
+	d.members("service", path, membersOf(serviceComponents(oldPkg.Services)), membersOf(serviceComponents(newPkg.Services)), false, func(p string, old, new interface{}) {
+		d.members("service method", p, membersOf(old.(*Struct).Methods), membersOf(new.(*Struct).Methods), false, d.method)
+	})
+}
+
+// ctorFields compares the fields of entities and value objects, whose constructors require all fields.
+func (d *differ) ctorFields(path string, old, new interface{}) {
+	d.fields(path, old.(*Struct).Fields, new.(*Struct).Fields, true)
+}
+
+// fields compares the fields by name. A removed and an added field of the same type are considered to be renamed.
+// Added fields are only breaking for constructors, which require all fields.
+func (d *differ) fields(path string, olds, news []*Field, ctor bool) {
+	oldByName := map[string]*Field{}
+	for _, f := range olds {
+		oldByName[f.Name.String()] = f
+	}
+
+	var added []*Field
+	for _, f := range news {
+		old, ok := oldByName[f.Name.String()]
+		if !ok {
+			added = append(added, f)
+			continue
+		}
+
+		delete(oldByName, f.Name.String())
+		fieldPath := path + "." + f.Name.String()
+		if old.Type.String() != f.Type.String() {
+			d.report(ChangeModified, true, fieldPath, f.Name, "type of field '%s' changed from '%s' to '%s'", f.Name.String(), old.Type.String(), f.Type.String())
+		}
+
+		if old.Private != f.Private {
+			if f.Private {
+				d.report(ChangeModified, true, fieldPath, f.Name, "field '%s' is not public anymore", f.Name.String())
+			} else {
+				d.report(ChangeModified, false, fieldPath, f.Name, "field '%s' has become public", f.Name.String())
+			}
+		}
+	}
+
+	var removed []*Field
+	for _, f := range olds {
+		if _, ok := oldByName[f.Name.String()]; ok {
+			removed = append(removed, f)
+		}
+	}
+
+	for _, f := range added {
+		renamed := false
+		for i, old := range removed {
+			if old.Type.String() == f.Type.String() {
+				d.report(ChangeRenamed, true, path+"."+f.Name.String(), f.Name, "field '%s' has been renamed to '%s'", old.Name.String(), f.Name.String())
+				removed = append(removed[:i], removed[i+1:]...)
+				renamed = true
+				break
+			}
+		}
+
+		if !renamed {
+			d.removedOrAdded("field", path+"."+f.Name.String(), nil, &f.Name, ctor)
+		}
+	}
+
+	for _, f := range removed {
+		d.removedOrAdded("field", path+"."+f.Name.String(), &f.Name, nil, false)
+	}
+}
+
+// method compares the signature and the error cases of a method. Parameter names are irrelevant for callers.
+func (d *differ) method(path string, old, new interface{}) {
+	oldMethod, newMethod := old.(*Method), new.(*Method)
+	d.params(path, "parameter", oldMethod.In, newMethod.In, newMethod.Name)
+	d.params(path, "result", oldMethod.Out, newMethod.Out, newMethod.Name)
+
+	oldErrs := map[string]*TypeDecl{}
+	for _, e := range oldMethod.Errors {
+		oldErrs[e.String()] = e
+	}
+
+	for _, e := range newMethod.Errors {
+		if _, ok := oldErrs[e.String()]; ok {
+			delete(oldErrs, e.String())
+			continue
+		}
+
+		d.report(ChangeAdded, false, path, e.Name, "error case '%s' has been added", e.String())
+	}
+
+	for _, e := range oldMethod.Errors {
+		if _, ok := oldErrs[e.String()]; ok {
+			d.report(ChangeRemoved, true, path, e.Name, "error case '%s' has been removed", e.String())
+		}
+	}
+}
+
+// crudOperations compares the generated methods of the repository like declared ones. An operation is matched
+// by its name and its signature changes together with the entity or the id type of its crud.
+func (d *differ) crudOperations(path string, olds, news []*CRUD) {
+	oldOps := map[string]*CRUD{}
+	for _, crud := range olds {
+		for _, op := range crud.Operations() {
+			oldOps[op] = crud
+		}
+	}
+
+	newOps := map[string]bool{}
+	for _, crud := range news {
+		for _, op := range crud.Operations() {
+			newOps[op] = true
+			old, ok := oldOps[op]
+			if !ok {
+				d.report(ChangeAdded, true, path+"."+op, crud.EntityType.Name, "crud operation '%s' has been added", op)
+				continue
+			}
+
+			if old.EntityType.String() != crud.EntityType.String() {
+				d.report(ChangeModified, true, path+"."+op, crud.EntityType.Name, "entity of crud operation '%s' changed from '%s' to '%s'", op, old.EntityType.String(), crud.EntityType.String())
+			}
+
+			if old.IDType.String() != crud.IDType.String() {
+				d.report(ChangeModified, true, path+"."+op, crud.EntityType.Name, "id of crud operation '%s' changed from '%s' to '%s'", op, old.IDType.String(), crud.IDType.String())
+			}
+		}
+	}
+
+	for _, crud := range olds {
+		for _, op := range crud.Operations() {
+			if !newOps[op] {
+				d.report(ChangeRemoved, true, path+"."+op, crud.EntityType.Name, "crud operation '%s' has been removed", op)
+			}
+		}
+	}
+}
+
+func (d *differ) params(path, what string, olds, news []*Param, method token.String) {
+	if len(olds) != len(news) {
+		d.report(ChangeModified, true, path, method, "%ss changed from (%s) to (%s)", what, paramList(olds), paramList(news))
+		return
+	}
+
+	for i, param := range news {
+		old := olds[i]
+		if old.Type.String() != param.Type.String() {
+			d.report(ChangeModified, true, path, param.Name, "type of %s %d changed from '%s' to '%s'", what, i+1, old.Type.String(), param.Type.String())
+		}
+	}
+}
+
+func paramList(params []*Param) string {
+	var tmp []string
+	for _, param := range params {
+		tmp = append(tmp, strings.TrimSpace(param.Name.String()+" "+param.Type.String()))
+	}
+
+	return strings.Join(tmp, ", ")
+}
+
+func valueComponents(values []*ValueObject) []*Struct {
+	var res []*Struct
+	for _, value := range values {
+		res = append(res, value.Component)
+	}
+
+	return res
+}
+
+func entityComponents(aggregate *Aggregate) []*Struct {
+	var res []*Struct
+	if aggregate.Root != nil {
+		res = append(res, aggregate.Root.Component)
+	}
+
+	for _, entity := range aggregate.Entities {
+		res = append(res, entity.Component)
+	}
+
+	return res
+}
+
+func serviceComponents(services []*Service) []*Struct {
+	var res []*Struct
+	for _, service := range services {
+		res = append(res, service.Component)
+	}
+
+	return res
+}
@@ -0,0 +1,118 @@
+package adl
+
+import (
+	"github.com/golangee/src/stdlib"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldPrj := newValidationProject(NewPackage("", "").
+		AddErrors(NewError("NotFound", "...is missing."), NewError("Other", "...is anything.")).
+		AddStructs(NewDTO("Ticket", "...is a ticket.").
+			AddFields(
+				NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)),
+				NewField("Name", "...is the name.", NewTypeDecl(stdlib.String)),
+				NewField("Prio", "...is the priority.", NewTypeDecl(stdlib.Int)),
+			)).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddMethods(
+				NewMethod("Find", "...finds.").
+					AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+					AddOut("", "...is the ticket.", NewTypeDecl("Ticket")).
+					AddErrors(NewTypeDecl("NotFound"), NewTypeDecl("Other")),
+				NewMethod("Delete", "...deletes."),
+			)))
+
+	newPrj := newValidationProject(NewPackage("", "").
+		AddErrors(NewError("NotFound", "...is missing.")).
+		AddStructs(NewDTO("Ticket", "...is a ticket.").
+			AddFields(
+				NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)),
+				NewField("Title", "...is the name.", NewTypeDecl(stdlib.String)),
+				NewField("Prio", "...is the priority.", NewTypeDecl(stdlib.Int64)),
+				NewField("Tags", "...are the tags.", NewTypeDecl("[]", NewTypeDecl(stdlib.String))),
+			)).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddMethods(
+				NewMethod("Find", "...finds.").
+					AddIn("ticketId", "...is the id.", NewTypeDecl(stdlib.String)).
+					AddOut("", "...is the ticket.", NewTypeDecl("Ticket")).
+					AddErrors(NewTypeDecl("NotFound")),
+				NewMethod("Count", "...counts."),
+			)))
+
+	want := []struct {
+		breaking bool
+		path     string
+		msg      string
+	}{
+		{true, "Tickets/core.Ticket.Prio", "type of field 'Prio' changed from 'int!' to 'int64!'"},
+		{true, "Tickets/core.Ticket.Title", "field 'Name' has been renamed to 'Title'"},
+		{false, "Tickets/core.Ticket.Tags", "field 'Tags' has been added"},
+		{true, "Tickets/core.Other", "error case 'Other' has been removed"},
+		{true, "Tickets/core.Tickets.Find", "type of parameter 1 changed from 'uuid!' to 'string!'"},
+		{true, "Tickets/core.Tickets.Find", "error case 'Other' has been removed"},
+		{true, "Tickets/core.Tickets.Count", "repository method 'Count' has been added"},
+		{true, "Tickets/core.Tickets.Delete", "repository method 'Delete' has been removed"},
+	}
+
+	report := Diff(oldPrj, newPrj)
+	if len(report.Changes) != len(want) {
+		t.Fatalf("expected %d changes but got:\n%s", len(want), report.Text())
+	}
+
+	for i, w := range want {
+		change := report.Changes[i]
+		if change.Breaking != w.breaking || change.Path != w.path || change.Message != w.msg {
+			t.Errorf("expected change %d to be %v %s: %s but got %v %s: %s", i, w.breaking, w.path, w.msg, change.Breaking, change.Path, change.Message)
+		}
+	}
+
+	if !strings.HasPrefix(report.Text(), "8 changes, 7 breaking\n") {
+		t.Errorf("unexpected text report:\n%s", report.Text())
+	}
+
+	if !strings.Contains(report.Markdown(), "| **breaking** | renamed | `Tickets/core.Ticket.Title` | field 'Name' has been renamed to 'Title' |") {
+		t.Errorf("unexpected markdown report:\n%s", report.Markdown())
+	}
+
+	if report := Diff(oldPrj, oldPrj); len(report.Changes) != 0 || report.IsBreaking() {
+		t.Fatalf("expected no changes but got:\n%s", report.Text())
+	}
+}
+
+func TestDiffCRUD(t *testing.T) {
+	newRepository := func(crud *CRUD) *Project {
+		return newValidationProject(NewPackage("", "").
+			AddStructs(NewDTO("Ticket", "...is a ticket."), NewDTO("Issue", "...is a ticket.")).
+			AddRepositories(NewInterface("Tickets", "...is a repo.").AddCRUDImpl(crud)))
+	}
+
+	oldPrj := newRepository(NewCRUD(NewTypeDecl("Ticket"), nil, PMemory, true, true, false, true, false, true, false))
+	newPrj := newRepository(NewCRUD(NewTypeDecl("Issue"), NewTypeDecl(stdlib.String), PMemory, true, false, false, false, true, false, false))
+
+	want := []string{
+		"entity of crud operation 'InsertOne' changed from 'Ticket' to 'Issue'",
+		"id of crud operation 'InsertOne' changed from '' to 'string!'",
+		"crud operation 'CountAll' has been added",
+		"crud operation 'FindOne' has been removed",
+		"crud operation 'DeleteOne' has been removed",
+		"crud operation 'FindAll' has been removed",
+	}
+
+	report := Diff(oldPrj, newPrj)
+	if len(report.Changes) != len(want) {
+		t.Fatalf("expected %d changes but got:\n%s", len(want), report.Text())
+	}
+
+	for i, msg := range want {
+		if change := report.Changes[i]; !change.Breaking || change.Message != msg {
+			t.Errorf("expected change %d to be breaking: %s but got %v: %s", i, msg, change.Breaking, change.Message)
+		}
+	}
+
+	if report := Diff(oldPrj, oldPrj); len(report.Changes) != 0 {
+		t.Fatalf("expected no changes but got:\n%s", report.Text())
+	}
+}
@@ -0,0 +1,411 @@
+package adl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golangee/architecture/arc/token"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var tokenStringType = reflect.TypeOf(token.String{})
+
+// LoadFile reads a Project from a JSON (*.json) or YAML (*.yaml or *.yml) document. Contrary to the
+// encoding/json representation, each token.String is just a plain string and the keys are the field names.
+// The positions of the strings refer to their location within the document. See also JSONSchema.
+func LoadFile(filename string) (*Project, error) {
+	fname, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve absolute path: %w", err)
+	}
+
+	if _, err := documentFormat(fname); err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read adl document: %w", err)
+	}
+
+	// yaml is a superset of json, so a single decoder is sufficient to get the positions of both formats
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return nil, token.NewPosError(token.NewFileNode(fname), "unable to parse adl document").SetCause(err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, token.NewPosError(token.NewFileNode(fname), "adl document is empty")
+	}
+
+	dec := newDocDecoder(fname, buf)
+	prj := &Project{}
+	if err := dec.decode(doc.Content[0], reflect.ValueOf(prj).Elem()); err != nil {
+		return nil, err
+	}
+
+	return prj, nil
+}
+
+// SaveFile writes the Project as a JSON (*.json) or YAML (*.yaml or *.yml) document. Empty values are
+// omitted, so that LoadFile restores the same project, except for the positions.
+func SaveFile(filename string, prj *Project) error {
+	format, err := documentFormat(filename)
+	if err != nil {
+		return err
+	}
+
+	node := encodeDocument(reflect.ValueOf(prj).Elem(), format)
+
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		var tmp bytes.Buffer
+		writeJSON(&tmp, node)
+		if err := json.Indent(&buf, tmp.Bytes(), "", "  "); err != nil {
+			return fmt.Errorf("unable to indent json: %w", err)
+		}
+
+		buf.WriteByte('\n')
+	default:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(node); err != nil {
+			return fmt.Errorf("unable to encode yaml: %w", err)
+		}
+
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("unable to encode yaml: %w", err)
+		}
+	}
+
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write adl document: %w", err)
+	}
+
+	return nil
+}
+
+func documentFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return "json", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	default:
+		return "", fmt.Errorf("unsupported adl document format '%s': expected *.json, *.yaml or *.yml", filename)
+	}
+}
+
+// docDecoder maps the yaml node tree reflectively on the model types.
+type docDecoder struct {
+	file       string
+	buf        []byte
+	lineStarts []int // byte offsets of each line
+}
+
+func newDocDecoder(file string, buf []byte) *docDecoder {
+	d := &docDecoder{file: file, buf: buf, lineStarts: []int{0}}
+	for i, b := range buf {
+		if b == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+
+	return d
+}
+
+// pos converts the one-based line and rune column into a position including the byte offset.
+func (d *docDecoder) pos(line, col int) token.Pos {
+	p := token.Pos{File: d.file, Line: line, Col: col, Offset: -1}
+	if line < 1 || line > len(d.lineStarts) {
+		return p
+	}
+
+	offset := d.lineStarts[line-1]
+	for i := 1; i < col && offset < len(d.buf); i++ {
+		_, size := utf8.DecodeRune(d.buf[offset:])
+		offset += size
+	}
+
+	p.Offset = offset
+
+	return p
+}
+
+// str returns the located value of the node. The position of quoted scalars excludes the quotes.
+func (d *docDecoder) str(n *yaml.Node) token.String {
+	s := token.NewString(n.Value)
+	s.BeginPos = d.pos(n.Line, n.Column)
+	if n.Kind == yaml.ScalarNode && n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		s.BeginPos = d.pos(n.Line, n.Column+1)
+	}
+
+	s.EndPos = s.BeginPos
+	if n.Kind == yaml.ScalarNode && !strings.ContainsRune(n.Value, '\n') {
+		s.EndPos.Col += utf8.RuneCountInString(n.Value)
+		s.EndPos.Offset += len(n.Value)
+	}
+
+	return s
+}
+
+// emptyAt returns an empty string located at the given node.
+func (d *docDecoder) emptyAt(n *yaml.Node) token.String {
+	s := token.NewString("")
+	s.BeginPos = d.pos(n.Line, n.Column)
+	s.EndPos = s.BeginPos
+
+	return s
+}
+
+func (d *docDecoder) expectKind(n *yaml.Node, kind yaml.Kind, what string) error {
+	if n.Kind != kind {
+		return token.NewPosError(d.str(n), "expected "+what)
+	}
+
+	return nil
+}
+
+func (d *docDecoder) decode(n *yaml.Node, v reflect.Value) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	if v.Type() == tokenStringType {
+		if err := d.expectKind(n, yaml.ScalarNode, "string"); err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(d.str(n)))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if err := d.expectKind(n, yaml.ScalarNode, "string"); err != nil {
+			return err
+		}
+
+		v.SetString(n.Value)
+	case reflect.Bool:
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return token.NewPosError(d.str(n), "expected boolean").SetCause(err)
+		}
+
+		v.SetBool(b)
+	case reflect.Int:
+		var i int
+		if err := n.Decode(&i); err != nil {
+			return token.NewPosError(d.str(n), "expected integer").SetCause(err)
+		}
+
+		v.SetInt(int64(i))
+	case reflect.Ptr:
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+			return nil
+		}
+
+		ptr := reflect.New(v.Type().Elem())
+		if err := d.decode(n, ptr.Elem()); err != nil {
+			return err
+		}
+
+		v.Set(ptr)
+	case reflect.Slice:
+		if err := d.expectKind(n, yaml.SequenceNode, "list"); err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(n.Content), len(n.Content))
+		for i, elem := range n.Content {
+			if err := d.decode(elem, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+	case reflect.Map:
+		if err := d.expectKind(n, yaml.MappingNode, "object"); err != nil {
+			return err
+		}
+
+		m := reflect.MakeMap(v.Type())
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(n.Content[i+1], val); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(reflect.ValueOf(n.Content[i].Value).Convert(v.Type().Key()), val)
+		}
+
+		v.Set(m)
+	case reflect.Struct:
+		return d.decodeStruct(n, v)
+	default:
+		panic("unsupported model type: " + v.Type().String())
+	}
+
+	return nil
+}
+
+// decodeStruct decodes the mapping into the struct. Absent strings are located at the mapping itself,
+// so that later errors about missing names or comments still point to the right place.
+func (d *docDecoder) decodeStruct(n *yaml.Node, v reflect.Value) error {
+	if err := d.expectKind(n, yaml.MappingNode, "object"); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).Type() == tokenStringType {
+			v.Field(i).Set(reflect.ValueOf(d.emptyAt(n)))
+		}
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		field, ok := v.Type().FieldByName(key.Value)
+		if !ok || len(field.Index) != 1 {
+			return token.NewPosError(d.str(key), "unknown property '"+key.Value+"' of "+v.Type().Name()).
+				SetHint("see the JSON Schema for all valid properties")
+		}
+
+		if err := d.decode(n.Content[i+1], v.Field(field.Index[0])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeDocument creates the document node tree from the model types. Empty values are omitted.
+func encodeDocument(v reflect.Value, format string) *yaml.Node {
+	if v.Type() == tokenStringType {
+		return encodeScalar(v.Interface().(token.String).Val, format)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return encodeScalar(v.String(), format)
+	case reflect.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v.Bool())}
+	case reflect.Int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(v.Int())}
+	case reflect.Ptr:
+		return encodeDocument(v.Elem(), format)
+	case reflect.Slice:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			n.Content = append(n.Content, encodeDocument(v.Index(i), format))
+		}
+
+		return n
+	case reflect.Map:
+		var keys []string
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+
+		sort.Strings(keys)
+
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range keys {
+			val := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			n.Content = append(n.Content, encodeScalar(key, format), encodeDocument(val, format))
+		}
+
+		return n
+	case reflect.Struct:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			if isEmptyValue(v.Field(i)) {
+				continue
+			}
+
+			n.Content = append(n.Content, encodeScalar(v.Type().Field(i).Name, format), encodeDocument(v.Field(i), format))
+		}
+
+		return n
+	default:
+		panic("unsupported model type: " + v.Type().String())
+	}
+}
+
+func encodeScalar(s, format string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if format == "yaml" && strings.ContainsRune(s, '\n') {
+		n.Style = yaml.LiteralStyle
+	}
+
+	return n
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	if v.Type() == tokenStringType {
+		return v.Interface().(token.String).Val == ""
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int:
+		return v.Int() == 0
+	case reflect.Ptr:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return false
+	}
+}
+
+// writeJSON emits the node tree as compact json.
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeJSON(buf, n.Content[i])
+			buf.WriteByte(':')
+			writeJSON(buf, n.Content[i+1])
+		}
+
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, elem := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeJSON(buf, elem)
+		}
+
+		buf.WriteByte(']')
+	default:
+		if n.Tag == "!!bool" || n.Tag == "!!int" {
+			buf.WriteString(n.Value)
+			return
+		}
+
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(n.Value)     // a string never fails
+		buf.Truncate(buf.Len() - 1) // remove the newline from Encode
+	}
+}
@@ -0,0 +1,129 @@
+package adl
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/stdlib"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "rewrites adl.schema.json")
+
+func TestSaveLoadFile(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddErrors(NewError("Other", "...is anything.")).
+		AddStructs(NewDTO("Ticket", "...is a ticket.").
+			AddFields(
+				NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)),
+				NewField("Tags", "...are tags.", NewTypeDecl(stdlib.Map, NewTypeDecl(stdlib.String), NewTypeDecl(stdlib.Int))),
+			)).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddCRUDImpl(NewCRUD(NewTypeDecl("Ticket"), nil, PMemory, true, true, true, true, true, true, true))))
+	prj.Modules[0].SetLicense("line 1\nline 2")
+	prj.Modules[0].BoundedContexts[0].AddUsecase(NewPackage("", "").
+		AddServices(NewService("Tickets", "...is a service.").
+			AddMethods(NewMethod("Find", "...finds.").
+				AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+				AddOut("", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+				AddErrors(NewTypeDecl("$BC/core.Other")).
+				SetHttp(NewHttpEndpoint(HttpGet, "/tickets/{id}").MapError("$BC/core.Other", 404)))))
+
+	for _, name := range []string{"prj.json", "prj.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			fname := filepath.Join(t.TempDir(), name)
+			if err := SaveFile(fname, prj); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := LoadFile(fname)
+			if err != nil {
+				t.Fatal(token.Explain(err))
+			}
+
+			if err := Validate(loaded); err != nil {
+				t.Fatal(token.Explain(err))
+			}
+
+			fname2 := filepath.Join(t.TempDir(), name)
+			if err := SaveFile(fname2, loaded); err != nil {
+				t.Fatal(err)
+			}
+
+			expected, _ := ioutil.ReadFile(fname)
+			actual, _ := ioutil.ReadFile(fname2)
+			if !bytes.Equal(expected, actual) {
+				t.Fatalf("expected round trip to be stable:\n%s\n\nbut got\n\n%s", expected, actual)
+			}
+
+			endpoint := loaded.Modules[0].BoundedContexts[0].Usecase[0].Services[0].Component.Methods[0].Http
+			if code := endpoint.StatusOf("$BC/core.Other"); code != 404 {
+				t.Fatalf("expected status 404 but got %d", code)
+			}
+
+			if loaded.Name.BeginPos.File != fname || loaded.Name.BeginPos.Line < 1 || loaded.Name.BeginPos.Offset < 0 {
+				t.Fatalf("expected located name but got %v", loaded.Name.BeginPos)
+			}
+		})
+	}
+}
+
+func TestLoadFilePositions(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "prj.yml")
+	src := "Name: \"demo\"\nModules:\n  - Name: srv\n    Unknown: true\n"
+	if err := ioutil.WriteFile(fname, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadFile(fname)
+	var posErr *token.PosError
+	if !errors.As(err, &posErr) {
+		t.Fatalf("expected PosError but got %v", err)
+	}
+
+	if pos := posErr.Details[0].Node.Begin(); pos.Line != 4 || pos.Col != 5 || pos.Offset != 40 {
+		t.Fatalf("expected error at 4:5 but got %v (offset %d)", pos, pos.Offset)
+	}
+
+	if err := ioutil.WriteFile(fname, []byte(src[:len(src)-18]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prj, err := LoadFile(fname)
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	if pos := prj.Name.BeginPos; pos.Line != 1 || pos.Col != 8 || pos.Offset != 7 || prj.Name.EndPos.Col != 12 {
+		t.Fatalf("expected quoted name at 1:8 but got %v", pos)
+	}
+
+	if pos := prj.Modules[0].Name.BeginPos; pos.Line != 3 || pos.Col != 11 {
+		t.Fatalf("expected module name at 3:11 but got %v", pos)
+	}
+
+	if pos := prj.Modules[0].Comment.BeginPos; pos.Line != 3 || pos.Col != 5 {
+		t.Fatalf("expected absent comment at its module 3:5 but got %v", pos)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	if *updateSchema {
+		if err := ioutil.WriteFile("adl.schema.json", schema, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	published, err := ioutil.ReadFile("adl.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(schema, published) {
+		t.Fatal("adl.schema.json is outdated, run go test ./arc/adl -run TestJSONSchema -update-schema")
+	}
+}
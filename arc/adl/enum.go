@@ -0,0 +1,61 @@
+package adl
+
+import "github.com/golangee/architecture/arc/token"
+
+// An Enum is a closed set of cases. If any case declares associated values, the enum becomes a tagged union,
+// whose cases carry their own fields.
+type Enum struct {
+	Comment token.String
+	Name    token.String
+	Cases   []*EnumCase
+}
+
+func NewEnum(name, comment string) *Enum {
+	return &Enum{
+		Comment: traceStr(comment),
+		Name:    traceStr(name),
+	}
+}
+
+func (e *Enum) AddCases(c ...*EnumCase) *Enum {
+	e.Cases = append(e.Cases, c...)
+	return e
+}
+
+// IsUnion returns true, if any case has associated values.
+func (e *Enum) IsUnion() bool {
+	for _, c := range e.Cases {
+		if len(c.Fields) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Enum) Normalize(ctx Ctx) {
+	for _, c := range e.Cases {
+		for _, field := range c.Fields {
+			field.Normalize(ctx)
+		}
+	}
+}
+
+// An EnumCase is a single case of an Enum with optional associated values.
+type EnumCase struct {
+	Comment token.String
+	Name    token.String
+	Fields  []*Field // the associated values.
+}
+
+func NewEnumCase(name, comment string) *EnumCase {
+	return &EnumCase{
+		Comment: traceStr(comment),
+		Name:    traceStr(name),
+	}
+}
+
+func (c *EnumCase) AddFields(f ...*Field) *EnumCase {
+	c.Fields = append(c.Fields, f...)
+	return c
+}
@@ -0,0 +1,42 @@
+package adl
+
+import (
+	"github.com/golangee/architecture/arc/token"
+	"strings"
+)
+
+// An Event is a domain event, which records that something relevant has happened. Events are raised by service
+// methods and are dispatched to all subscribed services.
+type Event struct {
+	Comment token.String
+	Name    token.String
+	Fields  []*Field // the payload of the event.
+}
+
+func NewEvent(name, comment string) *Event {
+	return &Event{
+		Comment: traceStr(comment),
+		Name:    traceStr(name),
+	}
+}
+
+func (e *Event) AddFields(f ...*Field) *Event {
+	e.Fields = append(e.Fields, f...)
+	return e
+}
+
+func (e *Event) Normalize(ctx Ctx) {
+	for _, field := range e.Fields {
+		field.Normalize(ctx)
+	}
+}
+
+// HandlerName returns the name of the service method, which handles the event with the given name. Qualified names
+// are reduced to the name of the event, so that the handlers of equally named events of different packages collide.
+func HandlerName(event string) string {
+	if pos := strings.LastIndex(event, "."); pos >= 0 {
+		event = event[pos+1:]
+	}
+
+	return "On" + event
+}
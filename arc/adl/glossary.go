@@ -0,0 +1,338 @@
+package adl
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/token"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// commonWords contains the technical and grammatical vocabulary, which is not part of any ubiquitous language and
+// is therefore valid in every identifier. Each Glossary may extend it, see Glossary.AddCommon.
+var commonWords = map[string]bool{
+	"a": true, "add": true, "added": true, "address": true, "after": true, "all": true, "already": true, "an": true,
+	"and": true, "as": true, "at": true, "before": true, "by": true, "can": true, "change": true, "changed": true,
+	"cfg": true, "comment": true, "config": true, "configuration": true, "context": true, "count": true,
+	"create": true, "created": true, "data": true, "date": true, "default": true, "delete": true, "deleted": true,
+	"description": true, "duration": true, "email": true, "error": true, "event": true, "exist": true, "file": true,
+	"find": true, "first": true, "for": true, "found": true, "from": true, "get": true, "handle": true, "has": true,
+	"host": true, "id": true, "in": true, "info": true, "invalid": true, "is": true, "it": true, "item": true,
+	"iterate": true, "key": true, "kind": true, "last": true, "limit": true, "list": true, "load": true, "max": true,
+	"min": true, "name": true, "new": true, "next": true, "not": true, "of": true, "offset": true, "on": true,
+	"or": true, "page": true, "path": true, "port": true, "put": true, "read": true, "remove": true, "removed": true,
+	"repository": true, "save": true, "service": true, "set": true, "size": true, "state": true, "status": true,
+	"store": true, "text": true, "time": true, "timestamp": true, "title": true, "to": true, "total": true,
+	"type": true, "update": true, "updated": true, "uri": true, "url": true, "uuid": true, "value": true,
+	"version": true, "with": true, "without": true,
+}
+
+// TermUsage denotes a type declaration of a bounded context, whose own name or member names use a glossary term.
+type TermUsage struct {
+	Package token.String // the name of the declaring package.
+	Type    token.String // the name of the declaring type.
+}
+
+// Lint checks the identifiers of the types, methods and fields of each BoundedContext against its ubiquitous
+// language, which consists of the project glossary and the glossary of the bounded context itself. Each identifier
+// is split into its camel case words and every word must either belong to a term or to the common technical
+// vocabulary. Forbidden synonyms are reported even if they are part of the common vocabulary. The result is
+// either nil or a *token.PosError whose details point to the offending identifiers.
+func Lint(prj *Project) error {
+	var details []token.ErrDetail
+	for _, module := range prj.Modules {
+		for _, bc := range module.BoundedContexts {
+			lang := newLanguage(prj.Glossary, bc.Glossary)
+			for _, term := range lang.undefined {
+				details = append(details, token.NewErrDetail(term, fmt.Sprintf("synonym '%s' belongs to an undefined term", term.String())))
+			}
+
+			walkIdentifiers(bc, func(pkg, typ, ident token.String) {
+				words := splitWords(ident.String())
+				for _, forbidden := range lang.forbidden {
+					if indexOf(words, forbidden.words) >= 0 {
+						details = append(details, token.NewErrDetail(ident, fmt.Sprintf("'%s' of identifier '%s' is a forbidden synonym of '%s'", forbidden.synonym.String(), ident.String(), forbidden.term)))
+					}
+				}
+
+				for _, word := range lang.unknown(words) {
+					details = append(details, token.NewErrDetail(ident, fmt.Sprintf("unknown domain term '%s' in identifier '%s'", word, ident.String())))
+				}
+			})
+		}
+	}
+
+	if len(details) == 0 {
+		return nil
+	}
+
+	return token.NewPosError(prj.Name, fmt.Sprintf("the architecture contains %d violations of the ubiquitous language", len(details)), details...)
+}
+
+// TermUsages returns for each glossary term, which is valid within the given bounded context, the types whose
+// names or member names use that term. The usages are sorted by package and type name.
+func TermUsages(prj *Project, bc *BoundedContext) map[string][]TermUsage {
+	lang := newLanguage(prj.Glossary, bc.Glossary)
+	res := map[string][]TermUsage{}
+	seen := map[string]bool{}
+	walkIdentifiers(bc, func(pkg, typ, ident token.String) {
+		words := splitWords(ident.String())
+		for _, term := range lang.terms {
+			key := term.key + "\x00" + pkg.String() + "\x00" + typ.String()
+			if seen[key] || indexOf(words, term.words) < 0 {
+				continue
+			}
+
+			seen[key] = true
+			res[term.key] = append(res[term.key], TermUsage{Package: pkg, Type: typ})
+		}
+	})
+
+	for _, usages := range res {
+		sort.Slice(usages, func(i, j int) bool {
+			if usages[i].Package.String() != usages[j].Package.String() {
+				return usages[i].Package.String() < usages[j].Package.String()
+			}
+
+			return usages[i].Type.String() < usages[j].Type.String()
+		})
+	}
+
+	return res
+}
+
+// phrase is a glossary term or synonym split into its normalized words.
+type phrase struct {
+	key     string       // the glossary key of the term.
+	term    string       // the identifier of the term.
+	synonym token.String // only set for forbidden synonyms.
+	words   []string
+}
+
+// language is the ubiquitous language of a bounded context.
+type language struct {
+	terms     []phrase
+	forbidden []phrase
+	undefined []token.String  // synonyms of terms, which have not been defined.
+	common    map[string]bool // the common words and those of the glossaries.
+}
+
+func newLanguage(glossaries ...*Glossary) *language {
+	lang := &language{common: map[string]bool{}}
+	for word := range commonWords {
+		lang.common[word] = true
+	}
+
+	for _, glossary := range glossaries {
+		if glossary == nil {
+			continue
+		}
+
+		for _, common := range glossary.Common {
+			for _, word := range splitWords(common.String()) {
+				lang.common[word] = true
+			}
+		}
+
+		keys := make([]string, 0, len(glossary.Terms))
+		for key := range glossary.Terms {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			term := glossary.Terms[key]
+			if term.Ident.String() == "" {
+				lang.undefined = append(lang.undefined, term.Synonyms...)
+				continue
+			}
+
+			lang.terms = append(lang.terms, phrase{key: key, term: term.Ident.String(), words: splitWords(term.Ident.String())})
+			for _, synonym := range term.Synonyms {
+				lang.forbidden = append(lang.forbidden, phrase{key: key, term: term.Ident.String(), synonym: synonym, words: splitWords(synonym.String())})
+			}
+		}
+	}
+
+	return lang
+}
+
+// unknown returns those words, which are neither covered by a term nor by the common vocabulary. Words of
+// forbidden synonyms are reported separately and are therefore never unknown.
+func (l *language) unknown(words []string) []string {
+	covered := make([]bool, len(words))
+	cover := func(phrases []phrase) {
+		for _, p := range phrases {
+			for offset := 0; offset < len(words); {
+				idx := indexOf(words[offset:], p.words)
+				if idx < 0 {
+					break
+				}
+
+				for i := range p.words {
+					covered[offset+idx+i] = true
+				}
+
+				offset += idx + len(p.words)
+			}
+		}
+	}
+
+	cover(l.terms)
+	cover(l.forbidden)
+
+	var res []string
+	for i, word := range words {
+		if !covered[i] && !l.common[word] {
+			res = append(res, word)
+		}
+	}
+
+	return res
+}
+
+// walkIdentifiers invokes the callback for the names of all types, methods and fields of the bounded context.
+func walkIdentifiers(bc *BoundedContext, f func(pkg, typ, ident token.String)) {
+	walkStruct := func(pkg token.String, s *Struct) {
+		f(pkg, s.Name, s.Name)
+		walkFields(s.Fields, func(field token.String) { f(pkg, s.Name, field) })
+		for _, method := range s.Methods {
+			f(pkg, s.Name, method.Name)
+		}
+	}
+
+	walkEntity := func(pkg token.String, e *Entity) {
+		walkStruct(pkg, e.Component)
+		if e.Identity != nil {
+			f(pkg, e.Component.Name, e.Identity.Name)
+		}
+	}
+
+	for _, p := range append(append([]*Package{}, bc.Core...), bc.Usecase...) {
+		for _, dto := range p.DTOs {
+			walkStruct(p.Name, dto)
+		}
+
+		for _, service := range p.Services {
+			walkStruct(p.Name, service.Component)
+		}
+
+		for _, e := range p.Events {
+			f(p.Name, e.Name, e.Name)
+			walkFields(e.Fields, func(field token.String) { f(p.Name, e.Name, field) })
+		}
+
+		for _, e := range p.Enums {
+			f(p.Name, e.Name, e.Name)
+		}
+
+		for _, e := range p.Errors {
+			f(p.Name, e.Name, e.Name)
+			walkFields(e.Fields, func(field token.String) { f(p.Name, e.Name, field) })
+		}
+
+		for _, aggregate := range p.Aggregates {
+			f(p.Name, aggregate.Name, aggregate.Name)
+			if aggregate.Root != nil {
+				walkEntity(p.Name, aggregate.Root)
+			}
+
+			for _, entity := range aggregate.Entities {
+				walkEntity(p.Name, entity)
+			}
+
+			for _, value := range aggregate.ValueObjects {
+				walkStruct(p.Name, value.Component)
+			}
+		}
+
+		for _, value := range p.ValueObjects {
+			walkStruct(p.Name, value.Component)
+		}
+
+		for _, repository := range p.Repositories {
+			f(p.Name, repository.Name, repository.Name)
+			for _, method := range repository.Methods {
+				f(p.Name, repository.Name, method.Name)
+			}
+		}
+	}
+}
+
+func walkFields(fields []*Field, f func(name token.String)) {
+	for _, field := range fields {
+		f(field.Name)
+	}
+}
+
+// splitWords splits the given identifier at camel case boundaries, digits and any other non-letters into lower
+// case and singular words, e.g. "TicketIDs" becomes [ticket id] and "HTTPServer" becomes [http server].
+func splitWords(ident string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, singular(strings.ToLower(string(word))))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(ident)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(word) > 0:
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// the trailing s of a plural abbreviation like IDs does not start a new word
+			pluralS := nextLower && runes[i+1] == 's' && (i+2 == len(runes) || !unicode.IsLower(runes[i+2]))
+			if prevLower || (nextLower && !pluralS) {
+				flush()
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	flush()
+
+	return words
+}
+
+// singular returns a naive singular form of the given lower case english word.
+func singular(word string) string {
+	switch {
+	case len(word) > 3 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 2 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// indexOf returns the first index of the sub sequence within words or -1.
+func indexOf(words, sub []string) int {
+	if len(sub) == 0 {
+		return -1
+	}
+
+	for i := 0; i+len(sub) <= len(words); i++ {
+		match := true
+		for j := range sub {
+			if words[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return i
+		}
+	}
+
+	return -1
+}
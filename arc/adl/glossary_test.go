@@ -0,0 +1,102 @@
+package adl
+
+import (
+	"errors"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/stdlib"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddErrors(NewError("TicketNotFound", "...is missing.")).
+		AddStructs(NewDTO("SupportTicket", "...is a ticket.").
+			AddFields(
+				NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)),
+				NewField("IssueIDs", "...are related tickets.", NewTypeDecl("[]", NewTypeDecl(stdlib.UUID))),
+				NewField("Wibble", "...is unknown.", NewTypeDecl(stdlib.String)),
+			)).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddMethods(NewMethod("FindByReporter", "...finds."))))
+
+	prj.PutGlossary("Support Ticket", "...is a support request.")
+	prj.Glossary.Forbid("Support Ticket", "Issue")
+	prj.Glossary.Forbid("Bug", "Defect")
+	prj.Modules[0].BoundedContexts[0].PutGlossary("Ticket", "...is the short form of a support ticket.")
+
+	wantErr := []string{
+		"synonym 'Defect' belongs to an undefined term",
+		"'Issue' of identifier 'IssueIDs' is a forbidden synonym of 'Support Ticket'",
+		"unknown domain term 'wibble' in identifier 'Wibble'",
+		"unknown domain term 'reporter' in identifier 'FindByReporter'",
+	}
+
+	var posErr *token.PosError
+	if err := Lint(prj); !errors.As(err, &posErr) {
+		t.Fatalf("expected a PosError but got %v", err)
+	}
+
+	details := posErr.Details[1:]
+	if len(details) != len(wantErr) {
+		t.Fatalf("expected %d details but got:\n%s", len(wantErr), token.Explain(posErr))
+	}
+
+	for i, want := range wantErr {
+		if details[i].Message != want {
+			t.Errorf("expected detail %d to be %q but got %q", i, want, details[i].Message)
+		}
+
+		if filepath.Base(details[i].Node.Begin().File) != "glossary_test.go" {
+			t.Errorf("expected detail %d to point into the test file but got %s", i, details[i].Node.Begin().String())
+		}
+	}
+
+	usages := TermUsages(prj, prj.Modules[0].BoundedContexts[0])
+	var got []string
+	for _, usage := range usages["Ticket"] {
+		got = append(got, usage.Type.String())
+	}
+
+	if want := []string{"SupportTicket", "TicketNotFound", "Tickets"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected usages %v but got %v", want, got)
+	}
+
+	if len(usages["Support Ticket"]) != 1 {
+		t.Errorf("expected a single usage of 'Support Ticket' but got %v", usages["Support Ticket"])
+	}
+}
+
+func TestLintCommonWords(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddStructs(NewDTO("Ticket", "...is a ticket.").
+			AddFields(NewField("ETags", "...are the entity tags.", NewTypeDecl(stdlib.String)))))
+
+	prj.PutGlossary("Ticket", "...is a support request.")
+	if err := Lint(prj); err == nil {
+		t.Fatal("expected an unknown domain term")
+	}
+
+	prj.Glossary.AddCommon("ETag")
+	if err := Lint(prj); err != nil {
+		t.Fatal(token.Explain(err))
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := map[string]string{
+		"TicketIDs":      "ticket id",
+		"HTTPServer":     "http server",
+		"findByReporter": "find by reporter",
+		"Status":         "status",
+		"categories_v2":  "category v",
+	}
+
+	for ident, want := range tests {
+		if got := strings.Join(splitWords(ident), " "); got != want {
+			t.Errorf("expected %q to be split into %q but got %q", ident, want, got)
+		}
+	}
+}
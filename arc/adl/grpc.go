@@ -0,0 +1,69 @@
+package adl
+
+import "github.com/golangee/architecture/arc/token"
+
+// The status codes of gRPC, named like the constants of google.golang.org/grpc/codes.
+const (
+	GrpcCanceled           = "Canceled"
+	GrpcUnknown            = "Unknown"
+	GrpcInvalidArgument    = "InvalidArgument"
+	GrpcDeadlineExceeded   = "DeadlineExceeded"
+	GrpcNotFound           = "NotFound"
+	GrpcAlreadyExists      = "AlreadyExists"
+	GrpcPermissionDenied   = "PermissionDenied"
+	GrpcResourceExhausted  = "ResourceExhausted"
+	GrpcFailedPrecondition = "FailedPrecondition"
+	GrpcAborted            = "Aborted"
+	GrpcOutOfRange         = "OutOfRange"
+	GrpcUnimplemented      = "Unimplemented"
+	GrpcInternal           = "Internal"
+	GrpcUnavailable        = "Unavailable"
+	GrpcDataLoss           = "DataLoss"
+	GrpcUnauthenticated    = "Unauthenticated"
+)
+
+// grpcCodes contains all valid names of status codes.
+var grpcCodes = map[string]bool{
+	GrpcCanceled: true, GrpcUnknown: true, GrpcInvalidArgument: true, GrpcDeadlineExceeded: true,
+	GrpcNotFound: true, GrpcAlreadyExists: true, GrpcPermissionDenied: true, GrpcResourceExhausted: true,
+	GrpcFailedPrecondition: true, GrpcAborted: true, GrpcOutOfRange: true, GrpcUnimplemented: true,
+	GrpcInternal: true, GrpcUnavailable: true, GrpcDataLoss: true, GrpcUnauthenticated: true,
+}
+
+// A GrpcEndpoint exposes a use case service method as an unary rpc of the according gRPC service. The
+// in-parameters become the fields of the request message and the results the fields of the response message.
+// A parameter of type context.Context is bound to the context of the call.
+type GrpcEndpoint struct {
+	Status []*GrpcStatus // status codes of the declared error cases. Unmapped cases result in FailedPrecondition.
+}
+
+// GrpcStatus maps a declared error case to a status code.
+type GrpcStatus struct {
+	Error token.String // the name of the error case, as declared by the method.
+	Code  token.String // the name of the status code, e.g. NotFound.
+}
+
+func NewGrpcEndpoint() *GrpcEndpoint {
+	return &GrpcEndpoint{}
+}
+
+// MapError responds the named error case with the named status code, e.g. GrpcNotFound.
+func (e *GrpcEndpoint) MapError(name, code string) *GrpcEndpoint {
+	e.Status = append(e.Status, &GrpcStatus{
+		Error: traceStr(name),
+		Code:  traceStr(code),
+	})
+
+	return e
+}
+
+// CodeOf returns the name of the status code of the named error case.
+func (e *GrpcEndpoint) CodeOf(name string) string {
+	for _, status := range e.Status {
+		if status.Error.String() == name {
+			return status.Code.String()
+		}
+	}
+
+	return GrpcFailedPrecondition
+}
@@ -0,0 +1,111 @@
+package adl
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/token"
+	"regexp"
+	"strings"
+)
+
+const (
+	HttpGet    = "GET"
+	HttpPost   = "POST"
+	HttpPut    = "PUT"
+	HttpPatch  = "PATCH"
+	HttpDelete = "DELETE"
+)
+
+// An HttpEndpoint exposes a use case service method as a REST endpoint. Each in-parameter is bound either by a
+// {name} segment of the path template, by a query value or by the JSON request body. A parameter of type
+// context.Context is bound to the requests context.
+type HttpEndpoint struct {
+	Verb   token.String   // one of HttpGet, HttpPost, HttpPut, HttpPatch or HttpDelete.
+	Path   token.String   // path template like /tickets/{id}.
+	Query  []token.String // names of in-parameters, which are bound to query values.
+	Body   token.String   // optional name of the in-parameter, which is decoded from the JSON request body.
+	Status []*HttpStatus  // status codes of the declared error cases. Unmapped cases result in 422.
+}
+
+// HttpStatus maps a declared error case to a status code.
+type HttpStatus struct {
+	Error token.String // the name of the error case, as declared by the method.
+	Code  int          // the status code, e.g. 404.
+}
+
+func NewHttpEndpoint(verb, path string) *HttpEndpoint {
+	return &HttpEndpoint{
+		Verb: traceStr(verb),
+		Path: traceStr(path),
+	}
+}
+
+// BindQuery binds the named in-parameters to the according query values.
+func (e *HttpEndpoint) BindQuery(names ...string) *HttpEndpoint {
+	for _, name := range names {
+		e.Query = append(e.Query, traceStr(name))
+	}
+
+	return e
+}
+
+// BindBody binds the named in-parameter to the JSON request body.
+func (e *HttpEndpoint) BindBody(name string) *HttpEndpoint {
+	e.Body = traceStr(name)
+	return e
+}
+
+// MapError responds the named error case with the given status code.
+func (e *HttpEndpoint) MapError(name string, code int) *HttpEndpoint {
+	e.Status = append(e.Status, &HttpStatus{
+		Error: traceStr(name),
+		Code:  code,
+	})
+
+	return e
+}
+
+// StatusOf returns the status code of the named error case.
+func (e *HttpEndpoint) StatusOf(name string) int {
+	for _, status := range e.Status {
+		if status.Error.String() == name {
+			return status.Code
+		}
+	}
+
+	return 422
+}
+
+// pathVarPattern matches a {name} segment of a path template.
+var pathVarPattern = regexp.MustCompile(`{[^/{}]*}`)
+
+// PathVars returns the names of all {name} segments of the path template, each positioned at the path template.
+func (e *HttpEndpoint) PathVars() ([]token.String, error) {
+	path := e.Path.String()
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path must start with '/'")
+	}
+
+	var res []token.String
+	names := map[string]bool{}
+	for _, segment := range strings.Split(path[1:], "/") {
+		if !strings.ContainsAny(segment, "{}") {
+			continue
+		}
+
+		if len(segment) < 3 || pathVarPattern.FindString(segment) != segment {
+			return nil, fmt.Errorf("invalid path segment '%s'", segment)
+		}
+
+		name := segment[1 : len(segment)-1]
+		if names[name] {
+			return nil, fmt.Errorf("duplicate path variable '%s'", name)
+		}
+
+		names[name] = true
+		v := e.Path
+		v.Val = name
+		res = append(res, v)
+	}
+
+	return res, nil
+}
@@ -12,6 +12,7 @@ type Project struct {
 	Comment  token.String
 	Modules  []*Module
 	Glossary *Glossary
+	Lint     bool // Lint rejects all identifiers, which violate the ubiquitous language, when rendering.
 }
 
 func NewProject(name, comment string) *Project {
@@ -34,16 +35,28 @@ func (w *Project) PutGlossary(key, val string) *Project {
 	return w
 }
 
+// SetLint enables checking the identifiers of all bounded contexts against their ubiquitous language, see Lint.
+func (w *Project) SetLint(lint bool) *Project {
+	w.Lint = lint
+	return w
+}
+
 func (w *Project) AddModules(p ...*Module) *Project {
 	w.Modules = append(w.Modules, p...)
 	return w
 }
 
+const (
+	ExecutableServer = "server"
+	ExecutableCli    = "cli"
+)
+
 // An Executable defines an entry point into the application. At least Java and Go support an arbitrary set of
 // main entry points.
 type Executable struct {
 	Comment             token.String
 	Name                token.String
+	Kind                token.String // either ExecutableServer (the default) or ExecutableCli.
 	BoundedContextPaths []token.String
 }
 
@@ -60,6 +73,18 @@ func (e *Executable) Normalize(ctx Ctx) {
 	}
 }
 
+// SetKind declares, how the executable drives the use cases. A server runs its driver adapters, like http or
+// grpc, until it is stopped. A cli exposes each use case service method as a subcommand, which is executed once.
+func (e *Executable) SetKind(kind string) *Executable {
+	e.Kind = traceStr(kind)
+	return e
+}
+
+// IsCli returns true, if the use cases are exposed as subcommands.
+func (e *Executable) IsCli() bool {
+	return e.Kind.String() == ExecutableCli
+}
+
 func (e *Executable) Application(paths ...string) *Executable {
 	for _, path := range paths {
 		e.BoundedContextPaths = append(e.BoundedContextPaths, traceStr(path))
@@ -94,7 +119,7 @@ func (p *Module) AddExecutables(e ...*Executable) *Module {
 }
 
 func (p *Module) SetLicense(str string) *Module {
-	p.Preamble.License = str
+	p.Preamble.License = traceStr(str)
 	return p
 }
 
@@ -110,8 +135,9 @@ func (p *Module) AddBoundedContexts(d ...*BoundedContext) *Module {
 
 // A Generator describes how this project should be generated.
 type Generator struct {
-	Go     *Golang
-	OutDir token.String // the target directory to (re) write the module
+	Go         *Golang
+	TypeScript *TypeScript  // optional frontend models and client of the exposed use cases.
+	OutDir     token.String // the target directory to (re) write the module
 }
 
 func NewGenerator() *Generator {
@@ -123,6 +149,11 @@ func (g *Generator) SetGo(d *Golang) *Generator {
 	return g
 }
 
+func (g *Generator) SetTypeScript(t *TypeScript) *Generator {
+	g.TypeScript = t
+	return g
+}
+
 func (g *Generator) SetOutDir(dir string) *Generator {
 	g.OutDir = traceStr(dir)
 	return g
@@ -135,9 +166,10 @@ type GoDist struct {
 
 // Golang describes how a Go project (or module) must be created or updated.
 type Golang struct {
-	Module   token.String // the name of the go module, e.g. github.com/worldiety/supportiety
-	GoDist   []*GoDist
-	Requires []token.String // require directives
+	Module    token.String // the name of the go module, e.g. github.com/worldiety/supportiety
+	GoDist    []*GoDist
+	Requires  []token.String // require directives
+	ProtoLock token.String   // the file which keeps the protobuf field numbers stable, relative like the out dir.
 }
 
 func NewGolang() *Golang {
@@ -163,13 +195,43 @@ func (g *Golang) Require(dep string) *Golang {
 	return g
 }
 
+// SetProtoLock sets the file, which records the numbers of all protobuf fields ever generated. It is read and
+// rewritten by each generation and should be kept next to the ADL file under version control.
+func (g *Golang) SetProtoLock(filename string) *Golang {
+	g.ProtoLock = traceStr(filename)
+	return g
+}
+
+// TypeScript describes how the TypeScript models and the fetch based client of a module must be created or
+// updated.
+type TypeScript struct {
+	OutDir token.String // the target directory to (re) write the sources
+}
+
+func NewTypeScript() *TypeScript {
+	return &TypeScript{}
+}
+
+func (t *TypeScript) SetOutDir(dir string) *TypeScript {
+	t.OutDir = traceStr(dir)
+	return t
+}
+
 // A BoundedContext is a cross-cutting thing, which is referenced from various places and contains its own
 // ubiquitous language (== glossary).
 type BoundedContext struct {
-	Name    token.String
-	Path    token.String
-	Core    []*Package // multiple core packages are allowed, to allow arbitrary large and complex nested (sub or supporting) domains.
-	Usecase []*Package // same for the use cases
+	Name     token.String
+	Path     token.String
+	Core     []*Package // multiple core packages are allowed, to allow arbitrary large and complex nested (sub or supporting) domains.
+	Usecase  []*Package // same for the use cases
+	Glossary *Glossary  // optional terms, which only belong to the ubiquitous language of this context.
+	Bindings []*Binding // explicit implementations of injected interfaces.
+}
+
+// A Binding declares the implementation, which is injected wherever the interface is required.
+type Binding struct {
+	Interface      token.String // full qualified name of the injected interface.
+	Implementation token.String // full qualified name of the implementing struct.
 }
 
 func NewBoundedContext(name, path string) *BoundedContext {
@@ -179,6 +241,20 @@ func NewBoundedContext(name, path string) *BoundedContext {
 	}
 }
 
+// PutGlossary defines a term of the ubiquitous language, which is only valid within this bounded context.
+func (b *BoundedContext) PutGlossary(key, val string) *BoundedContext {
+	if b.Glossary == nil {
+		b.Glossary = NewGlossary()
+	}
+
+	b.Glossary.Terms[key] = Term{
+		Ident:       traceStr(key),
+		Description: traceStr(val),
+	}
+
+	return b
+}
+
 const (
 	nMOD = "$MOD"
 	nBC  = "$BC"
@@ -190,8 +266,14 @@ type Ctx struct {
 }
 
 func (c Ctx) applyToken(t *token.String) {
-	t.Val = strings.ReplaceAll(t.Val, nMOD, c.Mod)
-	t.Val = strings.ReplaceAll(t.Val, nBC, c.BC)
+	t.Val = c.applyString(t.Val)
+}
+
+func (c Ctx) applyString(s string) string {
+	s = strings.ReplaceAll(s, nMOD, c.Mod)
+	s = strings.ReplaceAll(s, nBC, c.BC)
+
+	return s
 }
 
 // Normalize rewrites all path like things.
@@ -206,6 +288,23 @@ func (d *BoundedContext) Normalize(ctx Ctx) {
 	for _, p := range d.Usecase {
 		p.Normalize(ctx)
 	}
+
+	for _, b := range d.Bindings {
+		ctx.applyToken(&b.Interface)
+		ctx.applyToken(&b.Implementation)
+	}
+}
+
+// Bind declares the implementation of an interface, which is injected into the services of all executables
+// which contain this bounded context. An interface with a single generated implementation, like an in-memory
+// repository, is bound implicitly.
+func (d *BoundedContext) Bind(iface, implementation string) *BoundedContext {
+	d.Bindings = append(d.Bindings, &Binding{
+		Interface:      traceStr(iface),
+		Implementation: traceStr(implementation),
+	})
+
+	return d
 }
 
 func (d *BoundedContext) AddCore(l ...*Package) *BoundedContext {
@@ -225,6 +324,10 @@ type Package struct {
 	Services     []*Service
 	DTOs         []*Struct
 	Errors       []*Error
+	Aggregates   []*Aggregate
+	ValueObjects []*ValueObject // value objects which are shared between aggregates.
+	Events       []*Event
+	Enums        []*Enum
 }
 
 func NewPackage(name, comment string) *Package {
@@ -251,6 +354,26 @@ func (p *Package) AddServices(s ...*Service) *Package {
 	return p
 }
 
+func (p *Package) AddAggregates(a ...*Aggregate) *Package {
+	p.Aggregates = append(p.Aggregates, a...)
+	return p
+}
+
+func (p *Package) AddValueObjects(v ...*ValueObject) *Package {
+	p.ValueObjects = append(p.ValueObjects, v...)
+	return p
+}
+
+func (p *Package) AddEvents(e ...*Event) *Package {
+	p.Events = append(p.Events, e...)
+	return p
+}
+
+func (p *Package) AddEnums(e ...*Enum) *Package {
+	p.Enums = append(p.Enums, e...)
+	return p
+}
+
 func (p *Package) Normalize(ctx Ctx) {
 	for _, service := range p.Services {
 		service.Normalize(ctx)
@@ -267,6 +390,94 @@ func (p *Package) Normalize(ctx Ctx) {
 	for _, e := range p.Errors {
 		e.Normalize(ctx)
 	}
+
+	for _, a := range p.Aggregates {
+		a.Normalize(ctx)
+	}
+
+	for _, v := range p.ValueObjects {
+		v.Normalize(ctx)
+	}
+
+	for _, e := range p.Events {
+		e.Normalize(ctx)
+	}
+
+	for _, e := range p.Enums {
+		e.Normalize(ctx)
+	}
+}
+
+// AllErrors returns the declared errors followed by the error cases, which are generated for the cruds of the
+// repositories. The cases of an entity are shared by all of its repositories and carry its identity as id.
+func (p *Package) AllErrors() []*Error {
+	res := append([]*Error{}, p.Errors...)
+	seen := map[string]bool{}
+	for _, e := range p.Errors {
+		seen[e.Name.String()] = true
+	}
+
+	for _, repository := range p.Repositories {
+		for _, crud := range repository.CRUDs {
+			if crud.EntityType == nil {
+				continue
+			}
+
+			entity := crud.entityName()
+			notFound, alreadyExists := crud.ErrorNames()
+			for _, c := range []struct{ name, comment string }{
+				{notFound, "...indicates that no " + entity + " with the according id exists."},
+				{alreadyExists, "...indicates that a " + entity + " with the according id exists already."},
+			} {
+				if seen[c.name] {
+					continue
+				}
+
+				seen[c.name] = true
+				e := &Error{Name: crud.EntityType.Name, Comment: crud.EntityType.Name}
+				e.Name.Val = c.name
+				e.Comment.Val = c.comment
+				if id := p.identityOf(crud); id != nil {
+					e.Fields = append(e.Fields, NewField("id", "...is the identity of the entity.", id))
+				}
+
+				res = append(res, e)
+			}
+		}
+	}
+
+	return res
+}
+
+// identityOf returns the type of the identity of the crud entity or nil, if the entity is not declared by the
+// package.
+func (p *Package) identityOf(crud *CRUD) *TypeDecl {
+	if crud.IDType != nil {
+		return crud.IDType
+	}
+
+	name := crud.entityName()
+	for _, a := range p.Aggregates {
+		for _, entity := range append([]*Entity{a.Root}, a.Entities...) {
+			if entity != nil && entity.Identity != nil && entity.Component.Name.String() == name {
+				return entity.Identity.Type
+			}
+		}
+	}
+
+	for _, dto := range p.DTOs {
+		if dto.Name.String() != name {
+			continue
+		}
+
+		for _, field := range dto.Fields {
+			if field.Name.String() == "ID" {
+				return field.Type
+			}
+		}
+	}
+
+	return nil
 }
 
 type PersistenceType string
@@ -291,6 +502,47 @@ func NewCRUD(entityType *TypeDecl, IDType *TypeDecl, persistence PersistenceType
 	return &CRUD{EntityType: entityType, IDType: IDType, Persistence: persistence, InsertOne: createOne, FindOne: findOne, UpdateOne: updateOne, DeleteOne: deleteOne, CountAll: countAll, FindAll: findAll, IterateAll: iterateAll}
 }
 
+// Operations returns the names of the enabled operations, which are declared by the repository interface.
+func (i *CRUD) Operations() []string {
+	var res []string
+	for _, op := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"InsertOne", i.InsertOne},
+		{"FindOne", i.FindOne},
+		{"UpdateOne", i.UpdateOne},
+		{"DeleteOne", i.DeleteOne},
+		{"CountAll", i.CountAll},
+		{"FindAll", i.FindAll},
+		{"IterateAll", i.IterateAll},
+	} {
+		if op.enabled {
+			res = append(res, op.name)
+		}
+	}
+
+	return res
+}
+
+// ErrorNames returns the names of the error cases, which are declared for the entity in the sum type of the
+// package.
+func (i *CRUD) ErrorNames() (notFound, alreadyExists string) {
+	name := i.entityName()
+
+	return name + "NotFound", name + "AlreadyExists"
+}
+
+// entityName returns the unqualified name of the entity type.
+func (i *CRUD) entityName() string {
+	name := i.EntityType.Name.String()
+	if pos := strings.LastIndex(name, "."); pos >= 0 {
+		name = name[pos+1:]
+	}
+
+	return name
+}
+
 func (i *CRUD) Normalize(ctx Ctx) {
 	i.EntityType.Normalize(ctx)
 	if i.IDType != nil {
@@ -333,7 +585,9 @@ func (i *Interface) AddMethods(m ...*Method) *Interface {
 }
 
 type Service struct {
-	Component *Struct
+	Component     *Struct
+	Subscriptions []*TypeDecl // events which are handled by this service.
+	Lifecycle     bool        // Lifecycle declares the Start and Stop hooks, which are invoked by the application.
 }
 
 func NewService(name, comment string) *Service {
@@ -348,6 +602,9 @@ func NewService(name, comment string) *Service {
 
 func (s *Service) Normalize(ctx Ctx) {
 	s.Component.Normalize(ctx)
+	for _, decl := range s.Subscriptions {
+		decl.Normalize(ctx)
+	}
 }
 
 func (s *Service) AddFields(f ...*Field) *Service {
@@ -368,6 +625,21 @@ func (s *Service) AddInjections(m ...*Injection) *Service {
 	return s
 }
 
+// AddSubscriptions declares that the service handles the given events.
+func (s *Service) AddSubscriptions(events ...*TypeDecl) *Service {
+	s.Subscriptions = append(s.Subscriptions, events...)
+
+	return s
+}
+
+// SetLifecycle declares that the service is started and stopped together with the application. The services
+// are started in the order of their dependencies and stopped in reverse order.
+func (s *Service) SetLifecycle(lifecycle bool) *Service {
+	s.Lifecycle = lifecycle
+
+	return s
+}
+
 const (
 	// DTO is a data transfer stereotype.
 	DTO = "dto"
@@ -377,13 +649,19 @@ const (
 
 	// ServiceComponent is a service stereotype.
 	ServiceComponent = "service"
+
+	// LifecycleStart is the name of the hook, which starts a lifecycle service.
+	LifecycleStart = "Start"
+
+	// LifecycleStop is the name of the hook, which stops a lifecycle service.
+	LifecycleStop = "Stop"
 )
 
 type Injection struct {
 	Comment    token.String // why is this needed?
 	Name       token.String // usually the field name, but also as a reference/documentation name
 	Stereotype token.String // optional stereotype to indicate the type.
-	Type       *TypeDecl
+	Type       *TypeDecl    // a configuration, an interface or a pointer to a service.
 }
 
 func NewInjection(name, comment, stereotype string, typ *TypeDecl) *Injection {
@@ -444,11 +722,19 @@ func NewStruct(name, comment, stereotype string) *Struct {
 }
 
 func NewDTO(name, comment string) *Struct {
-	return NewStruct(name, comment, DTO)
+	return &Struct{
+		Comment:    traceStr(comment),
+		Name:       traceStr(name),
+		Stereotype: traceStr(DTO),
+	}
 }
 
 func NewConfig(name, comment string) *Struct {
-	return NewStruct(name, comment, Cfg)
+	return &Struct{
+		Comment:    traceStr(comment),
+		Name:       traceStr(name),
+		Stereotype: traceStr(Cfg),
+	}
 }
 
 func (d *Struct) Normalize(ctx Ctx) {
@@ -477,6 +763,7 @@ type Field struct {
 	Type           *TypeDecl
 	Private        bool
 	CfgCmdLineFlag bool
+	Constraints    []*Constraint // Constraints are checked by the generated Validate method of the declaring type.
 }
 
 func NewPrivateField(name, comment string, decl *TypeDecl) *Field {
@@ -503,6 +790,12 @@ func (f *Field) SetCfgCmdLineFlag(flag bool) *Field {
 	return f
 }
 
+// AddConstraints restricts the valid values of this field.
+func (f *Field) AddConstraints(c ...*Constraint) *Field {
+	f.Constraints = append(f.Constraints, c...)
+	return f
+}
+
 func (f *Field) Normalize(ctx Ctx) {
 	f.Type.Normalize(ctx)
 }
@@ -512,8 +805,11 @@ type Method struct {
 	Name        token.String
 	In          []*Param
 	Out         []*Param
-	Errors      []*TypeDecl // sum type of error types. Non-qualified names are always resolved to package local declarations or fail.
-	StubDefault bool        // StubDefault instructs the generator to emit an abstract or default implementation
+	Errors      []*TypeDecl   // sum type of error types. Non-qualified names are always resolved to package local declarations or fail.
+	Events      []*TypeDecl   // events which may be raised. Non-qualified names are resolved like errors.
+	StubDefault bool          // StubDefault instructs the generator to emit an abstract or default implementation
+	Http        *HttpEndpoint // optional REST exposition of a use case service method.
+	Grpc        *GrpcEndpoint // optional gRPC exposition of a use case service method.
 }
 
 func NewMethod(name, comment string) *Method {
@@ -536,6 +832,22 @@ func (m *Method) Normalize(ctx Ctx) {
 	for _, decl := range m.Errors {
 		decl.Normalize(ctx)
 	}
+
+	for _, decl := range m.Events {
+		decl.Normalize(ctx)
+	}
+
+	if m.Http != nil {
+		for _, status := range m.Http.Status {
+			ctx.applyToken(&status.Error)
+		}
+	}
+
+	if m.Grpc != nil {
+		for _, status := range m.Grpc.Status {
+			ctx.applyToken(&status.Error)
+		}
+	}
 }
 
 func (m *Method) AddIn(name, comment string, decl *TypeDecl) *Method {
@@ -563,6 +875,24 @@ func (m *Method) AddErrors(names ...*TypeDecl) *Method {
 	return m
 }
 
+// SetHttp exposes the method as a REST endpoint.
+func (m *Method) SetHttp(e *HttpEndpoint) *Method {
+	m.Http = e
+	return m
+}
+
+// SetGrpc exposes the method as an unary rpc.
+func (m *Method) SetGrpc(e *GrpcEndpoint) *Method {
+	m.Grpc = e
+	return m
+}
+
+// AddEvents declares the events which may be raised by this method.
+func (m *Method) AddEvents(events ...*TypeDecl) *Method {
+	m.Events = append(m.Events, events...)
+	return m
+}
+
 func (m *Method) OutParams(p ...*Param) *Method {
 	m.In = p
 	return m
@@ -595,10 +925,12 @@ func traceStr(v string) token.String {
 type Term struct {
 	Ident       token.String
 	Description token.String
+	Synonyms    []token.String // forbidden synonyms, which must be expressed by the Ident instead.
 }
 
 type Glossary struct {
-	Terms map[string]Term
+	Terms  map[string]Term
+	Common []token.String // additional technical vocabulary, which is valid in every identifier.
 }
 
 func NewGlossary() *Glossary {
@@ -620,9 +952,31 @@ func (g *Glossary) Put(key, val string) *Glossary {
 	return g
 }
 
+// Forbid declares synonyms of an already defined term, which must not be used within any identifier.
+func (g *Glossary) Forbid(key string, synonyms ...string) *Glossary {
+	term := g.Terms[key]
+	for _, synonym := range synonyms {
+		term.Synonyms = append(term.Synonyms, traceStr(synonym))
+	}
+
+	g.Terms[key] = term
+
+	return g
+}
+
+// AddCommon extends the technical vocabulary, which is not part of the ubiquitous language but is valid in every
+// identifier.
+func (g *Glossary) AddCommon(words ...string) *Glossary {
+	for _, word := range words {
+		g.Common = append(g.Common, traceStr(word))
+	}
+
+	return g
+}
+
 type Preamble struct {
 	Generator string
-	License   string
+	License   token.String
 }
 
 // A TypeDecl is the generified declaration of named types, consisting of full qualified name, a pointer flag
@@ -646,6 +1000,33 @@ func (t *TypeDecl) Normalize(ctx Ctx) {
 	}
 }
 
+// String returns a Go like notation of the declaration, e.g. *Ticket, []string!, map![string!]int! or List[T].
+func (t *TypeDecl) String() string {
+	if t == nil {
+		return ""
+	}
+
+	switch {
+	case t.IsPtr():
+		return "*" + t.TypeParams[0].String()
+	case t.IsSlice():
+		return "[]" + t.TypeParams[0].String()
+	case t.IsArray():
+		return "[" + t.TypeParams[0].Name.String() + "]" + t.TypeParams[1].String()
+	case t.IsMap():
+		return "map![" + t.TypeParams[0].String() + "]" + t.TypeParams[1].String()
+	case len(t.TypeParams) == 0:
+		return t.Name.String()
+	}
+
+	var params []string
+	for _, param := range t.TypeParams {
+		params = append(params, param.String())
+	}
+
+	return t.Name.String() + "[" + strings.Join(params, ", ") + "]"
+}
+
 // IsPtr checks for * and expects exactly 1 TypeParam.
 func (t *TypeDecl) IsPtr() bool {
 	return t.Name.String() == "*" && len(t.TypeParams) == 1
@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"github.com/golangee/architecture/arc/token"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+type kind int
+
+const (
+	kEOF kind = iota
+	kIdent
+	kString
+	kLBrace
+	kRBrace
+	kLParen
+	kRParen
+	kLBrack
+	kRBrack
+	kLess
+	kGreater
+	kComma
+	kStar
+)
+
+func (k kind) String() string {
+	switch k {
+	case kEOF:
+		return "end of file"
+	case kIdent:
+		return "identifier"
+	case kString:
+		return "string"
+	case kLBrace:
+		return "'{'"
+	case kRBrace:
+		return "'}'"
+	case kLParen:
+		return "'('"
+	case kRParen:
+		return "')'"
+	case kLBrack:
+		return "'['"
+	case kRBrack:
+		return "']'"
+	case kLess:
+		return "'<'"
+	case kGreater:
+		return "'>'"
+	case kComma:
+		return "','"
+	case kStar:
+		return "'*'"
+	default:
+		return "unknown token"
+	}
+}
+
+// A lexeme is a classified token with its (unquoted) value and exact source position.
+type lexeme struct {
+	kind kind
+	str  token.String
+}
+
+var punctuation = map[rune]kind{
+	'{': kLBrace,
+	'}': kRBrace,
+	'(': kLParen,
+	')': kRParen,
+	'[': kLBrack,
+	']': kRBrack,
+	'<': kLess,
+	'>': kGreater,
+	',': kComma,
+	'*': kStar,
+}
+
+// lexer splits the source into lexemes and tracks the byte offset, line and column of each of them.
+type lexer struct {
+	buf []byte
+	pos token.Pos
+}
+
+func newLexer(filename string, buf []byte) *lexer {
+	return &lexer{
+		buf: buf,
+		pos: token.Pos{File: filename, Offset: 0, Line: 1, Col: 1},
+	}
+}
+
+// isIdentRune returns true for all runes which may be part of an identifier, which includes
+// qualified type names like $BC/core.Ticket, stdlib types like uuid! or names like supportiety-srv.
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' || r == '.' || r == '/' || r == '!' || r == '-'
+}
+
+func (l *lexer) peekRune() (rune, int) {
+	if l.pos.Offset >= len(l.buf) {
+		return utf8.RuneError, 0
+	}
+
+	return utf8.DecodeRune(l.buf[l.pos.Offset:])
+}
+
+func (l *lexer) advance() rune {
+	r, size := l.peekRune()
+	l.pos.Offset += size
+	if r == '\n' {
+		l.pos.Line++
+		l.pos.Col = 1
+	} else {
+		l.pos.Col++
+	}
+
+	return r
+}
+
+// all returns all lexemes including the terminating kEOF.
+func (l *lexer) all() ([]lexeme, error) {
+	var res []lexeme
+	for {
+		lex, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, lex)
+		if lex.kind == kEOF {
+			return res, nil
+		}
+	}
+}
+
+func (l *lexer) next() (lexeme, error) {
+	if err := l.skipWhitespaceAndComments(); err != nil {
+		return lexeme{}, err
+	}
+
+	begin := l.pos
+	r, size := l.peekRune()
+	switch {
+	case size == 0:
+		return lexeme{kind: kEOF, str: l.str("", begin, begin)}, nil
+	case punctuation[r] != kEOF:
+		l.advance()
+		return lexeme{kind: punctuation[r], str: l.str(string(r), begin, l.pos)}, nil
+	case r == '"':
+		return l.quotedString()
+	case r == '`':
+		return l.rawString()
+	case isIdentRune(r):
+		for {
+			r, size := l.peekRune()
+			if size == 0 || !isIdentRune(r) {
+				break
+			}
+
+			l.advance()
+		}
+
+		return lexeme{kind: kIdent, str: l.str(string(l.buf[begin.Offset:l.pos.Offset]), begin, l.pos)}, nil
+	default:
+		end := begin
+		end.Col++
+		return lexeme{}, token.NewPosError(l.str(string(r), begin, end), "unexpected character "+strconv.QuoteRune(r))
+	}
+}
+
+func (l *lexer) skipWhitespaceAndComments() error {
+	for {
+		r, size := l.peekRune()
+		switch {
+		case size == 0:
+			return nil
+		case unicode.IsSpace(r):
+			l.advance()
+		case r == '/' && l.pos.Offset+1 < len(l.buf) && l.buf[l.pos.Offset+1] == '/':
+			for {
+				r, size := l.peekRune()
+				if size == 0 || r == '\n' {
+					break
+				}
+
+				l.advance()
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// quotedString reads an interpreted string literal. The position of the value excludes the quotes.
+func (l *lexer) quotedString() (lexeme, error) {
+	quoteBegin := l.pos
+	l.advance()
+	begin := l.pos
+	for {
+		r, size := l.peekRune()
+		if size == 0 || r == '\n' {
+			return lexeme{}, token.NewPosError(l.str("\"", quoteBegin, begin), "string literal not terminated")
+		}
+
+		if r == '\\' {
+			l.advance()
+			l.advance()
+			continue
+		}
+
+		if r == '"' {
+			break
+		}
+
+		l.advance()
+	}
+
+	end := l.pos
+	l.advance()
+
+	val, err := strconv.Unquote(string(l.buf[quoteBegin.Offset:l.pos.Offset]))
+	if err != nil {
+		return lexeme{}, token.NewPosError(l.str(string(l.buf[begin.Offset:end.Offset]), begin, end), "invalid string literal").SetCause(err)
+	}
+
+	return lexeme{kind: kString, str: l.str(val, begin, end)}, nil
+}
+
+// rawString reads a back quoted raw string literal, which may span multiple lines.
+func (l *lexer) rawString() (lexeme, error) {
+	quoteBegin := l.pos
+	l.advance()
+	begin := l.pos
+	for {
+		r, size := l.peekRune()
+		if size == 0 {
+			return lexeme{}, token.NewPosError(l.str("`", quoteBegin, begin), "raw string literal not terminated")
+		}
+
+		if r == '`' {
+			break
+		}
+
+		l.advance()
+	}
+
+	end := l.pos
+	l.advance()
+
+	return lexeme{kind: kString, str: l.str(string(l.buf[begin.Offset:end.Offset]), begin, end)}, nil
+}
+
+func (l *lexer) str(val string, begin, end token.Pos) token.String {
+	s := token.NewString(val)
+	s.BeginPos = begin
+	s.EndPos = end
+
+	return s
+}
@@ -0,0 +1,1448 @@
+// Package parser reads the textual architecture description language (*.adl files) into an adl.Project.
+// Contrary to the fluent Go DSL, each token.String carries the exact file, line, column and byte offset
+// of its origin, so that errors can be explained precisely. Line comments start with //, strings are
+// either double quoted (Go escapes) or back quoted (raw and multi line). The grammar is as follows:
+//
+//	File        = "project" Ident String { Glossary | Module } .
+//	Glossary    = "glossary" [ "lint" ] "{" { (Ident | String) String | "common" Ident { "," Ident } } "}" .
+//	Module      = "module" Ident String "{" { ModuleDecl } "}" .
+//	ModuleDecl  = "license" String
+//	            | "generator" "{" { "out" String | GoGenerator | TSGenerator } "}"
+//	            | "executable" Ident String [ "{" { "application" String | "kind" String } "}" ]
+//	            | "context" Ident String "{" { Layer | Binding } "}" .
+//	GoGenerator = "go" "{" { "module" String | "require" String | "dist" Ident Ident | "protolock" String } "}" .
+//	TSGenerator = "typescript" "{" { "out" String } "}" .
+//	Layer       = ( "core" | "usecase" ) [ Ident ] [ String ] "{" { PackageDecl } "}" .
+//	Binding     = "bind" Ident Ident .
+//	PackageDecl = "error" Ident String [ "{" { Field } "}" ]
+//	            | ( "dto" | "config" ) Ident String [ StructBody ]
+//	            | "service" [ "lifecycle" ] Ident String [ StructBody ]
+//	            | "event" Ident String [ "{" { Field } "}" ]
+//	            | "enum" Ident String "{" { "case" Ident String [ "{" { Field } "}" ] } "}"
+//	            | "struct" Ident Ident String [ StructBody ]
+//	            | "repository" Ident String [ "{" { Method | CRUD } "}" ]
+//	            | "aggregate" Ident String "{" { ( "root" | "entity" ) Entity | "value" ValueObject } "}"
+//	            | "value" ValueObject .
+//	Entity      = Ident String [ "{" { "id" Ident Type String [ "{" { Constraint } "}" ] | Field | Invariant } "}" ] .
+//	ValueObject = Ident String [ "{" { Field | Invariant } "}" ] .
+//	Invariant   = "invariant" Ident String .
+//	StructBody  = "{" { Field | Method | Injection | "subscribe" Type } "}" .
+//	Field       = "field" [ "private" ] [ "flag" ] Ident Type String [ "{" { Constraint } "}" ] .
+//	Constraint  = "required"
+//	            | ( "min" | "max" | "minLength" | "maxLength" | "pattern" ) Literal
+//	            | "oneOf" Literal { "," Literal }
+//	            | "predicate" Ident String .
+//	Literal     = Ident | String .
+//	Method      = "func" Ident String [ "{" { MethodDecl } "}" ] .
+//	MethodDecl  = "in" Ident Type String | "out" [ Ident ] Type String | "error" Type | "raise" Type | "nostub"
+//	            | "http" Ident String [ "{" { "query" Ident { "," Ident } | "body" Ident | "status" Ident Ident } "}" ]
+//	            | "grpc" [ "{" { "status" Ident Ident } "}" ] .
+//	Injection   = "inject" Ident Type String [ "as" Ident ] .
+//	CRUD        = "crud" Type [ "id" Type ] Ident "{" { Ident } "}" .
+//	Type        = "*" Type
+//	            | "[" [ Ident ] "]" Type
+//	            | "map" "[" Type "]" Type
+//	            | Ident [ "<" Type { "," Type } ">" ] .
+//
+// The struct declaration expects the stereotype first. Only services may subscribe to events. The identifier of a crud declaration denotes the
+// persistence type (in-memory, file or mysql) and the block lists the operations to generate (insertOne,
+// findOne, updateOne, deleteOne, countAll, findAll, iterateAll or just all).
+package parser
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/token"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+)
+
+// ParseFile reads and parses the given *.adl file. The positions refer to the absolute file path.
+func ParseFile(filename string) (*adl.Project, error) {
+	fname, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve absolute path: %w", err)
+	}
+
+	buf, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read adl file: %w", err)
+	}
+
+	return Parse(fname, buf)
+}
+
+// Parse interprets the given buffer as an *.adl file. The filename is only used to denote the positions.
+func Parse(filename string, buf []byte) (*adl.Project, error) {
+	lexemes, err := newLexer(filename, buf).all()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{lexemes: lexemes}
+
+	return p.parseFile()
+}
+
+type parser struct {
+	lexemes []lexeme
+	idx     int
+}
+
+func (p *parser) peek() lexeme {
+	return p.lexemes[p.idx]
+}
+
+func (p *parser) next() lexeme {
+	lex := p.lexemes[p.idx]
+	if lex.kind != kEOF {
+		p.idx++
+	}
+
+	return lex
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	lex := p.peek()
+	return lex.kind == kIdent && lex.str.Val == kw
+}
+
+func (p *parser) expect(k kind) (token.String, error) {
+	lex := p.next()
+	if lex.kind != k {
+		return lex.str, unexpected(lex, k.String())
+	}
+
+	return lex.str, nil
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	lex := p.next()
+	if lex.kind != kIdent || lex.str.Val != kw {
+		return unexpected(lex, "'"+kw+"'")
+	}
+
+	return nil
+}
+
+// nameAndComment parses the common Ident String sequence.
+func (p *parser) nameAndComment() (name, comment token.String, err error) {
+	if name, err = p.expect(kIdent); err != nil {
+		return
+	}
+
+	comment, err = p.expect(kString)
+
+	return
+}
+
+// block parses a braced sequence of keyword introduced declarations and delegates each keyword to f.
+func (p *parser) block(f func(kw lexeme) error) error {
+	if _, err := p.expect(kLBrace); err != nil {
+		return err
+	}
+
+	for {
+		lex := p.next()
+		switch lex.kind {
+		case kRBrace:
+			return nil
+		case kIdent:
+			if err := f(lex); err != nil {
+				return err
+			}
+		default:
+			return unexpected(lex, "declaration or '}'")
+		}
+	}
+}
+
+// optBlock is like block but only, if the next token opens a block.
+func (p *parser) optBlock(f func(kw lexeme) error) error {
+	if p.peek().kind != kLBrace {
+		return nil
+	}
+
+	return p.block(f)
+}
+
+func (p *parser) parseFile() (*adl.Project, error) {
+	if err := p.expectKeyword("project"); err != nil {
+		return nil, err
+	}
+
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	prj := adl.NewProject("", "")
+	prj.Name = name
+	prj.Comment = comment
+
+	for {
+		lex := p.next()
+		switch {
+		case lex.kind == kEOF:
+			return prj, nil
+		case lex.kind == kIdent && lex.str.Val == "glossary":
+			if err := p.parseGlossary(prj); err != nil {
+				return nil, err
+			}
+		case lex.kind == kIdent && lex.str.Val == "module":
+			m, err := p.parseModule()
+			if err != nil {
+				return nil, err
+			}
+
+			prj.AddModules(m)
+		default:
+			return nil, unexpected(lex, "'glossary' or 'module'")
+		}
+	}
+}
+
+func (p *parser) parseGlossary(prj *adl.Project) error {
+	if p.isKeyword("lint") {
+		p.next()
+		prj.Lint = true
+	}
+
+	if _, err := p.expect(kLBrace); err != nil {
+		return err
+	}
+
+	if prj.Glossary == nil {
+		prj.Glossary = adl.NewGlossary()
+	}
+
+	for {
+		lex := p.next()
+		switch lex.kind {
+		case kRBrace:
+			return nil
+		case kIdent, kString:
+			// a term named common is followed by its description but the common vocabulary by a word
+			if lex.kind == kIdent && lex.str.Val == "common" && p.peek().kind == kIdent {
+				if err := p.parseCommonWords(prj.Glossary); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			desc, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			if other, ok := prj.Glossary.Terms[lex.str.Val]; ok {
+				return token.NewPosError(lex.str, "duplicate glossary term",
+					token.NewErrDetail(other.Ident, "already defined here"),
+				)
+			}
+
+			prj.Glossary.Terms[lex.str.Val] = adl.Term{Ident: lex.str, Description: desc}
+		default:
+			return unexpected(lex, "glossary term or '}'")
+		}
+	}
+}
+
+// parseCommonWords parses the comma separated words, which extend the common vocabulary of the glossary.
+func (p *parser) parseCommonWords(glossary *adl.Glossary) error {
+	for {
+		word, err := p.expect(kIdent)
+		if err != nil {
+			return err
+		}
+
+		glossary.Common = append(glossary.Common, word)
+		if p.peek().kind != kComma {
+			return nil
+		}
+
+		p.next()
+	}
+}
+
+func (p *parser) parseModule() (*adl.Module, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	m := adl.NewModule("", "")
+	m.Name = name
+	m.Comment = comment
+
+	err = p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "license":
+			license, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			m.Preamble.License = license
+		case "generator":
+			if m.Generator == nil {
+				m.Generator = adl.NewGenerator()
+			}
+
+			return p.parseGenerator(m.Generator)
+		case "executable":
+			e, err := p.parseExecutable()
+			if err != nil {
+				return err
+			}
+
+			m.AddExecutables(e)
+		case "context":
+			bc, err := p.parseBoundedContext()
+			if err != nil {
+				return err
+			}
+
+			m.AddBoundedContexts(bc)
+		default:
+			return unexpected(kw, "'license', 'generator', 'executable' or 'context'")
+		}
+
+		return nil
+	})
+
+	return m, err
+}
+
+func (p *parser) parseGenerator(g *adl.Generator) error {
+	return p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "out":
+			dir, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			g.OutDir = dir
+		case "go":
+			if g.Go == nil {
+				g.Go = adl.NewGolang()
+			}
+
+			return p.parseGolang(g.Go)
+		case "typescript":
+			if g.TypeScript == nil {
+				g.TypeScript = adl.NewTypeScript()
+			}
+
+			return p.parseTypeScript(g.TypeScript)
+		default:
+			return unexpected(kw, "'out', 'go' or 'typescript'")
+		}
+
+		return nil
+	})
+}
+
+func (p *parser) parseTypeScript(t *adl.TypeScript) error {
+	return p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "out":
+			dir, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			t.OutDir = dir
+		default:
+			return unexpected(kw, "'out'")
+		}
+
+		return nil
+	})
+}
+
+func (p *parser) parseGolang(g *adl.Golang) error {
+	return p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "module":
+			mod, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			g.Module = mod
+		case "require":
+			req, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			g.Requires = append(g.Requires, req)
+		case "dist":
+			goos, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			goarch, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			g.GoDist = append(g.GoDist, &adl.GoDist{Os: goos, Arch: goarch})
+		case "protolock":
+			lock, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			g.ProtoLock = lock
+		default:
+			return unexpected(kw, "'module', 'require', 'dist' or 'protolock'")
+		}
+
+		return nil
+	})
+}
+
+func (p *parser) parseExecutable() (*adl.Executable, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	e := adl.NewExecutable("", "")
+	e.Name = name
+	e.Comment = comment
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "application":
+			path, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			e.BoundedContextPaths = append(e.BoundedContextPaths, path)
+		case "kind":
+			kind, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			e.Kind = kind
+		default:
+			return unexpected(kw, "'application' or 'kind'")
+		}
+
+		return nil
+	})
+
+	return e, err
+}
+
+func (p *parser) parseBoundedContext() (*adl.BoundedContext, error) {
+	name, path, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	bc := adl.NewBoundedContext("", "")
+	bc.Name = name
+	bc.Path = path
+
+	err = p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "core":
+			pkg, err := p.parsePackage(kw)
+			if err != nil {
+				return err
+			}
+
+			bc.AddCore(pkg)
+		case "usecase":
+			pkg, err := p.parsePackage(kw)
+			if err != nil {
+				return err
+			}
+
+			bc.AddUsecase(pkg)
+		case "bind":
+			iface, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			implementation, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			bc.Bindings = append(bc.Bindings, &adl.Binding{Interface: iface, Implementation: implementation})
+		default:
+			return unexpected(kw, "'core', 'usecase' or 'bind'")
+		}
+
+		return nil
+	})
+
+	return bc, err
+}
+
+// parsePackage parses a layer package. Name and comment are optional, because the root package of a layer
+// is unnamed. In that case, the empty values are located at the layer keyword.
+func (p *parser) parsePackage(kw lexeme) (*adl.Package, error) {
+	pkg := adl.NewPackage("", "")
+	pkg.Name = emptyAt(kw.str)
+	pkg.Comment = emptyAt(kw.str)
+
+	if p.peek().kind == kIdent {
+		pkg.Name = p.next().str
+	}
+
+	if p.peek().kind == kString {
+		pkg.Comment = p.next().str
+	}
+
+	err := p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "error":
+			e, err := p.parseError()
+			if err != nil {
+				return err
+			}
+
+			pkg.AddErrors(e)
+		case "dto":
+			s, err := p.parseStruct(withPos(kw.str, adl.DTO), nil)
+			if err != nil {
+				return err
+			}
+
+			pkg.AddStructs(s)
+		case "config":
+			s, err := p.parseStruct(withPos(kw.str, adl.Cfg), nil)
+			if err != nil {
+				return err
+			}
+
+			pkg.AddStructs(s)
+		case "struct":
+			stereotype, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			s, err := p.parseStruct(stereotype, nil)
+			if err != nil {
+				return err
+			}
+
+			pkg.AddStructs(s)
+		case "service":
+			lifecycle := p.isKeyword("lifecycle")
+			if lifecycle {
+				p.next()
+			}
+
+			svc := (&adl.Service{}).SetLifecycle(lifecycle)
+			s, err := p.parseStruct(withPos(kw.str, adl.ServiceComponent), svc)
+			if err != nil {
+				return err
+			}
+
+			svc.Component = s
+			pkg.AddServices(svc)
+		case "event":
+			e, err := p.parseEvent()
+			if err != nil {
+				return err
+			}
+
+			pkg.AddEvents(e)
+		case "enum":
+			e, err := p.parseEnum()
+			if err != nil {
+				return err
+			}
+
+			pkg.AddEnums(e)
+		case "repository":
+			r, err := p.parseInterface()
+			if err != nil {
+				return err
+			}
+
+			pkg.AddRepositories(r)
+		case "aggregate":
+			a, err := p.parseAggregate()
+			if err != nil {
+				return err
+			}
+
+			pkg.AddAggregates(a)
+		case "value":
+			v, err := p.parseValueObject(kw)
+			if err != nil {
+				return err
+			}
+
+			pkg.AddValueObjects(v)
+		default:
+			return unexpected(kw, "'error', 'dto', 'config', 'struct', 'service', 'event', 'enum', 'repository', 'aggregate' or 'value'")
+		}
+
+		return nil
+	})
+
+	return pkg, err
+}
+
+func (p *parser) parseError() (*adl.Error, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	e := adl.NewError("", "")
+	e.Name = name
+	e.Comment = comment
+
+	err = p.optBlock(func(kw lexeme) error {
+		if kw.str.Val != "field" {
+			return unexpected(kw, "'field'")
+		}
+
+		f, err := p.parseField()
+		if err != nil {
+			return err
+		}
+
+		e.AddFields(f)
+
+		return nil
+	})
+
+	return e, err
+}
+
+func (p *parser) parseEvent() (*adl.Event, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	e := adl.NewEvent("", "")
+	e.Name = name
+	e.Comment = comment
+
+	err = p.optBlock(func(kw lexeme) error {
+		if kw.str.Val != "field" {
+			return unexpected(kw, "'field'")
+		}
+
+		f, err := p.parseField()
+		if err != nil {
+			return err
+		}
+
+		e.AddFields(f)
+
+		return nil
+	})
+
+	return e, err
+}
+
+func (p *parser) parseEnum() (*adl.Enum, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	e := adl.NewEnum("", "")
+	e.Name = name
+	e.Comment = comment
+
+	err = p.block(func(kw lexeme) error {
+		if kw.str.Val != "case" {
+			return unexpected(kw, "'case'")
+		}
+
+		name, comment, err := p.nameAndComment()
+		if err != nil {
+			return err
+		}
+
+		c := adl.NewEnumCase("", "")
+		c.Name = name
+		c.Comment = comment
+		e.AddCases(c)
+
+		return p.optBlock(func(kw lexeme) error {
+			if kw.str.Val != "field" {
+				return unexpected(kw, "'field'")
+			}
+
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+
+			c.AddFields(f)
+
+			return nil
+		})
+	})
+
+	return e, err
+}
+
+// parseStruct parses the struct body. The service is nil for all other stereotypes and only a service
+// accepts event subscriptions.
+func (p *parser) parseStruct(stereotype token.String, svc *adl.Service) (*adl.Struct, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &adl.Struct{
+		Comment:    comment,
+		Name:       name,
+		Stereotype: stereotype,
+	}
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "field":
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+
+			s.AddFields(f)
+		case "func":
+			m, err := p.parseMethod()
+			if err != nil {
+				return err
+			}
+
+			s.Methods = append(s.Methods, m)
+		case "inject":
+			i, err := p.parseInjection()
+			if err != nil {
+				return err
+			}
+
+			s.Inject = append(s.Inject, i)
+		case "subscribe":
+			if svc == nil {
+				return unexpected(kw, "'field', 'func' or 'inject'")
+			}
+
+			typ, err := p.parseType()
+			if err != nil {
+				return err
+			}
+
+			svc.AddSubscriptions(typ)
+		default:
+			if svc != nil {
+				return unexpected(kw, "'field', 'func', 'inject' or 'subscribe'")
+			}
+
+			return unexpected(kw, "'field', 'func' or 'inject'")
+		}
+
+		return nil
+	})
+
+	return s, err
+}
+
+func (p *parser) parseAggregate() (*adl.Aggregate, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	a := adl.NewAggregate("", "")
+	a.Name = name
+	a.Comment = comment
+
+	err = p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "root":
+			if a.Root != nil {
+				return token.NewPosError(kw.str, "duplicate aggregate root",
+					token.NewErrDetail(a.Root.Component.Name, "already declared here"),
+				)
+			}
+
+			e, err := p.parseEntity(kw)
+			if err != nil {
+				return err
+			}
+
+			a.SetRoot(e)
+		case "entity":
+			e, err := p.parseEntity(kw)
+			if err != nil {
+				return err
+			}
+
+			a.AddEntities(e)
+		case "value":
+			v, err := p.parseValueObject(kw)
+			if err != nil {
+				return err
+			}
+
+			a.AddValueObjects(v)
+		default:
+			return unexpected(kw, "'root', 'entity' or 'value'")
+		}
+
+		return nil
+	})
+
+	return a, err
+}
+
+func (p *parser) parseEntity(kw lexeme) (*adl.Entity, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &adl.Entity{Component: &adl.Struct{
+		Comment:    comment,
+		Name:       name,
+		Stereotype: withPos(kw.str, adl.EntityComponent),
+	}}
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "id":
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+
+			e.SetIdentity(f)
+		case "field":
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+
+			e.AddFields(f)
+		case "invariant":
+			i, err := p.parseInvariant()
+			if err != nil {
+				return err
+			}
+
+			e.AddInvariants(i)
+		default:
+			return unexpected(kw, "'id', 'field' or 'invariant'")
+		}
+
+		return nil
+	})
+
+	return e, err
+}
+
+func (p *parser) parseValueObject(kw lexeme) (*adl.ValueObject, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &adl.ValueObject{Component: &adl.Struct{
+		Comment:    comment,
+		Name:       name,
+		Stereotype: withPos(kw.str, adl.ValueObjectComponent),
+	}}
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "field":
+			f, err := p.parseField()
+			if err != nil {
+				return err
+			}
+
+			v.AddFields(f)
+		case "invariant":
+			i, err := p.parseInvariant()
+			if err != nil {
+				return err
+			}
+
+			v.AddInvariants(i)
+		default:
+			return unexpected(kw, "'field' or 'invariant'")
+		}
+
+		return nil
+	})
+
+	return v, err
+}
+
+func (p *parser) parseInvariant() (*adl.Invariant, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	return &adl.Invariant{Comment: comment, Name: name}, nil
+}
+
+func (p *parser) parseField() (*adl.Field, error) {
+	f := &adl.Field{}
+	if p.isModifier("private") {
+		p.next()
+		f.Private = true
+	}
+
+	if p.isModifier("flag") {
+		p.next()
+		f.CfgCmdLineFlag = true
+	}
+
+	name, err := p.expect(kIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := p.expect(kString)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Name = name
+	f.Type = typ
+	f.Comment = comment
+
+	err = p.optBlock(func(kw lexeme) error {
+		c, err := p.parseConstraint(kw)
+		if err != nil {
+			return err
+		}
+
+		f.AddConstraints(c)
+
+		return nil
+	})
+
+	return f, err
+}
+
+// isModifier returns true, if the next lexeme is the given modifier keyword and not the name of a field. A name
+// is directly followed by the type and the comment, but a modifier is followed by the name, the type and the comment.
+func (p *parser) isModifier(kw string) bool {
+	if !p.isKeyword(kw) {
+		return false
+	}
+
+	start := p.idx
+	defer func() {
+		p.idx = start
+	}()
+
+	p.next()
+	if _, err := p.parseType(); err != nil {
+		return true
+	}
+
+	return p.peek().kind != kString
+}
+
+// parseConstraint parses the arguments of the constraint introduced by the given keyword. The literals are kept
+// as written and are checked against the field type by the validator.
+func (p *parser) parseConstraint(kw lexeme) (*adl.Constraint, error) {
+	c := &adl.Constraint{Comment: emptyAt(kw.str), Kind: kw.str, Value: emptyAt(kw.str)}
+	switch kw.str.Val {
+	case adl.ConstraintRequired:
+	case adl.ConstraintMin, adl.ConstraintMax, adl.ConstraintMinLength, adl.ConstraintMaxLength, adl.ConstraintPattern:
+		lit, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Value = lit
+	case adl.ConstraintOneOf:
+		for {
+			lit, err := p.literal()
+			if err != nil {
+				return nil, err
+			}
+
+			c.Values = append(c.Values, lit)
+			if p.peek().kind != kComma {
+				break
+			}
+
+			p.next()
+		}
+	case adl.ConstraintPredicate:
+		name, comment, err := p.nameAndComment()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Value = name
+		c.Comment = comment
+	default:
+		return nil, unexpected(kw, "constraint")
+	}
+
+	return c, nil
+}
+
+// literal parses a number, duration or string, which is either an identifier or a quoted string.
+func (p *parser) literal() (token.String, error) {
+	lex := p.next()
+	if lex.kind != kIdent && lex.kind != kString {
+		return lex.str, unexpected(lex, "literal")
+	}
+
+	return lex.str, nil
+}
+
+func (p *parser) parseInjection() (*adl.Injection, error) {
+	name, err := p.expect(kIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := p.expect(kString)
+	if err != nil {
+		return nil, err
+	}
+
+	i := adl.NewInjection("", "", "", typ)
+	i.Name = name
+	i.Comment = comment
+	i.Stereotype = emptyAt(comment)
+
+	if p.isKeyword("as") {
+		p.next()
+		if i.Stereotype, err = p.expect(kIdent); err != nil {
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+func (p *parser) parseInterface() (*adl.Interface, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	iface := adl.NewInterface("", "")
+	iface.Name = name
+	iface.Comment = comment
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "func":
+			m, err := p.parseMethod()
+			if err != nil {
+				return err
+			}
+
+			iface.AddMethods(m)
+		case "crud":
+			c, err := p.parseCRUD()
+			if err != nil {
+				return err
+			}
+
+			iface.AddCRUDImpl(c)
+		default:
+			return unexpected(kw, "'func' or 'crud'")
+		}
+
+		return nil
+	})
+
+	return iface, err
+}
+
+func (p *parser) parseMethod() (*adl.Method, error) {
+	name, comment, err := p.nameAndComment()
+	if err != nil {
+		return nil, err
+	}
+
+	m := adl.NewMethod("", "")
+	m.Name = name
+	m.Comment = comment
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "in":
+			name, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			typ, err := p.parseType()
+			if err != nil {
+				return err
+			}
+
+			comment, err := p.expect(kString)
+			if err != nil {
+				return err
+			}
+
+			m.In = append(m.In, &adl.Param{Comment: comment, Name: name, Type: typ})
+		case "out":
+			param, err := p.parseOutParam(kw)
+			if err != nil {
+				return err
+			}
+
+			m.Out = append(m.Out, param)
+		case "error":
+			typ, err := p.parseType()
+			if err != nil {
+				return err
+			}
+
+			m.AddErrors(typ)
+		case "raise":
+			typ, err := p.parseType()
+			if err != nil {
+				return err
+			}
+
+			m.AddEvents(typ)
+		case "nostub":
+			m.StubDefault = false
+		case "http":
+			e, err := p.parseHttp()
+			if err != nil {
+				return err
+			}
+
+			m.SetHttp(e)
+		case "grpc":
+			e, err := p.parseGrpc()
+			if err != nil {
+				return err
+			}
+
+			m.SetGrpc(e)
+		default:
+			return unexpected(kw, "'in', 'out', 'error', 'raise', 'nostub', 'http' or 'grpc'")
+		}
+
+		return nil
+	})
+
+	return m, err
+}
+
+// parseHttp parses the verb and path template of a REST endpoint followed by the parameter bindings and status
+// codes.
+func (p *parser) parseHttp() (*adl.HttpEndpoint, error) {
+	verb, err := p.expect(kIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := p.expect(kString)
+	if err != nil {
+		return nil, err
+	}
+
+	e := adl.NewHttpEndpoint("", "")
+	e.Verb = verb
+	e.Path = path
+
+	err = p.optBlock(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "query":
+			for {
+				name, err := p.expect(kIdent)
+				if err != nil {
+					return err
+				}
+
+				e.Query = append(e.Query, name)
+				if p.peek().kind != kComma {
+					break
+				}
+
+				p.next()
+			}
+		case "body":
+			name, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			e.Body = name
+		case "status":
+			name, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			code, err := p.expect(kIdent)
+			if err != nil {
+				return err
+			}
+
+			n, err := strconv.Atoi(code.Val)
+			if err != nil {
+				return token.NewPosError(code, "invalid status code").SetCause(err)
+			}
+
+			e.Status = append(e.Status, &adl.HttpStatus{Error: name, Code: n})
+		default:
+			return unexpected(kw, "'query', 'body' or 'status'")
+		}
+
+		return nil
+	})
+
+	return e, err
+}
+
+// parseGrpc parses the optional status codes of an unary rpc.
+func (p *parser) parseGrpc() (*adl.GrpcEndpoint, error) {
+	e := adl.NewGrpcEndpoint()
+	err := p.optBlock(func(kw lexeme) error {
+		if kw.str.Val != "status" {
+			return unexpected(kw, "'status'")
+		}
+
+		name, err := p.expect(kIdent)
+		if err != nil {
+			return err
+		}
+
+		code, err := p.expect(kIdent)
+		if err != nil {
+			return err
+		}
+
+		e.Status = append(e.Status, &adl.GrpcStatus{Error: name, Code: code})
+
+		return nil
+	})
+
+	return e, err
+}
+
+// parseOutParam handles the optional name of an out parameter. If the first type is not followed by the
+// comment but is a simple identifier, it has been the name.
+func (p *parser) parseOutParam(kw lexeme) (*adl.Param, error) {
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	name := emptyAt(kw.str)
+	if p.peek().kind != kString && len(typ.TypeParams) == 0 {
+		name = typ.Name
+		if typ, err = p.parseType(); err != nil {
+			return nil, err
+		}
+	}
+
+	comment, err := p.expect(kString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adl.Param{Comment: comment, Name: name, Type: typ}, nil
+}
+
+func (p *parser) parseCRUD() (*adl.CRUD, error) {
+	entity, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &adl.CRUD{EntityType: entity}
+	if p.isKeyword("id") {
+		p.next()
+		if c.IDType, err = p.parseType(); err != nil {
+			return nil, err
+		}
+	}
+
+	persistence, err := p.expect(kIdent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch adl.PersistenceType(persistence.Val) {
+	case adl.PMemory, adl.PFile, adl.PMySQL:
+		c.Persistence = adl.PersistenceType(persistence.Val)
+	default:
+		return nil, token.NewPosError(persistence, "unsupported persistence type").
+			SetHint("use one of " + string(adl.PMemory) + ", " + adl.PFile + " or " + adl.PMySQL)
+	}
+
+	err = p.block(func(kw lexeme) error {
+		switch kw.str.Val {
+		case "all":
+			c.InsertOne, c.FindOne, c.UpdateOne, c.DeleteOne = true, true, true, true
+			c.CountAll, c.FindAll, c.IterateAll = true, true, true
+		case "insertOne":
+			c.InsertOne = true
+		case "findOne":
+			c.FindOne = true
+		case "updateOne":
+			c.UpdateOne = true
+		case "deleteOne":
+			c.DeleteOne = true
+		case "countAll":
+			c.CountAll = true
+		case "findAll":
+			c.FindAll = true
+		case "iterateAll":
+			c.IterateAll = true
+		default:
+			return unexpected(kw, "crud operation")
+		}
+
+		return nil
+	})
+
+	return c, err
+}
+
+func (p *parser) parseType() (*adl.TypeDecl, error) {
+	lex := p.next()
+	switch lex.kind {
+	case kStar:
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		return &adl.TypeDecl{Name: lex.str, TypeParams: []*adl.TypeDecl{elem}}, nil
+	case kLBrack:
+		var length *adl.TypeDecl
+		if p.peek().kind == kIdent {
+			length = &adl.TypeDecl{Name: p.next().str}
+		}
+
+		rbrack, err := p.expect(kRBrack)
+		if err != nil {
+			return nil, err
+		}
+
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		name := lex.str
+		name.Val = "[]"
+		name.EndPos = rbrack.EndPos
+
+		if length != nil {
+			return &adl.TypeDecl{Name: name, TypeParams: []*adl.TypeDecl{length, elem}}, nil
+		}
+
+		return &adl.TypeDecl{Name: name, TypeParams: []*adl.TypeDecl{elem}}, nil
+	case kIdent:
+		if lex.str.Val == "map" && p.peek().kind == kLBrack {
+			p.next()
+			key, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := p.expect(kRBrack); err != nil {
+				return nil, err
+			}
+
+			val, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+
+			name := lex.str
+			name.Val = "map!"
+
+			return &adl.TypeDecl{Name: name, TypeParams: []*adl.TypeDecl{key, val}}, nil
+		}
+
+		decl := &adl.TypeDecl{Name: lex.str}
+		if p.peek().kind != kLess {
+			return decl, nil
+		}
+
+		p.next()
+		for {
+			param, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+
+			decl.TypeParams = append(decl.TypeParams, param)
+
+			sep := p.next()
+			switch sep.kind {
+			case kGreater:
+				return decl, nil
+			case kComma:
+			default:
+				return nil, unexpected(sep, "',' or '>'")
+			}
+		}
+	default:
+		return nil, unexpected(lex, "type")
+	}
+}
+
+// withPos returns a synthetic string value located at the given string, e.g. a stereotype which is implied
+// by a keyword.
+func withPos(at token.String, val string) token.String {
+	at.Val = val
+	return at
+}
+
+// emptyAt returns an empty string located at the begin of the given string.
+func emptyAt(at token.String) token.String {
+	at.Val = ""
+	at.EndPos = at.BeginPos
+
+	return at
+}
+
+func unexpected(lex lexeme, want string) error {
+	found := lex.kind.String()
+	if lex.kind == kIdent {
+		found = "'" + lex.str.Val + "'"
+	}
+
+	return token.NewPosError(lex.str, "expected "+want+" but found "+found)
+}
@@ -0,0 +1,552 @@
+package parser
+
+import (
+	"errors"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/token"
+	"testing"
+)
+
+const src = `// a comment
+project demo "...is a demo."
+
+module demo-srv "...is a module." {
+    context Demo "$MOD/internal/demo" {
+        core {
+            dto Äpfel "...counts apples." {
+                field Items []*$BC/core.Äpfel "...are nested."
+                field Fixed [4]byte "...is an array."
+                field Lookup map[string!]int! "...is a map."
+                field Generic my.List<string!, int!> "...has type parameters."
+            }
+            service lifecycle Tickets "...manages tickets."
+        }
+
+        bind $BC/core.Tickets $BC/core.InMemoryTickets
+    }
+}
+`
+
+func TestParse(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(src))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	assertPos(t, prj.Name, "demo", 2, 9, 21)
+	assertPos(t, prj.Comment, "...is a demo.", 2, 15, 27)
+
+	bc := prj.Modules[0].BoundedContexts[0]
+	assertPos(t, bc.Path, "$MOD/internal/demo", 5, 19, 97)
+
+	pkg := bc.Core[0]
+	if pkg.Name.Val != "" || pkg.Name.BeginPos.Line != 6 {
+		t.Fatalf("unexpected root package name: %#v", pkg.Name)
+	}
+
+	dto := pkg.DTOs[0]
+	if dto.Stereotype.Val != adl.DTO {
+		t.Fatalf("expected dto stereotype but got %s", dto.Stereotype)
+	}
+
+	// columns count runes but the offset counts bytes
+	assertPos(t, dto.Name, "Äpfel", 7, 17, 150)
+	if dto.Name.EndPos.Col != 22 || dto.Name.EndPos.Offset != 156 {
+		t.Fatalf("unexpected end: %v", dto.Name.EndPos)
+	}
+
+	items := dto.Fields[0].Type
+	if !items.IsSlice() || !items.TypeParams[0].IsPtr() || items.TypeParams[0].TypeParams[0].Name.Val != "$BC/core.Äpfel" {
+		t.Fatalf("unexpected slice: %#v", items)
+	}
+
+	if items.Name.EndPos.Col-items.Name.BeginPos.Col != 2 {
+		t.Fatalf("expected slice token to span both brackets: %#v", items.Name)
+	}
+
+	if fixed := dto.Fields[1].Type; !fixed.IsArray() || fixed.TypeParams[0].Name.Val != "4" {
+		t.Fatalf("unexpected array: %#v", fixed)
+	}
+
+	if lookup := dto.Fields[2].Type; !lookup.IsMap() || lookup.TypeParams[1].Name.Val != "int!" {
+		t.Fatalf("unexpected map: %#v", lookup)
+	}
+
+	if generic := dto.Fields[3].Type; len(generic.TypeParams) != 2 || generic.Name.Val != "my.List" {
+		t.Fatalf("unexpected generic: %#v", generic)
+	}
+
+	if b := bc.Bindings[0]; b.Interface.Val != "$BC/core.Tickets" || b.Implementation.Val != "$BC/core.InMemoryTickets" {
+		t.Fatalf("unexpected binding: %#v", b)
+	}
+
+	if srv := pkg.Services[0]; !srv.Lifecycle || srv.Component.Name.Val != "Tickets" {
+		t.Fatalf("unexpected lifecycle service: %#v", srv)
+	}
+}
+
+func TestParseAggregate(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        core {
+            value Money "...is an amount." {
+                field Cents int64! "...are the cents."
+                invariant Positive "...ensures a positive amount."
+            }
+
+            aggregate Order "...is an order." {
+                root Order "...is the root." {
+                    id ID uuid! "...is the id."
+                    field Total Money "...is the total."
+                }
+                entity Item "...is an order item." {
+                    id ID uuid! "...is the id."
+                    invariant Counted "...ensures a positive count."
+                }
+                value Note "...is a remark."
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	pkg := prj.Modules[0].BoundedContexts[0].Core[0]
+	if v := pkg.ValueObjects[0]; v.Component.Stereotype.Val != adl.ValueObjectComponent || v.Invariants[0].Name.Val != "Positive" {
+		t.Fatalf("unexpected value object: %#v", v)
+	}
+
+	a := pkg.Aggregates[0]
+	if a.Root.Component.Stereotype.Val != adl.AggregateRootComponent || a.Root.Identity.Name.Val != "ID" {
+		t.Fatalf("unexpected root: %#v", a.Root)
+	}
+
+	assertPos(t, a.Root.Component.Fields[0].Name, "Total", 13, 27, 443)
+
+	if e := a.Entities[0]; e.Component.Stereotype.Val != adl.EntityComponent || e.Invariants[0].Name.Val != "Counted" {
+		t.Fatalf("unexpected entity: %#v", e)
+	}
+
+	if v := a.ValueObjects[0]; v.Component.Name.Val != "Note" {
+		t.Fatalf("unexpected value object: %#v", v)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        core {
+            event TicketCreated "...is raised for new tickets." {
+                field ID uuid! "...is the id."
+            }
+
+            service Tickets "...is a service." {
+                func Create "...creates." {
+                    raise TicketCreated
+                }
+                subscribe $BC/core.TicketCreated
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	pkg := prj.Modules[0].BoundedContexts[0].Core[0]
+	if e := pkg.Events[0]; e.Name.Val != "TicketCreated" || e.Fields[0].Name.Val != "ID" {
+		t.Fatalf("unexpected event: %#v", e)
+	}
+
+	srv := pkg.Services[0]
+	assertPos(t, srv.Component.Methods[0].Events[0].Name, "TicketCreated", 11, 27, 339)
+	assertPos(t, srv.Subscriptions[0].Name, "$BC/core.TicketCreated", 13, 27, 397)
+}
+
+func TestParseEnums(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        core {
+            enum Status "...is the ticket status." {
+                case Open "...is a new ticket."
+                case Closed "...is a solved ticket."
+            }
+
+            enum Assignee "...is whom a ticket is assigned to." {
+                case Nobody "...means unassigned."
+                case Agent "...is a support agent." {
+                    field Name string! "...is the name of the agent."
+                }
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	pkg := prj.Modules[0].BoundedContexts[0].Core[0]
+	status := pkg.Enums[0]
+	if status.IsUnion() || len(status.Cases) != 2 {
+		t.Fatalf("unexpected enum: %#v", status)
+	}
+
+	assertPos(t, status.Cases[1].Name, "Closed", 7, 22, 214)
+
+	if assignee := pkg.Enums[1]; !assignee.IsUnion() || assignee.Cases[1].Fields[0].Name.Val != "Name" {
+		t.Fatalf("unexpected union: %#v", assignee)
+	}
+}
+
+func TestParseConstraints(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        core {
+            dto Ticket "...is a ticket." {
+                field Title string! "...is the title." {
+                    required
+                    minLength 3
+                    pattern "^[A-Z]"
+                    oneOf A, "B C"
+                }
+                field Score float64! "...is the score." {
+                    min -1
+                    max 0.5
+                    predicate Rounded "...ensures a rounded score."
+                }
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	fields := prj.Modules[0].BoundedContexts[0].Core[0].DTOs[0].Fields
+	title := fields[0].Constraints
+	if len(title) != 4 || title[0].Kind.Val != adl.ConstraintRequired || title[2].Value.Val != "^[A-Z]" {
+		t.Fatalf("unexpected constraints: %#v", title)
+	}
+
+	if oneOf := title[3]; len(oneOf.Values) != 2 || oneOf.Values[0].Val != "A" || oneOf.Values[1].Val != "B C" {
+		t.Fatalf("unexpected one-of constraint: %#v", oneOf)
+	}
+
+	assertPos(t, title[1].Value, "3", 8, 31, 251)
+
+	score := fields[1].Constraints
+	if score[0].Value.Val != "-1" || score[1].Value.Val != "0.5" {
+		t.Fatalf("unexpected bounds: %#v", score)
+	}
+
+	if predicate := score[2]; predicate.Value.Val != "Rounded" || predicate.Comment.Val != "...ensures a rounded score." {
+		t.Fatalf("unexpected predicate: %#v", predicate)
+	}
+}
+
+func TestParseFieldModifiers(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    license "MIT"
+    context Demo "$MOD/internal/demo" {
+        usecase {
+            config Settings "...are the settings." {
+                field private flag verbose bool! "...is a private flag."
+                field flag private bool! "...is a flag named private."
+                field flag bool! "...is named flag."
+                field private map[string!]int! "...is named private."
+                field private flag *string! "...is a private field named flag."
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	assertPos(t, prj.Modules[0].Preamble.License, "MIT", 3, 14, 50)
+
+	tests := []struct {
+		name          string
+		private, flag bool
+	}{
+		{"verbose", true, true},
+		{"private", false, true},
+		{"flag", false, false},
+		{"private", false, false},
+		{"flag", true, false},
+	}
+
+	fields := prj.Modules[0].BoundedContexts[0].Usecase[0].DTOs[0].Fields
+	if len(fields) != len(tests) {
+		t.Fatalf("expected %d fields but got %d", len(tests), len(fields))
+	}
+
+	for i, test := range tests {
+		if f := fields[i]; f.Name.Val != test.name || f.Private != test.private || f.CfgCmdLineFlag != test.flag {
+			t.Errorf("unexpected field %d: %s private=%v flag=%v", i, f.Name.Val, f.Private, f.CfgCmdLineFlag)
+		}
+	}
+}
+
+func TestParseGlossary(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+glossary lint {
+    common "...is a term, which is named like the keyword."
+    common ETag, URI
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	if !prj.Lint {
+		t.Error("expected the project to be linted")
+	}
+
+	if _, ok := prj.Glossary.Terms["common"]; !ok {
+		t.Errorf("expected the term common but got %v", prj.Glossary.Terms)
+	}
+
+	if len(prj.Glossary.Common) != 2 {
+		t.Fatalf("expected 2 common words but got %v", prj.Glossary.Common)
+	}
+
+	assertPos(t, prj.Glossary.Common[1], "URI", 4, 18, 109)
+}
+
+func TestParseHttp(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        usecase {
+            service Tickets "...is a service." {
+                func Find "...finds tickets." {
+                    in id uuid! "...is the ticket id."
+                    in limit int! "...is the page size."
+                    in offset int! "...is the page offset."
+                    out Ticket "...is the ticket."
+                    error NotFound
+                    http GET "/tickets/{id}" {
+                        query limit, offset
+                        status NotFound 404
+                    }
+                }
+                func Create "...creates a ticket." {
+                    in ticket Ticket "...is the new ticket."
+                    http POST "/tickets" { body ticket }
+                }
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	methods := prj.Modules[0].BoundedContexts[0].Usecase[0].Services[0].Component.Methods
+	find := methods[0].Http
+	if find.Verb.Val != adl.HttpGet || len(find.Query) != 2 || find.Query[1].Val != "offset" {
+		t.Fatalf("unexpected endpoint: %#v", find)
+	}
+
+	assertPos(t, find.Path, "/tickets/{id}", 12, 31, 480)
+
+	if find.StatusOf("NotFound") != 404 {
+		t.Fatalf("unexpected status: %#v", find.Status)
+	}
+
+	if create := methods[1].Http; create.Verb.Val != adl.HttpPost || create.Body.Val != "ticket" {
+		t.Fatalf("unexpected endpoint: %#v", create)
+	}
+}
+
+func TestParseGrpc(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        usecase {
+            service Tickets "...is a service." {
+                func Find "...finds tickets." {
+                    in id uuid! "...is the ticket id."
+                    out Ticket "...is the ticket."
+                    error NotFound
+                    grpc { status NotFound NotFound }
+                }
+                func Create "...creates a ticket." {
+                    in ticket Ticket "...is the new ticket."
+                    grpc
+                }
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	methods := prj.Modules[0].BoundedContexts[0].Usecase[0].Services[0].Component.Methods
+	find := methods[0].Grpc
+	if find == nil || find.CodeOf("NotFound") != adl.GrpcNotFound {
+		t.Fatalf("unexpected endpoint: %#v", find)
+	}
+
+	assertPos(t, find.Status[0].Code, adl.GrpcNotFound, 10, 44, 376)
+
+	if create := methods[1].Grpc; create == nil || len(create.Status) != 0 {
+		t.Fatalf("unexpected endpoint: %#v", create)
+	}
+}
+
+func TestParseCRUD(t *testing.T) {
+	prj, err := Parse("demo.adl", []byte(`project demo ""
+module demo-srv "" {
+    context Demo "$MOD/internal/demo" {
+        core {
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket id int64! file { countAll findAll iterateAll }
+            }
+            repository Others "...stores other tickets." {
+                crud $BC/core.Other in-memory { all }
+            }
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	repos := prj.Modules[0].BoundedContexts[0].Core[0].Repositories
+	paging := repos[0].CRUDs[0]
+	if paging.Persistence != adl.PFile || paging.IDType.Name.Val != "int64!" {
+		t.Fatalf("unexpected crud: %#v", paging)
+	}
+
+	if !paging.CountAll || !paging.FindAll || !paging.IterateAll || paging.InsertOne || paging.FindOne {
+		t.Fatalf("unexpected operations: %#v", paging)
+	}
+
+	assertPos(t, paging.EntityType.Name, "$BC/core.Ticket", 6, 22, 167)
+
+	all := repos[1].CRUDs[0]
+	if !all.InsertOne || !all.FindOne || !all.UpdateOne || !all.DeleteOne || !all.CountAll || !all.FindAll || !all.IterateAll || all.IDType != nil {
+		t.Fatalf("unexpected operations: %#v", all)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		col  int
+	}{
+		{
+			name: "missing project",
+			src:  `module x ""`,
+			line: 1,
+			col:  1,
+		},
+		{
+			name: "unknown declaration",
+			src:  "project x \"\"\nmodule y \"\" {\n  bla\n}",
+			line: 3,
+			col:  3,
+		},
+		{
+			name: "unterminated string",
+			src:  "project x \"abc\n",
+			line: 1,
+			col:  11,
+		},
+		{
+			name: "unsupported persistence",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { core { repository R \"\" { crud T cloud { all } } } } }",
+			line: 1,
+			col:  76,
+		},
+		{
+			name: "unknown executable option",
+			src:  "project x \"\" module y \"\" { executable z \"\" { kind \"cli\" daemon \"yes\" } }",
+			line: 1,
+			col:  57,
+		},
+		{
+			name: "duplicate aggregate root",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { core { aggregate A \"\" { root A \"\" root B \"\" } } } }",
+			line: 1,
+			col:  78,
+		},
+		{
+			name: "subscription of a dto",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { core { dto A \"\" { subscribe B } } } }",
+			line: 1,
+			col:  62,
+		},
+		{
+			name: "unknown constraint",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { core { dto A \"\" { field B int! \"\" { positive } } } } }",
+			line: 1,
+			col:  80,
+		},
+		{
+			name: "invalid status code",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { usecase { service A \"\" { func B \"\" { http GET \"/\" { status C not-found } } } } } }",
+			line: 1,
+			col:  105,
+		},
+		{
+			name: "unknown grpc option",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { usecase { service A \"\" { func B \"\" { grpc { body C } } } } } }",
+			line: 1,
+			col:  88,
+		},
+		{
+			name: "unknown crud operation",
+			src:  "project x \"\" module y \"\" { context Z \"p\" { core { repository R \"\" { crud T in-memory { findSome } } } } }",
+			line: 1,
+			col:  88,
+		},
+		{
+			name: "unexpected character",
+			src:  "project x \"\" ;",
+			line: 1,
+			col:  14,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("err.adl", []byte(tt.src))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			var posErr *token.PosError
+			if !errors.As(err, &posErr) {
+				t.Fatalf("expected PosError but got %v", err)
+			}
+
+			pos := posErr.Details[0].Node.Begin()
+			if pos.File != "err.adl" || pos.Line != tt.line || pos.Col != tt.col {
+				t.Fatalf("expected error at %d:%d but got %v: %v", tt.line, tt.col, pos, err)
+			}
+		})
+	}
+}
+
+func assertPos(t *testing.T, s token.String, val string, line, col, offset int) {
+	t.Helper()
+
+	if s.Val != val {
+		t.Fatalf("expected %q but got %q", val, s.Val)
+	}
+
+	if s.BeginPos.File != "demo.adl" || s.BeginPos.Line != line || s.BeginPos.Col != col || s.BeginPos.Offset != offset {
+		t.Fatalf("expected %q at %d:%d (offset %d) but got %v (offset %d)", val, line, col, offset, s.BeginPos, s.BeginPos.Offset)
+	}
+}
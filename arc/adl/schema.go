@@ -0,0 +1,120 @@
+package adl
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// schemaDescriptions documents the model types within the JSON Schema, so that editors can show them.
+var schemaDescriptions = map[string]string{
+	"Project":        "A Project has multiple modules, like libraries, servers or clients, frontends or backends.",
+	"Module":         "A Module is e.g. a server application, a frontend or a shared library.",
+	"Executable":     "An Executable defines an entry point into the application.",
+	"Generator":      "A Generator describes how this project should be generated.",
+	"Golang":         "Golang describes how a Go project (or module) must be created or updated.",
+	"GoDist":         "GoDist denotes a target operating system and architecture.",
+	"TypeScript":     "TypeScript describes how the TypeScript models and the fetch based client of a module must be created or updated.",
+	"BoundedContext": "A BoundedContext contains its own ubiquitous language and consists of core and usecase packages.",
+	"Binding":        "A Binding declares the implementation, which is injected wherever the interface is required.",
+	"Package":        "A Package contains repositories, services, structs and errors of a layer.",
+	"Interface":      "An Interface declares a repository with methods and optional CRUD implementations.",
+	"CRUD":           "CRUD represents an autogenerated piece of code to manage entities.",
+	"Service":        "A Service is a component with the service stereotype.",
+	"Struct":         "A Struct is a data type with a stereotype like dto, cfg or service.",
+	"Field":          "A Field of a struct or error.",
+	"Constraint":     "A Constraint restricts the valid values of a field, like required, min, max, pattern or one-of.",
+	"Injection":      "An Injection declares a dependency of a component.",
+	"Error":          "An Error is a case of the error sum type of a bounded context.",
+	"Enum":           "An Enum is a closed set of cases. If any case has associated values, it is a tagged union.",
+	"EnumCase":       "An EnumCase is a single case of an Enum with optional associated values.",
+	"Event":          "An Event is a domain event, which is raised by service methods and handled by subscribed services.",
+	"Aggregate":      "An Aggregate is a cluster of entities and value objects, which is only accessed through its root entity.",
+	"Entity":         "An Entity is a mutable domain object, which is distinguished by its identity.",
+	"ValueObject":    "A ValueObject is an immutable domain object without identity, which is distinguished by its attributes.",
+	"Invariant":      "An Invariant is a condition which must always hold for an entity or value object.",
+	"Method":         "A Method with in and out parameters and the possible error cases.",
+	"Param":          "A Param of a method.",
+	"HttpEndpoint":   "An HttpEndpoint exposes a use case service method by a verb, a path template and the bindings of its parameters.",
+	"HttpStatus":     "An HttpStatus maps a declared error case to a status code.",
+	"GrpcEndpoint":   "A GrpcEndpoint exposes a use case service method as an unary rpc.",
+	"GrpcStatus":     "A GrpcStatus maps a declared error case to a gRPC status code.",
+	"TypeDecl":       "A TypeDecl is the full qualified name of a type with optional type parameters, like *, [] or map!.",
+	"Glossary":       "A Glossary defines the ubiquitous language.",
+	"Term":           "A Term of the glossary.",
+	"Preamble":       "A Preamble is emitted at the top of each generated file.",
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) for the documents of LoadFile and SaveFile.
+func JSONSchema() []byte {
+	defs := map[string]interface{}{}
+	root := schemaOf(reflect.TypeOf(Project{}), defs)
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = "https://github.com/golangee/architecture/arc/adl/adl.schema.json"
+	root["title"] = "Architecture Description Language"
+	root["$defs"] = defs
+
+	buf, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		panic(err) // cannot happen with our static types
+	}
+
+	return append(buf, '\n')
+}
+
+// schemaOf returns the schema of the given type. Structs are registered in defs and referenced, which also
+// breaks the recursion of TypeDecl. The Project itself is inlined as the root.
+func schemaOf(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	if t == tokenStringType {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if t == reflect.TypeOf(PMemory) {
+			return map[string]interface{}{"type": "string", "enum": []PersistenceType{PMemory, PFile, PMySQL}}
+		}
+
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), defs)
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), defs)}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+
+		obj := map[string]interface{}{"type": "object", "additionalProperties": false}
+		if desc, ok := schemaDescriptions[t.Name()]; ok {
+			obj["description"] = desc
+		}
+
+		if t == reflect.TypeOf(Project{}) {
+			obj["properties"] = schemaProperties(t, defs)
+			return obj
+		}
+
+		defs[t.Name()] = obj // register before the properties to support recursive types
+		obj["properties"] = schemaProperties(t, defs)
+
+		return ref
+	default:
+		panic("unsupported model type: " + t.String())
+	}
+}
+
+func schemaProperties(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		props[t.Field(i).Name] = schemaOf(t.Field(i).Type, defs)
+	}
+
+	return props
+}
@@ -0,0 +1,1014 @@
+package adl
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/stdlib"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// goBuiltinTypes contains the predeclared Go types, which are valid unqualified names everywhere.
+var goBuiltinTypes = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true, "float32": true,
+	"float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"interface{}": true, "any": true,
+}
+
+// Validate performs a semantic check of the entire project before any generator gets involved. It walks every
+// Module, BoundedContext, Package, Struct, Interface, Method and CRUD and collects all unresolved types, duplicate
+// identifiers, empty names, invalid $MOD or $BC usages and violations of the dependency rules at once. The result is either nil or a *token.PosError
+// whose details point to the according declarations, so that token.Explain can show each of them.
+func Validate(prj *Project) error {
+	v := &validator{}
+	v.validateProject(prj)
+
+	if len(v.details) == 0 {
+		return nil
+	}
+
+	return token.NewPosError(prj.Name, fmt.Sprintf("the architecture contains %d semantic errors", len(v.details)), v.details...)
+}
+
+// declaration is an indexed and resolvable named thing within a package.
+type declaration struct {
+	name  token.String
+	kind  string  // one of the stereotypes DTO, Cfg, ServiceComponent or the kinds repository and error.
+	strct *Struct // only set for structural declarations.
+	bc    string  // name of the declaring bounded context.
+	layer string  // layer of the declaring package.
+}
+
+const (
+	declRepository = "repository"
+	declError      = "error"
+	declEvent      = "event"
+	declEnum       = "enum"
+)
+
+const (
+	layerCore        = "core"
+	layerUsecase     = "usecase"
+	layerApplication = "application"
+)
+
+// dependencyRule allows a reference into the denoted layer.
+type dependencyRule struct {
+	layer  string
+	sameBC bool // if true, the referenced declaration must belong to the same bounded context.
+}
+
+// dependencyRules defines which layer may refer to which other layers. The core is independent and must not refer
+// to anything but its own core packages, the usecase layer may refer to its own core and the application layer,
+// which wires everything together, may refer to everything. The bindings of a bounded context belong to the
+// application layer.
+var dependencyRules = map[string][]dependencyRule{
+	layerCore:        {{layer: layerCore, sameBC: true}},
+	layerUsecase:     {{layer: layerCore, sameBC: true}, {layer: layerUsecase, sameBC: true}},
+	layerApplication: {{layer: layerCore}, {layer: layerUsecase}, {layer: layerApplication}},
+}
+
+// pkgScope describes the lexical context in which names are resolved.
+type pkgScope struct {
+	ctx   Ctx
+	path  string // full qualified (and normalized) path of the package.
+	bc    string // name of the bounded context.
+	layer string // the layer of the package.
+}
+
+type validator struct {
+	details []token.ErrDetail
+	types   map[string]declaration  // full qualified <path>.<Name> of all types
+	errors  map[string]declaration  // full qualified <path>.<Name> of all error cases
+	routes  map[string]token.String // <verb> <path template> of all http endpoints
+	rpcs    map[string]token.String // <bounded context>.<method> of all grpc endpoints
+}
+
+func (v *validator) errorf(node token.Node, format string, args ...interface{}) {
+	v.details = append(v.details, token.NewErrDetail(node, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) validateProject(prj *Project) {
+	v.requireName(prj.Name, "project")
+
+	modNames := map[string]token.String{}
+	for _, module := range prj.Modules {
+		v.requireName(module.Name, "module")
+		v.unique(modNames, module.Name, "module")
+		v.validateModule(module)
+	}
+}
+
+func (v *validator) validateModule(mod *Module) {
+	v.types = map[string]declaration{}
+	v.errors = map[string]declaration{}
+	v.routes = map[string]token.String{}
+	v.rpcs = map[string]token.String{}
+
+	ctx := Ctx{Mod: nMOD}
+	if mod.Generator == nil {
+		v.errorf(mod.Name, "module has no generator settings")
+	} else if mod.Generator.Go != nil {
+		if mod.Generator.Go.Module.String() == "" {
+			v.errorf(mod.Name, "module has no go module name")
+		} else {
+			ctx.Mod = mod.Generator.Go.Module.String()
+		}
+	}
+
+	if mod.Generator != nil && mod.Generator.TypeScript != nil && mod.Generator.TypeScript.OutDir.String() == "" {
+		v.errorf(mod.Name, "module has no typescript output directory")
+	}
+
+	// first pass: check the bounded contexts and index all declarations
+	bcNames := map[string]token.String{}
+	bcPaths := map[string]token.String{}
+	for _, bc := range mod.BoundedContexts {
+		v.requireName(bc.Name, "bounded context")
+		v.unique(bcNames, bc.Name, "bounded context")
+		v.requireName(bc.Path, "bounded context path")
+		v.checkVars(bc.Path, false)
+
+		bcPath := normalizePath(ctx.applyString(bc.Path.String()))
+		if other, ok := bcPaths[bcPath]; ok && bcPath != "" {
+			v.errorf(bc.Path, "bounded context path '%s' is already declared at %s", bc.Path.String(), other.Begin().String())
+		} else {
+			bcPaths[bcPath] = bc.Path
+		}
+
+		for _, layer := range bcLayers(bc) {
+			pkgNames := map[string]token.String{}
+			for _, p := range layer.pkgs {
+				if p.Name.String() != "" {
+					v.unique(pkgNames, p.Name, layer.name+" package")
+				}
+
+				v.index(pkgScope{path: bcPackagePath(bcPath, layer.name, p), bc: bc.Name.String(), layer: layer.name}, p)
+			}
+		}
+	}
+
+	// second pass: check all declarations with a complete index
+	for _, bc := range mod.BoundedContexts {
+		bcCtx := ctx
+		bcCtx.BC = normalizePath(ctx.applyString(bc.Path.String()))
+		for _, layer := range bcLayers(bc) {
+			for _, p := range layer.pkgs {
+				v.validatePackage(pkgScope{ctx: bcCtx, path: bcPackagePath(bcCtx.BC, layer.name, p), bc: bc.Name.String(), layer: layer.name}, p)
+			}
+		}
+
+		appScope := pkgScope{ctx: bcCtx, path: normalizePath(bcCtx.BC + "/" + layerApplication), bc: bc.Name.String(), layer: layerApplication}
+		for _, binding := range bc.Bindings {
+			v.validateBinding(appScope, binding)
+		}
+	}
+
+	if len(v.rpcs) > 0 && (mod.Generator == nil || mod.Generator.Go == nil || mod.Generator.Go.ProtoLock.String() == "") {
+		v.errorf(mod.Name, "module exposes grpc methods but has no protobuf lock file")
+	}
+
+	execNames := map[string]token.String{}
+	for _, executable := range mod.Executables {
+		v.requireName(executable.Name, "executable")
+		v.unique(execNames, executable.Name, "executable")
+		if kind := executable.Kind.String(); kind != "" && kind != ExecutableServer && kind != ExecutableCli {
+			v.errorf(executable.Kind, "invalid executable kind '%s', expected '%s' or '%s'", kind, ExecutableServer, ExecutableCli)
+		}
+
+		for _, path := range executable.BoundedContextPaths {
+			if !v.checkVars(path, false) {
+				continue
+			}
+
+			if _, ok := bcPaths[normalizePath(ctx.applyString(path.String()))]; !ok {
+				v.errorf(path, "'%s' does not refer to a declared bounded context", path.String())
+			}
+		}
+	}
+}
+
+// index registers all declarations of the given package. Duplicates are reported.
+func (v *validator) index(s pkgScope, p *Package) {
+	register := func(name token.String, kind string, strct *Struct) {
+		v.requireName(name, kind)
+		if name.String() == "" {
+			return
+		}
+
+		fqn := s.path + "." + name.String()
+		if other, ok := v.types[fqn]; ok {
+			v.errorf(name, "%s '%s' is already declared at %s as %s", kind, name.String(), other.name.Begin().String(), other.kind)
+			return
+		}
+
+		v.types[fqn] = declaration{name: name, kind: kind, strct: strct, bc: s.bc, layer: s.layer}
+	}
+
+	for _, dto := range p.DTOs {
+		register(dto.Name, dto.Stereotype.String(), dto)
+	}
+
+	for _, repository := range p.Repositories {
+		register(repository.Name, declRepository, nil)
+	}
+
+	for _, service := range p.Services {
+		register(service.Component.Name, ServiceComponent, service.Component)
+	}
+
+	for _, a := range p.Aggregates {
+		if a.Root != nil {
+			register(a.Root.Component.Name, AggregateRootComponent, a.Root.Component)
+		}
+
+		for _, entity := range a.Entities {
+			register(entity.Component.Name, EntityComponent, entity.Component)
+		}
+
+		for _, value := range a.ValueObjects {
+			register(value.Component.Name, ValueObjectComponent, value.Component)
+		}
+	}
+
+	for _, value := range p.ValueObjects {
+		register(value.Component.Name, ValueObjectComponent, value.Component)
+	}
+
+	for _, e := range p.Events {
+		register(e.Name, declEvent, nil)
+	}
+
+	for _, e := range p.Enums {
+		register(e.Name, declEnum, nil)
+	}
+
+	for _, e := range p.Errors {
+		v.requireName(e.Name, declError)
+		fqn := s.path + "." + e.Name.String()
+		if other, ok := v.errors[fqn]; ok {
+			v.errorf(e.Name, "error '%s' is already declared at %s", e.Name.String(), other.name.Begin().String())
+			continue
+		}
+
+		v.errors[fqn] = declaration{name: e.Name, kind: declError, bc: s.bc, layer: s.layer}
+	}
+
+	// the error cases of a crud are generated into the package and shared by all repositories of the same entity.
+	// Conflicts with declared errors are reported by validatePackage.
+	for _, repository := range p.Repositories {
+		for _, crud := range repository.CRUDs {
+			if crud.EntityType == nil {
+				continue
+			}
+
+			notFound, alreadyExists := crud.ErrorNames()
+			for _, name := range []string{notFound, alreadyExists} {
+				fqn := s.path + "." + name
+				if _, ok := v.errors[fqn]; !ok {
+					v.errors[fqn] = declaration{name: crud.EntityType.Name, kind: declError, bc: s.bc, layer: s.layer}
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validatePackage(s pkgScope, p *Package) {
+	for _, dto := range p.DTOs {
+		v.validateStruct(s, dto)
+		v.rejectEndpoints(dto.Methods)
+	}
+
+	for _, service := range p.Services {
+		v.validateStruct(s, service.Component)
+
+		handlers := map[string]token.String{}
+		for _, method := range service.Component.Methods {
+			handlers[method.Name.String()] = method.Name
+		}
+
+		for _, decl := range service.Subscriptions {
+			v.validateEvent(s, decl, service.Component.Name)
+			if decl == nil {
+				continue
+			}
+
+			name := HandlerName(decl.Name.String())
+			if other, ok := handlers[name]; ok {
+				v.errorf(decl.Name, "handler '%s' of event '%s' conflicts with '%s' declared at %s", name, decl.Name.String(), other.String(), other.Begin().String())
+				continue
+			}
+
+			handlers[name] = decl.Name
+		}
+
+		for _, method := range service.Component.Methods {
+			if service.Lifecycle && (method.Name.String() == LifecycleStart || method.Name.String() == LifecycleStop) {
+				v.errorf(method.Name, "method '%s' of service '%s' conflicts with its lifecycle hook", method.Name.String(), service.Component.Name.String())
+			}
+
+			if method.Http != nil {
+				v.validateHttpEndpoint(s, method)
+			}
+
+			if method.Grpc != nil {
+				v.validateGrpcEndpoint(s, method)
+			}
+		}
+	}
+
+	for _, e := range p.Events {
+		names := map[string]token.String{}
+		for _, field := range e.Fields {
+			v.validateField(s, names, field)
+		}
+	}
+
+	for _, e := range p.Enums {
+		if len(e.Cases) == 0 {
+			v.errorf(e.Name, "enum '%s' has no cases", e.Name.String())
+		}
+
+		caseNames := map[string]token.String{}
+		for _, c := range e.Cases {
+			v.requireName(c.Name, "enum case")
+			v.unique(caseNames, c.Name, "enum case")
+
+			names := map[string]token.String{}
+			for _, field := range c.Fields {
+				v.validateField(s, names, field)
+			}
+		}
+	}
+
+	for _, e := range p.Errors {
+		names := map[string]token.String{}
+		for _, field := range e.Fields {
+			v.validateField(s, names, field)
+		}
+	}
+
+	for _, a := range p.Aggregates {
+		v.requireName(a.Name, "aggregate")
+		if a.Root == nil {
+			v.errorf(a.Name, "aggregate '%s' has no root entity", a.Name.String())
+		} else {
+			v.validateEntity(s, a.Root)
+		}
+
+		for _, entity := range a.Entities {
+			v.validateEntity(s, entity)
+		}
+
+		for _, value := range a.ValueObjects {
+			v.validateDomainObject(s, value.Component, value.Invariants)
+		}
+	}
+
+	for _, value := range p.ValueObjects {
+		v.validateDomainObject(s, value.Component, value.Invariants)
+	}
+
+	for _, repository := range p.Repositories {
+		methodNames := map[string]token.String{}
+		for _, method := range repository.Methods {
+			v.validateMethod(s, methodNames, method)
+		}
+
+		v.rejectEndpoints(repository.Methods)
+
+		for _, crud := range repository.CRUDs {
+			v.validateCRUD(s, repository.Name, crud)
+			if crud.EntityType == nil {
+				continue
+			}
+
+			// the crud operations and errors are generated into the repository interface and the package
+			for _, op := range crud.Operations() {
+				if other, ok := methodNames[op]; ok {
+					v.errorf(other, "method '%s' of repository '%s' conflicts with its crud operation", op, repository.Name.String())
+					continue
+				}
+
+				methodNames[op] = crud.EntityType.Name
+			}
+
+			notFound, alreadyExists := crud.ErrorNames()
+			for _, e := range p.Errors {
+				if e.Name.String() == notFound || e.Name.String() == alreadyExists {
+					v.errorf(e.Name, "error '%s' conflicts with the generated error of the crud of '%s'", e.Name.String(), repository.Name.String())
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validateStruct(s pkgScope, str *Struct) {
+	names := map[string]token.String{}
+	for _, field := range str.Fields {
+		v.validateField(s, names, field)
+	}
+
+	for _, injection := range str.Inject {
+		v.requireName(injection.Name, "injection")
+		v.unique(names, injection.Name, "field or injection")
+		v.validateTypeDecl(s, injection.Type, injection.Name)
+	}
+
+	methodNames := map[string]token.String{}
+	for _, method := range str.Methods {
+		v.validateMethod(s, methodNames, method)
+	}
+}
+
+func (v *validator) validateEntity(s pkgScope, entity *Entity) {
+	if entity.Identity == nil {
+		v.errorf(entity.Component.Name, "entity '%s' has no identity", entity.Component.Name.String())
+		v.validateDomainObject(s, entity.Component, entity.Invariants)
+		return
+	}
+
+	v.validateDomainObject(s, entity.Component, entity.Invariants, entity.Identity)
+}
+
+// validateDomainObject checks the fields and invariants of entities and value objects.
+func (v *validator) validateDomainObject(s pkgScope, str *Struct, invariants []*Invariant, identity ...*Field) {
+	names := map[string]token.String{}
+	for _, field := range append(identity, str.Fields...) {
+		v.validateField(s, names, field)
+	}
+
+	invariantNames := map[string]token.String{}
+	for _, invariant := range invariants {
+		v.requireName(invariant.Name, "invariant")
+		v.unique(invariantNames, invariant.Name, "invariant")
+	}
+}
+
+func (v *validator) validateField(s pkgScope, names map[string]token.String, field *Field) {
+	v.requireName(field.Name, "field")
+	v.unique(names, field.Name, "field or injection")
+	if v.validateTypeDecl(s, field.Type, field.Name) {
+		for _, c := range field.Constraints {
+			v.validateConstraint(field, c)
+		}
+	}
+}
+
+// validateConstraint checks that the constraint is applicable to the type of the field and that its literals are
+// valid for that type.
+func (v *validator) validateConstraint(field *Field, c *Constraint) {
+	typeName := field.Type.Name.String()
+	integer := typeName == stdlib.Int || typeName == stdlib.Int16 || typeName == stdlib.Int32 || typeName == stdlib.Int64 || typeName == stdlib.Byte
+	number := integer || typeName == stdlib.Float32 || typeName == stdlib.Float64
+	collection := typeName == stdlib.Map || typeName == stdlib.List || field.Type.IsSlice()
+
+	applicable := true
+	var literals []token.String
+	switch c.Kind.String() {
+	case ConstraintRequired:
+		applicable = typeName != stdlib.Bool
+	case ConstraintMin, ConstraintMax:
+		applicable = number || typeName == stdlib.Duration
+		literals = append(literals, c.Value)
+	case ConstraintMinLength, ConstraintMaxLength:
+		applicable = typeName == stdlib.String || collection
+		if n, err := strconv.Atoi(c.Value.String()); err != nil || n < 0 {
+			v.errorf(c.Value, "invalid length '%s' of field '%s'", c.Value.String(), field.Name.String())
+		}
+	case ConstraintPattern:
+		applicable = typeName == stdlib.String
+		if _, err := regexp.Compile(c.Value.String()); err != nil {
+			v.errorf(c.Value, "invalid pattern of field '%s': %v", field.Name.String(), err)
+		}
+	case ConstraintOneOf:
+		applicable = typeName == stdlib.String || number
+		if len(c.Values) == 0 {
+			v.errorf(c.Kind, "one-of constraint of field '%s' has no values", field.Name.String())
+		}
+
+		if number {
+			literals = append(literals, c.Values...)
+		}
+	case ConstraintPredicate:
+		v.requireName(c.Value, "predicate")
+	default:
+		v.errorf(c.Kind, "unknown constraint '%s' of field '%s'", c.Kind.String(), field.Name.String())
+		return
+	}
+
+	if !applicable {
+		v.errorf(c.Kind, "%s constraint is not applicable to field '%s' of type '%s'", c.Kind.String(), field.Name.String(), typeName)
+		return
+	}
+
+	for _, literal := range literals {
+		var err error
+		switch {
+		case typeName == stdlib.Duration:
+			_, err = time.ParseDuration(literal.String())
+		case integer:
+			_, err = strconv.ParseInt(literal.String(), 10, 64)
+		default:
+			_, err = strconv.ParseFloat(literal.String(), 64)
+		}
+
+		if err != nil {
+			v.errorf(literal, "invalid %s literal '%s' of field '%s'", c.Kind.String(), literal.String(), field.Name.String())
+		}
+	}
+}
+
+func (v *validator) validateMethod(s pkgScope, names map[string]token.String, method *Method) {
+	v.requireName(method.Name, "method")
+	v.unique(names, method.Name, "method")
+
+	paramNames := map[string]token.String{}
+	for _, param := range method.In {
+		v.requireName(param.Name, "in parameter")
+		v.unique(paramNames, param.Name, "parameter")
+		v.validateTypeDecl(s, param.Type, param.Name)
+	}
+
+	for _, param := range method.Out {
+		if param.Name.String() != "" {
+			v.unique(paramNames, param.Name, "parameter")
+		}
+
+		v.validateTypeDecl(s, param.Type, param.Name)
+	}
+
+	for _, decl := range method.Errors {
+		if decl == nil {
+			v.errorf(method.Name, "method declares a nil error")
+			continue
+		}
+
+		if !v.checkVars(decl.Name, true) {
+			continue
+		}
+
+		fqn := v.qualify(s, decl.Name.String())
+		e, ok := v.errors[fqn]
+		if !ok {
+			v.errorf(decl.Name, "error '%s' is not declared", decl.Name.String())
+			continue
+		}
+
+		v.checkDependency(s, decl.Name, e)
+	}
+
+	for _, decl := range method.Events {
+		v.validateEvent(s, decl, method.Name)
+	}
+}
+
+// validateBinding checks the interface of an explicit binding. The implementation is usually generated and
+// cannot be resolved before rendering, so only its variables are checked.
+func (v *validator) validateBinding(s pkgScope, binding *Binding) {
+	v.resolveTypeName(s, binding.Interface)
+	v.checkVars(binding.Implementation, true)
+}
+
+// validateHttpEndpoint checks that the endpoint of a use case service method binds each in-parameter exactly once
+// and that its routing and error mapping is unambiguous.
+func (v *validator) validateHttpEndpoint(s pkgScope, method *Method) {
+	e := method.Http
+	if s.layer != layerUsecase {
+		v.errorf(e.Verb, "http endpoint of method '%s' requires a use case service", method.Name.String())
+	}
+
+	switch e.Verb.String() {
+	case HttpGet, HttpPost, HttpPut, HttpPatch, HttpDelete:
+	default:
+		v.errorf(e.Verb, "unsupported http verb '%s'", e.Verb.String())
+	}
+
+	vars, err := e.PathVars()
+	if err != nil {
+		v.errorf(e.Path, "invalid http path '%s': %v", e.Path.String(), err)
+	}
+
+	route := e.Verb.String() + " " + pathVarPattern.ReplaceAllString(e.Path.String(), "{}")
+	if other, ok := v.routes[route]; ok {
+		v.errorf(e.Path, "http endpoint '%s %s' is already declared at %s", e.Verb.String(), e.Path.String(), other.Begin().String())
+	} else {
+		v.routes[route] = e.Path
+	}
+
+	params := map[string]*Param{}
+	for _, param := range method.In {
+		params[param.Name.String()] = param
+	}
+
+	bound := map[string]token.String{}
+	bind := func(name token.String, scalar bool) {
+		param, ok := params[name.String()]
+		if !ok {
+			v.errorf(name, "'%s' does not refer to an in parameter of method '%s'", name.String(), method.Name.String())
+			return
+		}
+
+		if other, ok := bound[name.String()]; ok {
+			v.errorf(name, "parameter '%s' is already bound at %s", name.String(), other.Begin().String())
+			return
+		}
+
+		bound[name.String()] = name
+		if scalar && param.Type != nil && len(param.Type.TypeParams) > 0 {
+			v.errorf(name, "parameter '%s' of type '%s' cannot be bound to a path or query value", name.String(), param.Type.String())
+		}
+	}
+
+	for _, name := range vars {
+		bind(name, true)
+	}
+
+	for _, name := range e.Query {
+		bind(name, true)
+	}
+
+	if e.Body.String() != "" {
+		bind(e.Body, false)
+	}
+
+	for _, param := range method.In {
+		if _, ok := bound[param.Name.String()]; !ok && (param.Type == nil || param.Type.Name.String() != "context.Context") {
+			v.errorf(param.Name, "parameter '%s' of method '%s' is not bound to the http request", param.Name.String(), method.Name.String())
+		}
+	}
+
+	var results []*Param
+	for _, param := range method.Out {
+		if param.Type != nil && param.Type.Name.String() != stdlib.Error {
+			results = append(results, param)
+		}
+	}
+
+	if len(results) > 1 {
+		for _, param := range results {
+			if param.Name.String() == "" {
+				v.errorf(method.Name, "results of method '%s' require names to be encoded as a json object", method.Name.String())
+				break
+			}
+		}
+	}
+
+	for _, status := range e.Status {
+		declared := false
+		for _, decl := range method.Errors {
+			if decl != nil && decl.Name.String() == status.Error.String() {
+				declared = true
+				break
+			}
+		}
+
+		if !declared {
+			v.errorf(status.Error, "error '%s' is not declared by method '%s'", status.Error.String(), method.Name.String())
+		}
+
+		if status.Code < 100 || status.Code > 599 {
+			v.errorf(status.Error, "invalid http status code %d", status.Code)
+		}
+	}
+}
+
+// validateGrpcEndpoint checks that the rpc name is unique within the bounded context and that the error mapping
+// refers to declared error cases and valid status codes.
+func (v *validator) validateGrpcEndpoint(s pkgScope, method *Method) {
+	e := method.Grpc
+	if s.layer != layerUsecase {
+		v.errorf(method.Name, "grpc endpoint of method '%s' requires a use case service", method.Name.String())
+	}
+
+	rpc := s.bc + "." + method.Name.String()
+	if other, ok := v.rpcs[rpc]; ok {
+		v.errorf(method.Name, "grpc endpoint '%s' is already declared at %s", method.Name.String(), other.Begin().String())
+	} else {
+		v.rpcs[rpc] = method.Name
+	}
+
+	var results []*Param
+	for _, param := range method.Out {
+		if param.Type != nil && param.Type.Name.String() != stdlib.Error {
+			results = append(results, param)
+		}
+	}
+
+	if len(results) > 1 {
+		for _, param := range results {
+			if param.Name.String() == "" {
+				v.errorf(method.Name, "results of method '%s' require names to be encoded as message fields", method.Name.String())
+				break
+			}
+		}
+	}
+
+	for _, status := range e.Status {
+		declared := false
+		for _, decl := range method.Errors {
+			if decl != nil && decl.Name.String() == status.Error.String() {
+				declared = true
+				break
+			}
+		}
+
+		if !declared {
+			v.errorf(status.Error, "error '%s' is not declared by method '%s'", status.Error.String(), method.Name.String())
+		}
+
+		if !grpcCodes[status.Code.String()] {
+			v.errorf(status.Code, "invalid grpc status code '%s'", status.Code.String())
+		}
+	}
+}
+
+// rejectEndpoints reports http and grpc endpoints of methods, which are not provided by a service.
+func (v *validator) rejectEndpoints(methods []*Method) {
+	for _, method := range methods {
+		if method.Http != nil {
+			v.errorf(method.Http.Verb, "http endpoint of method '%s' requires a use case service", method.Name.String())
+		}
+
+		if method.Grpc != nil {
+			v.errorf(method.Name, "grpc endpoint of method '%s' requires a use case service", method.Name.String())
+		}
+	}
+}
+
+// validateEvent checks that the declaration refers to a declared event.
+func (v *validator) validateEvent(s pkgScope, decl *TypeDecl, owner token.String) {
+	if decl == nil {
+		v.errorf(owner, "'%s' declares a nil event", owner.String())
+		return
+	}
+
+	if !v.validateTypeDecl(s, decl, owner) {
+		return
+	}
+
+	if other, ok := v.types[v.qualify(s, decl.Name.String())]; !ok || other.kind != declEvent {
+		v.errorf(decl.Name, "'%s' is not a declared event", decl.Name.String())
+	}
+}
+
+func (v *validator) validateCRUD(s pkgScope, repository token.String, crud *CRUD) {
+	if crud.EntityType == nil {
+		v.errorf(repository, "crud of '%s' has no entity type", repository.String())
+		return
+	}
+
+	switch crud.Persistence {
+	case PMemory, PFile, PMySQL:
+	default:
+		v.errorf(crud.EntityType.Name, "crud has an unknown persistence type '%s'", crud.Persistence)
+	}
+
+	if !v.validateTypeDecl(s, crud.EntityType, crud.EntityType.Name) {
+		return
+	}
+
+	if crud.IDType != nil {
+		v.validateTypeDecl(s, crud.IDType, crud.IDType.Name)
+		return
+	}
+
+	decl, ok := v.types[v.qualify(s, crud.EntityType.Name.String())]
+	if !ok {
+		return
+	}
+
+	switch decl.kind {
+	case AggregateRootComponent:
+		return // the identity is mandatory
+	case EntityComponent, ValueObjectComponent:
+		v.errorf(crud.EntityType.Name, "crud entity type '%s' must be an aggregate root but is declared as %s", crud.EntityType.Name.String(), decl.kind)
+		return
+	}
+
+	if decl.strct == nil {
+		v.errorf(crud.EntityType.Name, "crud entity type '%s' must be a struct but is a %s", crud.EntityType.Name.String(), decl.kind)
+		return
+	}
+
+	for _, field := range decl.strct.Fields {
+		if field.Name.String() == "ID" {
+			return
+		}
+	}
+
+	v.errorf(crud.EntityType.Name, "crud entity type '%s' has neither a custom id type nor an ID field", crud.EntityType.Name.String())
+}
+
+// validateTypeDecl checks recursively that the declaration and all its type parameters can be resolved.
+// The owner is used as error position, if the declaration itself is missing.
+func (v *validator) validateTypeDecl(s pkgScope, t *TypeDecl, owner token.String) bool {
+	if t == nil {
+		v.errorf(owner, "'%s' has no type declaration", owner.String())
+		return false
+	}
+
+	name := t.Name.String()
+	switch {
+	case name == "":
+		v.errorf(t.Name, "empty type name")
+		return false
+	case name == "*":
+		if !t.IsPtr() {
+			v.errorf(t.Name, "a pointer requires exactly 1 type parameter but found %d", len(t.TypeParams))
+			return false
+		}
+	case name == "[]":
+		if t.IsArray() {
+			if n, err := strconv.Atoi(t.TypeParams[0].Name.String()); err != nil || n < 0 {
+				v.errorf(t.TypeParams[0].Name, "invalid array length '%s'", t.TypeParams[0].Name.String())
+				return false
+			}
+
+			return v.validateTypeDecl(s, t.TypeParams[1], owner)
+		}
+
+		if !t.IsSlice() {
+			v.errorf(t.Name, "a slice requires 1 and an array 2 type parameters but found %d", len(t.TypeParams))
+			return false
+		}
+	case name == stdlib.Map:
+		if !t.IsMap() {
+			v.errorf(t.Name, "a map requires exactly 2 type parameters but found %d", len(t.TypeParams))
+			return false
+		}
+	case strings.HasSuffix(name, "!"):
+		if !isStdlibType(name) {
+			v.errorf(t.Name, "'%s' is not a standard library type", name)
+			return false
+		}
+	default:
+		if !v.resolveTypeName(s, t.Name) {
+			return false
+		}
+	}
+
+	valid := true
+	for _, param := range t.TypeParams {
+		if !v.validateTypeDecl(s, param, owner) {
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+// resolveTypeName checks that a named type can be found. Unqualified names are resolved within the package
+// and qualified names are only resolved, if they refer to a package of the module. Everything else is
+// considered to be an external type, which cannot be inspected.
+func (v *validator) resolveTypeName(s pkgScope, name token.String) bool {
+	if !v.checkVars(name, true) {
+		return false
+	}
+
+	str := name.String()
+	if !strings.Contains(str, ".") && goBuiltinTypes[str] {
+		return true
+	}
+
+	fqn := v.qualify(s, str)
+	if decl, ok := v.types[fqn]; ok {
+		v.checkDependency(s, name, decl)
+		return true
+	}
+
+	if !strings.Contains(str, ".") || strings.HasPrefix(fqn, normalizePath(s.ctx.Mod)+"/") {
+		v.errorf(name, "type '%s' cannot be resolved", str)
+		return false
+	}
+
+	return true
+}
+
+// checkDependency reports a reference, which is not allowed by the dependencyRules.
+func (v *validator) checkDependency(s pkgScope, ref token.String, decl declaration) {
+	for _, rule := range dependencyRules[s.layer] {
+		if rule.layer == decl.layer && (!rule.sameBC || s.bc == decl.bc) {
+			return
+		}
+	}
+
+	v.errorf(ref, "the %s layer of bounded context '%s' must not refer to '%s' of the %s layer of bounded context '%s' (declared at %s)",
+		s.layer, s.bc, ref.String(), decl.layer, decl.bc, decl.name.Begin().String())
+}
+
+// qualify returns the full qualified and normalized name.
+func (v *validator) qualify(s pkgScope, name string) string {
+	name = s.ctx.applyString(name)
+	lastDot := strings.LastIndex(name, ".")
+	if lastDot < 0 {
+		return s.path + "." + name
+	}
+
+	return normalizePath(name[:lastDot]) + "." + name[lastDot+1:]
+}
+
+// checkVars ensures that the variables $MOD and $BC are only used as a prefix and that no other variables are used.
+// $BC is only valid within a bounded context.
+func (v *validator) checkVars(str token.String, allowBC bool) bool {
+	val := str.String()
+	for i := strings.Index(val, "$"); i >= 0; i = nextIndex(val, "$", i+1) {
+		rest := val[i:]
+		var variable string
+		switch {
+		case strings.HasPrefix(rest, nMOD):
+			variable = nMOD
+		case strings.HasPrefix(rest, nBC):
+			variable = nBC
+		default:
+			v.errorf(str, "unknown variable in '%s', only %s and %s are supported", val, nMOD, nBC)
+			return false
+		}
+
+		if i != 0 {
+			v.errorf(str, "%s must be used as a prefix in '%s'", variable, val)
+			return false
+		}
+
+		if len(rest) > len(variable) && rest[len(variable)] != '/' && rest[len(variable)] != '.' {
+			v.errorf(str, "%s must be followed by a / or . in '%s'", variable, val)
+			return false
+		}
+
+		if variable == nBC && !allowBC {
+			v.errorf(str, "%s is not valid outside of a bounded context in '%s'", nBC, val)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v *validator) requireName(name token.String, what string) {
+	if strings.TrimSpace(name.String()) == "" {
+		v.errorf(name, "%s has an empty name", what)
+	}
+}
+
+func (v *validator) unique(names map[string]token.String, name token.String, what string) {
+	if name.String() == "" {
+		return
+	}
+
+	if other, ok := names[name.String()]; ok {
+		v.errorf(name, "%s '%s' is already declared at %s", what, name.String(), other.Begin().String())
+		return
+	}
+
+	names[name.String()] = name
+}
+
+type bcLayer struct {
+	name string
+	pkgs []*Package
+}
+
+func bcLayers(bc *BoundedContext) []bcLayer {
+	return []bcLayer{{name: layerCore, pkgs: bc.Core}, {name: layerUsecase, pkgs: bc.Usecase}}
+}
+
+// bcPackagePath returns the normalized package path of a core or usecase package.
+func bcPackagePath(bcPath, layer string, p *Package) string {
+	path := bcPath + "/" + layer
+	if p.Name.String() != "" {
+		path += "/" + p.Name.String()
+	}
+
+	return normalizePath(path)
+}
+
+// normalizePath mimics the package path rules of the generator, which are lowercase and without spaces.
+func normalizePath(path string) string {
+	return strings.ReplaceAll(strings.ToLower(path), " ", "_")
+}
+
+func isStdlibType(name string) bool {
+	for _, t := range stdlib.Types {
+		if t == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nextIndex(s, substr string, from int) int {
+	if from >= len(s) {
+		return -1
+	}
+
+	i := strings.Index(s[from:], substr)
+	if i < 0 {
+		return -1
+	}
+
+	return i + from
+}
@@ -0,0 +1,474 @@
+package adl
+
+import (
+	"errors"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/stdlib"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newValidationProject(core *Package) *Project {
+	return NewProject("prj", "...is a test project.").
+		AddModules(
+			NewModule("mod", "...is a test module.").
+				SetGenerator(NewGenerator().SetGo(NewGolang().SetModName("example.com/mod"))).
+				AddExecutables(NewExecutable("srv", "...is a test server.").Application("$MOD/internal/tickets")).
+				AddBoundedContexts(NewBoundedContext("Tickets", "$MOD/internal/tickets").AddCore(core)),
+		)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		core    *Package
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			core: NewPackage("", "").
+				AddErrors(NewError("Other", "...is anything.")).
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(
+						NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)),
+						NewField("Parent", "...is optional.", NewTypeDecl("*", NewTypeDecl("$BC/core.Ticket"))),
+						NewField("Tags", "...are tags.", NewTypeDecl(stdlib.Map, NewTypeDecl(stdlib.String), NewTypeDecl("sync.Mutex"))),
+					)).
+				AddRepositories(NewInterface("Tickets", "...is a repo.").
+					AddMethods(NewMethod("Find", "...finds.").
+						AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+						AddOut("", "...is the ticket.", NewTypeDecl("Ticket")).
+						AddErrors(NewTypeDecl("Other"))).
+					AddCRUDImpl(NewCRUD(NewTypeDecl("Ticket"), nil, PMemory, true, true, true, true, true, true, true))),
+		},
+		{
+			name: "unresolved types",
+			core: NewPackage("", "").
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(
+						NewField("A", "...is unknown.", NewTypeDecl("Tick")),
+						NewField("B", "...is unknown.", NewTypeDecl("$BC/core.Tick")),
+						NewField("C", "...is unknown.", NewTypeDecl("stuff!")),
+					)),
+			wantErr: []string{"type 'Tick' cannot be resolved", "type '$BC/core.Tick' cannot be resolved", "'stuff!' is not a standard library type"},
+		},
+		{
+			name: "duplicates and empty names",
+			core: NewPackage("", "").
+				AddStructs(
+					NewDTO("Ticket", "...is a ticket.").
+						AddFields(
+							NewField("A", "...is a.", NewTypeDecl(stdlib.Int)),
+							NewField("A", "...is a again.", NewTypeDecl(stdlib.Int)),
+							NewField("", "...has no name.", NewTypeDecl(stdlib.Int)),
+						),
+					NewDTO("Ticket", "...is a ticket again."),
+				),
+			wantErr: []string{"dto 'Ticket' is already declared", "field or injection 'A' is already declared", "field has an empty name"},
+		},
+		{
+			name: "invalid variables",
+			core: NewPackage("", "").
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(
+						NewField("A", "...is misplaced.", NewTypeDecl("x/$BC/core.Ticket")),
+						NewField("B", "...is unknown.", NewTypeDecl("$BCX/core.Ticket")),
+						NewField("C", "...is unknown.", NewTypeDecl("$FOO/core.Ticket")),
+					)),
+			wantErr: []string{"$BC must be used as a prefix", "$BC must be followed by", "unknown variable"},
+		},
+		{
+			name: "undeclared errors and crud without id",
+			core: NewPackage("", "").
+				AddStructs(NewDTO("Ticket", "...is a ticket.")).
+				AddRepositories(NewInterface("Tickets", "...is a repo.").
+					AddMethods(NewMethod("Find", "...finds.").AddErrors(NewTypeDecl("NotFound"))).
+					AddCRUDImpl(NewCRUD(NewTypeDecl("Ticket"), nil, PMemory, true, true, true, true, true, true, true))),
+			wantErr: []string{"error 'NotFound' is not declared", "has neither a custom id type nor an ID field"},
+		},
+		{
+			name: "valid aggregate",
+			core: NewPackage("", "").
+				AddValueObjects(NewValueObject("Money", "...is an amount.").
+					AddFields(NewField("Cents", "...are the cents.", NewTypeDecl(stdlib.Int))).
+					AddInvariants(NewInvariant("Positive", "...ensures a positive amount."))).
+				AddAggregates(NewAggregate("Order", "...is an order.").
+					SetRoot(NewEntity("Order", "...is the root.").
+						SetIdentity(NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID))).
+						AddFields(NewField("Total", "...is the total.", NewTypeDecl("Money")))).
+					AddEntities(NewEntity("Item", "...is an order item.").
+						SetIdentity(NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID))))).
+				AddRepositories(NewInterface("Orders", "...is a repo.").
+					AddCRUDImpl(NewCRUD(NewTypeDecl("Order"), nil, PMemory, true, true, true, true, true, true, true))),
+		},
+		{
+			name: "invalid aggregate",
+			core: NewPackage("", "").
+				AddValueObjects(NewValueObject("Money", "...is an amount.").
+					AddInvariants(NewInvariant("", "...has no name."))).
+				AddAggregates(
+					NewAggregate("Order", "...is an order.").
+						AddEntities(NewEntity("Item", "...is an order item.")),
+				).
+				AddRepositories(NewInterface("Items", "...is a repo.").
+					AddCRUDImpl(NewCRUD(NewTypeDecl("Item"), nil, PMemory, true, true, true, true, true, true, true))),
+			wantErr: []string{"aggregate 'Order' has no root entity", "entity 'Item' has no identity", "invariant has an empty name", "must be an aggregate root but is declared as entity"},
+		},
+		{
+			name: "valid events",
+			core: NewPackage("", "").
+				AddEvents(NewEvent("TicketCreated", "...is raised for new tickets.").
+					AddFields(NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)))).
+				AddServices(NewService("Tickets", "...is a service.").
+					AddMethods(NewMethod("Create", "...creates.").AddEvents(NewTypeDecl("TicketCreated"))).
+					AddSubscriptions(NewTypeDecl("$BC/core.TicketCreated"))),
+		},
+		{
+			name: "invalid events",
+			core: NewPackage("", "").
+				AddStructs(NewDTO("Ticket", "...is a ticket.")).
+				AddEvents(NewEvent("TicketCreated", "...is raised for new tickets.")).
+				AddServices(NewService("Tickets", "...is a service.").
+					AddMethods(NewMethod("Create", "...creates.").AddEvents(NewTypeDecl("TicketDeleted"))).
+					AddSubscriptions(NewTypeDecl("Ticket"))),
+			wantErr: []string{"type 'TicketDeleted' cannot be resolved", "'Ticket' is not a declared event"},
+		},
+		{
+			name: "colliding event handlers",
+			core: NewPackage("", "").
+				AddEvents(NewEvent("TicketCreated", "...is raised for new tickets.")).
+				AddServices(
+					NewService("Tickets", "...is a service.").
+						AddSubscriptions(NewTypeDecl("TicketCreated"), NewTypeDecl("$BC/core.TicketCreated")),
+					NewService("Mails", "...is a service.").
+						AddMethods(NewMethod("OnTicketCreated", "...handles by hand.")).
+						AddSubscriptions(NewTypeDecl("TicketCreated")),
+				),
+			wantErr: []string{"handler 'OnTicketCreated' of event '$BC/core.TicketCreated' conflicts with 'TicketCreated'", "handler 'OnTicketCreated' of event 'TicketCreated' conflicts with 'OnTicketCreated'"},
+		},
+		{
+			name: "crud conflicts",
+			core: NewPackage("", "").
+				AddErrors(NewError("TicketNotFound", "...is declared by hand.")).
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)))).
+				AddRepositories(NewInterface("Tickets", "...is a repo.").
+					AddMethods(NewMethod("FindOne", "...finds.")).
+					AddCRUDImpl(NewCRUD(NewTypeDecl("Ticket"), nil, PMemory, true, true, false, false, false, false, false))),
+			wantErr: []string{"method 'FindOne' of repository 'Tickets' conflicts with its crud operation", "error 'TicketNotFound' conflicts with the generated error of the crud of 'Tickets'"},
+		},
+		{
+			name: "valid lifecycle",
+			core: NewPackage("", "").
+				AddServices(NewService("Tickets", "...is a service.").
+					AddMethods(NewMethod("Create", "...creates.")).
+					SetLifecycle(true)),
+		},
+		{
+			name: "invalid lifecycle",
+			core: NewPackage("", "").
+				AddServices(NewService("Tickets", "...is a service.").
+					AddMethods(NewMethod("Start", "...starts.")).
+					SetLifecycle(true)),
+			wantErr: []string{"method 'Start' of service 'Tickets' conflicts with its lifecycle hook"},
+		},
+		{
+			name: "valid enums",
+			core: NewPackage("", "").
+				AddEnums(
+					NewEnum("Priority", "...is an urgency.").AddCases(NewEnumCase("low", "...can wait."), NewEnumCase("high", "...is urgent.")),
+					NewEnum("Reply", "...is a reply.").AddCases(
+						NewEnumCase("Text", "...is a text.").AddFields(NewField("Body", "...is the text.", NewTypeDecl(stdlib.String))),
+						NewEnumCase("Closed", "...closes."),
+					),
+				).
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(NewField("Prio", "...is the priority.", NewTypeDecl("Priority")))),
+		},
+		{
+			name: "invalid enums",
+			core: NewPackage("", "").
+				AddEnums(
+					NewEnum("Priority", "...is an urgency."),
+					NewEnum("Reply", "...is a reply.").AddCases(
+						NewEnumCase("Text", "...is a text.").AddFields(NewField("Body", "...is unknown.", NewTypeDecl("Text"))),
+						NewEnumCase("Text", "...is a text again."),
+					),
+				),
+			wantErr: []string{"enum 'Priority' has no cases", "type 'Text' cannot be resolved", "enum case 'Text' is already declared"},
+		},
+		{
+			name: "valid constraints",
+			core: NewPackage("", "").
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(
+						NewField("Title", "...is the title.", NewTypeDecl(stdlib.String)).AddConstraints(NewRequired(), NewMinLength(3), NewPattern("^[A-Z]"), NewOneOf("A", "B")),
+						NewField("Score", "...is the score.", NewTypeDecl(stdlib.Int)).AddConstraints(NewMin("0"), NewMax("10"), NewPredicate("even", "...must be even.")),
+						NewField("Timeout", "...is the timeout.", NewTypeDecl(stdlib.Duration)).AddConstraints(NewMax("1m")),
+						NewField("Tags", "...are tags.", NewTypeDecl("[]", NewTypeDecl(stdlib.String))).AddConstraints(NewMaxLength(3)),
+					)),
+		},
+		{
+			name: "invalid constraints",
+			core: NewPackage("", "").
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(
+						NewField("Title", "...is the title.", NewTypeDecl(stdlib.String)).AddConstraints(NewMin("1"), NewPattern("[")),
+						NewField("Score", "...is the score.", NewTypeDecl(stdlib.Int)).AddConstraints(NewMax("1.5"), NewOneOf(), NewPredicate("", "...has no name.")),
+						NewField("Done", "...is done.", NewTypeDecl(stdlib.Bool)).AddConstraints(NewRequired()),
+					)),
+			wantErr: []string{
+				"min constraint is not applicable to field 'Title'",
+				"invalid pattern of field 'Title'",
+				"invalid max literal '1.5' of field 'Score'",
+				"one-of constraint of field 'Score' has no values",
+				"predicate has an empty name",
+				"required constraint is not applicable to field 'Done'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(newValidationProject(tt.core))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatal(token.Explain(err))
+				}
+
+				return
+			}
+
+			var posErr *token.PosError
+			if !errors.As(err, &posErr) {
+				t.Fatalf("expected a PosError but got %v", err)
+			}
+
+			details := posErr.Details[1:]
+			if len(details) != len(tt.wantErr) {
+				t.Fatalf("expected %d details but got:\n%s", len(tt.wantErr), token.Explain(err))
+			}
+
+			for i, want := range tt.wantErr {
+				if !strings.Contains(details[i].Message, want) {
+					t.Errorf("expected detail %d to contain %q but got %q", i, want, details[i].Message)
+				}
+
+				if filepath.Base(details[i].Node.Begin().File) != "validate_test.go" {
+					t.Errorf("expected detail %d to point into the test file but got %s", i, details[i].Node.Begin().String())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateExecutable(t *testing.T) {
+	prj := newValidationProject(NewPackage("", ""))
+	prj.Modules[0].AddExecutables(NewExecutable("other", "...refers to nothing.").Application("$MOD/internal/unknown", "$BC/core"))
+
+	var posErr *token.PosError
+	if err := Validate(prj); !errors.As(err, &posErr) || len(posErr.Details) != 3 {
+		t.Fatalf("expected 2 invalid bounded context paths but got %v", token.Explain(err))
+	}
+
+	prj = newValidationProject(NewPackage("", ""))
+	prj.Modules[0].AddExecutables(
+		NewExecutable("ops", "...is a command line tool.").SetKind(ExecutableCli).Application("$MOD/internal/tickets"),
+		NewExecutable("daemon", "...has an unknown kind.").SetKind("daemon").Application("$MOD/internal/tickets"),
+	)
+
+	posErr = nil
+	if err := Validate(prj); !errors.As(err, &posErr) || len(posErr.Details) != 2 || !strings.Contains(posErr.Details[1].Message, "invalid executable kind 'daemon'") {
+		t.Fatalf("expected a single invalid executable kind but got %v", token.Explain(err))
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddStructs(NewDTO("Ticket", "...is a ticket.").
+			AddFields(
+				NewField("Self", "...is in the same core.", NewTypeDecl("*", NewTypeDecl("$BC/core.Ticket"))),
+				NewField("Cfg", "...is from the usecase layer.", NewTypeDecl("$BC/usecase.Config")),
+				NewField("Invoice", "...is from another bounded context.", NewTypeDecl("$MOD/internal/billing/core.Invoice")),
+			)))
+
+	prj.Modules[0].BoundedContexts[0].AddUsecase(NewPackage("", "").
+		AddStructs(NewConfig("Config", "...is a config.")).
+		AddServices(NewService("Tickets", "...is a service.").
+			AddInjections(
+				NewInjection("cfg", "...is from the same usecase layer.", Cfg, NewTypeDecl("$BC/usecase.Config")),
+				NewInjection("billing", "...is from another bounded context.", ServiceComponent, NewTypeDecl("$MOD/internal/billing/usecase.Billing")),
+			).
+			AddMethods(NewMethod("Find", "...finds.").
+				AddOut("", "...is from the own core.", NewTypeDecl("$BC/core.Ticket")).
+				AddErrors(NewTypeDecl("$MOD/internal/billing/core.Overdue")))))
+
+	// the application layer wires everything together, so binding the interface of another context is fine
+	prj.Modules[0].BoundedContexts[0].Bind("$MOD/internal/billing/core.Invoices", "$MOD/internal/billing/core.InMemoryInvoices")
+
+	prj.Modules[0].AddBoundedContexts(NewBoundedContext("Billing", "$MOD/internal/billing").
+		AddCore(NewPackage("", "").
+			AddErrors(NewError("Overdue", "...is an unpaid invoice.")).
+			AddStructs(NewDTO("Invoice", "...is an invoice.")).
+			AddRepositories(NewInterface("Invoices", "...stores invoices."))).
+		AddUsecase(NewPackage("", "").AddServices(NewService("Billing", "...is a service."))))
+
+	wantErr := []string{
+		"the core layer of bounded context 'Tickets' must not refer to '$BC/usecase.Config' of the usecase layer of bounded context 'Tickets'",
+		"the core layer of bounded context 'Tickets' must not refer to '$MOD/internal/billing/core.Invoice' of the core layer of bounded context 'Billing'",
+		"the usecase layer of bounded context 'Tickets' must not refer to '$MOD/internal/billing/usecase.Billing' of the usecase layer of bounded context 'Billing'",
+		"the usecase layer of bounded context 'Tickets' must not refer to '$MOD/internal/billing/core.Overdue' of the core layer of bounded context 'Billing'",
+	}
+
+	// the first detail is the summary of all violations
+	var posErr *token.PosError
+	if err := Validate(prj); !errors.As(err, &posErr) || len(posErr.Details) != len(wantErr)+1 {
+		t.Fatalf("expected %d dependency violations but got %v", len(wantErr), token.Explain(err))
+	}
+
+	for i, want := range wantErr {
+		if detail := posErr.Details[i+1]; !strings.Contains(detail.Message, want) || filepath.Base(detail.Node.Begin().File) != "validate_test.go" {
+			t.Errorf("expected detail %d to contain %q but got %q at %s", i, want, detail.Message, detail.Node.Begin().String())
+		}
+	}
+}
+
+func TestValidateHttp(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddErrors(NewError("NotFound", "...is missing.")).
+		AddStructs(NewDTO("Ticket", "...is a ticket.")).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddMethods(NewMethod("Find", "...finds.").SetHttp(NewHttpEndpoint(HttpGet, "/repo")))))
+
+	prj.Modules[0].BoundedContexts[0].AddUsecase(NewPackage("", "").
+		AddServices(NewService("Tickets", "...is a service.").
+			AddMethods(
+				NewMethod("Find", "...finds.").
+					AddIn("ctx", "...is the context.", NewTypeDecl("context.Context")).
+					AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+					AddIn("verbose", "...is optional.", NewTypeDecl(stdlib.Bool)).
+					AddOut("", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+					AddErrors(NewTypeDecl("$BC/core.NotFound")).
+					SetHttp(NewHttpEndpoint(HttpGet, "/tickets/{id}").BindQuery("verbose").MapError("$BC/core.NotFound", 404)),
+				NewMethod("Update", "...updates.").
+					AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+					AddIn("ticket", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+					SetHttp(NewHttpEndpoint(HttpPut, "/tickets/{id}").BindBody("ticket")),
+				NewMethod("Replace", "...replaces.").
+					AddIn("key", "...is the id.", NewTypeDecl(stdlib.UUID)).
+					SetHttp(NewHttpEndpoint(HttpPut, "/tickets/{key}")),
+				NewMethod("List", "...lists.").
+					AddIn("tags", "...are tags.", NewTypeDecl("[]", NewTypeDecl(stdlib.String))).
+					AddIn("other", "...is unbound.", NewTypeDecl(stdlib.String)).
+					AddOut("", "...are the tickets.", NewTypeDecl("[]", NewTypeDecl("$BC/core.Ticket"))).
+					AddOut("count", "...is the total.", NewTypeDecl(stdlib.Int64)).
+					SetHttp(NewHttpEndpoint("FETCH", "/tickets/{key}").BindQuery("tags", "tags").MapError("Missing", 999)),
+				NewMethod("Delete", "...deletes.").
+					SetHttp(NewHttpEndpoint(HttpDelete, "tickets/{}")),
+			)))
+
+	wantErr := []string{
+		"http endpoint of method 'Find' requires a use case service",
+		"http endpoint 'PUT /tickets/{key}' is already declared at",
+		"unsupported http verb 'FETCH'",
+		"'key' does not refer to an in parameter of method 'List'",
+		"parameter 'tags' of type '[]string!' cannot be bound to a path or query value",
+		"parameter 'tags' is already bound at",
+		"parameter 'other' of method 'List' is not bound to the http request",
+		"results of method 'List' require names to be encoded as a json object",
+		"error 'Missing' is not declared by method 'List'",
+		"invalid http status code 999",
+		"invalid http path 'tickets/{}': path must start with '/'",
+	}
+
+	var posErr *token.PosError
+	if err := Validate(prj); !errors.As(err, &posErr) || len(posErr.Details) != len(wantErr)+1 {
+		t.Fatalf("expected %d invalid http endpoints but got %v", len(wantErr), token.Explain(err))
+	}
+
+	for i, want := range wantErr {
+		if detail := posErr.Details[i+1]; !strings.Contains(detail.Message, want) || filepath.Base(detail.Node.Begin().File) != "validate_test.go" {
+			t.Errorf("expected detail %d to contain %q but got %q at %s", i, want, detail.Message, detail.Node.Begin().String())
+		}
+	}
+}
+
+func TestValidateGrpc(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddErrors(NewError("NotFound", "...is missing.")).
+		AddStructs(NewDTO("Ticket", "...is a ticket.")).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddMethods(NewMethod("Find", "...finds.").SetGrpc(NewGrpcEndpoint()))))
+
+	prj.Modules[0].BoundedContexts[0].AddUsecase(NewPackage("", "").
+		AddServices(
+			NewService("Tickets", "...is a service.").
+				AddMethods(
+					NewMethod("Find", "...finds.").
+						AddIn("ctx", "...is the context.", NewTypeDecl("context.Context")).
+						AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+						AddOut("", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+						AddErrors(NewTypeDecl("$BC/core.NotFound")).
+						SetGrpc(NewGrpcEndpoint().MapError("$BC/core.NotFound", GrpcNotFound)),
+					NewMethod("List", "...lists.").
+						AddOut("", "...are the tickets.", NewTypeDecl("[]", NewTypeDecl("$BC/core.Ticket"))).
+						AddOut("count", "...is the total.", NewTypeDecl(stdlib.Int64)).
+						SetGrpc(NewGrpcEndpoint().MapError("Missing", "Gone")),
+				),
+			NewService("Others", "...is another service.").
+				AddMethods(NewMethod("Find", "...finds.").SetGrpc(NewGrpcEndpoint())),
+		))
+
+	wantErr := []string{
+		"grpc endpoint of method 'Find' requires a use case service",
+		"results of method 'List' require names to be encoded as message fields",
+		"error 'Missing' is not declared by method 'List'",
+		"invalid grpc status code 'Gone'",
+		"grpc endpoint 'Find' is already declared at",
+		"module exposes grpc methods but has no protobuf lock file",
+	}
+
+	var posErr *token.PosError
+	if err := Validate(prj); !errors.As(err, &posErr) || len(posErr.Details) != len(wantErr)+1 {
+		t.Fatalf("expected %d invalid grpc endpoints but got %v", len(wantErr), token.Explain(err))
+	}
+
+	for i, want := range wantErr {
+		if detail := posErr.Details[i+1]; !strings.Contains(detail.Message, want) || filepath.Base(detail.Node.Begin().File) != "validate_test.go" {
+			t.Errorf("expected detail %d to contain %q but got %q at %s", i, want, detail.Message, detail.Node.Begin().String())
+		}
+	}
+}
+
+func TestValidateCrudErrors(t *testing.T) {
+	prj := newValidationProject(NewPackage("", "").
+		AddStructs(NewDTO("Ticket", "...is a ticket.").
+			AddFields(NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)))).
+		AddRepositories(NewInterface("Tickets", "...is a repo.").
+			AddCRUDImpl(NewCRUD(NewTypeDecl("Ticket"), nil, PMemory, true, true, false, false, false, false, false))))
+
+	prj.Modules[0].BoundedContexts[0].AddUsecase(NewPackage("", "").
+		AddServices(NewService("Tickets", "...is a service.").
+			AddMethods(
+				NewMethod("Find", "...finds.").
+					AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+					AddOut("", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+					AddOut("", "...if anything goes wrong.", NewTypeDecl(stdlib.Error)).
+					AddErrors(NewTypeDecl("$BC/core.TicketNotFound")).
+					SetHttp(NewHttpEndpoint(HttpGet, "/tickets/{id}").MapError("$BC/core.TicketNotFound", 404)),
+				NewMethod("Create", "...creates.").
+					AddIn("ticket", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+					AddOut("", "...if anything goes wrong.", NewTypeDecl(stdlib.Error)).
+					AddErrors(NewTypeDecl("$BC/core.TicketAlreadyExists"), NewTypeDecl("$BC/core.TicketGone")),
+			)))
+
+	var posErr *token.PosError
+	if err := Validate(prj); !errors.As(err, &posErr) || len(posErr.Details) != 2 {
+		t.Fatalf("expected only the undeclared error but got %v", token.Explain(err))
+	}
+
+	if detail := posErr.Details[1]; !strings.Contains(detail.Message, "error '$BC/core.TicketGone' is not declared") {
+		t.Fatalf("unexpected detail %q", detail.Message)
+	}
+}
@@ -0,0 +1,238 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	gotoken "go/token"
+	"strings"
+)
+
+// renderAggregate emits the root entity, the other entities and the value objects of the aggregate.
+// Value objects are emitted first, so that the entities can resolve their equality methods.
+func renderAggregate(parent *ast.File, aggregate *adl.Aggregate) error {
+	for _, value := range aggregate.ValueObjects {
+		if _, err := addValueObject(parent, value); err != nil {
+			return err
+		}
+	}
+
+	if aggregate.Root != nil {
+		if _, err := addEntity(parent, aggregate.Root); err != nil {
+			return err
+		}
+	}
+
+	for _, entity := range aggregate.Entities {
+		if _, err := addEntity(parent, entity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addEntity emits a mutable struct whose identity is only accessible through its accessor method.
+// The constructor requires all fields and checks the invariants.
+func addEntity(parent *ast.File, entity *adl.Entity) (*ast.Struct, error) {
+	compo := entity.Component
+	recName := strings.ToLower(compo.Name.String()[0:1])
+	typ := ast.NewStruct(compo.Name.String()).
+		SetComment(compo.Comment.String() + "\n\nThe stereotype of this type is '" + compo.Stereotype.String() + "'. It is distinguished by its identity.").
+		SetDefaultRecName(recName)
+	parent.AddTypes(typ)
+
+	identity := entity.Identity
+	typ.AddFields(ast.NewField(paramName(identity.Name.String()), astutil.MakeTypeDecl(identity.Type)).
+		SetComment(identity.Comment.String()).
+		SetVisibility(ast.Private))
+
+	accessor := golang.MakePublic(identity.Name.String())
+	typ.AddMethods(ast.NewFunc(accessor).
+		SetComment("...returns the identity of this entity.").
+		SetRecName(recName).
+		AddResults(ast.NewParam("", astutil.MakeTypeDecl(identity.Type))).
+		SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit(recName + "." + paramName(identity.Name.String()))))))
+	stereotype.StructFrom(typ).SetIdentityAccessor(accessor)
+
+	assigns := paramName(identity.Name.String()) + ": " + paramName(identity.Name.String()) + ",\n"
+	for _, field := range compo.Fields {
+		f := ast.NewField(field.Name.String(), astutil.MakeTypeDecl(field.Type)).SetComment(field.Comment.String())
+		if field.Private {
+			f.SetVisibility(ast.Private)
+		}
+
+		typ.AddFields(f)
+		assigns += field.Name.String() + ": " + paramName(field.Name.String()) + ",\n"
+	}
+
+	constructor := ast.NewFunc("New" + golang.MakePublic(compo.Name.String())).
+		SetComment("...creates a new " + compo.Name.String() + " entity and checks its invariants.")
+	for _, field := range append([]*adl.Field{identity}, compo.Fields...) {
+		constructor.AddParams(ast.NewParam(paramName(field.Name.String()), astutil.MakeTypeDecl(field.Type)).SetComment(field.Comment.String()))
+	}
+
+	fields := append([]*adl.Field{identity}, compo.Fields...)
+	fieldName := func(name string) string {
+		if name == identity.Name.String() {
+			return paramName(name)
+		}
+
+		return name
+	}
+
+	if err := addInvariants(parent, typ, constructor, compo, entity.Invariants, assigns, fields, fieldName); err != nil {
+		return nil, err
+	}
+
+	return typ, nil
+}
+
+// addValueObject emits an immutable struct with unexported fields, getters, a constructor and an Equal method.
+func addValueObject(parent *ast.File, value *adl.ValueObject) (*ast.Struct, error) {
+	compo := value.Component
+	recName := strings.ToLower(compo.Name.String()[0:1])
+	typ := ast.NewStruct(compo.Name.String()).
+		SetComment(compo.Comment.String() + "\n\nThe stereotype of this type is '" + compo.Stereotype.String() + "'. It is immutable and only distinguished by its values.").
+		SetDefaultRecName(recName)
+	parent.AddTypes(typ)
+
+	constructor := ast.NewFunc("New" + golang.MakePublic(compo.Name.String())).
+		SetComment("...creates a new " + compo.Name.String() + " value and checks its invariants.")
+
+	assigns := ""
+	var equals []string
+	for _, field := range compo.Fields {
+		private := paramName(field.Name.String())
+		typ.AddFields(ast.NewField(private, astutil.MakeTypeDecl(field.Type)).
+			SetComment(field.Comment.String()).
+			SetVisibility(ast.Private))
+
+		if !field.Private {
+			typ.AddMethods(ast.NewFunc(golang.MakePublic(field.Name.String())).
+				SetComment(field.Comment.String()).
+				SetRecName(recName).
+				AddResults(ast.NewParam("", astutil.MakeTypeDecl(field.Type))).
+				SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit(recName + "." + private)))))
+		}
+
+		constructor.AddParams(ast.NewParam(paramName(field.Name.String()), astutil.MakeTypeDecl(field.Type)).SetComment(field.Comment.String()))
+		assigns += private + ": " + paramName(field.Name.String()) + ",\n"
+		equals = append(equals, equalExpr(parent, field.Type, recName+"."+private, "other."+private))
+	}
+
+	equalBody := "return true"
+	if len(equals) > 0 {
+		equalBody = "return " + strings.Join(equals, " &&\n")
+	}
+
+	typ.AddMethods(ast.NewFunc("Equal").
+		SetComment("...returns true, if all values are equal.").
+		SetRecName(recName).
+		AddParams(ast.NewParam("other", ast.NewSimpleTypeDecl(ast.Name(parent.Pkg().Path+"."+compo.Name.String())))).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Bool))).
+		SetBody(ast.NewBlock(ast.NewTpl(equalBody))))
+
+	if err := addInvariants(parent, typ, constructor, compo, value.Invariants, assigns, compo.Fields, paramName); err != nil {
+		return nil, err
+	}
+
+	return typ, nil
+}
+
+// addInvariants completes the constructor, which validates the field constraints, and emits a check method for
+// each invariant. The default check methods and custom predicates are declared by an embedded stub type and
+// should be shadowed by a concrete implementation.
+func addInvariants(parent *ast.File, typ *ast.Struct, constructor *ast.Func, compo *adl.Struct, invariants []*adl.Invariant, assigns string, fields []*adl.Field, fieldName func(string) string) error {
+	name := compo.Name.String()
+	typeDecl := ast.NewSimpleTypeDecl(ast.Name(parent.Pkg().Path + "." + name))
+	constructor.AddResults(
+		ast.NewParam("", typeDecl),
+		ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+	)
+
+	typ.AddFactoryRefs(constructor)
+	parent.AddNodes(constructor)
+
+	var defaultType *ast.Struct
+	if len(invariants) > 0 || golang.HasPredicates(fields) {
+		defaultType = ast.NewStruct(golang.MakePrivate("Default" + name)).
+			SetComment("...is an implementation stub for the invariants of " + name + ".\nThe sole purpose of this type is to declare the invariants and each method should be shadowed\nby a concrete implementation.").
+			SetVisibility(ast.Private)
+
+		typ.AddEmbedded(ast.NewSimpleTypeDecl(ast.Name(defaultType.TypeName)))
+		parent.AddTypes(defaultType)
+	}
+
+	body := "return " + name + "{\n" + assigns + "}"
+	if golang.HasConstraints(fields) {
+		if _, err := golang.AddValidateFunc(typ, fields, fieldName, defaultType); err != nil {
+			return err
+		}
+
+		body = "obj := " + name + "{\n" + assigns + "}\n" +
+			"if err := obj.Validate(); err != nil {\nreturn " + name + "{}, {{.Use \"fmt.Errorf\"}}(\"invalid '" + name + "': %w\", err)\n}\n\n" +
+			"return obj"
+	}
+
+	if len(invariants) == 0 {
+		constructor.SetBody(ast.NewBlock(ast.NewTpl(body + ", nil\n")))
+		return nil
+	}
+
+	constructor.SetBody(ast.NewBlock(ast.NewTpl(body + ".checkInvariants()\n")))
+
+	checks := ""
+	for _, invariant := range invariants {
+		check := "check" + golang.MakePublic(invariant.Name.String())
+		defaultType.AddMethods(ast.NewFunc(check).
+			SetComment(invariant.Comment.String() + "\nShadow this method as required.").
+			SetVisibility(ast.Private).
+			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+			SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit("nil")))))
+
+		checks += "if err := " + typ.DefaultRecName + "." + check + "(); err != nil {\n" +
+			"return " + name + "{}, {{.Use \"fmt.Errorf\"}}(\"invariant '" + invariant.Name.String() + "' of '" + name + "' violated: %w\", err)\n}\n\n"
+	}
+
+	typ.AddMethods(ast.NewFunc("checkInvariants").
+		SetComment("...checks all invariants and returns either the unchanged instance or the first violation.").
+		SetVisibility(ast.Private).
+		SetRecName(typ.DefaultRecName).
+		AddResults(
+			ast.NewParam("", typeDecl.Clone()),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		).
+		SetBody(ast.NewBlock(ast.NewTpl(checks + "return " + typ.DefaultRecName + ", nil\n"))))
+
+	return nil
+}
+
+// equalExpr returns a boolean expression which compares the values a and b of the given type. Simple types are
+// compared directly, other value objects by their Equal method and everything else deeply.
+func equalExpr(ctx ast.Node, t *adl.TypeDecl, a, b string) string {
+	name := t.Name.String()
+	switch {
+	case name == stdlib.Time:
+		return a + ".Equal(" + b + ")"
+	case len(t.TypeParams) == 0 && name != stdlib.Map && (strings.HasSuffix(name, "!") || !strings.Contains(name, ".")) && astutil.Resolve(ctx, name) == nil:
+		return a + " == " + b
+	case len(t.TypeParams) == 0 && astutil.MethodByName(astutil.Resolve(ctx, name), "Equal") != nil:
+		return a + ".Equal(" + b + ")"
+	default:
+		return "{{.Use \"reflect.DeepEqual\"}}(" + a + ", " + b + ")"
+	}
+}
+
+// paramName converts the field name into a private field or parameter name, which is not a keyword.
+func paramName(fieldName string) string {
+	name := golang.MakePrivate(fieldName)
+	if gotoken.IsKeyword(name) {
+		return name + "_"
+	}
+
+	return name
+}
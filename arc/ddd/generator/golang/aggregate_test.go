@@ -0,0 +1,70 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderAggregate(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            value Money "...is an amount." {
+                field Cents int64! "...are the cents."
+                invariant Positive "...ensures a positive amount."
+            }
+
+            aggregate Order "...is an order." {
+                root Order "...is the root." {
+                    id ID uuid! "...is the id."
+                    field Total Money "...is the total."
+                }
+                entity Item "...is an order item." {
+                    id ID int64! "...is the id."
+                }
+            }
+
+            repository Orders "...stores orders." {
+                crud $BC/core.Order in-memory { all }
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/values.go",
+		"func (m Money) Equal(other Money) bool {",
+		"func NewMoney(cents int64) (Money, error) {",
+	)
+	assertContains(t, files, "internal/tickets/core/aggregates.go",
+		"func (o Order) ID() uuid.UUID {",
+		"func NewItem(id int64) (Item, error) {",
+	)
+	// values are immutable and compared by their fields
+	files["internal/tickets/core/aggregates_test.go"] = `package core
+
+import (
+	"github.com/golangee/uuid"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	total, err := NewMoney(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := NewMoney(42)
+	if total.Cents() != 42 || !total.Equal(other) {
+		t.Fatalf("unexpected value %v", total)
+	}
+
+	id := uuid.New()
+	order, err := NewOrder(id, total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.ID() != id || !order.Total.Equal(total) {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
+`
+
+	buildFiles(t, files)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/golangee/architecture/arc/adl"
 	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/doc"
 	"github.com/golangee/architecture/arc/generator/golang"
 	"github.com/golangee/architecture/arc/generator/stereotype"
 	"github.com/golangee/architecture/arc/token"
@@ -26,6 +27,7 @@ func renderApps(dst *ast.Mod, src *adl.Module) error {
 		cmd.SetComment("...contains individual applications and dependency injection layers for each executable.")
 		cmd.SetPreamble(makePreamble(src.Preamble))
 
+		var injectionDocs []doc.Node
 		for _, executable := range src.Executables {
 			cmdPkg := astutil.MkPkg(dst, getApplicationPath(dst, executable))
 			cmdPkg.SetComment("...defines the application and dependency injection layer for the '" + executable.Name.String() + "' executable.\n\n" + executable.Comment.String())
@@ -47,7 +49,7 @@ func renderApps(dst *ast.Mod, src *adl.Module) error {
 			appStub.AddMethods(
 				ast.NewFunc("configure").
 					SetVisibility(ast.Private).
-					SetComment("...resets, prepares and parses the configuration. The priority of evaluation is:\n\n  0. hardcoded defaults\n  1. values from configuration file\n  2. values from environment variables\n  3. values from command flags").
+					SetComment("...resets, prepares, parses and validates the configuration. The priority of evaluation is:\n\n  0. hardcoded defaults\n  1. values from configuration file\n  2. values from environment variables\n  3. values from command flags").
 					SetPtrReceiver(true).
 					SetRecName(appStub.DefaultRecName).
 					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
@@ -97,10 +99,18 @@ func renderApps(dst *ast.Mod, src *adl.Module) error {
 								if err:= cfgFlagSet.Parse({{.Use "os.Args"}}[1:]); err != nil {
 									return {{.Use "fmt.Errorf"}}("invalid arguments: %w",err)
 								}
+								{{if .Get "cli"}}
+								// the remaining arguments select and configure the subcommand
+								{{.Get "rec"}}.args = cfgFlagSet.Args()
+								{{end}}
+
+								if err:={{.Get "rec"}}.cfg.Validate();err!=nil{
+									return {{.Use "fmt.Errorf"}}("invalid configuration: %w",err)
+								}
 
 								return nil
 								`,
-							).Put("rec", appStub.DefaultRecName).Put("appName", strings.ToLower(executable.Name.String())),
+							).Put("rec", appStub.DefaultRecName).Put("appName", strings.ToLower(executable.Name.String())).Put("cli", executable.IsCli()),
 						),
 					),
 
@@ -115,16 +125,19 @@ func renderApps(dst *ast.Mod, src *adl.Module) error {
 							`if err:={{.Get "rec"}}.configure();err!=nil{
 									return {{.Use "fmt.Errorf"}}("cannot configure: %w",err)
 								}
-
+								{{if .Get "events"}}
+								if err:={{.Get "rec"}}.subscribeEvents();err!=nil{
+									return {{.Use "fmt.Errorf"}}("cannot subscribe events: %w",err)
+								}
+								{{end}}
 								return nil
 								`,
-						).Put("rec", appStub.DefaultRecName),
+						).Put("rec", appStub.DefaultRecName).Put("events", hasEvents(src)),
 					)),
 
 				ast.NewFunc("Run").
 					AddParams(ast.NewParam("ctx", ast.NewSimpleTypeDecl("context.Context"))).
-					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
-					SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit("nil")))),
+					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
 			)
 
 			appConst := ast.NewFunc("New"+app.TypeName).
@@ -145,19 +158,48 @@ func renderApps(dst *ast.Mod, src *adl.Module) error {
 			appStub.AddFields(ast.NewField("self", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(astutil.FullQualifiedName(app))))).SetVisibility(ast.Private).SetComment("...provides a pointer to the actual Application instance to provide\none level of a quasi-vtable calling indirection for simple method 'overriding'."))
 			appStub.SetComment("...aggregates all contained bounded contexts and starts their driver adapters.")
 
+			if executable.IsCli() {
+				appStub.AddFields(ast.NewField("args", ast.NewSliceTypeDecl(ast.NewSimpleTypeDecl(stdlib.String))).SetVisibility(ast.Private).SetComment("...are the arguments after the global flags, which select and configure the subcommand."))
+			}
+
+			var eventFile *ast.File
+			if hasEvents(src) {
+				eventFile = ast.NewFile("events.go").SetPreamble(makePreamble(src.Preamble))
+				cmdPkg.AddFiles(eventFile)
+				makeEventBusGetter(appStub)
+			}
+
+			binder, err := newBinder(cmdPkg, src, executable)
+			if err != nil {
+				return err
+			}
+
+			var services []*ast.Struct
+
 			for _, path := range executable.BoundedContextPaths {
 				bc := astutil.FindPkg(dst, path.String())
 				if bc == nil {
 					return token.NewPosError(path, "invalid bounded context import path: "+path.String())
 				}
 
+				// the event publishers must be available, before any service can be injected with them
+				if eventFile != nil {
+					publishers := findInterfaces(findPrefixPkgs(dst, golang.MakePkgPath(path.String())), func(s stereotype.Interface) bool {
+						return s.IsEventPublisher()
+					})
+
+					for _, publisher := range publishers {
+						makePublisherGetter(eventFile, appStub, publisher)
+					}
+				}
+
 				// the domain core
 				coreServices := findTypes(findPrefixPkgs(dst, golang.MakePkgPath(path.String(), pkgCore)), func(s stereotype.Struct) bool {
 					return s.IsService()
 				})
 
 				for _, service := range coreServices {
-					if err := makeServiceGetter(appStub, service); err != nil {
+					if err := makeServiceGetter(appStub, service, binder); err != nil {
 						return fmt.Errorf("cannot create service: %w", err)
 					}
 				}
@@ -168,25 +210,217 @@ func renderApps(dst *ast.Mod, src *adl.Module) error {
 				})
 
 				for _, service := range usecaseServices {
-					if err := makeServiceGetter(appStub, service); err != nil {
+					if err := makeServiceGetter(appStub, service, binder); err != nil {
 						return fmt.Errorf("cannot create service: %w", err)
 					}
 				}
+
+				services = append(services, coreServices...)
+				services = append(services, usecaseServices...)
+			}
+
+			if eventFile != nil {
+				if err := makeSubscribeEvents(appStub, services); err != nil {
+					return fmt.Errorf("cannot subscribe events: %w", err)
+				}
+			}
+
+			run := astutil.MethodByName(appStub, "Run")
+			httpExposed := httpServices(src, executable)
+			grpcExposed := grpcServices(src, executable)
+			if len(httpExposed) > 0 {
+				if err := makeHttpHandler(appStub, src, httpExposed); err != nil {
+					return fmt.Errorf("cannot create http handler: %w", err)
+				}
 			}
 
+			if len(grpcExposed) > 0 {
+				if err := makeGrpcServer(appStub, src, grpcExposed); err != nil {
+					return fmt.Errorf("cannot create grpc server: %w", err)
+				}
+			}
+
+			if err := checkInjectionCycles(binder.graph, executable); err != nil {
+				return err
+			}
+
+			makeResolveSingletons(appStub, binder.graph)
+			injectionDocs = append(injectionDocs, injectionDoc(binder.graph, executable)...)
+
+			if err := makeStartServices(appStub, services); err != nil {
+				return fmt.Errorf("cannot create lifecycle: %w", err)
+			}
+
+			// the use cases are either executed as a subcommand or served by the driver adapters
+			execute := `{{.Get "rec"}}.self.serve(ctx)`
+			if cliExposed := cliServices(src, executable); len(cliExposed) > 0 {
+				commandFile := ast.NewFile("commands.go").SetPreamble(makePreamble(src.Preamble))
+				cmdPkg.AddFiles(commandFile)
+				if err := makeCliCommands(commandFile, appStub, cliExposed); err != nil {
+					return fmt.Errorf("cannot create cli commands: %w", err)
+				}
+
+				execute = `{{.Use "` + golang.MakePkgPath(dst.Name, pkgCli) + `.Execute"}}(ctx, "{{.Get "appName"}}", {{.Get "rec"}}.args, {{.Use "os.Stdout"}}, {{.Get "rec"}}.self.getCommands()...)`
+				run.SetComment("...starts the services, executes the subcommand, which is selected by the arguments, prints its results to\nthe standard output and finally stops the services in reverse order.")
+			} else {
+				serve := ast.NewFunc("serve").
+					SetVisibility(ast.Private).
+					SetRecName(appStub.DefaultRecName).
+					SetPtrReceiver(true).
+					AddParams(ast.NewParam("ctx", ast.NewSimpleTypeDecl("context.Context"))).
+					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+
+				if len(httpExposed) > 0 || len(grpcExposed) > 0 {
+					serve.SetComment("...serves the driver adapters until the context is cancelled or a server fails.").
+						SetBody(ast.NewBlock(ast.NewTpl(makeRunBody(len(httpExposed) > 0, len(grpcExposed) > 0)).Put("rec", appStub.DefaultRecName)))
+				} else {
+					serve.SetComment("...blocks until the context is cancelled, because there is no driver adapter to serve.").
+						SetBody(ast.NewBlock(ast.NewTpl("<-ctx.Done()\n\nreturn nil\n")))
+				}
+
+				appStub.AddMethods(serve)
+				run.SetComment("...starts the services in the order of their dependencies, serves the driver adapters until the\ncontext is cancelled or a server fails and finally stops the services in reverse order.")
+			}
+
+			run.SetRecName(appStub.DefaultRecName).
+				SetPtrReceiver(true).
+				SetBody(ast.NewBlock(ast.NewTpl(
+					`res := {{.Get "rec"}}.self.startServices(ctx)
+					if res == nil {
+						res = `+execute+`
+					}
+
+					if err := {{.Get "rec"}}.services.Stop({{.Get "rec"}}.cfg.Lifecycle.ShutdownTimeout); err != nil && res == nil {
+						res = err
+					}
+
+					return res
+					`,
+				).Put("rec", appStub.DefaultRecName).Put("appName", strings.ToLower(executable.Name.String()))))
 		}
+
+		renderInjectionDoc(dst, injectionDocs)
 	}
 
 	return nil
 }
 
+// makeRunBody returns the template source, which serves http and grpc concurrently. The first failing server
+// or the cancelled context shuts down all servers.
+func makeRunBody(withHttp, withGrpc bool) string {
+	body := ""
+	start := "done := make(chan error, 2)\n"
+	if withHttp {
+		body += `handler, err := {{.Get "rec"}}.self.getHttpHandler()
+			if err != nil {
+				return {{.Use "fmt.Errorf"}}("cannot get http handler: %w", err)
+			}
+
+			httpServer := &{{.Use "net/http.Server"}}{
+				Addr:         {{.Get "rec"}}.cfg.HTTP.Address,
+				Handler:      handler,
+				ReadTimeout:  {{.Get "rec"}}.cfg.HTTP.ReadTimeout,
+				WriteTimeout: {{.Get "rec"}}.cfg.HTTP.WriteTimeout,
+			}
+
+			`
+
+		start += `go func() {
+				done <- {{.Use "fmt.Errorf"}}("cannot serve http: %w", httpServer.ListenAndServe())
+			}()
+
+			`
+	}
+
+	if withGrpc {
+		body += `grpcServer, err := {{.Get "rec"}}.self.getGrpcServer()
+			if err != nil {
+				return {{.Use "fmt.Errorf"}}("cannot get grpc server: %w", err)
+			}
+
+			listener, err := {{.Use "net.Listen"}}("tcp", {{.Get "rec"}}.cfg.GRPC.Address)
+			if err != nil {
+				return {{.Use "fmt.Errorf"}}("cannot listen for grpc: %w", err)
+			}
+
+			`
+
+		start += `go func() {
+				done <- {{.Use "fmt.Errorf"}}("cannot serve grpc: %w", grpcServer.Serve(listener))
+			}()
+
+			`
+	}
+
+	body += start + `
+		var res error
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+		}
+
+		`
+
+	if withHttp {
+		body += `shutdownCtx, cancel := {{.Use "context.WithTimeout"}}({{.Use "context.Background"}}(), {{.Get "rec"}}.cfg.HTTP.ShutdownTimeout)
+			defer cancel()
+
+			if err := httpServer.Shutdown(shutdownCtx); err != nil && res == nil {
+				res = {{.Use "fmt.Errorf"}}("cannot shutdown http server: %w", err)
+			}
+
+			`
+	}
+
+	if withGrpc {
+		body += `stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+			case <-{{.Use "time.After"}}({{.Get "rec"}}.cfg.GRPC.ShutdownTimeout):
+				grpcServer.Stop()
+			}
+
+			`
+	}
+
+	return body + "return res\n"
+}
+
 func getApplicationPath(mod *ast.Mod, exec *adl.Executable) string {
 	return golang.MakePkgPath(mod.Name, pkgInternalApp, golang2.MakeIdentifier(exec.Name.String()))
 }
 
-func makeGetter(app *ast.Struct, typ ast.TypeDecl) (*ast.Func, error) {
+// makeGetter returns the getter of the injected type and creates it, if required. An interface is resolved by the
+// binder and returns its cached implementation. A pointer to a service returns the cached service. Types, which
+// cannot be injected, are reported at their first injection.
+func makeGetter(app *ast.Struct, typ ast.TypeDecl, binder *binder) (*ast.Func, error) {
+	if service := injectedService(app, typ); service != nil {
+		if err := makeServiceGetter(app, service, binder); err != nil {
+			return nil, fmt.Errorf("cannot create service: %w", err)
+		}
+
+		getter := astutil.MethodByName(app, "get"+golang.GlobalFlatName(service))
+		if _, ok := typ.(*ast.TypeDeclPtr); ok {
+			return getter, nil
+		}
+
+		return makeServiceValueGetter(app, service, getter), nil
+	}
+
 	if _, ok := typ.(*ast.SimpleTypeDecl); !ok {
-		return nil, fmt.Errorf("type must be a SimpleTypeDecl: " + typ.String())
+		return nil, token.NewPosError(binder.pos(typ.String()), "cannot inject '"+typ.String()+"'").
+			SetHint("inject a configuration, a pointer to a service or an interface")
+	}
+
+	resolvedType := astutil.Resolve(app, typ.String())
+	if resolvedType == nil {
+		return nil, token.NewPosError(binder.pos(typ.String()), "injected type '"+typ.String()+"' cannot be resolved").
+			SetHint("use the full qualified name, e.g. $BC/core." + ast.Name(typ.String()).Identifier())
 	}
 
 	funName := "get" + golang.GlobalFlatName2(typ)
@@ -216,34 +450,108 @@ func makeGetter(app *ast.Struct, typ ast.TypeDecl) (*ast.Func, error) {
 	fun.SetBody(body)
 	app.AddMethods(fun)
 
-	resolvedType := astutil.Resolve(app, typ.String())
-	if resolvedType == nil {
-		body.Add(ast.NewTpl("panic(\"not implemented\")"))
-		return fun, nil
-	}
-
 	switch t := resolvedType.(type) {
 	case *ast.Struct:
 		uberCfg := astutil.Resolve(app, astutil.Pkg(app).Path+".Configuration").(*ast.Struct)
 		selPath := makeSelectorPathForConfig(uberCfg, typ.String())
 		if selPath == "" {
-			body.Add(ast.NewTpl(`panic("not implemented")`))
-		} else {
-			body.Add(ast.NewTpl("return " + app.DefaultRecName + ".cfg." + selPath + ", nil\n"))
+			return nil, token.NewPosError(binder.pos(typ.String()), "'"+typ.String()+"' is not a configuration of the executable").
+				SetHint("only configurations, services and interfaces can be injected")
 		}
+
+		body.Add(ast.NewTpl("return " + app.DefaultRecName + ".cfg." + selPath + ", nil\n"))
 	case *ast.Interface:
-		implementations := astutil.FindImplementations(app, ast.Name(typ.String()))
-		if len(implementations) == 0 {
-			body.Add(ast.NewTpl(`panic("no implementation available")`))
+		if stereotype.InterfaceFrom(t).IsDatabase() {
+			if err := makeDatabaseGetter(app, fun, typ, binder); err != nil {
+				return nil, fmt.Errorf("cannot create database: %w", err)
+			}
+
+			return fun, nil
+		}
+
+		impl, err := binder.implementation(t)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := makeServiceGetter(app, impl, binder); err != nil {
+			return nil, fmt.Errorf("cannot create implementation: %w", err)
 		}
 
+		binder.graph.edge(binder.graph.node(app, typ.String()), binder.graph.node(impl, astutil.FullQualifiedName(impl)), "bound", binder.bindingPos(typ.String()))
+		fun.SetComment("...returns the bound implementation '" + astutil.FullQualifiedName(impl) + "'.")
+		body.Add(ast.NewTpl("return " + app.DefaultRecName + ".self.get" + golang.GlobalFlatName(impl) + "()\n"))
 	default:
-		return nil, fmt.Errorf("unsupported resolved getter injection type: %v", t)
+		return nil, token.NewPosError(binder.pos(typ.String()), "cannot inject '"+typ.String()+"'").
+			SetHint("inject a configuration, a pointer to a service or an interface")
 	}
 
 	return fun, nil
 }
 
+// makeDatabaseGetter implements the getter of a generated database connection. The connection is opened with
+// the options of its package, which are part of the configuration, and is only cached after all pending migrations
+// of the package have been applied. The application registers the mysql driver, which is used by Open.
+func makeDatabaseGetter(app *ast.Struct, getter *ast.Func, typ ast.TypeDecl, binder *binder) error {
+	importMySQLDriver(astutil.File(app))
+
+	pkgPath := ast.Name(typ.String()).Qualifier()
+	optsType := ast.NewSimpleTypeDecl(ast.Name(pkgPath + ".Options"))
+	optsGetter, err := makeGetter(app, optsType, binder)
+	if err != nil {
+		return fmt.Errorf("invalid database options: %w", err)
+	}
+
+	dbField := ast.NewField(golang.MakePrivate(golang.GlobalFlatName2(typ)), typ.Clone()).SetVisibility(ast.Private)
+	app.AddFields(dbField)
+
+	node := binder.graph.node(app, typ.String())
+	node.getter = getter.FunName
+	binder.graph.edge(node, binder.graph.node(app, optsType.String()), "opts", nil)
+
+	getter.SetComment("...opens the database, applies all pending migrations and returns the cached connection.")
+	getter.SetBody(ast.NewBlock(ast.NewTpl(`if {{.Get "rec"}}.{{.Get "field"}} != nil {
+			return {{.Get "rec"}}.{{.Get "field"}}, nil
+		}
+
+		opts, err := {{.Get "rec"}}.self.{{.Get "optsGetter"}}()
+		if err != nil {
+			return nil, {{.Use "fmt.Errorf"}}("cannot get parameter 'opts': %w", err)
+		}
+
+		db, err := {{.Use (.Get "open")}}(opts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open database: %w", err)
+		}
+
+		if err := {{.Use (.Get "migrate")}}(db); err != nil {
+			return nil, fmt.Errorf("cannot migrate database: %w", err)
+		}
+
+		{{.Get "rec"}}.{{.Get "field"}} = db
+
+		return db, nil
+	`).
+		Put("rec", app.DefaultRecName).
+		Put("field", dbField.FieldName).
+		Put("optsGetter", optsGetter.FunName).
+		Put("open", pkgPath+".Open").
+		Put("migrate", pkgPath+".Migrate")))
+
+	return nil
+}
+
+// importMySQLDriver adds the side-effect-only import of the mysql driver to the file, if not yet imported.
+func importMySQLDriver(file *ast.File) {
+	for _, imp := range file.Imports() {
+		if imp.Name == mysqlDriver {
+			return
+		}
+	}
+
+	file.AddNodes(ast.NewImport("_", mysqlDriver).SetComment("side-effect-only import to load mysql driver"))
+}
+
 // inspects the given root fields and tries to find the according typedecl. This is a bit of a poking and returns
 // the empty string, if not found.
 func makeSelectorPathForConfig(root *ast.Struct, typeDecl string) string {
@@ -264,7 +572,9 @@ func makeSelectorPathForConfig(root *ast.Struct, typeDecl string) string {
 	return ""
 }
 
-func makeServiceGetter(app, service *ast.Struct) error {
+// makeServiceGetter creates the getter, which constructs and caches the service or the bound implementation.
+// The getter is only created once.
+func makeServiceGetter(app, service *ast.Struct, binder *binder) error {
 	getter := ast.NewFunc("get"+golang.GlobalFlatName(service)).
 		SetRecName(app.DefaultRecName).
 		SetPtrReceiver(true).
@@ -273,6 +583,10 @@ func makeServiceGetter(app, service *ast.Struct) error {
 			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
 		).SetVisibility(ast.Private)
 
+	if astutil.MethodByName(app, getter.FunName) != nil {
+		return nil
+	}
+
 	serviceField := ast.NewField(golang.MakePrivate(golang.GlobalFlatName(service)), ast.NewTypeDeclPtr(astutil.TypeDecl(service))).
 		SetVisibility(ast.Private)
 
@@ -286,35 +600,107 @@ func makeServiceGetter(app, service *ast.Struct) error {
 		lang.Term(),
 	)
 
-	factory := service.FactoryRefs[0] // always expecting at least one factory
-	factoryFQN := ast.Name(ast.Name(astutil.FullQualifiedName(service)).Qualifier() + "." + factory.FunName)
-	var callIdents []ast.Expr
-	for _, param := range factory.Params() {
-		paramGetter, err := makeGetter(app, param.TypeDecl())
-		if err != nil {
-			return fmt.Errorf("invalid service parameter: %w", err)
-		}
+	// the getter is registered early, so that a cyclic injection does not recurse the generator forever
+	app.AddFields(serviceField)
+	app.AddMethods(getter)
+	node := binder.graph.singleton(service, getter.FunName)
+
+	if len(service.FactoryRefs) == 0 {
+		// a plain struct without factory is usable by its zero value
+		body.Add(ast.NewTpl("s := &{{.Use \"" + astutil.FullQualifiedName(service) + "\"}}{}\n"))
+	} else {
+		factory := service.FactoryRefs[0]
+		factoryFQN := ast.Name(ast.Name(astutil.FullQualifiedName(service)).Qualifier() + "." + factory.FunName)
+		var callIdents []ast.Expr
+		for _, param := range factory.Params() {
+			paramGetter, err := makeGetter(app, param.TypeDecl(), binder)
+			if err != nil {
+				return fmt.Errorf("invalid service parameter: %w", err)
+			}
+
+			dst := param.TypeDecl().String()
+			if s := injectedService(app, param.TypeDecl()); s != nil {
+				dst = astutil.FullQualifiedName(s)
+			}
 
-		callParamGetter := ast.NewCallExpr(ast.NewSelExpr(ast.NewSelExpr(ast.NewIdent(getter.RecName()), ast.NewIdent("self")), ast.NewIdent(paramGetter.FunName)))
-		body.Add(lang.TryDefine(ast.NewIdent(param.ParamName), callParamGetter, "cannot get parameter '"+param.ParamName+"'"))
+			binder.graph.edge(node, binder.graph.node(app, dst), param.ParamName, binder.paramPos(astutil.FullQualifiedName(service), param.ParamName))
 
-		callIdents = append(callIdents, ast.NewIdent(param.ParamName))
+			callParamGetter := ast.NewCallExpr(ast.NewSelExpr(ast.NewSelExpr(ast.NewIdent(getter.RecName()), ast.NewIdent("self")), ast.NewIdent(paramGetter.FunName)))
+			body.Add(lang.TryDefine(ast.NewIdent(param.ParamName), callParamGetter, "cannot get parameter '"+param.ParamName+"'"))
+
+			callIdents = append(callIdents, ast.NewIdent(param.ParamName))
+		}
+
+		body.Add(lang.Term())
+		if len(factory.Results()) == 1 {
+			body.Add(ast.NewAssign(ast.Exprs(ast.NewIdent("s")), ast.AssignDefine, ast.Exprs(lang.CallStatic(factoryFQN, callIdents...))), lang.Term())
+		} else {
+			body.Add(lang.TryDefine(ast.NewIdentLit("s"), lang.CallStatic(factoryFQN, callIdents...), "cannot create service '"+service.TypeName+"'"))
+		}
 	}
 
-	body.Add(lang.Term())
-	body.Add(lang.TryDefine(ast.NewIdentLit("s"), lang.CallStatic(factoryFQN, callIdents...), "cannot create service '"+service.TypeName+"'"))
 	body.Add(lang.Term())
 	body.Add(ast.NewAssign(ast.Exprs(ast.NewSelExpr(ast.NewIdent(getter.RecName()), ast.NewIdent(serviceField.FieldName))), ast.AssignSimple, ast.Exprs(ast.NewIdent("s"))))
-	body.Add(lang.Term(), lang.Term())
+	body.Add(lang.Term())
+	if stereotype.StructFrom(service).IsLifecycle() {
+		// the dependencies have been constructed and registered already, which keeps the order of the lifecycle
+		body.Add(ast.NewTpl(getter.RecName() + `.services.Add("` + service.TypeName + `", s)` + "\n"))
+	}
+
+	body.Add(lang.Term())
 	body.Add(ast.NewReturnStmt(ast.NewIdent("s"), ast.NewIdentLit("nil")))
 	getter.SetBody(body)
 
-	app.AddFields(serviceField)
-	app.AddMethods(getter)
-
 	return nil
 }
 
+// makeServiceValueGetter returns the getter of a service, which is injected by value. It copies the singleton, so
+// that the service is still constructed only once and its injections are part of the same graph.
+func makeServiceValueGetter(app, service *ast.Struct, getter *ast.Func) *ast.Func {
+	name := getter.FunName + "Value"
+	if fun := astutil.MethodByName(app, name); fun != nil {
+		return fun
+	}
+
+	fun := ast.NewFunc(name).
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(
+			ast.NewParam("", astutil.TypeDecl(service)),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		)
+
+	fun.SetBody(ast.NewBlock(ast.NewTpl(`s, err := ` + app.DefaultRecName + `.self.` + getter.FunName + `()
+		if err != nil {
+			return {{.Use "` + astutil.FullQualifiedName(service) + `"}}{}, err
+		}
+
+		return *s, nil
+	`)))
+	app.AddMethods(fun)
+
+	return fun
+}
+
+// injectedService returns the service, if the type is the service or a pointer to it, otherwise nil.
+func injectedService(app *ast.Struct, typ ast.TypeDecl) *ast.Struct {
+	if ptr, ok := typ.(*ast.TypeDeclPtr); ok {
+		typ = ptr.TypeDecl()
+	}
+
+	if _, ok := typ.(*ast.SimpleTypeDecl); !ok {
+		return nil
+	}
+
+	service, ok := astutil.Resolve(app, typ.String()).(*ast.Struct)
+	if !ok || !stereotype.StructFrom(service).IsService() {
+		return nil
+	}
+
+	return service
+}
+
 // findPrefixPkgs returns all packages using the according prefix.
 func findPrefixPkgs(mod *ast.Mod, prefix string) []*ast.Pkg {
 	var r []*ast.Pkg
@@ -327,6 +713,16 @@ func findPrefixPkgs(mod *ast.Mod, prefix string) []*ast.Pkg {
 	return r
 }
 
+// findInterfaces returns all annotated interfaces from the packages.
+func findInterfaces(pkg []*ast.Pkg, predicate func(s stereotype.Interface) bool) []*ast.Interface {
+	var r []*ast.Interface
+	for _, a := range pkg {
+		r = append(r, stereotype.PkgFrom(a).FindInterfaces(predicate)...)
+	}
+
+	return r
+}
+
 // findServices returns all annotated services from the package.
 func findTypes(pkg []*ast.Pkg, predicate func(s stereotype.Struct) bool) []*ast.Struct {
 	var r []*ast.Struct
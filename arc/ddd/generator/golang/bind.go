@@ -0,0 +1,169 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/ast"
+	"strings"
+)
+
+// binder resolves the implementations of the injected interfaces for a single executable.
+type binder struct {
+	bindings   map[string]*adl.Binding // explicit bindings by the full qualified interface name.
+	injections map[string]token.String // the first injection by the full qualified type name, to locate errors.
+	params     map[string]token.String // the injections by their injectionKey, to locate the edges of the graph.
+	services   map[string]token.String // the declared services by their full qualified name.
+	executable *adl.Executable         // locates the errors of injections, which are not declared by the architecture.
+	graph      *injectionGraph         // records each injection and binding, while the getters are created.
+}
+
+// newBinder collects the bindings of all bounded contexts of the executable. An interface must not be bound
+// multiple times.
+func newBinder(ctx ast.Node, src *adl.Module, executable *adl.Executable) (*binder, error) {
+	b := &binder{
+		bindings:   map[string]*adl.Binding{},
+		injections: map[string]token.String{},
+		params:     map[string]token.String{},
+		services:   map[string]token.String{},
+		executable: executable,
+		graph:      newInjectionGraph(),
+	}
+
+	for _, bc := range executableContexts(src, executable) {
+		for _, binding := range bc.Bindings {
+			if other, ok := b.bindings[binding.Interface.String()]; ok {
+				return nil, token.NewPosError(binding.Interface, "interface '"+binding.Interface.String()+"' is bound ambiguously",
+					token.NewErrDetail(other.Interface, "already bound to '"+other.Implementation.String()+"'"))
+			}
+
+			if _, ok := astutil.Resolve(ctx, binding.Interface.String()).(*ast.Interface); !ok {
+				return nil, token.NewPosError(binding.Interface, "'"+binding.Interface.String()+"' is not a declared interface")
+			}
+
+			b.bindings[binding.Interface.String()] = binding
+		}
+
+		for _, layer := range []struct {
+			name string
+			pkgs []*adl.Package
+		}{{pkgCore, bc.Core}, {pkgUsecase, bc.Usecase}} {
+			for _, pkg := range layer.pkgs {
+				for _, service := range pkg.Services {
+					serviceFQN := bcPkgPath(bc, layer.name, pkg) + "." + service.Component.Name.String()
+					b.services[serviceFQN] = service.Component.Name
+					for _, injection := range service.Component.Inject {
+						fqn := astutil.MakeTypeDecl(injection.Type).String()
+						if _, ok := b.injections[fqn]; !ok {
+							b.injections[fqn] = injection.Type.Name
+						}
+
+						b.params[injectionKey(serviceFQN, injection.Name.String())] = injection.Type.Name
+					}
+				}
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// implementation returns the struct, which is injected for the given interface. An explicit binding is preferred,
+// otherwise the interface must have exactly one declared implementation.
+func (b *binder) implementation(iface *ast.Interface) (*ast.Struct, error) {
+	fqn := astutil.FullQualifiedName(iface)
+	if binding, ok := b.bindings[fqn]; ok {
+		impl, ok := astutil.Resolve(iface, binding.Implementation.String()).(*ast.Struct)
+		if !ok {
+			return nil, token.NewPosError(binding.Implementation, "'"+binding.Implementation.String()+"' is not a declared struct")
+		}
+
+		for _, method := range iface.Methods() {
+			if !hasMethod(impl, method.FunName) {
+				return nil, token.NewPosError(binding.Implementation, "'"+binding.Implementation.String()+"' does not implement '"+fqn+"'",
+					token.NewErrDetail(binding.Interface, "missing method '"+method.FunName+"'"))
+			}
+		}
+
+		return impl, nil
+	}
+
+	candidates := astutil.FindImplementations(iface, ast.Name(fqn))
+	switch len(candidates) {
+	case 0:
+		return nil, token.NewPosError(b.pos(fqn), "interface '"+fqn+"' has no implementation").
+			SetHint("declare a binding in its bounded context")
+	case 1:
+		return candidates[0], nil
+	default:
+		var names []string
+		for _, candidate := range candidates {
+			names = append(names, astutil.FullQualifiedName(candidate))
+		}
+
+		return nil, token.NewPosError(b.pos(fqn), "interface '"+fqn+"' has ambiguous implementations "+strings.Join(names, ", ")).
+			SetHint("declare a binding in its bounded context")
+	}
+}
+
+// pos returns the position of the first injection of the type or the executable, if it is not declared.
+func (b *binder) pos(fqn string) token.Node {
+	if pos, ok := b.injections[fqn]; ok {
+		return pos
+	}
+
+	return b.executable.Name
+}
+
+// paramPos returns the position of the injected parameter of the service. Parameters, which are not declared by
+// the architecture, like event publishers, are located at their service or at the executable.
+func (b *binder) paramPos(serviceFQN, param string) token.Node {
+	if pos, ok := b.params[injectionKey(serviceFQN, param)]; ok {
+		return pos
+	}
+
+	if pos, ok := b.services[serviceFQN]; ok {
+		return pos
+	}
+
+	return b.executable.Name
+}
+
+// bindingPos returns the position of the explicit binding of the interface or of its first injection.
+func (b *binder) bindingPos(fqn string) token.Node {
+	if binding, ok := b.bindings[fqn]; ok {
+		return binding.Implementation
+	}
+
+	return b.pos(fqn)
+}
+
+// hasMethod returns true, if the struct or any of its embedded structs declares the method.
+func hasMethod(s *ast.Struct, name string) bool {
+	if astutil.MethodByName(s, name) != nil {
+		return true
+	}
+
+	for _, embedded := range s.Embedded {
+		if e, ok := astutil.Resolve(s, embedded.String()).(*ast.Struct); ok && hasMethod(e, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// executableContexts returns the bounded contexts, which are contained in the executable.
+func executableContexts(src *adl.Module, executable *adl.Executable) []*adl.BoundedContext {
+	var res []*adl.BoundedContext
+	for _, path := range executable.BoundedContextPaths {
+		for _, bc := range src.BoundedContexts {
+			if golang.MakePkgPath(bc.Path.String()) == golang.MakePkgPath(path.String()) {
+				res = append(res, bc)
+			}
+		}
+	}
+
+	return res
+}
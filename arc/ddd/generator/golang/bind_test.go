@@ -0,0 +1,144 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		context string
+		line    int
+		msg     string
+	}{
+		{
+			name: "missing implementation",
+			context: `
+        core {
+            repository Tickets "...stores tickets." {
+                func Count "...counts tickets." {
+                    out int64! "...is the count."
+                }
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                inject tickets $BC/core.Tickets "...are the tickets."
+            }
+        }`,
+			line: 27,
+			msg:  "has no implementation",
+		},
+		{
+			name: "ambiguous implementations",
+			context: `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket in-memory { countAll }
+                crud $BC/core.Ticket in-memory { findOne }
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                inject tickets $BC/core.Tickets "...are the tickets."
+            }
+        }`,
+			line: 30,
+			msg:  "has ambiguous implementations",
+		},
+		{
+			name: "not implementing",
+			context: `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+
+            repository Tickets "...stores tickets." {
+                func Count "...counts tickets." {
+                    out int64! "...is the count."
+                }
+            }
+
+            repository Others "...stores other tickets." {
+                crud $BC/core.Ticket in-memory { findOne }
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                inject tickets $BC/core.Tickets "...are the tickets."
+            }
+        }
+
+        bind $BC/core.Tickets $BC/core.InMemoryOthers`,
+			line: 39,
+			msg:  "does not implement",
+		},
+		{
+			name: "duplicate binding",
+			context: `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket in-memory { countAll }
+            }
+        }
+
+        bind $BC/core.Tickets $BC/core.InMemoryTickets
+        bind $BC/core.Tickets $BC/core.InMemoryTickets`,
+			line: 28,
+			msg:  "is bound ambiguously",
+		},
+		{
+			name: "unresolvable injection",
+			context: `
+        usecase {
+            config Settings "...are the settings." {
+                field Verbose bool! "...logs more."
+            }
+
+            service Board "...shows tickets." {
+                inject settings Settings "...are the settings."
+            }
+        }`,
+			line: 23,
+			msg:  "cannot be resolved",
+		},
+		{
+			name: "injected dto",
+			context: `
+        usecase {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+
+            service Board "...shows tickets." {
+                inject ticket $BC/usecase.Ticket "...is the ticket."
+            }
+        }`,
+			line: 23,
+			msg:  "is not a configuration",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := renderContext(test.context)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			assertPosError(t, err, test.line, test.msg)
+		})
+	}
+}
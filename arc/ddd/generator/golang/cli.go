@@ -0,0 +1,418 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+const pkgCli = "internal/cli"
+
+// cliFlagTypes are the parameter types, which are directly supported by the generated ConfigureFlags methods. All
+// other parameters are declared as string flags and decoded by cli.Decode.
+var cliFlagTypes = map[string]bool{
+	stdlib.Bool:     true,
+	stdlib.Int:      true,
+	stdlib.String:   true,
+	stdlib.Int64:    true,
+	stdlib.Duration: true,
+	stdlib.Float64:  true,
+}
+
+// renderCli emits the package which contains the subcommand dispatcher and the output formats, if any executable
+// is a command line tool.
+func renderCli(dst *ast.Mod, src *adl.Module) error {
+	exposed := false
+	for _, executable := range src.Executables {
+		if len(cliServices(src, executable)) > 0 {
+			exposed = true
+			break
+		}
+	}
+
+	if !exposed {
+		return nil
+	}
+
+	cli := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgCli))
+	cli.SetPreamble(makePreamble(src.Preamble)).
+		SetComment("...provides the subcommand dispatcher and the output formats of the generated command line driver adapters.")
+
+	cli.AddFiles(
+		ast.NewFile("cli.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					const (
+						FormatJSON  = "json"
+						FormatTable = "table"
+					)
+
+					// Command is a subcommand, which invokes a single use case service method.
+					type Command struct {
+						Name  string
+						Usage string
+
+						// Flags configures the flags of the subcommand. It may be nil.
+						Flags func(flags *{{.Use "flag.FlagSet"}})
+
+						// Run invokes the use case with the parsed flags and returns the results to print.
+						Run func(ctx {{.Use "context.Context"}}) (interface{}, error)
+					}
+
+					// Execute parses the flags of the subcommand, which is named by the first argument, runs it and
+					// prints its results to w. The help subcommand lists all commands.
+					func Execute(ctx {{.Use "context.Context"}}, name string, args []string, w {{.Use "io.Writer"}}, commands ...Command) error {
+						if len(args) == 0 {
+							return {{.Use "fmt.Errorf"}}("missing command, see '%s help'", name)
+						}
+
+						if args[0] == "help" {
+							tw := {{.Use "text/tabwriter.NewWriter"}}(w, 0, 4, 2, ' ', 0)
+							for _, cmd := range commands {
+								{{.Use "fmt.Fprintf"}}(tw, "%s\t%s\n", cmd.Name, cmd.Usage)
+							}
+
+							return tw.Flush()
+						}
+
+						for _, cmd := range commands {
+							if cmd.Name != args[0] {
+								continue
+							}
+
+							flags := {{.Use "flag.NewFlagSet"}}(name+" "+cmd.Name, {{.Use "flag.ContinueOnError"}})
+							format := flags.String("output", FormatJSON, "is the output format, either json or table.")
+							if cmd.Flags != nil {
+								cmd.Flags(flags)
+							}
+
+							if err := flags.Parse(args[1:]); err != nil {
+								return {{.Use "fmt.Errorf"}}("invalid arguments: %w", err)
+							}
+
+							if *format != FormatJSON && *format != FormatTable {
+								return {{.Use "fmt.Errorf"}}("invalid output format '%s'", *format)
+							}
+
+							res, err := cmd.Run(ctx)
+							if err != nil {
+								return err
+							}
+
+							return Print(w, *format, res)
+						}
+
+						return {{.Use "fmt.Errorf"}}("unknown command '%s', see '%s help'", args[0], name)
+					}
+
+					// Decode parses the flag value into v. Types which implement encoding.TextUnmarshaler, like uuids
+					// or times, and urls are parsed from their text form, all others from json. An empty value
+					// keeps v untouched.
+					func Decode(value string, v interface{}) error {
+						if value == "" {
+							return nil
+						}
+
+						switch t := v.(type) {
+						case {{.Use "encoding.TextUnmarshaler"}}:
+							return t.UnmarshalText([]byte(value))
+						case *{{.Use "net/url.URL"}}:
+							u, err := {{.Use "net/url.Parse"}}(value)
+							if err != nil {
+								return err
+							}
+
+							*t = *u
+
+							return nil
+						default:
+							return {{.Use "encoding/json.Unmarshal"}}([]byte(value), v)
+						}
+					}
+
+					// Print writes the results in the given format. Nil results are omitted. A table shows a slice
+					// of structs by a row per element and a column per exported field, a struct or a map by a row
+					// per field or key.
+					func Print(w {{.Use "io.Writer"}}, format string, v interface{}) error {
+						if v == nil {
+							return nil
+						}
+
+						switch format {
+						case FormatJSON:
+							enc := {{.Use "encoding/json.NewEncoder"}}(w)
+							enc.SetIndent("", "  ")
+
+							return enc.Encode(v)
+						case FormatTable:
+							tw := {{.Use "text/tabwriter.NewWriter"}}(w, 0, 4, 2, ' ', 0)
+							writeTable(tw, {{.Use "reflect.ValueOf"}}(v))
+
+							return tw.Flush()
+						default:
+							return {{.Use "fmt.Errorf"}}("unsupported output format '%s'", format)
+						}
+					}
+
+					func writeTable(w {{.Use "io.Writer"}}, v {{.Use "reflect.Value"}}) {
+						v = indirect(v)
+						if _, ok := stringer(v); ok {
+							{{.Use "fmt.Fprintln"}}(w, cell(v))
+							return
+						}
+
+						switch v.Kind() {
+						case {{.Use "reflect.Slice"}}, {{.Use "reflect.Array"}}:
+							elem := v.Type().Elem()
+							for elem.Kind() == {{.Use "reflect.Ptr"}} {
+								elem = elem.Elem()
+							}
+
+							if elem.Kind() != {{.Use "reflect.Struct"}} || elem.Implements(stringerType) || {{.Use "reflect.PtrTo"}}(elem).Implements(stringerType) {
+								for i := 0; i < v.Len(); i++ {
+									{{.Use "fmt.Fprintln"}}(w, cell(v.Index(i)))
+								}
+
+								return
+							}
+
+							var header []string
+							for i := 0; i < elem.NumField(); i++ {
+								if elem.Field(i).PkgPath == "" {
+									header = append(header, elem.Field(i).Name)
+								}
+							}
+
+							{{.Use "fmt.Fprintln"}}(w, {{.Use "strings.Join"}}(header, "\t"))
+							for i := 0; i < v.Len(); i++ {
+								row := indirect(v.Index(i))
+								cells := make([]string, 0, len(header))
+								for _, name := range header {
+									if row.IsValid() {
+										cells = append(cells, cell(row.FieldByName(name)))
+									} else {
+										cells = append(cells, "")
+									}
+								}
+
+								{{.Use "fmt.Fprintln"}}(w, {{.Use "strings.Join"}}(cells, "\t"))
+							}
+						case {{.Use "reflect.Struct"}}:
+							for i := 0; i < v.NumField(); i++ {
+								if v.Type().Field(i).PkgPath == "" {
+									{{.Use "fmt.Fprintf"}}(w, "%s\t%s\n", v.Type().Field(i).Name, cell(v.Field(i)))
+								}
+							}
+						case {{.Use "reflect.Map"}}:
+							keys := v.MapKeys()
+							{{.Use "sort.Slice"}}(keys, func(i, j int) bool {
+								return cell(keys[i]) < cell(keys[j])
+							})
+
+							for _, key := range keys {
+								{{.Use "fmt.Fprintf"}}(w, "%s\t%s\n", cell(key), cell(v.MapIndex(key)))
+							}
+						default:
+							{{.Use "fmt.Fprintln"}}(w, cell(v))
+						}
+					}
+
+					var stringerType = {{.Use "reflect.TypeOf"}}((*{{.Use "fmt.Stringer"}})(nil)).Elem()
+
+					func indirect(v {{.Use "reflect.Value"}}) {{.Use "reflect.Value"}} {
+						for (v.Kind() == {{.Use "reflect.Ptr"}} || v.Kind() == {{.Use "reflect.Interface"}}) && !v.IsNil() {
+							v = v.Elem()
+						}
+
+						return v
+					}
+
+					func stringer(v {{.Use "reflect.Value"}}) ({{.Use "fmt.Stringer"}}, bool) {
+						if !v.IsValid() || !v.CanInterface() {
+							return nil, false
+						}
+
+						s, ok := v.Interface().({{.Use "fmt.Stringer"}})
+
+						return s, ok
+					}
+
+					func cell(v {{.Use "reflect.Value"}}) string {
+						v = indirect(v)
+						if !v.IsValid() || (v.Kind() == {{.Use "reflect.Ptr"}} || v.Kind() == {{.Use "reflect.Interface"}}) && v.IsNil() {
+							return ""
+						}
+
+						if s, ok := stringer(v); ok {
+							return s.String()
+						}
+
+						return {{.Use "fmt.Sprint"}}(v.Interface())
+					}
+				`),
+			),
+	)
+
+	return nil
+}
+
+// cliServices returns the use case services of the bounded contexts of the executable, if it is a command line
+// tool. Each method of a use case service is exposed as a subcommand.
+func cliServices(src *adl.Module, executable *adl.Executable) []exposedService {
+	if !executable.IsCli() {
+		return nil
+	}
+
+	return exposedServices(src, executable, func(method *adl.Method) bool {
+		return true
+	})
+}
+
+// kebabCase converts the go identifier into a lower case command name, e.g. FindTicket into find-ticket.
+func kebabCase(s string) string {
+	return strings.ReplaceAll(snakeCase(s), "_", "-")
+}
+
+// makeCliCommands declares a flag struct per use case service method and the getter of the subcommands, which
+// invoke them.
+func makeCliCommands(file *ast.File, app *ast.Struct, services []exposedService) error {
+	cliPkg := golang.MakePkgPath(astutil.Mod(app).Name, pkgCli)
+	body := "var commands []{{.Use \"" + cliPkg + ".Command\"}}\n"
+	for _, s := range services {
+		getter := "get" + golang.GlobalFlatName2(ast.NewSimpleTypeDecl(ast.Name(s.pkgPath+"."+s.service.Component.Name.String())))
+		if astutil.MethodByName(app, getter) == nil {
+			return fmt.Errorf("service '%s' has no getter", s.service.Component.Name.String())
+		}
+
+		for _, method := range s.service.Component.Methods {
+			cmd, err := cliCommand(file, app, s, getter, method)
+			if err != nil {
+				return fmt.Errorf("cannot create command of '%s': %w", method.Name.String(), err)
+			}
+
+			body += "\n" + cmd
+		}
+	}
+
+	app.AddMethods(ast.NewFunc("getCommands").
+		SetComment("...returns a subcommand for each use case service method.\nShadow this method at the Application to add or remove commands.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(ast.NewParam("", ast.NewSliceTypeDecl(ast.NewSimpleTypeDecl(ast.Name(cliPkg+".Command"))))).
+		SetBody(ast.NewBlock(ast.NewTpl(body+"\nreturn commands\n").Put("rec", app.DefaultRecName))))
+
+	return nil
+}
+
+// cliCommand declares the flag struct of the method within the file and returns the source, which appends the
+// subcommand to the commands variable.
+func cliCommand(file *ast.File, app *ast.Struct, s exposedService, getter string, method *adl.Method) (string, error) {
+	cliPkg := golang.MakePkgPath(astutil.Mod(app).Name, pkgCli)
+	service := s.service.Component.Name.String()
+	name := golang.MakePrivate(service) + golang.MakePublic(method.Name.String())
+	flagsType := name + "Flags"
+
+	flags := ast.NewStruct(flagsType).
+		SetVisibility(ast.Private).
+		SetComment("...contains the flags of the " + kebabCase(service) + "-" + kebabCase(method.Name.String()) + " command.")
+
+	run := "service, err := {{.Get \"rec\"}}.self." + getter + "()\n" +
+		"if err != nil {\nreturn nil, {{.Use \"fmt.Errorf\"}}(\"cannot get service '" + service + "': %w\", err)\n}\n\n"
+
+	var args []string
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			args = append(args, "ctx")
+			continue
+		}
+
+		field := golang.MakePublic(param.Name.String())
+		if cliFlagTypes[param.Type.Name.String()] {
+			flags.AddFields(ast.NewField(field, astutil.MakeTypeDecl(param.Type)).SetComment(param.Comment.String()))
+			args = append(args, name+"."+field)
+			continue
+		}
+
+		flags.AddFields(ast.NewField(field, ast.NewSimpleTypeDecl(stdlib.String)).SetComment(param.Comment.String()))
+		arg := golang.MakePrivate(param.Name.String())
+		args = append(args, arg)
+		run += "var " + arg + " " + useType(astutil.MakeTypeDecl(qualifyTypeDecl(s.pkgPath, param.Type))) + "\n" +
+			"if err := {{.Use \"" + cliPkg + ".Decode\"}}(" + name + "." + field + ", &" + arg + "); err != nil {\n" +
+			"return nil, {{.Use \"fmt.Errorf\"}}(\"invalid flag '" + strings.ToLower(field) + "': %w\", err)\n}\n\n"
+	}
+
+	for _, field := range flags.Fields() {
+		stereotype.FieldFrom(field).SetProgramFlag(true)
+	}
+
+	file.AddNodes(flags) // add it early, functions may need contextual information like package path
+	flags.DefaultRecName = "f"
+	if _, err := golang.AddParseFlagFunc("", flags); err != nil {
+		return "", fmt.Errorf("unable to add parse-flag func: %w", err)
+	}
+
+	var results []string
+	var resultNames []string
+	hasErr := false
+	for i, param := range method.Out {
+		if param.Type.Name.String() == stdlib.Error {
+			results = append(results, "err")
+			hasErr = true
+			continue
+		}
+
+		resultName := param.Name.String()
+		if resultName == "" {
+			resultName = "result" + strconv.Itoa(i)
+		}
+
+		resultNames = append(resultNames, resultName)
+		results = append(results, "res"+strconv.Itoa(len(resultNames)-1))
+	}
+
+	call := "service." + method.Name.String() + "(" + strings.Join(args, ", ") + ")"
+	switch {
+	case len(results) == 1 && hasErr:
+		call = "err = " + call
+	case len(results) > 0:
+		call = strings.Join(results, ", ") + " := " + call
+	}
+
+	run += call + "\n"
+	if hasErr {
+		run += "if err != nil {\nreturn nil, err\n}\n"
+	}
+
+	run += "\n"
+
+	switch len(resultNames) {
+	case 0:
+		run += "return nil, nil\n"
+	case 1:
+		run += "return res0, nil\n"
+	default:
+		run += "return map[string]interface{}{\n"
+		for i, resultName := range resultNames {
+			run += strconv.Quote(resultName) + ": res" + strconv.Itoa(i) + ",\n"
+		}
+
+		run += "}, nil\n"
+	}
+
+	return name + " := &" + flagsType + "{}\n" +
+		"commands = append(commands, {{.Use \"" + cliPkg + ".Command\"}}{\n" +
+		"Name: " + strconv.Quote(kebabCase(service)+"-"+kebabCase(method.Name.String())) + ",\n" +
+		"Usage: " + strconv.Quote(golang2.DeEllipsis(method.Name.String(), method.Comment.String())) + ",\n" +
+		"Flags: " + name + ".ConfigureFlags,\n" +
+		"Run: func(ctx {{.Use \"context.Context\"}}) (interface{}, error) {\n" + run + "},\n" +
+		"})\n", nil
+}
@@ -0,0 +1,83 @@
+package golang_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCli(t *testing.T) {
+	src := strings.Replace(module, "%s", `
+        usecase {
+            service Tickets "...manages tickets." {
+                func Rename "...renames a ticket." {
+                    in id uuid! "...is the ticket id."
+                    in title string! "...is the new title."
+                    in limit int! "...is the page size."
+                    out error! "...if anything goes wrong."
+                }
+            }
+        }`, 1)
+
+	src = strings.Replace(src, `application "$MOD/internal/tickets"`, `application "$MOD/internal/tickets"
+        kind "cli"`, 1)
+
+	files := renderProject(t, src)
+	assertContains(t, files, "internal/application/demoserver/application.go",
+		`cli.Execute(ctx, "demo-server", d.args, os.Stdout, d.self.getCommands()...)`,
+		`Name:  "tickets-rename",`,
+	)
+	assertContains(t, files, "internal/cli/cli.go", "func Decode(value string, v interface{}) error {")
+	// the subcommands parse their flags and print the results
+	files["internal/cli/cli_test.go"] = `package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+)
+
+type row struct {
+	Name  string
+	Count int
+}
+
+func TestExecute(t *testing.T) {
+	var limit string
+	cmd := Command{
+		Name:  "list",
+		Usage: "lists rows.",
+		Flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&limit, "limit", "", "is the page size.")
+		},
+		Run: func(ctx context.Context) (interface{}, error) {
+			var n int
+			if err := Decode(limit, &n); err != nil {
+				return nil, err
+			}
+
+			return []row{{Name: "a", Count: n}}, nil
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Execute(context.Background(), "demo", []string{"list", "-limit", "3", "-output", "table"}, &buf, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Name  Count\na     3\n"; buf.String() != want {
+		t.Fatalf("expected %q but got %q", want, buf.String())
+	}
+
+	if err := Execute(context.Background(), "demo", []string{"list", "-limit", "x"}, &buf, cmd); err == nil {
+		t.Fatal("expected an invalid limit to fail")
+	}
+
+	if err := Execute(context.Background(), "demo", []string{"delete"}, &buf, cmd); err == nil {
+		t.Fatal("expected an unknown command to fail")
+	}
+}
+`
+
+	buildFiles(t, files)
+}
@@ -0,0 +1,519 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const pkgClient = "pkg"
+
+// renderClients emits a typed http client package pkg/<bc>client for each bounded context, whose use case
+// services are exposed by an executable.
+func renderClients(dst *ast.Mod, src *adl.Module) error {
+	var bcs []*adl.BoundedContext
+	services := map[*adl.BoundedContext][]exposedService{}
+	for _, executable := range src.Executables {
+		for _, s := range httpServices(src, executable) {
+			if _, ok := services[s.bc]; !ok {
+				bcs = append(bcs, s.bc)
+			}
+
+			if !containsService(services[s.bc], s) {
+				services[s.bc] = append(services[s.bc], s)
+			}
+		}
+	}
+
+	for _, bc := range bcs {
+		if err := renderClient(dst, src, bc, services[bc]); err != nil {
+			return fmt.Errorf("cannot render client of '%s': %w", bc.Name.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// renderClient emits the client package of the bounded context.
+func renderClient(dst *ast.Mod, src *adl.Module, bc *adl.BoundedContext, services []exposedService) error {
+	restPkg := golang.MakePkgPath(dst.Name, pkgRest)
+	pkg := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgClient, clientPkgName(bc)))
+	pkg.SetPreamble(makePreamble(src.Preamble)).
+		SetComment("...provides a typed http client of the exposed use cases of the " + bc.Name.String() + " bounded context.")
+
+	pkg.AddFiles(
+		ast.NewFile("client.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					// Options configures a Client.
+					type Options struct {
+						// BaseURL is the address of the server, e.g. http://localhost:8080.
+						BaseURL string
+
+						// Timeout limits each request including reading the response. Zero means no timeout.
+						Timeout {{.Use "time.Duration"}}
+
+						// Transport performs the requests. If nil, http.DefaultTransport is used.
+						Transport {{.Use "net/http.RoundTripper"}}
+					}
+
+					// Client performs the http requests of the exposed use case services.
+					type Client struct {
+						baseURL *{{.Use "net/url.URL"}}
+						http    *{{.Use "net/http.Client"}}
+					}
+
+					// NewClient creates a Client from the given options.
+					func NewClient(opts Options) (*Client, error) {
+						baseURL, err := {{.Use "net/url.Parse"}}(opts.BaseURL)
+						if err != nil {
+							return nil, {{.Use "fmt.Errorf"}}("invalid base url: %w", err)
+						}
+
+						transport := opts.Transport
+						if transport == nil {
+							transport = {{.Use "net/http.DefaultTransport"}}
+						}
+
+						return &Client{
+							baseURL: baseURL,
+							http:    &{{.Use "net/http.Client"}}{Transport: transport, Timeout: opts.Timeout},
+						}, nil
+					}
+
+					// ResponseError is returned for any error response, which does not denote a declared error case.
+					type ResponseError struct {
+						Status     int                    // Status is the http status code.
+						Case       string                 // Case is the name of the error case, if any.
+						Message    string                 // Message is the error message of the server.
+						Properties map[string]interface{} // Properties contains the raw values of the error case, if any.
+					}
+
+					// Error returns the status code and the message of the server.
+					func (e *ResponseError) Error() string {
+						return {{.Use "fmt.Sprintf"}}("%d: %s", e.Status, e.Message)
+					}
+
+					// response returns the error itself and is promoted to the declared error cases, which embed it.
+					func (e *ResponseError) response() *ResponseError {
+						return e
+					}
+
+					// AsResponseError finds the first error in err's chain, which has been decoded from an error response.
+					// This includes the declared error cases.
+					func AsResponseError(err error) *ResponseError {
+						var target interface{ response() *ResponseError }
+						if {{.Use "errors.As"}}(err, &target) {
+							return target.response()
+						}
+
+						return nil
+					}
+
+					// do sends the request and decodes the json response into the result, if not nil.
+					func (c *Client) do(ctx {{.Use "context.Context"}}, method, path string, query {{.Use "net/url.Values"}}, body, result interface{}) error {
+						u := *c.baseURL
+						u.Path = {{.Use "strings.TrimSuffix"}}(u.Path, "/") + path
+						u.RawQuery = query.Encode()
+
+						var reader {{.Use "io.Reader"}}
+						if body != nil {
+							buf, err := {{.Use "encoding/json.Marshal"}}(body)
+							if err != nil {
+								return {{.Use "fmt.Errorf"}}("cannot encode request body: %w", err)
+							}
+
+							reader = {{.Use "bytes.NewReader"}}(buf)
+						}
+
+						req, err := {{.Use "net/http.NewRequestWithContext"}}(ctx, method, u.String(), reader)
+						if err != nil {
+							return {{.Use "fmt.Errorf"}}("cannot create request: %w", err)
+						}
+
+						req.Header.Set("Accept", "application/json")
+						if body != nil {
+							req.Header.Set("Content-Type", "application/json")
+						}
+
+						resp, err := c.http.Do(req)
+						if err != nil {
+							return {{.Use "fmt.Errorf"}}("cannot send request: %w", err)
+						}
+
+						defer resp.Body.Close()
+
+						if resp.StatusCode >= {{.Use "net/http.StatusBadRequest"}} {
+							var e {{.Use "` + restPkg + `.Error"}}
+							if err := {{.Use "encoding/json.NewDecoder"}}(resp.Body).Decode(&e); err != nil {
+								return &ResponseError{Status: resp.StatusCode, Message: resp.Status}
+							}
+
+							e.Status = resp.StatusCode
+
+							return decodeError(e)
+						}
+
+						if result == nil || resp.StatusCode == {{.Use "net/http.StatusNoContent"}} {
+							return nil
+						}
+
+						if err := {{.Use "encoding/json.NewDecoder"}}(resp.Body).Decode(result); err != nil {
+							return {{.Use "fmt.Errorf"}}("cannot decode response body: %w", err)
+						}
+
+						return nil
+					}
+
+					// formatValue encodes a path or query value the same way, as the server parses it.
+					func formatValue(v interface{}) (string, error) {
+						if m, ok := v.({{.Use "encoding.TextMarshaler"}}); ok {
+							buf, err := m.MarshalText()
+							if err != nil {
+								return "", err
+							}
+
+							return string(buf), nil
+						}
+
+						return {{.Use "fmt.Sprint"}}(v), nil
+					}
+
+					// decodeProperty decodes the named property of an error response into dst.
+					func decodeProperty(properties map[string]interface{}, name string, dst interface{}) error {
+						v, ok := properties[name]
+						if !ok {
+							return nil
+						}
+
+						buf, err := {{.Use "encoding/json.Marshal"}}(v)
+						if err != nil {
+							return err
+						}
+
+						return {{.Use "encoding/json.Unmarshal"}}(buf, dst)
+					}
+				`),
+			),
+	)
+
+	errFile := ast.NewFile("errors.go").SetPreamble(makePreamble(src.Preamble))
+	errFile.AddNodes(ast.NewTpl(clientErrors(bc, restPkg)))
+	pkg.AddFiles(errFile)
+
+	typeFile := ast.NewFile("types.go").SetPreamble(makePreamble(src.Preamble))
+	serviceFile := ast.NewFile("services.go").SetPreamble(makePreamble(src.Preamble))
+	aliases := map[string]string{}
+	for _, s := range services {
+		typ := ast.NewStruct(s.service.Component.Name.String()).
+			SetComment(s.service.Component.Comment.String() + "\n\nEach method performs a request against the according http endpoint.").
+			SetDefaultRecName("c").
+			AddFields(ast.NewField("client", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(pkg.Path+".Client")))).SetVisibility(ast.Private))
+
+		for _, method := range s.service.Component.Methods {
+			if method.Http == nil {
+				continue
+			}
+
+			fun, err := clientMethod(s, method)
+			if err != nil {
+				return fmt.Errorf("cannot create method '%s': %w", method.Name.String(), err)
+			}
+
+			typ.AddMethods(fun)
+
+			for _, param := range append(append([]*adl.Param{}, method.In...), method.Out...) {
+				addTypeAliases(aliases, dst.Name, qualifyTypeDecl(s.pkgPath, param.Type))
+			}
+		}
+
+		serviceFile.AddTypes(typ)
+		serviceFile.AddNodes(ast.NewTpl(
+			"// " + typ.TypeName + " returns the client of the " + typ.TypeName + " service.\n" +
+				"func (c *Client) " + typ.TypeName + "() *" + typ.TypeName + " {\nreturn &" + typ.TypeName + "{client: c}\n}\n",
+		))
+	}
+
+	if len(aliases) > 0 {
+		for _, name := range sortedKeys(aliases) {
+			typeFile.AddNodes(ast.NewTpl("// " + name + " is the type of the server.\ntype " + name + " = {{.Use \"" + aliases[name] + "\"}}\n"))
+		}
+
+		pkg.AddFiles(typeFile)
+	}
+
+	pkg.AddFiles(serviceFile)
+
+	asyncFile := ast.NewFile("async.go").SetPreamble(makePreamble(src.Preamble))
+	for _, s := range services {
+		asyncFile.AddNodes(ast.NewTpl(asyncService(s)))
+	}
+
+	pkg.AddFiles(asyncFile)
+
+	if hasWasmDist(src) {
+		renderWasmClient(dst, src, bc, pkg.Path, services)
+	}
+
+	return nil
+}
+
+// clientMethod creates the method, which performs the request of the exposed service method. It has the same
+// parameters and results, but always accepts a context and returns an error.
+func clientMethod(s exposedService, method *adl.Method) (*ast.Func, error) {
+	e := method.Http
+	vars, err := e.PathVars()
+	if err != nil {
+		return nil, err
+	}
+
+	fun := ast.NewFunc(method.Name.String()).
+		SetComment(method.Comment.String() + "\nIt sends " + e.Verb.String() + " " + e.Path.String() + ".").
+		SetRecName("c").
+		SetPtrReceiver(true)
+
+	ctx := ""
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			ctx = golang.MakePrivate(param.Name.String())
+		}
+	}
+
+	if ctx == "" {
+		ctx = "ctx"
+		fun.AddParams(ast.NewParam(ctx, ast.NewSimpleTypeDecl("context.Context")))
+	}
+
+	for _, param := range method.In {
+		fun.AddParams(ast.NewParam(golang.MakePrivate(param.Name.String()), astutil.MakeTypeDecl(qualifyTypeDecl(s.pkgPath, param.Type))))
+	}
+
+	// the zero values are returned in case of any error
+	var results, zeros, resultNames []string
+	var resultTypes []*adl.TypeDecl
+	body := ""
+	for _, param := range method.Out {
+		if param.Type.Name.String() == stdlib.Error {
+			continue
+		}
+
+		name := "res" + strconv.Itoa(len(results))
+		t := qualifyTypeDecl(s.pkgPath, param.Type)
+		fun.AddResults(ast.NewParam("", astutil.MakeTypeDecl(t)))
+		body += "var " + name + " " + useType(astutil.MakeTypeDecl(t)) + "\n"
+		results = append(results, name)
+		zeros = append(zeros, name)
+		resultNames = append(resultNames, param.Name.String())
+		resultTypes = append(resultTypes, t)
+	}
+
+	fun.AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+	fail := func(msg string) string {
+		return "if err != nil {\nreturn " + strings.Join(append(zeros, "{{.Use \"fmt.Errorf\"}}(\""+msg+": %w\", err)"), ", ") + "\n}\n\n"
+	}
+
+	if body != "" {
+		body += "\n"
+	}
+
+	body += "query := {{.Use \"net/url.Values\"}}{}\n"
+	var path []string
+	literal := ""
+	for _, segment := range strings.Split(e.Path.String()[1:], "/") {
+		literal += "/"
+		if !strings.HasPrefix(segment, "{") || !containsName(vars, segment[1:len(segment)-1]) {
+			literal += segment
+			continue
+		}
+
+		name := segment[1 : len(segment)-1]
+		body += name + "Value, err := formatValue(" + golang.MakePrivate(name) + ")\n" + fail("invalid path parameter '"+name+"'")
+		path = append(path, strconv.Quote(literal), "{{.Use \"net/url.PathEscape\"}}("+name+"Value)")
+		literal = ""
+	}
+
+	if literal != "" {
+		path = append(path, strconv.Quote(literal))
+	}
+
+	for _, name := range e.Query {
+		body += name.String() + "Value, err := formatValue(" + golang.MakePrivate(name.String()) + ")\n" + fail("invalid query parameter '"+name.String()+"'") +
+			"query.Set(" + strconv.Quote(name.String()) + ", " + name.String() + "Value)\n\n"
+	}
+
+	reqBody := "nil"
+	if e.Body.String() != "" {
+		reqBody = golang.MakePrivate(e.Body.String())
+	}
+
+	result := "nil"
+	switch len(results) {
+	case 0:
+	case 1:
+		result = "&res0"
+	default:
+		body += "var res struct {\n"
+		for i, name := range resultNames {
+			body += golang.MakePublic(name) + " " + useType(astutil.MakeTypeDecl(resultTypes[i])) + " `json:\"" + name + "\"`\n"
+		}
+
+		body += "}\n\n"
+		result = "&res"
+	}
+
+	body += "if err := c.client.do(" + ctx + ", " + strconv.Quote(e.Verb.String()) + ", " + strings.Join(path, " + ") + ", query, " + reqBody + ", " + result + "); err != nil {\n" +
+		"return " + strings.Join(append(zeros, "err"), ", ") + "\n}\n\n"
+
+	if len(results) > 1 {
+		for i, name := range resultNames {
+			body += results[i] + " = res." + golang.MakePublic(name) + "\n"
+		}
+
+		body += "\n"
+	}
+
+	body += "return " + strings.Join(append(results, "nil"), ", ") + "\n"
+
+	fun.SetBody(ast.NewBlock(ast.NewTpl(body)))
+
+	return fun, nil
+}
+
+// clientErrors returns the source of the error cases of the bounded context, which implement the same behavior
+// as the server side error cases, so that the As functions of the sum type match. The sum type and its As
+// functions are aliased.
+func clientErrors(bc *adl.BoundedContext, restPkg string) string {
+	sumType := golang2.MakePublic(bc.Name.String()) + "Error"
+	sumMarker := errorMarker(bc.Name.String())
+	var tmp strings.Builder
+	var cases strings.Builder
+	seen := map[string]bool{}
+	hasSumType := false
+	for _, layer := range []struct {
+		name string
+		pkgs []*adl.Package
+	}{{pkgCore, bc.Core}, {pkgUsecase, bc.Usecase}} {
+		for _, p := range layer.pkgs {
+			pkgPath := bcPkgPath(bc, layer.name, p)
+			errs := p.AllErrors()
+			if len(errs) > 0 && !hasSumType {
+				hasSumType = true
+				tmp.WriteString("// " + sumType + " is the sum type of all " + bc.Name.String() + " errors.\n")
+				tmp.WriteString("type " + sumType + " = {{.Use \"" + pkgPath + "." + sumType + "\"}}\n\n")
+				tmp.WriteString("// As" + sumType + " finds the first error in err's chain that matches any " + sumType + " behavior.\n")
+				tmp.WriteString("func As" + sumType + "(err error) " + sumType + " {\nreturn {{.Use \"" + pkgPath + ".As" + sumType + "\"}}(err)\n}\n\n")
+			}
+
+			for _, anErr := range errs {
+				if seen[anErr.Name.String()] {
+					continue
+				}
+
+				seen[anErr.Name.String()] = true
+				caseType := errorCaseName(bc.Name.String(), anErr.Name.String())
+				structType := golang2.MakePrivate(caseType)
+				comment := golang2.DeEllipsis(caseType, anErr.Comment.String())
+
+				tmp.WriteString("// " + comment + "\ntype " + caseType + " = {{.Use \"" + pkgPath + "." + caseType + "\"}}\n\n")
+				tmp.WriteString("// As" + caseType + " finds the first error in err's chain that matches any " + caseType + " behavior.\n")
+				tmp.WriteString("func As" + caseType + "(err error) " + caseType + " {\nreturn {{.Use \"" + pkgPath + ".As" + caseType + "\"}}(err)\n}\n\n")
+
+				tmp.WriteString("// " + structType + " is the decoded " + anErr.Name.String() + " error response.\n")
+				tmp.WriteString("type " + structType + " struct {\nResponseError\n")
+				for _, field := range anErr.Fields {
+					tmp.WriteString(golang2.MakePrivate(field.Name.String()) + " " + useType(astutil.MakeTypeDecl(qualifyTypeDecl(pkgPath, field.Type))) + "\n")
+				}
+
+				tmp.WriteString("}\n\n")
+				for _, field := range anErr.Fields {
+					getter := golang2.MakePublic(golang2.MakePrivate(field.Name.String()))
+					tmp.WriteString("// " + getter + " returns the value of " + field.Name.String() + ".\n")
+					tmp.WriteString("func (e *" + structType + ") " + getter + "() " + useType(astutil.MakeTypeDecl(qualifyTypeDecl(pkgPath, field.Type))) + " {\nreturn e." + golang2.MakePrivate(field.Name.String()) + "\n}\n\n")
+				}
+
+				for _, marker := range []string{sumMarker, errorMarker(anErr.Name.String())} {
+					tmp.WriteString("// " + marker + " returns always true.\nfunc (e *" + structType + ") " + marker + "() bool {\nreturn true\n}\n\n")
+				}
+
+				tmp.WriteString("// Unwrap returns always nil, because the cause is not transmitted.\nfunc (e *" + structType + ") Unwrap() error {\nreturn nil\n}\n\n")
+
+				cases.WriteString("case " + strconv.Quote(anErr.Name.String()) + ":\nerr := &" + structType + "{ResponseError: base}\n")
+				for _, field := range anErr.Fields {
+					getter := golang2.MakePublic(golang2.MakePrivate(field.Name.String()))
+					cases.WriteString("if e := decodeProperty(res.Properties, " + strconv.Quote(getter) + ", &err." + golang2.MakePrivate(field.Name.String()) + "); e != nil {\nreturn {{.Use \"fmt.Errorf\"}}(\"cannot decode property '" + getter + "' of %s: %w\", err.Error(), e)\n}\n\n")
+				}
+
+				cases.WriteString("return err\n")
+			}
+		}
+	}
+
+	tmp.WriteString("// decodeError returns the declared error case of the response or a *ResponseError.\n")
+	tmp.WriteString("func decodeError(res {{.Use \"" + restPkg + ".Error\"}}) error {\n")
+	tmp.WriteString("base := ResponseError{Status: res.Status, Case: res.Case, Message: res.Message, Properties: res.Properties}\n")
+	if cases.Len() > 0 {
+		tmp.WriteString("switch res.Case {\n" + cases.String() + "}\n\n")
+	}
+
+	tmp.WriteString("return &base\n}\n")
+
+	return tmp.String()
+}
+
+// addTypeAliases collects the types of the type declaration, which are declared by the module, by their simple
+// names.
+func addTypeAliases(aliases map[string]string, modPath string, t *adl.TypeDecl) {
+	name := t.Name.String()
+	if idx := strings.LastIndex(name, "."); idx > 0 && strings.HasPrefix(name, modPath+"/") && !strings.Contains(name[idx:], "/") {
+		if _, ok := aliases[name[idx+1:]]; !ok {
+			aliases[name[idx+1:]] = name
+		}
+	}
+
+	for i, param := range t.TypeParams {
+		if t.IsArray() && i == 0 {
+			continue
+		}
+
+		addTypeAliases(aliases, modPath, param)
+	}
+}
+
+// errorMarker returns the name of the marker method of an error case or sum type.
+func errorMarker(name string) string {
+	return golang2.MakePublic(strings.TrimSuffix(name, "Error"))
+}
+
+// clientPkgName returns the name of the client package of the bounded context, e.g. ticketsclient.
+func clientPkgName(bc *adl.BoundedContext) string {
+	return strings.ToLower(golang2.MakeIdentifier(bc.Name.String())) + "client"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func containsService(services []exposedService, s exposedService) bool {
+	for _, other := range services {
+		if other.service == s.service {
+			return true
+		}
+	}
+
+	return false
+}
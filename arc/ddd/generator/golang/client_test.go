@@ -0,0 +1,38 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderClient(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            error NotFound "...indicates a missing ticket."
+
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+        }
+
+        usecase {
+            service Tickets "...manages tickets." {
+                func Find "...finds a ticket." {
+                    in id uuid! "...is the id."
+                    out $BC/core.Ticket "...is the ticket."
+                    out error! "...if anything goes wrong."
+                    error $BC/core.NotFound
+                    http GET "/tickets/{id}" {
+                        status $BC/core.NotFound 404
+                    }
+                }
+            }
+        }`)
+
+	assertContains(t, files, "pkg/ticketsclient/services.go",
+		"func (c *Tickets) Find(ctx context.Context, id uuid.UUID) (core.Ticket, error) {",
+	)
+	assertContains(t, files, "pkg/ticketsclient/errors.go",
+		"func AsTicketsNotFoundError(err error) TicketsNotFoundError {",
+	)
+	buildFiles(t, files)
+}
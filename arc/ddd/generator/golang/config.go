@@ -16,6 +16,8 @@ import (
 
 const ifParseEnv = "if err:={{.Get `rec`}}.{{.Get `field`}}.ParseEnv();err!=nil{\nreturn {{.Use `fmt.Errorf`}}(\"cannot parse '{{.Get `field`}}': %w\",err)}\n"
 
+const ifValidate = "if err:={{.Get `rec`}}.{{.Get `field`}}.Validate();err!=nil{\nreturn {{.Use `fmt.Errorf`}}(\"invalid '{{.Get `field`}}': %w\",err)}\n"
+
 func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 	if len(src.Executables) == 0 {
 		return nil
@@ -30,6 +32,7 @@ func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 
 		uberResetBody := ast.NewBlock()
 		uberParseEnvBody := ast.NewBlock()
+		uberValidateBody := ast.NewBlock()
 		uberConfigureFlagsBody := ast.NewBlock()
 
 		uberCfg.AddMethods(
@@ -55,6 +58,13 @@ func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 				SetComment("...tries to parse the environment variables into this instance.").
 				SetBody(uberParseEnvBody),
 
+			ast.NewFunc("Validate").
+				SetPtrReceiver(true).
+				SetRecName(uberCfg.DefaultRecName).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+				SetComment("...checks the constraints of all configurations and returns the first invalid one.").
+				SetBody(uberValidateBody),
+
 			ast.NewFunc("ParseFile").
 				SetPtrReceiver(true).
 				SetRecName(uberCfg.DefaultRecName).
@@ -119,6 +129,13 @@ func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
 					SetComment("...tries to parse the environment variables into this instance.").
 					SetBody(ast.NewBlock()),
+
+				ast.NewFunc("Validate").
+					SetPtrReceiver(true).
+					SetRecName(bcCfg.DefaultRecName).
+					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+					SetComment("...checks the constraints of all configurations and returns the first invalid one.").
+					SetBody(ast.NewBlock()),
 			)
 
 			uberResetBody.Add(
@@ -136,6 +153,11 @@ func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 				Put("rec", uberCfg.DefaultRecName),
 			)
 
+			uberValidateBody.Add(ast.NewTpl(ifValidate).
+				Put("field", golang.MakePublic(bc.Name)).
+				Put("rec", uberCfg.DefaultRecName),
+			)
+
 			file.AddNodes(bcCfg)
 
 			if _, err := addConfigHolder(dst, bcCfg, bc.Name, path.String(), pkgCore); err != nil {
@@ -152,6 +174,45 @@ func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 				ast.NewReturnStmt(ast.NewIdentLit("nil")),
 				lang.Term(),
 			)
+
+			astutil.MethodByName(bcCfg, "Validate").Body().Add(
+				ast.NewReturnStmt(ast.NewIdentLit("nil")),
+				lang.Term(),
+			)
+		}
+
+		lifecycle := ast.NewField("Lifecycle", ast.NewSimpleTypeDecl(ast.Name(golang.MakePkgPath(dst.Name, pkgInternalApp)+"."+lifecycleConfigType))).
+			SetComment("...contains the options to start and stop the services.")
+		uberCfg.AddFields(lifecycle)
+		uberResetBody.Add(astutil.CallMember(uberCfg.DefaultRecName, lifecycle.FieldName, "Reset"), lang.Term())
+		uberConfigureFlagsBody.Add(astutil.CallMember(uberCfg.DefaultRecName, lifecycle.FieldName, "ConfigureFlags", ast.NewIdent("flags")), lang.Term())
+		uberParseEnvBody.Add(ast.NewTpl(ifParseEnv).
+			Put("field", lifecycle.FieldName).
+			Put("rec", uberCfg.DefaultRecName),
+		)
+
+		if len(httpServices(src, executable)) > 0 {
+			field := ast.NewField("HTTP", ast.NewSimpleTypeDecl(ast.Name(golang.MakePkgPath(dst.Name, pkgRest)+"."+restConfigType))).
+				SetComment("...contains the options of the http server.")
+			uberCfg.AddFields(field)
+			uberResetBody.Add(astutil.CallMember(uberCfg.DefaultRecName, field.FieldName, "Reset"), lang.Term())
+			uberConfigureFlagsBody.Add(astutil.CallMember(uberCfg.DefaultRecName, field.FieldName, "ConfigureFlags", ast.NewIdent("flags")), lang.Term())
+			uberParseEnvBody.Add(ast.NewTpl(ifParseEnv).
+				Put("field", field.FieldName).
+				Put("rec", uberCfg.DefaultRecName),
+			)
+		}
+
+		if len(grpcServices(src, executable)) > 0 {
+			field := ast.NewField("GRPC", ast.NewSimpleTypeDecl(ast.Name(golang.MakePkgPath(dst.Name, pkgRpc)+"."+rpcConfigType))).
+				SetComment("...contains the options of the grpc server.")
+			uberCfg.AddFields(field)
+			uberResetBody.Add(astutil.CallMember(uberCfg.DefaultRecName, field.FieldName, "Reset"), lang.Term())
+			uberConfigureFlagsBody.Add(astutil.CallMember(uberCfg.DefaultRecName, field.FieldName, "ConfigureFlags", ast.NewIdent("flags")), lang.Term())
+			uberParseEnvBody.Add(ast.NewTpl(ifParseEnv).
+				Put("field", field.FieldName).
+				Put("rec", uberCfg.DefaultRecName),
+			)
 		}
 
 		uberParseEnvBody.Add(
@@ -159,6 +220,11 @@ func renderConfigs(dst *ast.Mod, src *adl.Module) error {
 			ast.NewReturnStmt(ast.NewIdentLit("nil")),
 		)
 
+		uberValidateBody.Add(
+			lang.Term(),
+			ast.NewReturnStmt(ast.NewIdentLit("nil")),
+		)
+
 		exampleJson, err := exampleJson(uberCfg)
 		if err != nil {
 			return fmt.Errorf("unable to encode example json: %w", err)
@@ -192,6 +258,7 @@ func addConfigHolder(mod *ast.Mod, dst *ast.Struct, bcName, bcPath, pathSuffix s
 
 	resetBody := ast.NewBlock()
 	parseEnvBody := ast.NewBlock()
+	validateBody := ast.NewBlock()
 	configureFlagsBody := ast.NewBlock()
 	holder.AddMethods(
 		ast.NewFunc("Reset").
@@ -207,6 +274,13 @@ func addConfigHolder(mod *ast.Mod, dst *ast.Struct, bcName, bcPath, pathSuffix s
 			SetComment("...tries to parse the environment variables into this instance.").
 			SetBody(parseEnvBody),
 
+		ast.NewFunc("Validate").
+			SetPtrReceiver(true).
+			SetRecName(holder.DefaultRecName).
+			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+			SetComment("...checks the constraints of all configurations and returns the first invalid one.").
+			SetBody(validateBody),
+
 		ast.NewFunc("ConfigureFlags").
 			SetPtrReceiver(true).
 			SetRecName(holder.DefaultRecName).
@@ -232,11 +306,19 @@ func addConfigHolder(mod *ast.Mod, dst *ast.Struct, bcName, bcPath, pathSuffix s
 
 		f := ast.NewField(cfg.TypeName, ast.NewSimpleTypeDecl(ast.Name(astutil.FullQualifiedName(cfg))))
 		resetBody.Add(astutil.CallMember(holder.DefaultRecName, f.FieldName, "Reset"), lang.Term())
-		configureFlagsBody.Add(astutil.CallMember(holder.DefaultRecName, f.FieldName, "ConfigureFlags", ast.NewIdent("flags")))
+		configureFlagsBody.Add(astutil.CallMember(holder.DefaultRecName, f.FieldName, "ConfigureFlags", ast.NewIdent("flags")), lang.Term())
 		parseEnvBody.Add(ast.NewTpl(ifParseEnv).
 			Put("field", f.FieldName).
 			Put("rec", holder.DefaultRecName),
 		)
+
+		if astutil.MethodByName(cfg, "Validate") != nil {
+			validateBody.Add(ast.NewTpl(ifValidate).
+				Put("field", f.FieldName).
+				Put("rec", holder.DefaultRecName),
+			)
+		}
+
 		holder.AddFields(f)
 	}
 
@@ -245,6 +327,11 @@ func addConfigHolder(mod *ast.Mod, dst *ast.Struct, bcName, bcPath, pathSuffix s
 		ast.NewReturnStmt(ast.NewIdentLit("nil")),
 	)
 
+	validateBody.Add(
+		lang.Term(),
+		ast.NewReturnStmt(ast.NewIdentLit("nil")),
+	)
+
 	astutil.File(dst).AddNodes(holder)
 	field := ast.NewField(golang.MakePublic(pathSuffix), ast.NewSimpleTypeDecl(ast.Name(astutil.Pkg(dst).Path+"."+holder.TypeName)))
 	dst.AddFields(field)
@@ -267,10 +354,21 @@ func addConfigHolder(mod *ast.Mod, dst *ast.Struct, bcName, bcPath, pathSuffix s
 		Put("rec", dst.DefaultRecName),
 	)
 
+	parentValidate := astutil.MethodByName(dst, "Validate")
+	parentValidate.Body().Add(ast.NewTpl(ifValidate).
+		Put("field", field.FieldName).
+		Put("rec", dst.DefaultRecName),
+	)
+
 	return holder, nil
 }
 
 func addConfigUtil(bcName, pathSuffix string, cfg *ast.Struct) error {
+	// the utilities belong to the bounded context and are shared by all executables, which include it
+	if astutil.MethodByName(cfg, "Reset") != nil {
+		return nil
+	}
+
 	if cfg.DefaultRecName == "" {
 		cfg.DefaultRecName = strings.ToLower(cfg.TypeName[:1])
 	}
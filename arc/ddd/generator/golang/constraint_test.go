@@ -0,0 +1,69 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderConstraints(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field Title string! "...is the title." {
+                    required
+                    minLength 3
+                    maxLength 80
+                    pattern "^[A-Z]"
+                }
+                field Priority string! "...is the priority." {
+                    oneOf low, high
+                }
+                field Score float64! "...is the score." {
+                    min -1
+                    max 0.5
+                    predicate Rounded "...ensures a rounded score."
+                }
+            }
+
+            config Settings "...configures tickets." {
+                field Timeout duration! "...is the request timeout." {
+                    min 1s
+                }
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/dtos.go",
+		"func (t *Ticket) Validate() error {",
+		"func (s *Settings) Validate() error {",
+	)
+	assertContains(t, files, "internal/application/demoserver/application.go", "if err := d.cfg.Validate(); err != nil {")
+	// the violations of all fields are collected
+	files["internal/tickets/core/dtos_test.go"] = `package core
+
+import (
+	"errors"
+	"example.com/demo/internal/validation"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	ticket := Ticket{Title: "Printer", Priority: "low", Score: 0.5}
+	if err := ticket.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ticket = Ticket{Title: "pr", Priority: "urgent", Score: -2}
+	var vErr *validation.Error
+	if err := ticket.Validate(); !errors.As(err, &vErr) || len(vErr.Violations) != 4 {
+		t.Fatalf("expected 4 violations but got %v", err)
+	}
+
+	settings := Settings{Timeout: time.Millisecond}
+	if err := settings.Validate(); err == nil {
+		t.Fatal("expected a violation of the minimum timeout")
+	}
+}
+`
+
+	buildFiles(t, files)
+}
@@ -0,0 +1,124 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"github.com/golangee/src/stdlib/lang"
+)
+
+// crudContract describes the generated operations of a CRUD, which are declared by the repository interface
+// and implemented by each persistence.
+type crudContract struct {
+	crud          *adl.CRUD
+	entityType    ast.TypeDecl
+	keyType       ast.TypeDecl
+	selector      string          // the identity relative to an entity variable, e.g. ".ID".
+	pkgPath       string          // the package of the repository, which also declares the query types.
+	notFound      *lang.ErrorCase // returned, if no entity with the identity exists.
+	alreadyExists *lang.ErrorCase // returned, if an entity with the identity exists already.
+	sumType       *lang.Error
+}
+
+// newCrudContract resolves the identity of the entity and adds the error cases of the CRUD to the sum type of
+// the package.
+func newCrudContract(file *ast.File, crud *adl.CRUD, sumType *lang.Error) (*crudContract, error) {
+	entityType := astutil.MakeTypeDecl(crud.EntityType)
+	if astutil.Resolve(file, entityType.String()) == nil {
+		return nil, fmt.Errorf("crud entity type cannot be resolved: " + entityType.String())
+	}
+
+	keyType, selector, err := crudIdentity(file, crud, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	entityName := ast.Name(entityType.String()).Identifier()
+	notFoundName, alreadyExistsName := crud.ErrorNames()
+	c := &crudContract{
+		crud:       crud,
+		entityType: entityType,
+		keyType:    keyType,
+		selector:   selector,
+		pkgPath:    file.Pkg().Path,
+		sumType:    sumType,
+	}
+
+	c.notFound = errorCase(sumType, notFoundName, "...indicates that no "+entityName+" with the according id exists.", keyType)
+	c.alreadyExists = errorCase(sumType, alreadyExistsName, "...indicates that a "+entityName+" with the according id exists already.", keyType)
+
+	if crud.FindAll || crud.IterateAll {
+		if err := renderCrudQuery(file, crud, entityType); err != nil {
+			return nil, fmt.Errorf("unable to render query: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// errorCase returns the case of the sum type with the given name and adds it, if required. Multiple
+// repositories of the same entity share their cases.
+func errorCase(sumType *lang.Error, name, comment string, keyType ast.TypeDecl) *lang.ErrorCase {
+	for _, c := range sumType.Cases {
+		if c.TypeName == name {
+			return c
+		}
+	}
+
+	c := lang.NewErrorCase(name).SetComment(comment).AddProperty("id", keyType.Clone(), "...is the identity of the entity.")
+	sumType.AddCase(c)
+
+	return c
+}
+
+// errorName returns the name of the public interface of the error case.
+func (c *crudContract) errorName(errCase *lang.ErrorCase) string {
+	return errorCaseName(c.sumType.GroupName, errCase.TypeName)
+}
+
+// methods returns new signatures of the enabled operations without a body.
+func (c *crudContract) methods() []*ast.Func {
+	queryName, _, visitorName := crudQueryName(c.entityType)
+	var res []*ast.Func
+	for _, op := range c.crud.Operations() {
+		fun := ast.NewFunc(op)
+		switch op {
+		case "InsertOne":
+			fun.SetComment("...inserts the entity or fails with " + c.errorName(c.alreadyExists) + ", if an entity with the same\nidentity exists already.").
+				AddParams(ast.NewParam("entity", c.entityType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		case "FindOne":
+			fun.SetComment("...returns the entity with the given id or fails with " + c.errorName(c.notFound) + ".").
+				AddParams(ast.NewParam("id", c.keyType.Clone())).
+				AddResults(ast.NewParam("", c.entityType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		case "UpdateOne":
+			fun.SetComment("...replaces the entity with the same identity or fails with " + c.errorName(c.notFound) + ".").
+				AddParams(ast.NewParam("entity", c.entityType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		case "DeleteOne":
+			fun.SetComment("...deletes the entity with the given id. Is a no-op if no such entity exists.").
+				AddParams(ast.NewParam("id", c.keyType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		case "CountAll":
+			fun.SetComment("...returns the amount of all entities.").
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Int64))).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		case "FindAll":
+			fun.SetComment("...returns the page of the sorted entities, which is selected by the query.").
+				AddParams(ast.NewParam("query", ast.NewSimpleTypeDecl(ast.Name(c.pkgPath+"."+queryName)))).
+				AddResults(ast.NewParam("", ast.NewSliceTypeDecl(c.entityType.Clone()))).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		case "IterateAll":
+			fun.SetComment("...invokes the visitor for each entity in the order of their identity, until it returns false.\nThe visitor may modify the repository.").
+				AddParams(ast.NewParam("visitor", ast.NewSimpleTypeDecl(ast.Name(c.pkgPath+"."+visitorName)))).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)))
+		}
+
+		res = append(res, fun)
+	}
+
+	return res
+}
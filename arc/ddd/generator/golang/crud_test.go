@@ -0,0 +1,40 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderCrudErrors(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket in-memory { insertOne findOne }
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                func Find "...finds a ticket." {
+                    in id uuid! "...is the id."
+                    out $BC/core.Ticket "...is the ticket."
+                    out error! "...if anything goes wrong."
+                    error $BC/core.TicketNotFound
+                    http GET "/tickets/{id}" {
+                        status $BC/core.TicketNotFound 404
+                    }
+                }
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/repositories.go", "FindOne(id uuid.UUID) (Ticket, error)")
+	assertContains(t, files, "internal/application/demoserver/application.go",
+		`rest.Error{Status: 404, Case: "TicketNotFound", Message: e.Error(), Properties: map[string]interface{}{"ID": e.ID()}}`,
+	)
+	assertContains(t, files, "pkg/ticketsclient/errors.go", "func (e *ticketsTicketNotFoundError) ID() uuid.UUID {")
+	assertContains(t, files, "demo-server.openapi.json", `"TicketNotFound"`)
+	buildFiles(t, files)
+}
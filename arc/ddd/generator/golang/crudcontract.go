@@ -0,0 +1,338 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+// crudContractEntities is the amount of entities, which are inserted out of order by the contract test.
+const crudContractEntities = 5
+
+// keySource returns the template expression, which creates the distinct identity of the i-th entity. The
+// identities are sorted in the order of i. Only key types with a natural order are supported.
+func keySource(typ ast.TypeDecl, i string) (string, bool) {
+	switch typ.String() {
+	case stdlib.String:
+		return `{{.Use "fmt.Sprintf"}}("id-%03d", ` + i + `)`, true
+	case stdlib.Int, stdlib.Int16, stdlib.Int32, stdlib.Int64, stdlib.Byte, stdlib.Rune,
+		stdlib.Float32, stdlib.Float64, stdlib.Duration:
+		// the builtin type names are only resolved into go type names by the template
+		return `{{.Use "` + typ.String() + `"}}(` + i + ` + 1)`, true
+	case stdlib.UUID:
+		return `{{.Use "` + stdlib.UUID + `"}}{byte(` + i + ` + 1)}`, true
+	default:
+		return "", false
+	}
+}
+
+// markerSource returns a field of the entity and a value different from its zero value, which the contract test
+// uses to detect whether an update has been applied. It returns false, if the entity has no such field.
+func markerSource(file *ast.File, c *crudContract) (string, string, bool) {
+	s, ok := astutil.Resolve(file, c.entityType.String()).(*ast.Struct)
+	if !ok {
+		return "", "", false
+	}
+
+	for _, field := range s.Fields() {
+		if "."+field.FieldName == c.selector || field.Visibility() != ast.Public {
+			continue
+		}
+
+		switch field.TypeDecl().String() {
+		case stdlib.String:
+			return field.FieldName, `"updated"`, true
+		case stdlib.Bool:
+			return field.FieldName, "true", true
+		case stdlib.Int, stdlib.Int16, stdlib.Int32, stdlib.Int64, stdlib.Byte, stdlib.Rune,
+			stdlib.Float32, stdlib.Float64, stdlib.Duration:
+			return field.FieldName, "42", true
+		}
+	}
+
+	return "", "", false
+}
+
+// use returns the template expression, which refers to the declaration of the repository package.
+func (c *crudContract) use(name string) string {
+	return `{{.Use "` + c.pkgPath + "." + name + `"}}`
+}
+
+// renderCrudContract declares a reusable test of the semantics of the CRUD operations, so that all implementations
+// of the repository interface behave the same, including the cases of the error sum type. The contract test requires
+// InsertOne and an identity, which is settable and has a natural order, otherwise it is not declared.
+// The contract test is declared in the test support package of the repository package, e.g. coretest, so that
+// other implementations can import it. A test file of the support package runs it for the generated
+// implementation.
+func renderCrudContract(file *ast.File, iface *ast.Interface, c *crudContract, repo *ast.Struct) error {
+	key, ok := keySource(c.keyType, "i")
+	if !ok || !c.crud.InsertOne || strings.HasSuffix(c.selector, ")") {
+		return nil
+	}
+
+	runner, err := crudContractRunner(file, iface, c, repo)
+	if err != nil {
+		return err
+	}
+
+	entity := `{{.Use "` + c.entityType.String() + `"}}`
+	repoType := c.use(iface.TypeName)
+	notFound := c.use("As" + c.errorName(c.notFound))
+	alreadyExists := c.use("As" + c.errorName(c.alreadyExists))
+	queryName, _, _ := crudQueryName(c.entityType)
+	queryType := c.use(queryName)
+	usesAll := c.crud.DeleteOne || c.crud.CountAll || c.crud.FindAll || c.crud.IterateAll
+	usesIDs := c.crud.FindAll || c.crud.IterateAll
+
+	tmp := &strings.Builder{}
+	tmp.WriteString("// " + iface.TypeName + "ContractTest verifies, that the implementation satisfies the contract of " + iface.TypeName + ".\n")
+	tmp.WriteString("// The factory must return a new and empty repository for each invocation of a subtest. Other implementations\n")
+	tmp.WriteString("// of the repository should run it in their own tests.\n")
+	tmp.WriteString("func " + iface.TypeName + "ContractTest(t *{{.Use \"testing.T\"}}, factory func(t *testing.T) " + repoType + ") {\n")
+	tmp.WriteString("newEntity := func(i int) " + entity + " {\nvar entity " + entity + "\nentity" + c.selector + " = " + key + "\n\nreturn entity\n}\n\n")
+
+	if usesAll {
+		tmp.WriteString("// insertAll inserts the entities out of order, so that an implementation cannot rely on the insertion order\n")
+		tmp.WriteString("insertAll := func(t *testing.T, repo " + repoType + ") []" + entity + " {\n")
+		tmp.WriteString("t.Helper()\n")
+		tmp.WriteString("var res []" + entity + "\n")
+		tmp.WriteString("for i := 0; i < " + strconv.Itoa(crudContractEntities) + "; i++ {\nres = append(res, newEntity(i))\n}\n\n")
+		tmp.WriteString("for _, i := range []int{3, 0, 4, 1, 2} {\n")
+		tmp.WriteString("if err := repo.InsertOne(res[i]); err != nil {\nt.Fatalf(\"cannot insert entity %d: %v\", i, err)\n}\n}\n\n")
+		tmp.WriteString("return res\n}\n\n")
+	}
+
+	if usesIDs {
+		tmp.WriteString("assertIDs := func(t *testing.T, got []" + entity + ", want ..." + entity + ") {\n")
+		tmp.WriteString("t.Helper()\n")
+		tmp.WriteString("if len(got) != len(want) {\nt.Fatalf(\"expected %d entities but got %d\", len(want), len(got))\n}\n\n")
+		tmp.WriteString("for i := range want {\nif got[i]" + c.selector + " != want[i]" + c.selector + " {\n")
+		tmp.WriteString("t.Fatalf(\"expected entity %v at %d but got %v\", want[i]" + c.selector + ", i, got[i]" + c.selector + ")\n}\n}\n}\n\n")
+	}
+
+	// each operation is verified on its own repository, so that the subtests are independent
+	tmp.WriteString("t.Run(\"InsertOne\", func(t *testing.T) {\n")
+	tmp.WriteString("repo := factory(t)\nentity := newEntity(0)\n")
+	tmp.WriteString("if err := repo.InsertOne(entity); err != nil {\nt.Fatal(err)\n}\n\n")
+	tmp.WriteString("err := repo.InsertOne(entity)\n")
+	tmp.WriteString("if e := " + alreadyExists + "(err); e == nil || e.ID() != entity" + c.selector + " {\n")
+	tmp.WriteString("t.Fatalf(\"expected " + c.errorName(c.alreadyExists) + " but got %v\", err)\n}\n")
+	tmp.WriteString("})\n\n")
+
+	if c.crud.FindOne {
+		tmp.WriteString("t.Run(\"FindOne\", func(t *testing.T) {\n")
+		tmp.WriteString("repo := factory(t)\nentity := newEntity(0)\n")
+		tmp.WriteString("if err := repo.InsertOne(entity); err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("found, err := repo.FindOne(entity" + c.selector + ")\n")
+		tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("if found" + c.selector + " != entity" + c.selector + " {\nt.Fatalf(\"expected %v but found %v\", entity" + c.selector + ", found" + c.selector + ")\n}\n\n")
+		tmp.WriteString("missing := newEntity(1)" + c.selector + "\n")
+		tmp.WriteString("_, err = repo.FindOne(missing)\n")
+		tmp.WriteString("if e := " + notFound + "(err); e == nil || e.ID() != missing {\n")
+		tmp.WriteString("t.Fatalf(\"expected " + c.errorName(c.notFound) + " but got %v\", err)\n}\n")
+		tmp.WriteString("})\n\n")
+	}
+
+	if c.crud.UpdateOne {
+		tmp.WriteString("t.Run(\"UpdateOne\", func(t *testing.T) {\n")
+		tmp.WriteString("repo := factory(t)\nentity := newEntity(0)\n")
+		tmp.WriteString("err := repo.UpdateOne(entity)\n")
+		tmp.WriteString("if e := " + notFound + "(err); e == nil || e.ID() != entity" + c.selector + " {\n")
+		tmp.WriteString("t.Fatalf(\"expected " + c.errorName(c.notFound) + " but got %v\", err)\n}\n\n")
+		tmp.WriteString("if err := repo.InsertOne(entity); err != nil {\nt.Fatal(err)\n}\n\n")
+		field, value, hasMarker := markerSource(file, c)
+		if hasMarker {
+			tmp.WriteString("entity." + field + " = " + value + "\n")
+		}
+
+		tmp.WriteString("if err := repo.UpdateOne(entity); err != nil {\nt.Fatal(err)\n}\n")
+		if hasMarker && c.crud.FindOne {
+			tmp.WriteString("\nfound, err := repo.FindOne(entity" + c.selector + ")\n")
+			tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+			tmp.WriteString("if found." + field + " != entity." + field + " {\n")
+			tmp.WriteString("t.Fatalf(\"expected the updated " + field + " %v but found %v\", entity." + field + ", found." + field + ")\n}\n")
+		}
+
+		tmp.WriteString("})\n\n")
+	}
+
+	if c.crud.DeleteOne {
+		tmp.WriteString("t.Run(\"DeleteOne\", func(t *testing.T) {\n")
+		tmp.WriteString("repo := factory(t)\nall := insertAll(t, repo)\n")
+		tmp.WriteString("if err := repo.DeleteOne(all[1]" + c.selector + "); err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("// deleting a missing entity is a no-op\n")
+		tmp.WriteString("if err := repo.DeleteOne(all[1]" + c.selector + "); err != nil {\nt.Fatalf(\"expected no error but got %v\", err)\n}\n")
+		if c.crud.FindOne {
+			tmp.WriteString("\n_, err := repo.FindOne(all[1]" + c.selector + ")\n")
+			tmp.WriteString("if e := " + notFound + "(err); e == nil {\n")
+			tmp.WriteString("t.Fatalf(\"expected " + c.errorName(c.notFound) + " but got %v\", err)\n}\n\n")
+			tmp.WriteString("if _, err := repo.FindOne(all[2]" + c.selector + "); err != nil {\nt.Fatalf(\"expected other entities to remain: %v\", err)\n}\n")
+		}
+
+		if c.crud.CountAll {
+			tmp.WriteString("\ncount, err := repo.CountAll()\n")
+			tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+			tmp.WriteString("if count != " + strconv.Itoa(crudContractEntities-1) + " {\nt.Fatalf(\"expected " + strconv.Itoa(crudContractEntities-1) + " entities but counted %d\", count)\n}\n")
+		}
+
+		tmp.WriteString("})\n\n")
+	}
+
+	if c.crud.CountAll {
+		tmp.WriteString("t.Run(\"CountAll\", func(t *testing.T) {\n")
+		tmp.WriteString("repo := factory(t)\n")
+		tmp.WriteString("count, err := repo.CountAll()\n")
+		tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("if count != 0 {\nt.Fatalf(\"expected an empty repository but counted %d\", count)\n}\n\n")
+		tmp.WriteString("insertAll(t, repo)\n")
+		tmp.WriteString("count, err = repo.CountAll()\n")
+		tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("if count != " + strconv.Itoa(crudContractEntities) + " {\nt.Fatalf(\"expected " + strconv.Itoa(crudContractEntities) + " entities but counted %d\", count)\n}\n")
+		tmp.WriteString("})\n\n")
+	}
+
+	if c.crud.FindAll {
+		type page struct {
+			name, query, want string
+		}
+
+		pages := []page{
+			{"all", queryType + "{}", "all..."},
+			{"limit", queryType + "{Limit: 2}", "all[0], all[1]"},
+			{"offset", queryType + "{Offset: 2, Limit: 2}", "all[2], all[3]"},
+			{"offset beyond", queryType + "{Offset: " + strconv.Itoa(crudContractEntities) + "}", ""},
+			{"descending", queryType + "{Descending: true, Limit: 2}", "all[4], all[3]"},
+			{"after", queryType + "{After: &all[1]}", "all[2], all[3], all[4]"},
+			{"after descending", queryType + "{After: &all[1], Descending: true}", "all[0]"},
+			{"after ignores offset", queryType + "{After: &all[1], Offset: 2, Limit: 1}", "all[2]"},
+		}
+
+		tmp.WriteString("t.Run(\"FindAll\", func(t *testing.T) {\n")
+		tmp.WriteString("repo := factory(t)\nall := insertAll(t, repo)\n")
+		tmp.WriteString("pages := []struct {\nname string\nquery " + queryType + "\nwant []" + entity + "\n}{\n")
+		for _, p := range pages {
+			want := "nil"
+			if p.want == "all..." {
+				want = "all"
+			} else if p.want != "" {
+				want = "[]" + entity + "{" + p.want + "}"
+			}
+
+			tmp.WriteString("{name: \"" + p.name + "\", query: " + p.query + ", want: " + want + "},\n")
+		}
+
+		tmp.WriteString("}\n\n")
+		tmp.WriteString("for _, p := range pages {\n")
+		tmp.WriteString("res, err := repo.FindAll(p.query)\n")
+		tmp.WriteString("if err != nil {\nt.Fatalf(\"%s: %v\", p.name, err)\n}\n\n")
+		tmp.WriteString("t.Run(p.name, func(t *testing.T) {\nassertIDs(t, res, p.want...)\n})\n}\n\n")
+		tmp.WriteString("if _, err := repo.FindAll(" + queryType + "{Offset: -1}); err == nil {\n")
+		tmp.WriteString("t.Fatal(\"expected an error for a negative offset\")\n}\n")
+		tmp.WriteString("})\n\n")
+	}
+
+	if c.crud.IterateAll {
+		tmp.WriteString("t.Run(\"IterateAll\", func(t *testing.T) {\n")
+		tmp.WriteString("repo := factory(t)\nall := insertAll(t, repo)\n")
+		tmp.WriteString("var visited []" + entity + "\n")
+		tmp.WriteString("err := repo.IterateAll(func(entity " + entity + ") bool {\nvisited = append(visited, entity)\n\nreturn true\n})\n\n")
+		tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("assertIDs(t, visited, all...)\n\n")
+		tmp.WriteString("visited = nil\n")
+		tmp.WriteString("err = repo.IterateAll(func(entity " + entity + ") bool {\nvisited = append(visited, entity)\n\nreturn len(visited) < 2\n})\n\n")
+		tmp.WriteString("if err != nil {\nt.Fatal(err)\n}\n\n")
+		tmp.WriteString("assertIDs(t, visited, all[0], all[1])\n")
+		tmp.WriteString("})\n")
+	}
+
+	tmp.WriteString("}\n")
+
+	parent := file.Pkg()
+	pkg := astutil.MkPkg(astutil.Mod(file), golang.MakePkgPath(parent.Path, crudContractPkgName(parent)))
+	if pkg.ObjComment == nil {
+		pkg.SetComment("...provides the contract tests of the repositories of package " + parent.Name + ", which all their implementations must pass.")
+		if parent.Preamble != nil {
+			pkg.SetPreamble(parent.Preamble.Text)
+		}
+	}
+
+	contracts := astutil.MkFile(pkg, "contracts.go")
+	if contracts.Preamble == nil && file.Preamble != nil {
+		contracts.SetPreamble(file.Preamble.Text)
+	}
+
+	contracts.AddNodes(ast.NewTpl(tmp.String()))
+
+	tests := astutil.MkFile(pkg, "repositories_test.go")
+	if tests.Preamble == nil && file.Preamble != nil {
+		tests.SetPreamble(file.Preamble.Text)
+	}
+
+	tests.AddNodes(ast.NewTpl(runner))
+	if c.crud.Persistence == adl.PMySQL {
+		importMySQLDriver(tests)
+	}
+
+	return nil
+}
+
+// crudContractPkgName returns the name of the test support package of the repository package, like httptest for
+// the http package.
+func crudContractPkgName(pkg *ast.Pkg) string {
+	return pkg.Name + "test"
+}
+
+// crudContractRunner returns the template of the test, which runs the contract test for the generated
+// implementation. The file based repositories are created in temporary directories. The MySQL based repositories
+// require a database, which is dedicated to tests, because their table is emptied for each subtest. Its name is
+// read from the MYSQL_TEST_DATABASE environment variable, otherwise the test is skipped. The test registers the
+// driver and closes the connection, when it has finished.
+func crudContractRunner(file *ast.File, iface *ast.Interface, c *crudContract, repo *ast.Struct) (string, error) {
+	name := "Test" + repo.TypeName
+	contract := iface.TypeName + "ContractTest(t, func(t *testing.T) " + c.use(iface.TypeName) + " {\n"
+	factory := c.use("New" + repo.TypeName)
+
+	switch c.crud.Persistence {
+	case adl.PMemory:
+		return "// " + name + " runs the contract test of " + iface.TypeName + ".\n" +
+			"func " + name + "(t *{{.Use \"testing.T\"}}) {\n" +
+			contract +
+			"return " + factory + "()\n})\n}\n", nil
+	case adl.PFile:
+		return "// " + name + " runs the contract test of " + iface.TypeName + " in temporary directories.\n" +
+			"func " + name + "(t *{{.Use \"testing.T\"}}) {\n" +
+			contract +
+			"repo, err := " + factory + "(" + c.use(repo.TypeName+"Config") + "{" + crudFileDirField(repo) + ": t.TempDir()})\n" +
+			"if err != nil {\nt.Fatal(err)\n}\n\n" +
+			"return repo\n})\n}\n", nil
+	case adl.PMySQL:
+		m, err := newSQLMapping(file, c.crud)
+		if err != nil {
+			return "", err
+		}
+
+		return "// " + name + " runs the contract test of " + iface.TypeName + " against the database, which is selected by\n" +
+			"// the MYSQL_TEST_DATABASE environment variable. All other options are parsed from the environment.\n" +
+			"// The table is emptied for each subtest, so never select a database with valuable data.\n" +
+			"func " + name + "(t *{{.Use \"testing.T\"}}) {\n" +
+			"database := {{.Use \"os.Getenv\"}}(\"MYSQL_TEST_DATABASE\")\n" +
+			"if database == \"\" {\nt.Skip(\"MYSQL_TEST_DATABASE is not set\")\n}\n\n" +
+			"var opts " + c.use("Options") + "\nopts.Reset()\n" +
+			"if err := opts.ParseEnv(); err != nil {\nt.Fatal(err)\n}\n\n" +
+			"opts.Database = database\n" +
+			"db, err := " + c.use("Open") + "(opts)\n" +
+			"if err != nil {\nt.Fatal(err)\n}\n\n" +
+			"t.Cleanup(func() {\nif closer, ok := db.({{.Use \"io.Closer\"}}); ok {\n_ = closer.Close()\n}\n})\n\n" +
+			"if err := " + c.use("Migrate") + "(db); err != nil {\nt.Fatal(err)\n}\n\n" +
+			contract +
+			"if _, err := db.ExecContext({{.Use \"context.Background\"}}(), " + strconv.Quote(`DELETE FROM "`+m.table+`"`) + "); err != nil {\nt.Fatal(err)\n}\n\n" +
+			"return " + factory + "(db)\n})\n}\n", nil
+	default:
+		return "", fmt.Errorf("unsupported persistence '%s'", c.crud.Persistence)
+	}
+}
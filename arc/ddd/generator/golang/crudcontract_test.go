@@ -0,0 +1,52 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderCrudContract(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID int64! "...is the id."
+                field Title string! "...is the title."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket in-memory { all }
+            }
+        }`)
+
+	if _, ok := files["internal/tickets/core/contracts_test.go"]; ok {
+		t.Fatal("expected the contract test in an importable package")
+	}
+
+	assertContains(t, files, "internal/tickets/core/coretest/contracts.go",
+		"package coretest",
+		"func TicketsContractTest(t *testing.T, factory func(t *testing.T) core.Tickets) {",
+		"entity.ID = int64(i + 1)",
+		"if e := core.AsTicketsTicketNotFoundError(err); e == nil || e.ID() != missing {",
+	)
+	assertContains(t, files, "internal/tickets/core/coretest/repositories_test.go",
+		"func TestInMemoryTickets(t *testing.T) {",
+		"return core.NewInMemoryTickets()",
+	)
+
+	// another implementation runs the contract in its own package
+	files["internal/tickets/other/other_test.go"] = `package other
+
+import (
+	"example.com/demo/internal/tickets/core"
+	"example.com/demo/internal/tickets/core/coretest"
+	"testing"
+)
+
+func TestOther(t *testing.T) {
+	coretest.TicketsContractTest(t, func(t *testing.T) core.Tickets {
+		return core.NewInMemoryTickets()
+	})
+}
+`
+
+	buildFiles(t, files)
+}
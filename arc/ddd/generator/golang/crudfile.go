@@ -0,0 +1,333 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"strings"
+)
+
+// renderCrudFile renders a repository, which stores each entity as a json document within a directory. The
+// directory is declared by a configuration, which is aggregated into the configuration of each executable.
+func renderCrudFile(file *ast.File, c *crudContract, repo *ast.Struct) error {
+	entityName := ast.Name(c.entityType.String()).Identifier()
+	cfg, dirField := renderCrudFileConfig(file, repo)
+
+	repo.SetComment("...implements a file based repository for " + entityName + " entities. Each entity is stored as a json\n" +
+		"document within the configured directory and is replaced atomically by renaming a temporary file. Only the\n" +
+		"exported fields of an entity are persisted.")
+	repo.AddFields(
+		ast.NewField("dir", ast.NewSimpleTypeDecl(stdlib.String)).SetVisibility(ast.Private),
+		ast.NewField("mutex", ast.NewSimpleTypeDecl("sync.RWMutex")).SetVisibility(ast.Private),
+	)
+
+	constructor := ast.NewFunc("New"+repo.TypeName).
+		SetComment("...creates the configured directory, if required, and returns a repository of its documents.").
+		AddParams(ast.NewParam("cfg", ast.NewSimpleTypeDecl(ast.Name(file.Pkg().Path+"."+cfg.TypeName)))).
+		AddResults(
+			ast.NewParam("", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(file.Pkg().Path+"."+repo.TypeName)))),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		).
+		SetBody(ast.NewBlock(ast.NewTpl(`if err := {{.Use "os.MkdirAll"}}(cfg.` + dirField + `, 0755); err != nil {
+				return nil, {{.Use "fmt.Errorf"}}("cannot create directory: %w", err)
+			}
+
+			return &` + repo.TypeName + `{dir: cfg.` + dirField + `}, nil
+		`)))
+
+	repo.AddFactoryRefs(constructor)
+	file.AddNodes(constructor)
+
+	entity := `{{.Use "` + c.entityType.String() + `"}}`
+	queryName, _, _ := crudQueryName(c.entityType)
+
+	repo.AddMethods(
+		ast.NewFunc("path").
+			SetComment("...returns the file name of the entity. The identity is hex encoded, so that it is a valid and\nunique file name on any file system.").
+			SetVisibility(ast.Private).
+			SetRecName(repo.DefaultRecName).
+			SetPtrReceiver(true).
+			AddParams(ast.NewParam("id", c.keyType.Clone())).
+			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.String))).
+			SetBody(ast.NewBlock(ast.NewTpl(`return {{.Use "path/filepath.Join"}}(r.dir, {{.Use "encoding/hex.EncodeToString"}}([]byte({{.Use "fmt.Sprint"}}(id)))+".json")
+			`))),
+
+		ast.NewFunc("write").
+			SetComment("...replaces the document of the entity atomically. The caller must hold the write lock.").
+			SetVisibility(ast.Private).
+			SetRecName(repo.DefaultRecName).
+			SetPtrReceiver(true).
+			AddParams(ast.NewParam("entity", c.entityType.Clone())).
+			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+			SetBody(ast.NewBlock(ast.NewTpl(`buf, err := {{.Use "encoding/json.Marshal"}}(entity)
+				if err != nil {
+					return {{.Use "fmt.Errorf"}}("cannot encode entity: %w", err)
+				}
+
+				tmp, err := {{.Use "os.CreateTemp"}}(r.dir, "*.tmp")
+				if err != nil {
+					return fmt.Errorf("cannot create temporary file: %w", err)
+				}
+
+				defer os.Remove(tmp.Name()) // intentionally ignoring the error, the file has been renamed usually
+
+				if _, err := tmp.Write(buf); err != nil {
+					_ = tmp.Close()
+					return fmt.Errorf("cannot write temporary file: %w", err)
+				}
+
+				if err := tmp.Sync(); err != nil {
+					_ = tmp.Close()
+					return fmt.Errorf("cannot sync temporary file: %w", err)
+				}
+
+				if err := tmp.Close(); err != nil {
+					return fmt.Errorf("cannot close temporary file: %w", err)
+				}
+
+				if err := os.Rename(tmp.Name(), r.path(entity`+c.selector+`)); err != nil {
+					return fmt.Errorf("cannot replace document: %w", err)
+				}
+
+				return nil
+			`))),
+
+		ast.NewFunc("exists").
+			SetComment("...reports whether a document of the entity exists.").
+			SetVisibility(ast.Private).
+			SetRecName(repo.DefaultRecName).
+			SetPtrReceiver(true).
+			AddParams(ast.NewParam("id", c.keyType.Clone())).
+			AddResults(
+				ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Bool)),
+				ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+			).
+			SetBody(ast.NewBlock(ast.NewTpl(`_, err := {{.Use "os.Stat"}}(r.path(id))
+				if err == nil {
+					return true, nil
+				}
+
+				if os.IsNotExist(err) {
+					return false, nil
+				}
+
+				return false, {{.Use "fmt.Errorf"}}("cannot stat document: %w", err)
+			`))),
+
+		ast.NewFunc("readAll").
+			SetComment("...decodes the documents of all entities in an unspecified order. The caller must hold the read lock.").
+			SetVisibility(ast.Private).
+			SetRecName(repo.DefaultRecName).
+			SetPtrReceiver(true).
+			AddResults(
+				ast.NewParam("", ast.NewSliceTypeDecl(c.entityType.Clone())),
+				ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+			).
+			SetBody(ast.NewBlock(ast.NewTpl(`files, err := {{.Use "os.ReadDir"}}(r.dir)
+				if err != nil {
+					return nil, {{.Use "fmt.Errorf"}}("cannot read directory: %w", err)
+				}
+
+				res := make([]`+entity+`, 0, len(files))
+				for _, file := range files {
+					if file.IsDir() || !{{.Use "strings.HasSuffix"}}(file.Name(), ".json") {
+						continue
+					}
+
+					buf, err := os.ReadFile({{.Use "path/filepath.Join"}}(r.dir, file.Name()))
+					if err != nil {
+						return nil, fmt.Errorf("cannot read document: %w", err)
+					}
+
+					var entity `+entity+`
+					if err := {{.Use "encoding/json.Unmarshal"}}(buf, &entity); err != nil {
+						return nil, fmt.Errorf("cannot decode document '%s': %w", file.Name(), err)
+					}
+
+					res = append(res, entity)
+				}
+
+				return res, nil
+			`))),
+	)
+
+	for _, method := range c.methods() {
+		var body *ast.Block
+		switch method.FunName {
+		case "InsertOne", "UpdateOne":
+			cond, errCase := "exists", c.alreadyExists
+			if method.FunName == "UpdateOne" {
+				cond, errCase = "!exists", c.notFound
+			}
+
+			body = ast.NewBlock(
+				ast.NewTpl(`r.mutex.Lock()
+					defer r.mutex.Unlock()
+
+					id := entity`+c.selector+`
+					exists, err := r.exists(id)
+					if err != nil {
+						return err
+					}
+
+					if `+cond+` {
+				`),
+				ast.NewReturnStmt(errCase.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					return r.write(entity)
+				`),
+			)
+		case "FindOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`r.mutex.RLock()
+					defer r.mutex.RUnlock()
+
+					var entity `+entity+`
+					buf, err := {{.Use "os.ReadFile"}}(r.path(id))
+					if os.IsNotExist(err) {
+				`),
+				ast.NewReturnStmt(ast.NewIdent("entity"), c.notFound.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					if err != nil {
+						return entity, {{.Use "fmt.Errorf"}}("cannot read document: %w", err)
+					}
+
+					if err := {{.Use "encoding/json.Unmarshal"}}(buf, &entity); err != nil {
+						return entity, fmt.Errorf("cannot decode document: %w", err)
+					}
+
+					return entity, nil
+				`),
+			)
+		case "DeleteOne":
+			body = ast.NewBlock(ast.NewTpl(`r.mutex.Lock()
+				defer r.mutex.Unlock()
+
+				if err := {{.Use "os.Remove"}}(r.path(id)); err != nil && !os.IsNotExist(err) {
+					return {{.Use "fmt.Errorf"}}("cannot delete document: %w", err)
+				}
+
+				return nil
+			`))
+		case "CountAll":
+			body = ast.NewBlock(ast.NewTpl(`r.mutex.RLock()
+				defer r.mutex.RUnlock()
+
+				files, err := {{.Use "os.ReadDir"}}(r.dir)
+				if err != nil {
+					return 0, {{.Use "fmt.Errorf"}}("cannot read directory: %w", err)
+				}
+
+				var count int64
+				for _, file := range files {
+					if !file.IsDir() && {{.Use "strings.HasSuffix"}}(file.Name(), ".json") {
+						count++
+					}
+				}
+
+				return count, nil
+			`))
+		case "FindAll":
+			body = ast.NewBlock(ast.NewTpl(`if err := query.Validate(); err != nil {
+					return nil, err
+				}
+
+				r.mutex.RLock()
+				all, err := r.readAll()
+				r.mutex.RUnlock()
+
+				if err != nil {
+					return nil, err
+				}
+
+				res := all[:0]
+				for _, entity := range all {
+					if query.After == nil || query.Less(*query.After, entity) {
+						res = append(res, entity)
+					}
+				}
+
+				` + crudPageSource))
+		case "IterateAll":
+			// the visitor is invoked on a snapshot without holding the lock, so that it may modify the repository
+			body = ast.NewBlock(ast.NewTpl(`r.mutex.RLock()
+				snapshot, err := r.readAll()
+				r.mutex.RUnlock()
+
+				if err != nil {
+					return err
+				}
+
+				order := ` + queryName + `{}
+				{{.Use "sort.Slice"}}(snapshot, func(i, j int) bool {
+					return order.Less(snapshot[i], snapshot[j])
+				})
+
+				for _, entity := range snapshot {
+					if !visitor(entity) {
+						break
+					}
+				}
+
+				return nil
+			`))
+		default:
+			return fmt.Errorf("unsupported crud operation '%s'", method.FunName)
+		}
+
+		repo.AddMethods(method.SetRecName(repo.DefaultRecName).SetPtrReceiver(true).SetBody(body))
+	}
+
+	return nil
+}
+
+// crudFileDirField returns the name of the directory field of the configuration of the file based repository.
+func crudFileDirField(repo *ast.Struct) string {
+	return strings.TrimPrefix(repo.TypeName, crudRepoPrefix(adl.PFile)) + "Dir"
+}
+
+// renderCrudFileConfig declares the configuration of the file based repository and returns it together with the
+// name of its directory field. The field name contains the repository name, because the environment variables
+// and program flags of all configurations of a layer share the same namespace.
+func renderCrudFileConfig(file *ast.File, repo *ast.Struct) (*ast.Struct, string) {
+	name := strings.TrimPrefix(repo.TypeName, crudRepoPrefix(adl.PFile))
+	dirField := crudFileDirField(repo)
+
+	// the default directory follows the package, so that the repositories of bounded contexts do not collide
+	defaultDir := "data/" + strings.ToLower(name)
+	const marker = "internal/"
+	if pos := strings.Index(file.Pkg().Path, marker); pos >= 0 {
+		defaultDir = "data/" + file.Pkg().Path[pos+len(marker):] + "/" + strings.ToLower(name)
+	}
+
+	cfg := ast.NewStruct(repo.TypeName + "Config").
+		SetComment("...configures the " + repo.TypeName + " repository.").
+		SetDefaultRecName("c").
+		AddFields(
+			ast.NewField(dirField, ast.NewSimpleTypeDecl(stdlib.String)).
+				SetComment("...is the directory, which contains the json documents of the entities.").
+				SetDefault(ast.NewStrLit(defaultDir)),
+		)
+
+	cfg.AddMethods(ast.NewFunc("Validate").
+		SetComment("...returns an error, if the directory is empty.").
+		SetRecName(cfg.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+		SetBody(ast.NewBlock(ast.NewTpl(`if c.` + dirField + ` == "" {
+				return {{.Use "fmt.Errorf"}}("` + dirField + ` must not be empty")
+			}
+
+			return nil
+		`))))
+
+	// the configuration is aggregated by the configuration holder of the layer
+	stereotype.StructFrom(cfg).SetIsConfiguration(true)
+	file.AddTypes(cfg)
+
+	return cfg, dirField
+}
@@ -0,0 +1,38 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderFileCRUD(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+                field Title string! "...is the title."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket file { all }
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                inject tickets $BC/core.Tickets "...are the tickets."
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/repositories.go",
+		"func (r *FileTickets) FindAll(query TicketQuery) ([]Ticket, error) {",
+		"TicketsDir string",
+	)
+	assertContains(t, files, "internal/tickets/core/coretest/repositories_test.go",
+		"repo, err := core.NewFileTickets(core.FileTicketsConfig{TicketsDir: t.TempDir()})",
+	)
+	assertContains(t, files, "internal/application/demoserver/application.go",
+		"return d.cfg.Tickets.Core.FileTicketsConfig, nil",
+		"s, err := core.NewFileTickets(cfg)",
+	)
+	buildFiles(t, files)
+}
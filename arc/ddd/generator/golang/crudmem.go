@@ -5,135 +5,194 @@ import (
 	"github.com/golangee/architecture/arc/adl"
 	"github.com/golangee/architecture/arc/generator/astutil"
 	"github.com/golangee/src/ast"
-	"github.com/golangee/src/stdlib"
+	"github.com/golangee/src/stdlib/lang"
 )
 
-func renderCrud(file *ast.File, iface *ast.Interface, crud *adl.CRUD) (*ast.Struct, error) {
-	entityType := astutil.MakeTypeDecl(crud.EntityType)
-	resolvedEntityType := astutil.Resolve(file, entityType.String())
-	if resolvedEntityType == nil {
-		return nil, fmt.Errorf("crud entity type cannot be resolved: " + entityType.String())
+// renderCrud declares the CRUD operations at the repository interface and renders the implementation of the
+// configured persistence, including a compile-time assertion, that it satisfies the interface.
+func renderCrud(file *ast.File, iface *ast.Interface, crud *adl.CRUD, sumType *lang.Error) (*ast.Struct, error) {
+	contract, err := newCrudContract(file, crud, sumType)
+	if err != nil {
+		return nil, err
 	}
 
-	repo := ast.NewStruct("InMemory" + iface.TypeName)
+	iface.AddMethods(contract.methods()...)
+
+	// the prefix keeps the implementations of different persistences apart
+	repo := ast.NewStruct(crudRepoPrefix(crud.Persistence) + iface.TypeName)
 	repo.SetDefaultRecName("r")
+	repo.Implements = append(repo.Implements, ast.Name(astutil.FullQualifiedName(iface)))
 	file.AddTypes(repo)
 
 	switch crud.Persistence {
 	case adl.PMemory:
-		return repo, renderCrudMem(file, iface, crud, entityType, repo)
+		if err := renderCrudMem(file, contract, repo); err != nil {
+			return nil, err
+		}
+	case adl.PFile:
+		if err := renderCrudFile(file, contract, repo); err != nil {
+			return nil, err
+		}
+	case adl.PMySQL:
+		if err := renderCrudMySQL(file, contract, repo); err != nil {
+			return nil, err
+		}
+	default:
+		panic("unknown persistence " + crud.Persistence)
 	}
 
-	panic("unknown persistence " + crud.Persistence)
-}
+	if err := renderCrudContract(file, iface, contract, repo); err != nil {
+		return nil, err
+	}
 
-func renderCrudMem(file *ast.File, iface *ast.Interface, crud *adl.CRUD, entityType ast.TypeDecl, repo *ast.Struct) error {
-	repo.SetComment("...implements a hashmap based in-memory implementation for " + ast.Name(entityType.String()).Identifier() + " entities.")
-	var keyType ast.TypeDecl
-	if crud.IDType != nil {
-		keyType = astutil.MakeTypeDecl(crud.IDType)
-	} else {
-		resolvedEntityType := astutil.Resolve(file, entityType.String())
-
-		id := astutil.FieldByName(resolvedEntityType, "ID")
-		if id == nil {
-			return fmt.Errorf("crud entity type has neither custom ID type nor an ID field")
-		}
+	file.AddNodes(ast.NewTpl("// " + repo.TypeName + " must implement " + iface.TypeName + ".\nvar _ " + iface.TypeName + " = (*" + repo.TypeName + ")(nil)\n"))
+
+	return repo, nil
+}
 
-		keyType = id.TypeDecl().Clone()
+// crudRepoPrefix returns the type name prefix of the repository implementation of the persistence.
+func crudRepoPrefix(persistence adl.PersistenceType) string {
+	switch persistence {
+	case adl.PMemory:
+		return "InMemory"
+	case adl.PFile:
+		return "File"
+	case adl.PMySQL:
+		return "MySQL"
+	default:
+		return ""
 	}
+}
 
+func renderCrudMem(file *ast.File, c *crudContract, repo *ast.Struct) error {
+	repo.SetComment("...implements a hashmap based in-memory implementation for " + ast.Name(c.entityType.String()).Identifier() + " entities.")
 	repo.AddFields(
-		ast.NewField("store", ast.NewMapDecl(keyType, entityType)).SetVisibility(ast.Private),
+		ast.NewField("store", ast.NewMapDecl(c.keyType, c.entityType)).SetVisibility(ast.Private),
 		ast.NewField("mutex", ast.NewSimpleTypeDecl("sync.RWMutex")).SetVisibility(ast.Private),
 	)
 
-	if crud.InsertOne {
-		repo.AddMethods(
-			ast.NewFunc("InsertOne").
-				SetComment("...inserts the entity or fails if already exists.").
-				AddParams(ast.NewParam("entity", entityType.Clone())).
-				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
-				SetRecName(repo.DefaultRecName).
-				SetBody(
-					ast.NewBlock(ast.NewTpl(`r.mutex.Lock()
-						defer r.mutex.Unlock()
-					
-						if _, ok := r.store[entity.ID]; ok {
-							return {{.Use "io/fs.ErrExist"}}
-						}
-					
-						r.store[entity.ID] = entity
-					
-						return nil
-					`)),
-				),
-		)
-	}
+	constructor := ast.NewFunc("New" + repo.TypeName).
+		SetComment("...allocates an empty " + repo.TypeName + ".").
+		AddResults(ast.NewParam("", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(file.Pkg().Path+"."+repo.TypeName))))).
+		SetBody(ast.NewBlock(
+			ast.NewTpl(repo.DefaultRecName+" := &"+repo.TypeName+"{}\n"),
+			ast.NewAssign(ast.Exprs(ast.NewSelExpr(ast.NewIdent(repo.DefaultRecName), ast.NewIdent("store"))), ast.AssignSimple, ast.Exprs(ast.NewCallExpr(ast.NewIdent("make"), ast.NewMapDecl(c.keyType.Clone(), c.entityType.Clone())))),
+			lang.Term(),
+			lang.Term(),
+			ast.NewReturnStmt(ast.NewIdent(repo.DefaultRecName)),
+		))
 
-	if crud.UpdateOne {
-		repo.AddMethods(
-			ast.NewFunc("UpdateOne").
-				SetComment("...updates the entity or fails if does not exist.").
-				AddParams(ast.NewParam("entity", entityType.Clone())).
-				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
-				SetRecName(repo.DefaultRecName).
-				SetBody(
-					ast.NewBlock(ast.NewTpl(`r.mutex.Lock()
-						defer r.mutex.Unlock()
-					
-						if _, ok := r.store[entity.ID]; !ok {
-							return {{.Use "io/fs.ErrNotExist"}}
-						}
-					
-						r.store[entity.ID] = entity
-					
-						return nil
-					`)),
-				),
-		)
-	}
+	repo.AddFactoryRefs(constructor)
+	file.AddNodes(constructor)
 
-	if crud.FindOne {
-		repo.AddMethods(
-			ast.NewFunc("FindOne").
-				SetComment("...finds the entity or fails if does not exist.").
-				AddParams(ast.NewParam("id", keyType.Clone())).
-				AddResults(ast.NewParam("", entityType.Clone())).
-				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
-				SetRecName(repo.DefaultRecName).
-				SetBody(
-					ast.NewBlock(ast.NewTpl(`r.mutex.RLock()
-						defer r.mutex.RUnlock()
-					
-						v, ok := r.store[id];
-						if !ok {
-							return v, {{.Use "io/fs.ErrNotExist"}}
-						}
-					
-						return v, nil
-					`)),
-				),
-		)
-	}
+	entity := `{{.Use "` + c.entityType.String() + `"}}`
+	queryName, _, _ := crudQueryName(c.entityType)
+	for _, method := range c.methods() {
+		var body *ast.Block
+		switch method.FunName {
+		case "InsertOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`r.mutex.Lock()
+					defer r.mutex.Unlock()
+
+					id := entity`+c.selector+`
+					if _, ok := r.store[id]; ok {
+				`),
+				ast.NewReturnStmt(c.alreadyExists.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					r.store[id] = entity
+
+					return nil
+				`),
+			)
+		case "UpdateOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`r.mutex.Lock()
+					defer r.mutex.Unlock()
+
+					id := entity`+c.selector+`
+					if _, ok := r.store[id]; !ok {
+				`),
+				ast.NewReturnStmt(c.notFound.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					r.store[id] = entity
+
+					return nil
+				`),
+			)
+		case "FindOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`r.mutex.RLock()
+					defer r.mutex.RUnlock()
+
+					v, ok := r.store[id]
+					if !ok {
+				`),
+				ast.NewReturnStmt(ast.NewIdent("v"), c.notFound.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					return v, nil
+				`),
+			)
+		case "DeleteOne":
+			body = ast.NewBlock(ast.NewTpl(`r.mutex.Lock()
+				defer r.mutex.Unlock()
+
+				delete(r.store, id)
+
+				return nil
+			`))
+		case "CountAll":
+			body = ast.NewBlock(ast.NewTpl(`r.mutex.RLock()
+				defer r.mutex.RUnlock()
+
+				return int64(len(r.store)), nil
+			`))
+		case "FindAll":
+			body = ast.NewBlock(ast.NewTpl(`if err := query.Validate(); err != nil {
+					return nil, err
+				}
+
+				r.mutex.RLock()
+				defer r.mutex.RUnlock()
+
+				res := make([]` + entity + `, 0, len(r.store))
+				for _, entity := range r.store {
+					if query.After == nil || query.Less(*query.After, entity) {
+						res = append(res, entity)
+					}
+				}
+
+				` + crudPageSource))
+		case "IterateAll":
+			// the visitor is invoked on a snapshot without holding the lock, so that it may modify the repository
+			body = ast.NewBlock(ast.NewTpl(`r.mutex.RLock()
+				snapshot := make([]` + entity + `, 0, len(r.store))
+				for _, entity := range r.store {
+					snapshot = append(snapshot, entity)
+				}
+				r.mutex.RUnlock()
+
+				order := ` + queryName + `{}
+				{{.Use "sort.Slice"}}(snapshot, func(i, j int) bool {
+					return order.Less(snapshot[i], snapshot[j])
+				})
+
+				for _, entity := range snapshot {
+					if !visitor(entity) {
+						break
+					}
+				}
+
+				return nil
+			`))
+		default:
+			return fmt.Errorf("unsupported crud operation '%s'", method.FunName)
+		}
 
-	if crud.DeleteOne {
-		repo.AddMethods(
-			ast.NewFunc("DeleteOne").
-				SetComment("...deletes the entity with the given id. Is a no-op if no such entity exists.").
-				AddParams(ast.NewParam("id", keyType.Clone())).
-				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
-				SetRecName(repo.DefaultRecName).
-				SetBody(
-					ast.NewBlock(ast.NewTpl(`r.mutex.Lock()
-						defer r.mutex.Unlock()
-
-						delete(r.store,id)
-					
-						return nil
-					`)),
-				),
-		)
+		repo.AddMethods(method.SetRecName(repo.DefaultRecName).SetPtrReceiver(true).SetBody(body))
 	}
 
 	return nil
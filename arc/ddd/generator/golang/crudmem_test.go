@@ -0,0 +1,29 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderMemoryCRUD(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+                field Title string! "...is the title."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket in-memory { all }
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/repositories.go",
+		"FindAll(query TicketQuery) ([]Ticket, error)",
+		"IterateAll(visitor TicketVisitor) error",
+		"func (r *InMemoryTickets) FindAll(query TicketQuery) ([]Ticket, error) {",
+		"func (r *InMemoryTickets) CountAll() (int64, error) {",
+	)
+
+	// the generated contract tests verify the paging of the in-memory repository
+	buildFiles(t, files)
+}
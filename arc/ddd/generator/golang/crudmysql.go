@@ -0,0 +1,518 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/architecture/arc/sql"
+	sqlgen "github.com/golangee/architecture/arc/sql/generator/golang"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mysqlDriver is the module and the package of the mysql driver.
+const mysqlDriver = "github.com/go-sql-driver/mysql"
+
+// mysqlDuplicateEntry is the number of the mysql error, which reports a duplicate key.
+const mysqlDuplicateEntry = 1062
+
+// sqlColumn maps a field of an entity to a column of its table.
+type sqlColumn struct {
+	name     string
+	field    string
+	sqlType  string
+	sortable bool // true, if the entities can be ordered by the field, see renderCrudQuery.
+}
+
+// sqlMapping maps a CRUD entity to a table.
+type sqlMapping struct {
+	table   string
+	key     sqlColumn
+	columns []sqlColumn // all mapped fields, including the key.
+}
+
+// newSQLMapping derives the table of the CRUD entity. Explicit table and column names of the sql stereotypes
+// are kept, otherwise they are derived from the package, the entity and the field names and declared as
+// stereotypes, so that the mapping is stable for all later generator stages. Fields without a natural column type
+// are not mapped.
+func newSQLMapping(file *ast.File, crud *adl.CRUD) (*sqlMapping, error) {
+	entityType := astutil.MakeTypeDecl(crud.EntityType)
+	entity, ok := astutil.Resolve(file, entityType.String()).(*ast.Struct)
+	if !ok {
+		return nil, fmt.Errorf("mysql persistence requires a struct entity: %s", entityType.String())
+	}
+
+	_, selector, err := crudIdentity(file, crud, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	if selector != ".ID" {
+		return nil, fmt.Errorf("mysql persistence requires the identity in the ID field of '%s'", entity.TypeName)
+	}
+
+	m := &sqlMapping{}
+	if table, ok := stereotype.StructFrom(entity).SQLTableName(); ok {
+		m.table = table
+	} else {
+		m.table = snakeCase(entity.TypeName)
+		const marker = "internal/"
+		if pos := strings.Index(astutil.Pkg(entity).Path, marker); pos >= 0 {
+			m.table = strings.ReplaceAll(astutil.Pkg(entity).Path[pos+len(marker):], "/", "_") + "_" + m.table
+		}
+
+		stereotype.StructFrom(entity).SetSQLTableName(m.table)
+	}
+
+	for _, field := range entity.Fields() {
+		if field.Visibility() != ast.Public {
+			continue
+		}
+
+		sqlType, ok := sqlColumnType(field.TypeDecl(), field.FieldName == "ID")
+		if !ok {
+			if field.FieldName == "ID" {
+				return nil, fmt.Errorf("the ID field of '%s' has no sql column type: %s", entity.TypeName, field.TypeDecl().String())
+			}
+
+			continue
+		}
+
+		name, ok := stereotype.FieldFrom(field).SQLColumnName()
+		if !ok {
+			name = snakeCase(field.FieldName)
+			stereotype.FieldFrom(field).SetSQLColumnName(name)
+		}
+
+		_, sortable := lessSource(field.TypeDecl(), "a", "b")
+		col := sqlColumn{name: name, field: field.FieldName, sqlType: sqlType, sortable: sortable}
+		if field.FieldName == "ID" {
+			m.key = col
+		}
+
+		m.columns = append(m.columns, col)
+	}
+
+	return m, nil
+}
+
+// sqlColumnType returns the MySQL column type of the go type. Strings of keys are limited, because a
+// primary key cannot be unbounded. Strings are compared by their bytes, like in go.
+func sqlColumnType(typ ast.TypeDecl, key bool) (string, bool) {
+	const binary = " COLLATE utf8mb4_bin"
+	switch typ.String() {
+	case stdlib.String:
+		if key {
+			return "VARCHAR(255)" + binary, true
+		}
+
+		return "TEXT" + binary, true
+	case stdlib.Bool:
+		return "BOOLEAN", true
+	case stdlib.Int, stdlib.Int64, stdlib.Duration:
+		return "BIGINT", true
+	case stdlib.Int32, stdlib.Rune:
+		return "INT", true
+	case stdlib.Int16:
+		return "SMALLINT", true
+	case stdlib.Byte:
+		return "TINYINT UNSIGNED", true
+	case stdlib.Float32:
+		return "FLOAT", true
+	case stdlib.Float64:
+		return "DOUBLE", true
+	case stdlib.Time:
+		return "DATETIME(6)", true
+	case stdlib.UUID:
+		return "CHAR(36)", true
+	default:
+		return "", false
+	}
+}
+
+// createTable returns the statement, which creates the table, if it does not exist.
+func (m *sqlMapping) createTable() string {
+	var cols []string
+	for _, col := range m.columns {
+		cols = append(cols, `"`+col.name+`" `+col.sqlType+` NOT NULL`)
+	}
+
+	return `CREATE TABLE IF NOT EXISTS "` + m.table + `" (` + strings.Join(cols, ", ") + `, PRIMARY KEY ("` + m.key.name + `"))`
+}
+
+// selectors returns the selectors of all mapped fields relative to the prefix.
+func (m *sqlMapping) selectors(prefix string) []token.String {
+	var res []token.String
+	for _, col := range m.columns {
+		res = append(res, token.NewString(prefix+"."+col.field))
+	}
+
+	return res
+}
+
+// names returns the quoted column names and a placeholder for each column.
+func (m *sqlMapping) names() (string, string) {
+	var names, placeholders []string
+	for _, col := range m.columns {
+		names = append(names, `"`+col.name+`"`)
+		placeholders = append(placeholders, "?")
+	}
+
+	return strings.Join(names, ", "), strings.Join(placeholders, ", ")
+}
+
+// findAllSource returns the template of FindAll, which selects the page of the query by its order, limit and
+// either its offset or the keyset of its last entity. Unknown orders sort by the identity, like Less.
+func (m *sqlMapping) findAllSource(entityType ast.TypeDecl) string {
+	entityName := ast.Name(entityType.String()).Identifier()
+	key := strconv.Quote(`"` + m.key.name + `"`)
+	names, _ := m.names()
+
+	var targets []string
+	cases := &strings.Builder{}
+	for _, col := range m.columns {
+		targets = append(targets, "&entity."+col.field)
+		if !col.sortable || col.field == m.key.field {
+			continue
+		}
+
+		cases.WriteString("case " + entityName + "By" + col.field + ":\n")
+		cases.WriteString("col = " + strconv.Quote(`"`+col.name+`"`) + "\n")
+		cases.WriteString("if query.After != nil {\nafter = query.After." + col.field + "\n}\n")
+	}
+
+	return `if err := query.Validate(); err != nil {
+			return nil, err
+		}
+
+		var col string
+		var after interface{}
+		switch query.OrderBy {
+		` + cases.String() + `}
+
+		cmp, dir := " > ", " ASC"
+		if query.Descending {
+			cmp, dir = " < ", " DESC"
+		}
+
+		q := ` + strconv.Quote("SELECT "+names+` FROM "`+m.table+`"`) + `
+		var args []interface{}
+		if query.After != nil {
+			if col == "" {
+				q += ` + strconv.Quote(` WHERE "`+m.key.name+`"`) + ` + cmp + "?"
+			} else {
+				q += " WHERE (" + col + cmp + "? OR (" + col + ` + strconv.Quote(` = ? AND "`+m.key.name+`"`) + ` + cmp + "?))"
+				args = append(args, after, after)
+			}
+
+			args = append(args, query.After.` + m.key.field + `)
+		}
+
+		orderBy := ` + key + ` + dir
+		if col != "" {
+			orderBy = col + dir + ", " + orderBy
+		}
+
+		q += " ORDER BY " + orderBy
+		offset := query.After == nil && query.Offset > 0
+		switch {
+		case query.Limit > 0:
+			q += " LIMIT ?"
+			args = append(args, query.Limit)
+		case offset:
+			// mysql has no offset without a limit
+			q += " LIMIT 18446744073709551615"
+		}
+
+		if offset {
+			q += " OFFSET ?"
+			args = append(args, query.Offset)
+		}
+
+		rows, err := r.db.QueryContext({{.Use "context.Background"}}(), q, args...)
+		if err != nil {
+			return nil, {{.Use "fmt.Errorf"}}("cannot query '%s': %w", q, err)
+		}
+
+		defer rows.Close()
+
+		var res []{{.Use "` + entityType.String() + `"}}
+		for rows.Next() {
+			var entity {{.Use "` + entityType.String() + `"}}
+			if err := rows.Scan(` + strings.Join(targets, ", ") + `); err != nil {
+				return nil, fmt.Errorf("scan of '%s' failed: %w", q, err)
+			}
+
+			res = append(res, entity)
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("query of '%s' failed: %w", q, err)
+		}
+
+		return res, nil
+	`
+}
+
+// methods returns the queries and bindings of the statements interface.
+func (m *sqlMapping) methods() []sql.Method {
+	names, placeholders := m.names()
+	var assignments []string
+	for _, col := range m.columns {
+		assignments = append(assignments, `"`+col.name+`" = ?`)
+	}
+
+	table := `"` + m.table + `"`
+	where := ` WHERE "` + m.key.name + `" = ?`
+	id := []token.String{token.NewString("id")}
+
+	return []sql.Method{
+		{
+			Name:    token.NewString("Insert"),
+			Query:   token.NewString("INSERT INTO " + table + " (" + names + ") VALUES (" + placeholders + ")"),
+			Mapping: sql.ExecOne{In: m.selectors("entity")},
+		},
+		{
+			Name:    token.NewString("Update"),
+			Query:   token.NewString("UPDATE " + table + " SET " + strings.Join(assignments, ", ") + where),
+			Mapping: sql.ExecOne{In: append(m.selectors("entity"), token.NewString("entity.ID"))},
+		},
+		{
+			Name:    token.NewString("Delete"),
+			Query:   token.NewString("DELETE FROM " + table + where),
+			Mapping: sql.ExecOne{In: id},
+		},
+		{
+			Name:    token.NewString("CountByID"),
+			Query:   token.NewString("SELECT COUNT(*) FROM " + table + where),
+			Mapping: sql.QueryOne{In: id, Out: []token.String{token.NewString(".")}},
+		},
+		{
+			Name:    token.NewString("Select"),
+			Query:   token.NewString("SELECT " + names + " FROM " + table + where),
+			Mapping: sql.QueryMany{In: id, Out: m.selectors("")},
+		},
+		{
+			Name:    token.NewString("SelectAll"),
+			Query:   token.NewString("SELECT " + names + " FROM " + table + ` ORDER BY "` + m.key.name + `"`),
+			Mapping: sql.QueryMany{Out: m.selectors("")},
+		},
+		{
+			Name:    token.NewString("CountAll"),
+			Query:   token.NewString("SELECT COUNT(*) FROM " + table),
+			Mapping: sql.QueryOne{Out: []token.String{token.NewString(".")}},
+		},
+	}
+}
+
+// crudStatementsName returns the name of the interface, which declares the sql statements of the repository.
+func crudStatementsName(iface string) string {
+	return iface + "Statements"
+}
+
+// renderCrudMySQL renders a repository, which delegates to the sql statements of the entity table. The statements
+// are implemented by the sql generator, see renderSQL. Pages depend on the query and are selected by a dynamic
+// statement, which sorts like Less. A duplicate primary key is reported as the already exists error.
+func renderCrudMySQL(file *ast.File, c *crudContract, repo *ast.Struct) error {
+	m, err := newSQLMapping(file, c.crud)
+	if err != nil {
+		return err
+	}
+
+	pkgPath := file.Pkg().Path
+	entityName := ast.Name(c.entityType.String()).Identifier()
+	stmtsName := crudStatementsName(strings.TrimPrefix(repo.TypeName, crudRepoPrefix(adl.PMySQL)))
+	entitySlice := ast.NewSliceTypeDecl(c.entityType.Clone())
+
+	stmts := ast.NewInterface(stmtsName).
+		SetComment("...declares the sql statements of the table '"+m.table+"', which are used by "+repo.TypeName+".").
+		AddMethods(
+			ast.NewFunc("Insert").
+				SetComment("...inserts a row.").
+				AddParams(ast.NewParam("entity", c.entityType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+			ast.NewFunc("Update").
+				SetComment("...replaces all columns of the row with the same identity.").
+				AddParams(ast.NewParam("entity", c.entityType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+			ast.NewFunc("Delete").
+				SetComment("...deletes the row with the given identity.").
+				AddParams(ast.NewParam("id", c.keyType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+			ast.NewFunc("CountByID").
+				SetComment("...returns 1, if a row with the given identity exists, otherwise 0.").
+				AddParams(ast.NewParam("id", c.keyType.Clone())).
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Int64)), ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+			ast.NewFunc("Select").
+				SetComment("...returns the row with the given identity or no row at all.").
+				AddParams(ast.NewParam("id", c.keyType.Clone())).
+				AddResults(ast.NewParam("", entitySlice.Clone()), ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+			ast.NewFunc("SelectAll").
+				SetComment("...returns all rows in the order of their identity.").
+				AddResults(ast.NewParam("", entitySlice.Clone()), ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+			ast.NewFunc("CountAll").
+				SetComment("...returns the amount of all rows.").
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Int64)), ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))),
+		)
+
+	file.AddTypes(stmts)
+
+	repo.SetComment("...implements a MySQL based repository for " + entityName + " entities, which are stored in the table\n'" +
+		m.table + "'. Only the fields with a column are persisted. The table is created by the migrations of the package.")
+	repo.AddFields(
+		ast.NewField("db", ast.NewSimpleTypeDecl(ast.Name(pkgPath+".DBTX"))).SetVisibility(ast.Private),
+		ast.NewField("stmts", ast.NewSimpleTypeDecl(ast.Name(pkgPath+"."+stmtsName))).SetVisibility(ast.Private),
+	)
+
+	constructor := ast.NewFunc("New" + repo.TypeName).
+		SetComment("...returns a repository, which uses the given database connection or transaction.").
+		AddParams(ast.NewParam("db", ast.NewSimpleTypeDecl(ast.Name(pkgPath+".DBTX")))).
+		AddResults(ast.NewParam("", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(pkgPath+"."+repo.TypeName))))).
+		SetBody(ast.NewBlock(ast.NewTpl("return &" + repo.TypeName + "{db: db, stmts: New" + sqlgen.ImplTypeName(sql.MySQL, stmtsName) + "(db)}\n")))
+
+	repo.AddFactoryRefs(constructor)
+	file.AddNodes(constructor)
+
+	entity := `{{.Use "` + c.entityType.String() + `"}}`
+	for _, method := range c.methods() {
+		var body *ast.Block
+		switch method.FunName {
+		case "InsertOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`err := r.stmts.Insert(entity)
+					var mysqlErr *{{.Use "`+mysqlDriver+`.MySQLError"}}
+					if {{.Use "errors.As"}}(err, &mysqlErr) && mysqlErr.Number == `+strconv.Itoa(mysqlDuplicateEntry)+` {
+				`),
+				ast.NewReturnStmt(c.alreadyExists.Make(ast.NewIdent("entity"+c.selector))),
+				ast.NewTpl(`}
+
+					return err
+				`),
+			)
+		case "UpdateOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`id := entity`+c.selector+`
+					n, err := r.stmts.CountByID(id)
+					if err != nil {
+						return err
+					}
+
+					if n == 0 {
+				`),
+				ast.NewReturnStmt(c.notFound.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					return r.stmts.Update(entity)
+				`),
+			)
+		case "FindOne":
+			body = ast.NewBlock(
+				ast.NewTpl(`var entity `+entity+`
+					res, err := r.stmts.Select(id)
+					if err != nil {
+						return entity, err
+					}
+
+					if len(res) == 0 {
+				`),
+				ast.NewReturnStmt(ast.NewIdent("entity"), c.notFound.Make(ast.NewIdent("id"))),
+				ast.NewTpl(`}
+
+					return res[0], nil
+				`),
+			)
+		case "DeleteOne":
+			body = ast.NewBlock(ast.NewTpl("return r.stmts.Delete(id)\n"))
+		case "CountAll":
+			body = ast.NewBlock(ast.NewTpl("return r.stmts.CountAll()\n"))
+		case "FindAll":
+			body = ast.NewBlock(ast.NewTpl(m.findAllSource(c.entityType)))
+		case "IterateAll":
+			body = ast.NewBlock(ast.NewTpl(`snapshot, err := r.stmts.SelectAll()
+				if err != nil {
+					return err
+				}
+
+				for _, entity := range snapshot {
+					if !visitor(entity) {
+						break
+					}
+				}
+
+				return nil
+			`))
+		default:
+			return fmt.Errorf("unsupported crud operation '%s'", method.FunName)
+		}
+
+		repo.AddMethods(method.SetRecName(repo.DefaultRecName).SetPtrReceiver(true).SetBody(body))
+	}
+
+	return nil
+}
+
+// renderSQL emits the sql support of all MySQL CRUDs of the package into the package itself: the DBTX
+// connection, the Options, which are aggregated into the configuration of each executable, the Migrate
+// function and the implementations of the statement interfaces. The tables of all entities are created by the first
+// migration of the package. A later change of an entity changes the checksum of that migration, so that Migrate
+// refuses to apply any migration, until the table has been migrated manually.
+func renderSQL(file *ast.File, src *adl.Package) error {
+	pkg := file.Pkg()
+	ctx := &sql.Ctx{
+		Dialect: sql.MySQL,
+		Mod:     token.NewString(astutil.Mod(pkg).Name),
+		Pkg:     token.NewString(pkg.Path),
+	}
+
+	var migration *sql.Migration
+	tables := map[string]bool{}
+	for _, repository := range src.Repositories {
+		for _, crud := range repository.CRUDs {
+			if crud.Persistence != adl.PMySQL {
+				continue
+			}
+
+			m, err := newSQLMapping(file, crud)
+			if err != nil {
+				return err
+			}
+
+			ctx.Repositories = append(ctx.Repositories, sql.Repository{
+				Implements: token.NewString(pkg.Path + "." + crudStatementsName(repository.Name.String())),
+				Methods:    m.methods(),
+			})
+
+			if migration == nil {
+				name := crud.EntityType.Name
+				name.Val = "crud_schema"
+				migration = &sql.Migration{ID: time.Unix(0, 0).UTC(), Name: name}
+				ctx.Migrations = append(ctx.Migrations, migration)
+			}
+
+			if !tables[m.table] {
+				tables[m.table] = true
+				stmt := crud.EntityType.Name
+				stmt.Val = m.createTable()
+				migration.Statements = append(migration.Statements, stmt)
+			}
+		}
+	}
+
+	if len(ctx.Repositories) == 0 {
+		return nil
+	}
+
+	astutil.Mod(pkg).Require(mysqlDriver + " latest")
+
+	prj, ok := astutil.Mod(pkg).Parent().(*ast.Prj)
+	if !ok {
+		return fmt.Errorf("module of package '%s' has no project", pkg.Path)
+	}
+
+	return sqlgen.RenderSQL(prj, ctx)
+}
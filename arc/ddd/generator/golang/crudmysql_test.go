@@ -0,0 +1,57 @@
+package golang_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMySQLCRUD(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+                field Title string! "...is the title."
+                field Created time! "...is the creation time."
+            }
+
+            repository Tickets "...stores tickets." {
+                crud $BC/core.Ticket mysql { all }
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                inject tickets $BC/core.Tickets "...are the tickets."
+            }
+        }`)
+
+	assertContains(t, files, "go.mod", "github.com/go-sql-driver/mysql latest")
+	assertContains(t, files, "internal/application/demoserver/application.go",
+		`_ "github.com/go-sql-driver/mysql"`,
+		"db, err := core.Open(opts)",
+	)
+	assertContains(t, files, "internal/tickets/core/repositories.go",
+		"if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {",
+		"return ticketsTicketAlreadyExistsError{id: entity.ID}",
+		`col = "\"title\""`,
+		`q += " WHERE (" + col + cmp + "? OR (" + col + " = ? AND \"id\"" + cmp + "?))"`,
+		`q += " ORDER BY " + orderBy`,
+		"rows, err := r.db.QueryContext(context.Background(), q, args...)",
+	)
+	assertContains(t, files, "internal/tickets/core/coretest/repositories_test.go",
+		`database := os.Getenv("MYSQL_TEST_DATABASE")`,
+		"db, err := core.Open(opts)",
+		"_ = closer.Close()",
+		`_ "github.com/go-sql-driver/mysql"`,
+		`if _, err := db.ExecContext(context.Background(), "DELETE FROM \"tickets_core_ticket\""); err != nil {`,
+	)
+	assertContains(t, files, "internal/tickets/core/ticketsstatements.go",
+		`SELECT \"id\", \"title\", \"created\" FROM \"tickets_core_ticket\" ORDER BY \"id\"`,
+	)
+
+	if strings.Contains(files["internal/tickets/core/dbtx.go"], "go-sql-driver") {
+		t.Fatalf("expected the driver to be imported by the application:\n%s", files["internal/tickets/core/dbtx.go"])
+	}
+
+	buildFiles(t, files)
+}
@@ -0,0 +1,191 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"strings"
+)
+
+// crudIdentity returns the key type of the CRUD entity and the selector of its identity, which is relative
+// to an entity variable, e.g. ".ID".
+func crudIdentity(file *ast.File, crud *adl.CRUD, entityType ast.TypeDecl) (ast.TypeDecl, string, error) {
+	var keyType ast.TypeDecl
+	selector := ".ID"
+	resolvedEntityType := astutil.Resolve(file, entityType.String())
+	if s, ok := resolvedEntityType.(*ast.Struct); ok {
+		if accessor, ok := stereotype.StructFrom(s).IdentityAccessor(); ok {
+			selector = "." + accessor + "()"
+			keyType = astutil.MethodByName(s, accessor).Results()[0].TypeDecl().Clone()
+		}
+	}
+
+	if crud.IDType != nil {
+		keyType = astutil.MakeTypeDecl(crud.IDType)
+	} else if keyType == nil {
+		id := astutil.FieldByName(resolvedEntityType, "ID")
+		if id == nil {
+			return nil, "", fmt.Errorf("crud entity type has neither custom ID type nor an ID field")
+		}
+
+		keyType = id.TypeDecl().Clone()
+	}
+
+	return keyType, selector, nil
+}
+
+// lessSource returns the template expression, which reports whether a is sorted before b. Only types with a
+// natural order are sortable.
+func lessSource(typ ast.TypeDecl, a, b string) (string, bool) {
+	switch typ.String() {
+	case stdlib.String, stdlib.Int, stdlib.Int16, stdlib.Int32, stdlib.Int64, stdlib.Byte, stdlib.Rune,
+		stdlib.Float32, stdlib.Float64, stdlib.Duration:
+		return a + " < " + b, true
+	case stdlib.Bool:
+		return "!" + a + " && " + b, true
+	case stdlib.Time:
+		return a + ".Before(" + b + ")", true
+	case stdlib.UUID:
+		if strings.HasSuffix(a, ")") {
+			// the array returned by an accessor is not addressable
+			return "", false
+		}
+
+		return `{{.Use "bytes.Compare"}}(` + a + "[:], " + b + "[:]) < 0", true
+	default:
+		return "", false
+	}
+}
+
+// crudPageSource sorts the candidates in res, which are already filtered by query.After, and returns the page
+// selected by the query. It is shared by all persistences, so that they page the same way.
+const crudPageSource = `{{.Use "sort.Slice"}}(res, func(i, j int) bool {
+		return query.Less(res[i], res[j])
+	})
+
+	if query.After == nil {
+		if query.Offset >= len(res) {
+			return res[:0], nil
+		}
+
+		res = res[query.Offset:]
+	}
+
+	if query.Limit > 0 && query.Limit < len(res) {
+		res = res[:query.Limit]
+	}
+
+	return res, nil
+`
+
+// crudQueryName returns the names of the query, order and visitor types of the entity.
+func crudQueryName(entityType ast.TypeDecl) (query, order, visitor string) {
+	name := ast.Name(entityType.String()).Identifier()
+	return name + "Query", name + "Order", name + "Visitor"
+}
+
+// renderCrudQuery declares the query, order and visitor types of the CRUD entity in the package of the repository,
+// so that every persistence implementation pages and sorts the same way. The types are declared once per entity.
+func renderCrudQuery(file *ast.File, crud *adl.CRUD, entityType ast.TypeDecl) error {
+	queryName, orderName, visitorName := crudQueryName(entityType)
+	if astutil.Resolve(file, file.Pkg().Path+"."+queryName) != nil {
+		return nil
+	}
+
+	keyType, selector, err := crudIdentity(file, crud, entityType)
+	if err != nil {
+		return err
+	}
+
+	idLess, ok := lessSource(keyType, "a"+selector, "b"+selector)
+	if !ok {
+		idLess = `{{.Use "fmt.Sprint"}}(a` + selector + `) < fmt.Sprint(b` + selector + `)`
+	}
+
+	entityName := ast.Name(entityType.String()).Identifier()
+	entity := `{{.Use "` + entityType.String() + `"}}`
+
+	type sortField struct {
+		name, less, greater string
+	}
+
+	var fields []sortField
+	if s, ok := astutil.Resolve(file, entityType.String()).(*ast.Struct); ok {
+		for _, field := range s.Fields() {
+			if "."+field.FieldName == selector || field.Visibility() != ast.Public {
+				continue
+			}
+
+			less, ok := lessSource(field.TypeDecl(), "a."+field.FieldName, "b."+field.FieldName)
+			if !ok {
+				continue
+			}
+
+			greater, _ := lessSource(field.TypeDecl(), "b."+field.FieldName, "a."+field.FieldName)
+			fields = append(fields, sortField{name: field.FieldName, less: less, greater: greater})
+		}
+	}
+
+	tmp := &strings.Builder{}
+	tmp.WriteString("// " + orderName + " selects the field, by which " + entityName + " entities are sorted.\n")
+	tmp.WriteString("type " + orderName + " int\n\n")
+	tmp.WriteString("const (\n")
+	tmp.WriteString("// " + entityName + "ByIdentity sorts the entities only by their identity.\n")
+	tmp.WriteString(entityName + "ByIdentity " + orderName + " = iota\n")
+	for _, field := range fields {
+		tmp.WriteString("// " + entityName + "By" + field.name + " sorts the entities by their " + field.name + " field.\n")
+		tmp.WriteString(entityName + "By" + field.name + "\n")
+	}
+
+	tmp.WriteString(")\n\n")
+
+	tmp.WriteString("// " + visitorName + " is invoked for each " + entityName + " entity and returns false to stop the iteration.\n")
+	tmp.WriteString("type " + visitorName + " func(entity " + entity + ") bool\n\n")
+
+	tmp.WriteString("// Less reports whether the entity a is sorted before b. Entities, which are equal in the selected\n")
+	tmp.WriteString("// field, are ordered by their identity, so that the order is total and the pages are stable.\n")
+	tmp.WriteString("func (q " + queryName + ") Less(a, b " + entity + ") bool {\n")
+	if len(fields) > 0 {
+		tmp.WriteString("switch q.OrderBy {\n")
+		for _, field := range fields {
+			tmp.WriteString("case " + entityName + "By" + field.name + ":\n")
+			tmp.WriteString("if " + field.less + " {\nreturn !q.Descending\n}\n\n")
+			tmp.WriteString("if " + field.greater + " {\nreturn q.Descending\n}\n")
+		}
+
+		tmp.WriteString("}\n\n")
+	}
+
+	idGreater := strings.NewReplacer("a"+selector, "b"+selector, "b"+selector, "a"+selector).Replace(idLess)
+	tmp.WriteString("if q.Descending {\nreturn " + idGreater + "\n}\n\n")
+	tmp.WriteString("return " + idLess + "\n}\n\n")
+
+	tmp.WriteString("// Validate returns an error, if the offset or the limit is negative.\n")
+	tmp.WriteString("func (q " + queryName + ") Validate() error {\n")
+	tmp.WriteString("if q.Offset < 0 || q.Limit < 0 {\n")
+	tmp.WriteString(`return {{.Use "fmt.Errorf"}}("invalid ` + queryName + `: negative offset %d or limit %d", q.Offset, q.Limit)` + "\n}\n\n")
+	tmp.WriteString("return nil\n}\n")
+
+	query := ast.NewStruct(queryName).
+		SetComment("...selects a sorted page of "+entityName+" entities. A page is either selected by an offset or by\nthe last entity of the previous page, which is stable even if entities are inserted or deleted concurrently.").
+		AddFields(
+			ast.NewField("OrderBy", ast.NewSimpleTypeDecl(ast.Name(file.Pkg().Path+"."+orderName))).
+				SetComment("...selects the field, by which the entities are sorted."),
+			ast.NewField("Descending", ast.NewSimpleTypeDecl(stdlib.Bool)).
+				SetComment("...reverses the order."),
+			ast.NewField("Offset", ast.NewSimpleTypeDecl(stdlib.Int)).
+				SetComment("...skips the first entities of the sorted result. It is ignored, if After is set."),
+			ast.NewField("Limit", ast.NewSimpleTypeDecl(stdlib.Int)).
+				SetComment("...is the maximum amount of entities. Zero means no limit."),
+			ast.NewField("After", ast.NewTypeDeclPtr(entityType.Clone())).
+				SetComment("...is the last entity of the previous page. If set, only the entities, which are sorted after it,\nare returned."),
+		)
+
+	file.AddTypes(query)
+	file.AddNodes(ast.NewTpl(tmp.String()))
+
+	return nil
+}
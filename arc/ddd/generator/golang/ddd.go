@@ -6,14 +6,19 @@ import (
 	"github.com/golangee/architecture/arc/generator/astutil"
 	"github.com/golangee/architecture/arc/generator/doc"
 	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/protobuf"
 	"github.com/golangee/architecture/arc/generator/stereotype"
 	"github.com/golangee/architecture/arc/token"
 	"github.com/golangee/src/ast"
 	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib/lang"
 	"strings"
 )
 
-func RenderModule(dst *ast.Prj, prj *adl.Project, src *adl.Module) error {
+// RenderModule emits the Go module of the given architecture module. The lock contains the protobuf field numbers
+// of all former generations and is only required, if the module exposes grpc methods. New fields are registered
+// in it, so that it must be passed to RenderProtoLock afterwards.
+func RenderModule(dst *ast.Prj, prj *adl.Project, src *adl.Module, lock *protobuf.Lock) error {
 	normalizeNames(src)
 
 	if src.Generator == nil {
@@ -63,6 +68,41 @@ func RenderModule(dst *ast.Prj, prj *adl.Project, src *adl.Module) error {
 		return token.NewPosError(src.Name, "cannot render build info").SetCause(err)
 	}
 
+	// eventbus
+	if err := renderEventBus(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render event bus").SetCause(err)
+	}
+
+	// validation
+	if err := renderValidation(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render validation").SetCause(err)
+	}
+
+	// rest
+	if err := renderRest(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render rest").SetCause(err)
+	}
+
+	// http clients
+	if err := renderClients(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render http clients").SetCause(err)
+	}
+
+	// grpc
+	if err := renderGrpc(mod, src, lock); err != nil {
+		return token.NewPosError(src.Name, "cannot render grpc").SetCause(err)
+	}
+
+	// cli
+	if err := renderCli(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render cli").SetCause(err)
+	}
+
+	// lifecycle
+	if err := renderLifecycle(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render lifecycle").SetCause(err)
+	}
+
 	// execs
 	if err := renderExecs(mod, src); err != nil {
 		return token.NewPosError(src.Name, "cannot render executable entry points").SetCause(err)
@@ -73,9 +113,18 @@ func RenderModule(dst *ast.Prj, prj *adl.Project, src *adl.Module) error {
 		return token.NewPosError(src.Name, "cannot render makefile").SetCause(err)
 	}
 
+	// openapi
+	if err := renderOpenAPI(mod, src); err != nil {
+		return token.NewPosError(src.Name, "cannot render openapi").SetCause(err)
+	}
+
 	// bounded context packages
 	for _, bc := range src.BoundedContexts {
-		domainTerm := prj.Glossary.Terms[bc.Name.String()]
+		var domainTerm adl.Term
+		if prj.Glossary != nil {
+			domainTerm = prj.Glossary.Terms[bc.Name.String()]
+		}
+
 		domainRootPkg := golang.MakePkgPath(bc.Path.String())
 		domain := astutil.MkPkg(mod, domainRootPkg)
 		domain.SetComment("...contains the core and usecase packages which represent the bounded contexts" + bc.Name.String() + " domain model.\n" + golang2.DeEllipsis(domainTerm.Ident.String(), domainTerm.Description.String()))
@@ -104,6 +153,9 @@ func RenderModule(dst *ast.Prj, prj *adl.Project, src *adl.Module) error {
 		}
 	}
 
+	// glossary
+	renderGlossary(mod, prj, src)
+
 	// build super configuration
 	if err := renderConfigs(mod, src); err != nil {
 		return token.NewPosError(src.Name, "cannot render configurations").SetCause(err)
@@ -126,16 +178,31 @@ func renderUserTypes(parent *ast.Pkg, srcMod *adl.Module, srcBc *adl.BoundedCont
 		pkg.SetComment(src.Comment.String())
 	}
 
-	// errors
-	if len(src.Errors) > 0 {
+	// errors, a crud requires the sum type for its own error cases
+	var sumType *lang.Error
+	if len(src.Errors) > 0 || hasCRUDs(src) {
 		file := ast.NewFile(strings.ToLower("errors.go"))
 		pkg.AddFiles(file)
 		file.SetPreamble(makePreamble(srcMod.Preamble))
 
-		if _, err := buildErrors(file, srcMod, srcBc, src, src.Errors); err != nil {
+		errType, err := buildErrors(file, srcMod, srcBc, src, src.Errors)
+		if err != nil {
 			return fmt.Errorf("cannot build errors: %w", err)
 		}
 
+		sumType = errType
+	}
+
+	// enums
+	if len(src.Enums) > 0 {
+		file := ast.NewFile(strings.ToLower("enums.go"))
+		pkg.AddFiles(file)
+		file.SetPreamble(makePreamble(srcMod.Preamble))
+		for _, enum := range src.Enums {
+			if err := golang.AddEnum(file, enum); err != nil {
+				return fmt.Errorf("cannot render enum: %w", err)
+			}
+		}
 	}
 
 	// dtos
@@ -153,6 +220,38 @@ func renderUserTypes(parent *ast.Pkg, srcMod *adl.Module, srcBc *adl.BoundedCont
 		}
 	}
 
+	// shared value objects
+	if len(src.ValueObjects) > 0 {
+		file := ast.NewFile(strings.ToLower("values.go"))
+		pkg.AddFiles(file)
+		file.SetPreamble(makePreamble(srcMod.Preamble))
+		for _, value := range src.ValueObjects {
+			if _, err := addValueObject(file, value); err != nil {
+				return fmt.Errorf("cannot render value object: %w", err)
+			}
+		}
+	}
+
+	// aggregates
+	if len(src.Aggregates) > 0 {
+		file := ast.NewFile(strings.ToLower("aggregates.go"))
+		pkg.AddFiles(file)
+		file.SetPreamble(makePreamble(srcMod.Preamble))
+		for _, aggregate := range src.Aggregates {
+			if err := renderAggregate(file, aggregate); err != nil {
+				return fmt.Errorf("cannot render aggregate: %w", err)
+			}
+		}
+	}
+
+	// events
+	if len(src.Events) > 0 {
+		file := ast.NewFile(strings.ToLower("events.go"))
+		pkg.AddFiles(file)
+		file.SetPreamble(makePreamble(srcMod.Preamble))
+		renderEvents(file, src.Events)
+	}
+
 	// repos
 	if len(src.Repositories) > 0 {
 		file := ast.NewFile(strings.ToLower("repositories.go"))
@@ -166,12 +265,16 @@ func renderUserTypes(parent *ast.Pkg, srcMod *adl.Module, srcBc *adl.BoundedCont
 			}
 
 			for _, d := range repository.CRUDs {
-				_, err := renderCrud(file, iface, d)
+				_, err := renderCrud(file, iface, d, sumType)
 				if err != nil {
 					return fmt.Errorf("unable to render CRUD: %w", err)
 				}
 			}
 		}
+
+		if err := renderSQL(file, src); err != nil {
+			return fmt.Errorf("unable to render sql support: %w", err)
+		}
 	}
 
 	// services
@@ -180,11 +283,17 @@ func renderUserTypes(parent *ast.Pkg, srcMod *adl.Module, srcBc *adl.BoundedCont
 		pkg.AddFiles(file)
 		file.SetPreamble(makePreamble(srcMod.Preamble))
 		for _, srv := range src.Services {
-			t, err := golang.AddComponent(file, srv.Component)
-			stereotype.StructFrom(t).SetIsService(true)
+			compo, subscriptions := serviceWithEvents(pkg, srv)
+			if srv.Lifecycle {
+				serviceWithLifecycle(compo)
+			}
+
+			t, err := golang.AddComponent(file, compo)
 			if err != nil {
 				return err
 			}
+
+			stereotype.StructFrom(t).SetIsService(true).SetEventSubscriptions(subscriptions).SetLifecycle(srv.Lifecycle)
 		}
 
 	}
@@ -192,6 +301,17 @@ func renderUserTypes(parent *ast.Pkg, srcMod *adl.Module, srcBc *adl.BoundedCont
 	return nil
 }
 
+// hasCRUDs returns true, if any repository of the package is implemented by a generated CRUD.
+func hasCRUDs(src *adl.Package) bool {
+	for _, repository := range src.Repositories {
+		if len(repository.CRUDs) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 func buildInterface(parent *ast.File, srcMod *adl.Module, src *adl.Package, iface *adl.Interface) (*ast.Interface, error) {
 	aType := ast.NewInterface(iface.Name.String()).SetComment(iface.Comment.String())
 	parent.AddTypes(aType)
@@ -203,7 +323,7 @@ func buildInterface(parent *ast.File, srcMod *adl.Module, src *adl.Package, ifac
 		}
 
 		for _, param := range method.Out {
-			aMethod.AddResults(ast.NewParam(param.Name.String(), astutil.MakeTypeDecl(param.Type)).SetComment(param.Comment.String()))
+			aMethod.AddResults(ast.NewParam(golang.ResultName(method, param), astutil.MakeTypeDecl(param.Type)).SetComment(param.Comment.String()))
 		}
 
 		aType.AddMethods(aMethod)
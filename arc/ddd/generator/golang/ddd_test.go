@@ -0,0 +1,36 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderModule(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            error NotFound "...indicates a missing ticket."
+
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+                field Title string! "...is the title."
+            }
+
+            repository Tickets "...stores tickets." {
+                func Find "...finds a ticket." {
+                    in id uuid! "...is the id."
+                    out Ticket "...is the ticket."
+                    out error! "...if anything goes wrong."
+                    error NotFound
+                }
+            }
+        }
+
+        usecase {
+            service Tickets "...manages tickets." {
+                func Hello "...says hello."
+            }
+        }`)
+
+	assertContains(t, files, "go.mod", "module example.com/demo")
+	assertContains(t, files, "internal/tickets/core/repositories.go", "Find(id uuid.UUID) (Ticket, error)")
+	buildFiles(t, files)
+}
@@ -0,0 +1,74 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderEnums(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            enum Status "...is the ticket status." {
+                case Open "...is a new ticket."
+                case Closed "...is a solved ticket."
+            }
+
+            enum Assignee "...is whom a ticket is assigned to." {
+                case Nobody "...means unassigned."
+                case Agent "...is a support agent." {
+                    field Name string! "...is the name of the agent."
+                }
+            }
+
+            config Settings "...configures tickets." {
+                field flag Initial $BC/core.Status "...is the status of new tickets."
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/enums.go",
+		"func ParseStatus(str string) (Status, error) {",
+		"func (s *Status) UnmarshalText(text []byte) error {",
+		"type AssigneeAgent struct {",
+	)
+	assertContains(t, files, "internal/tickets/core/dtos.go",
+		`flags.Var(&s.Initial, "tickets-core-initial", "...is the status of new tickets.")`,
+	)
+	// the cases are encoded by their names
+	files["internal/tickets/core/enums_test.go"] = `package core
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	buf, err := json.Marshal([]Status{StatusOpen, StatusClosed})
+	if err != nil || string(buf) != "[\"Open\",\"Closed\"]" {
+		t.Fatalf("unexpected encoding %s: %v", buf, err)
+	}
+
+	if _, err := json.Marshal(Status(7)); err == nil {
+		t.Fatal("expected an undeclared case to fail")
+	}
+
+	var status Status
+	if err := json.Unmarshal([]byte("\"Pending\""), &status); err == nil {
+		t.Fatal("expected an unknown name to fail")
+	}
+
+	var settings Settings
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	settings.ConfigureFlags(flags)
+	if err := flags.Parse([]string{"-tickets-core-initial", "Closed"}); err != nil || settings.Initial != StatusClosed {
+		t.Fatalf("expected the closed status but got %v: %v", settings.Initial, err)
+	}
+
+	var assignee Assignee = AssigneeAgent{Name: "Alice"}
+	if assignee.Kind() != AssigneeKindAgent || assignee.Kind().String() != "Agent" {
+		t.Fatalf("unexpected case %v", assignee.Kind())
+	}
+}
+`
+
+	buildFiles(t, files)
+}
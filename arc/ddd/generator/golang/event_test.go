@@ -0,0 +1,107 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderEvents(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            event TicketCreated "...is raised for new tickets." {
+                field ID uuid! "...is the id."
+            }
+        }
+
+        usecase {
+            service Tickets "...manages tickets." {
+                func Create "...creates a ticket." {
+                    out error! "...if anything goes wrong."
+                    raise $BC/core.TicketCreated
+                }
+            }
+
+            service Mails "...notifies about tickets." {
+                subscribe $BC/core.TicketCreated
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/core/events.go",
+		"func (t TicketCreated) EventName() string {",
+		"type Publisher interface {",
+	)
+	assertContains(t, files, "internal/tickets/usecase/services.go",
+		"func NewTickets(corePublisher core.Publisher) (*Tickets, error) {",
+		"OnTicketCreated(ctx context.Context, evt core.TicketCreated) error",
+	)
+	assertContains(t, files, "internal/application/demoserver/application.go", "func (d *defaultApplication) subscribeEvents() error {")
+	buildFiles(t, files)
+}
+
+func TestRenderAsyncEventBus(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            event TicketCreated "...is raised for new tickets." {
+                field ID uuid! "...is the id."
+            }
+        }`)
+
+	// the handler runs after the publisher has cancelled its context but still sees its values
+	files["internal/eventbus/eventbus_test.go"] = `package eventbus
+
+import (
+	"context"
+	"testing"
+)
+
+type key struct{}
+
+func TestDetachedContext(t *testing.T) {
+	bus := NewAsyncBus(func(name string, err error) {
+		t.Errorf("cannot handle %s: %v", name, err)
+	})
+
+	done := make(chan struct{})
+	bus.Subscribe("created", func(ctx context.Context, evt interface{}) error {
+		<-done
+		if ctx.Err() != nil {
+			t.Errorf("expected a detached context but got %v", ctx.Err())
+		}
+
+		if ctx.Value(key{}) != "value" {
+			t.Errorf("expected the value of the publisher but got %v", ctx.Value(key{}))
+		}
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	if err := bus.Publish(ctx, "created", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	close(done)
+	bus.Wait()
+}
+`
+
+	buildFiles(t, files)
+}
+
+func TestRenderEventHandlerConflict(t *testing.T) {
+	_, err := renderContext(`
+        core {
+            event TicketCreated "...is raised for new tickets."
+        }
+
+        usecase {
+            event TicketCreated "...is raised for imported tickets."
+
+            service Mails "...notifies about tickets." {
+                subscribe $BC/core.TicketCreated
+                subscribe TicketCreated
+            }
+        }`)
+
+	assertPosError(t, err, 26, "handler 'OnTicketCreated' of event 'TicketCreated' conflicts with '$BC/core.TicketCreated'")
+}
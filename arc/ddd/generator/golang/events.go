@@ -0,0 +1,373 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"sort"
+	"strings"
+)
+
+const (
+	pkgEventBus   = "internal/eventbus"
+	eventBusType  = "Bus"
+	publisherType = "Publisher"
+)
+
+// renderEventBus emits the in-process event bus package, if the module declares any events.
+func renderEventBus(dst *ast.Mod, src *adl.Module) error {
+	if !hasEvents(src) {
+		return nil
+	}
+
+	bus := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgEventBus))
+	bus.SetPreamble(makePreamble(src.Preamble)).
+		SetComment("...provides an in-process bus to dispatch the domain events of all bounded contexts.")
+
+	bus.AddFiles(
+		ast.NewFile("eventbus.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					// Handler processes a published event. The dynamic type of the event is defined by its name.
+					type Handler func(ctx {{.Use "context.Context"}}, evt interface{}) error
+
+					// Bus is an in-process event bus, which dispatches published events to all subscribed handlers.
+					// A synchronous bus invokes the handlers sequentially within the publishing goroutine and stops
+					// at the first failure. An asynchronous bus invokes each handler within its own goroutine and
+					// reports failures to its error handler. The handlers of an asynchronous bus get a detached
+					// context, which keeps the values of the publisher but is never cancelled, because the
+					// publisher usually returns before the event has been handled.
+					type Bus struct {
+						mutex    {{.Use "sync.RWMutex"}}
+						handlers map[string][]Handler
+						async    bool
+						onError  func(name string, err error)
+						pending  {{.Use "sync.WaitGroup"}}
+					}
+
+					// NewSyncBus creates a new synchronous Bus.
+					func NewSyncBus() *Bus {
+						return &Bus{handlers: map[string][]Handler{}}
+					}
+
+					// NewAsyncBus creates a new asynchronous Bus. Each failed delivery is reported to onError.
+					func NewAsyncBus(onError func(name string, err error)) *Bus {
+						return &Bus{handlers: map[string][]Handler{}, async: true, onError: onError}
+					}
+
+					// Subscribe registers the handler for all events with the given name.
+					func (b *Bus) Subscribe(name string, handler Handler) {
+						b.mutex.Lock()
+						defer b.mutex.Unlock()
+
+						b.handlers[name] = append(b.handlers[name], handler)
+					}
+
+					// Publish dispatches the event to all handlers, which have been subscribed to the given name.
+					// A synchronous bus returns the first error of a handler. An asynchronous bus returns immediately
+					// and never fails.
+					func (b *Bus) Publish(ctx context.Context, name string, evt interface{}) error {
+						b.mutex.RLock()
+						handlers := b.handlers[name]
+						b.mutex.RUnlock()
+
+						if b.async {
+							ctx = detachedContext{Context: {{.Use "context.Background"}}(), values: ctx}
+						}
+
+						for _, handler := range handlers {
+							if !b.async {
+								if err := handler(ctx, evt); err != nil {
+									return {{.Use "fmt.Errorf"}}("cannot handle event '%s': %w", name, err)
+								}
+
+								continue
+							}
+
+							b.pending.Add(1)
+							go func(handler Handler) {
+								defer b.pending.Done()
+								if err := handler(ctx, evt); err != nil && b.onError != nil {
+									b.onError(name, err)
+								}
+							}(handler)
+						}
+
+						return nil
+					}
+
+					// Wait blocks until all pending asynchronous deliveries have been completed.
+					func (b *Bus) Wait() {
+						b.pending.Wait()
+					}
+
+					// detachedContext provides the values of the publisher context, without its deadline and
+					// cancellation.
+					type detachedContext struct {
+						context.Context
+						values context.Context
+					}
+
+					// Value returns the value of the publisher context.
+					func (c detachedContext) Value(key interface{}) interface{} {
+						return c.values.Value(key)
+					}
+`),
+			),
+	)
+
+	return nil
+}
+
+// hasEvents returns true, if any package of the module declares an event.
+func hasEvents(src *adl.Module) bool {
+	for _, bc := range src.BoundedContexts {
+		for _, p := range append(append([]*adl.Package{}, bc.Core...), bc.Usecase...) {
+			if len(p.Events) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// renderEvents emits the event types of the package and the according Publisher port.
+func renderEvents(file *ast.File, events []*adl.Event) {
+	publisher := ast.NewInterface(publisherType).
+		SetComment("...is the port to publish the domain events of this package. The implementation is provided by the\napplication, which dispatches the events to all subscribed services.")
+	stereotype.InterfaceFrom(publisher).SetIsEventPublisher(true)
+
+	for _, event := range events {
+		name := event.Name.String()
+		recName := strings.ToLower(name[0:1])
+		typ := ast.NewStruct(name).
+			SetComment(event.Comment.String() + "\n\nThe stereotype of this type is 'event'.").
+			SetDefaultRecName(recName)
+
+		for _, field := range event.Fields {
+			f := ast.NewField(field.Name.String(), astutil.MakeTypeDecl(field.Type)).SetComment(field.Comment.String())
+			if field.Private {
+				f.SetVisibility(ast.Private)
+			}
+
+			typ.AddFields(f)
+		}
+
+		fqn := file.Pkg().Path + "." + name
+		typ.AddMethods(ast.NewFunc("EventName").
+			SetComment("...returns the unique name of this event.").
+			SetRecName(recName).
+			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.String))).
+			SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewStrLit(fqn)))))
+
+		file.AddTypes(typ)
+
+		publisher.AddMethods(ast.NewFunc("Publish"+name).
+			SetComment("...publishes the "+name+" event.").
+			AddParams(
+				ast.NewParam("ctx", ast.NewSimpleTypeDecl("context.Context")),
+				ast.NewParam("evt", ast.NewSimpleTypeDecl(ast.Name(fqn))),
+			).
+			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))))
+	}
+
+	file.AddTypes(publisher)
+}
+
+// eventName returns the full qualified name of the referenced event. Unqualified names are resolved within the
+// given package.
+func eventName(pkg *ast.Pkg, decl *adl.TypeDecl) string {
+	name := decl.Name.String()
+	if strings.Contains(name, ".") {
+		return name
+	}
+
+	return pkg.Path + "." + name
+}
+
+// serviceWithEvents returns a derived component of the service, which additionally declares a handler method
+// for each subscribed event and injects a Publisher for each package of the raised events.
+func serviceWithEvents(pkg *ast.Pkg, srv *adl.Service) (compo *adl.Struct, subscriptions []string) {
+	tmp := *srv.Component
+	compo = &tmp
+	compo.Methods = nil
+	compo.Inject = append([]*adl.Injection{}, srv.Component.Inject...)
+
+	publishers := map[string]bool{}
+	for _, method := range srv.Component.Methods {
+		if len(method.Events) == 0 {
+			compo.Methods = append(compo.Methods, method)
+			continue
+		}
+
+		m := *method
+		var names []string
+		for _, decl := range method.Events {
+			fqn := eventName(pkg, decl)
+			names = append(names, ast.Name(fqn).Identifier())
+			publishers[ast.Name(fqn).Qualifier()] = true
+		}
+
+		m.Comment.Val += "\n\nThis method may raise the following events: " + strings.Join(names, ", ") + "."
+		compo.Methods = append(compo.Methods, &m)
+	}
+
+	var pkgPaths []string
+	for path := range publishers {
+		pkgPaths = append(pkgPaths, path)
+	}
+
+	sort.Strings(pkgPaths)
+	for _, path := range pkgPaths {
+		pkgName := golang.PkgPathBase(path)
+		compo.Inject = append(compo.Inject, adl.NewInjection(
+			golang.MakePrivate(golang2.MakeIdentifier(pkgName))+publisherType,
+			"...publishes the events of the "+pkgName+" package.",
+			"publisher",
+			adl.NewTypeDecl(path+"."+publisherType),
+		))
+	}
+
+	for _, decl := range srv.Subscriptions {
+		fqn := eventName(pkg, decl)
+		name := ast.Name(fqn).Identifier()
+		compo.Methods = append(compo.Methods, adl.NewMethod(adl.HandlerName(fqn), "...handles the "+name+" event.").
+			AddIn("ctx", "...is the context of the publisher.", adl.NewTypeDecl("context.Context")).
+			AddIn("evt", "...is the published event.", adl.NewTypeDecl(fqn)).
+			AddOut("", "...if the event cannot be handled.", adl.NewTypeDecl(stdlib.Error)))
+
+		subscriptions = append(subscriptions, fqn)
+	}
+
+	return compo, subscriptions
+}
+
+// makeEventBusGetter declares the lazy event bus getter of the application.
+func makeEventBusGetter(app *ast.Struct) {
+	busType := ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(golang.MakePkgPath(astutil.Mod(app).Name, pkgEventBus) + "." + eventBusType)))
+
+	app.AddFields(ast.NewField("eventBus", busType).
+		SetVisibility(ast.Private).
+		SetComment("...dispatches the domain events of all bounded contexts."))
+
+	app.AddMethods(ast.NewFunc("getEventBus").
+		SetComment("...returns the in-process event bus, which is synchronous by default.\nShadow this method at the Application to use an asynchronous bus instead.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(
+			ast.NewParam("", busType.Clone()),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		).
+		SetBody(ast.NewBlock(ast.NewTpl(
+			`if {{.Get "rec"}}.eventBus != nil {
+				return {{.Get "rec"}}.eventBus, nil
+			}
+
+			{{.Get "rec"}}.eventBus = {{.Use "`+golang.MakePkgPath(astutil.Mod(app).Name, pkgEventBus)+`.NewSyncBus"}}()
+
+			return {{.Get "rec"}}.eventBus, nil
+			`,
+		).Put("rec", app.DefaultRecName))))
+}
+
+// makePublisherGetter declares an adapter type, which implements the publisher port by the event bus, and a
+// getter for it. The getter is named like the one of makeGetter, so that services can be injected with it.
+func makePublisherGetter(file *ast.File, app *ast.Struct, publisher *ast.Interface) {
+	typ := ast.NewSimpleTypeDecl(ast.Name(astutil.FullQualifiedName(publisher)))
+	flatName := golang.GlobalFlatName2(typ)
+
+	adapter := ast.NewStruct(golang.MakePrivate(flatName)).
+		SetComment("...implements the " + golang.PkgPathBase(string(typ.SimpleName.Qualifier())) + "." + publisherType + " port using the event bus.").
+		SetVisibility(ast.Private).
+		SetDefaultRecName("p").
+		AddFields(ast.NewField("bus", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl(ast.Name(golang.MakePkgPath(astutil.Mod(app).Name, pkgEventBus)+"."+eventBusType)))).SetVisibility(ast.Private))
+
+	for _, method := range publisher.Methods() {
+		fun := ast.NewFunc(method.FunName).
+			SetComment(method.CommentText()).
+			SetRecName(adapter.DefaultRecName).
+			SetBody(ast.NewBlock(ast.NewTpl("return p.bus.Publish(ctx, evt.EventName(), evt)")))
+
+		for _, param := range method.Params() {
+			fun.AddParams(ast.NewParam(param.ParamName, param.TypeDecl().Clone()))
+		}
+
+		for _, param := range method.Results() {
+			fun.AddResults(ast.NewParam(param.ParamName, param.TypeDecl().Clone()))
+		}
+
+		adapter.AddMethods(fun)
+	}
+
+	file.AddTypes(adapter)
+
+	app.AddMethods(ast.NewFunc("get"+flatName).
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(
+			ast.NewParam("", typ),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		).
+		SetBody(ast.NewBlock(ast.NewTpl(
+			`bus, err := {{.Get "rec"}}.self.getEventBus()
+			if err != nil {
+				return nil, {{.Use "fmt.Errorf"}}("cannot get event bus: %w", err)
+			}
+
+			return {{.Get "adapter"}}{bus: bus}, nil
+			`,
+		).Put("rec", app.DefaultRecName).Put("adapter", adapter.TypeName))))
+}
+
+// makeSubscribeEvents declares the method which subscribes the handlers of all services at the event bus.
+func makeSubscribeEvents(app *ast.Struct, services []*ast.Struct) error {
+	body := ""
+	for _, service := range services {
+		subscriptions := stereotype.StructFrom(service).EventSubscriptions()
+		if len(subscriptions) == 0 {
+			continue
+		}
+
+		getter := "get" + golang.GlobalFlatName(service)
+		if astutil.MethodByName(app, getter) == nil {
+			return fmt.Errorf("service '%s' has no getter", service.TypeName)
+		}
+
+		varName := golang.MakePrivate(golang.GlobalFlatName(service))
+		body += "\n" + varName + ", err := {{.Get \"rec\"}}.self." + getter + "()\n" +
+			"if err != nil {\nreturn {{.Use \"fmt.Errorf\"}}(\"cannot get service '" + service.TypeName + "': %w\", err)\n}\n\n"
+
+		for _, fqn := range subscriptions {
+			body += "bus.Subscribe({{.Use \"" + fqn + "\"}}{}.EventName(), func(ctx {{.Use \"context.Context\"}}, evt interface{}) error {\n" +
+				"return " + varName + "." + adl.HandlerName(fqn) + "(ctx, evt.({{.Use \"" + fqn + "\"}}))\n})\n\n"
+		}
+	}
+
+	// events may be published without any subscriber
+	if body != "" {
+		body = `bus, err := {{.Get "rec"}}.self.getEventBus()
+		if err != nil {
+			return {{.Use "fmt.Errorf"}}("cannot get event bus: %w", err)
+		}
+		` + body
+	}
+
+	app.AddMethods(ast.NewFunc("subscribeEvents").
+		SetComment("...subscribes the event handlers of all services at the event bus.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+		SetBody(ast.NewBlock(ast.NewTpl(body+"\nreturn nil\n").Put("rec", app.DefaultRecName))))
+
+	return nil
+}
@@ -45,12 +45,21 @@ func renderExecs(dst *ast.Mod, src *adl.Module) error {
 										 err := realMain(ctx)
 										 done()
 
+										 {{if .Get "cli"}}
+										 if err != nil {
+											{{.Use "fmt.Fprintln"}}({{.Use "os.Stderr"}}, err)
+											{{.Use "os.Exit"}}(1)
+										 }
+										 {{else}}
 										 if err != nil {
 											logger.Println(ecs.Fatal(), err)
+											{{.Use "os.Exit"}}(1)
 										 }
 
 										 logger.Println(ecs.Info(), "successful shutdown")
+										 {{end}}
 								`).
+									Put("cli", executable.IsCli()).
 									Put("appName", executable.Name.String()).
 									Put("logger", dst.Name+"/internal/logging.NewLoggerFromEnv").
 									Put("buildinfo", dst.Name+"/internal/buildinfo.Build"),
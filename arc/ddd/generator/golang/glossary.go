@@ -0,0 +1,142 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/doc"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"sort"
+	"strings"
+)
+
+const docGlossary = "glossary.md"
+
+// renderGlossary emits a documentation page, which lists the ubiquitous language of each bounded context and
+// cross-links every term with the types which use it and vice versa.
+func renderGlossary(dst *ast.Mod, prj *adl.Project, src *adl.Module) {
+	var nodes []doc.Node
+	for _, bc := range src.BoundedContexts {
+		terms := glossaryTerms(prj.Glossary, bc.Glossary)
+		if len(terms) == 0 {
+			continue
+		}
+
+		usages := adl.TermUsages(prj, bc)
+		typeTerms := map[string][]string{}
+		var types []string
+		nodes = append(nodes, doc.NewElement("h3").Append(doc.NewText(bc.Name.String())))
+
+		for _, key := range sortedTermKeys(terms) {
+			term := terms[key]
+			nodes = append(nodes,
+				doc.NewElement("h4").SetAttr("id", anchor("term", bc.Name.String(), key)).Append(doc.NewText(term.Ident.String())),
+				doc.NewElement("p").Append(doc.NewText(golang2.DeEllipsis(term.Ident.String(), term.Description.String()))),
+			)
+
+			if len(term.Synonyms) > 0 {
+				avoid := doc.NewElement("p").Append(doc.NewText("Avoid: "))
+				for i, synonym := range term.Synonyms {
+					if i > 0 {
+						avoid.Append(doc.NewText(", "))
+					}
+
+					avoid.Append(doc.NewElement("code").Append(doc.NewText(synonym.String())))
+				}
+
+				nodes = append(nodes, avoid)
+			}
+
+			if len(usages[key]) == 0 {
+				continue
+			}
+
+			list := doc.NewElement("ul")
+			for _, usage := range usages[key] {
+				name := qualifiedTypeName(usage)
+				if _, ok := typeTerms[name]; !ok {
+					types = append(types, name)
+				}
+
+				typeTerms[name] = append(typeTerms[name], key)
+				list.Append(doc.NewElement("li").Append(
+					doc.NewElement("a").SetAttr("href", "#"+anchor("type", bc.Name.String(), name)).Append(doc.NewText(name)),
+				))
+			}
+
+			nodes = append(nodes, doc.NewElement("p").Append(doc.NewText("Used by:")), list)
+		}
+
+		sort.Strings(types)
+		for _, name := range types {
+			list := doc.NewElement("ul")
+			for _, key := range typeTerms[name] {
+				list.Append(doc.NewElement("li").Append(
+					doc.NewElement("a").SetAttr("href", "#"+anchor("term", bc.Name.String(), key)).Append(doc.NewText(terms[key].Ident.String())),
+				))
+			}
+
+			nodes = append(nodes,
+				doc.NewElement("h4").SetAttr("id", anchor("type", bc.Name.String(), name)).Append(doc.NewText(name)),
+				list,
+			)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return
+	}
+
+	stereotype.Doc(dst, "", docGlossary, append([]doc.Node{
+		doc.NewElement("h2").Append(doc.NewText("glossary")),
+		doc.NewElement("p").Append(doc.NewText("the ubiquitous language of each bounded context and the types which use it.")),
+	}, nodes...)...)
+}
+
+// glossaryTerms merges the given glossaries, so that later terms shadow earlier ones.
+func glossaryTerms(glossaries ...*adl.Glossary) map[string]adl.Term {
+	res := map[string]adl.Term{}
+	for _, glossary := range glossaries {
+		if glossary == nil {
+			continue
+		}
+
+		for key, term := range glossary.Terms {
+			if term.Ident.String() != "" {
+				res[key] = term
+			}
+		}
+	}
+
+	return res
+}
+
+func sortedTermKeys(terms map[string]adl.Term) []string {
+	keys := make([]string, 0, len(terms))
+	for key := range terms {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func qualifiedTypeName(usage adl.TermUsage) string {
+	if usage.Package.String() == "" {
+		return usage.Type.String()
+	}
+
+	return usage.Package.String() + "." + usage.Type.String()
+}
+
+// anchor creates a stable heading id from the given parts.
+func anchor(parts ...string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '-'
+	}, strings.ToLower(strings.Join(parts, "-"))), "-")
+}
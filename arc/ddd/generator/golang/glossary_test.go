@@ -0,0 +1,55 @@
+package golang_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderGlossary(t *testing.T) {
+	src := strings.Replace(module, "%s", `
+        core {
+            dto Ticket "...is a ticket." {
+                field Title string! "...is the title."
+            }
+        }`, 1)
+
+	src = strings.Replace(src, "\nmodule demo-srv", `
+glossary {
+    ticket "...is a support request of a customer."
+    title "...summarizes a ticket."
+}
+
+module demo-srv`, 1)
+
+	files := renderProject(t, src)
+	assertContains(t, files, "docs/content/docs/demo-srv/glossary.md",
+		"#### title {#term-tickets-title}",
+		"- [Ticket](#type-tickets-ticket)",
+		"- [title](#term-tickets-title)",
+	)
+}
+
+func TestRenderGlossaryLint(t *testing.T) {
+	src := strings.Replace(module, "%s", `
+        core {
+            dto Ticket "...is a ticket." {
+                field Title string! "...is the title."
+                field ETag string! "...is the entity tag."
+            }
+        }`, 1)
+
+	glossary := `
+glossary lint {
+    ticket "...is a support request of a customer."
+    title "...summarizes a ticket."
+}
+
+module demo-srv`
+
+	_, err := renderSource(strings.Replace(src, "\nmodule demo-srv", glossary, 1))
+	assertPosError(t, err, 25, "unknown domain term 'e' in identifier 'ETag'")
+
+	// the linter is opt-in and the common vocabulary is extensible
+	renderProject(t, strings.Replace(src, "\nmodule demo-srv", strings.Replace(glossary, " lint", "", 1), 1))
+	renderProject(t, strings.Replace(src, "\nmodule demo-srv", strings.Replace(glossary, "}", "    common ETag\n}", 1), 1))
+}
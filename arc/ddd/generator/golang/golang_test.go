@@ -0,0 +1,199 @@
+package golang_test
+
+import (
+	"errors"
+	"github.com/golangee/architecture/arc"
+	"github.com/golangee/architecture/arc/adl/parser"
+	"github.com/golangee/architecture/arc/generator/protobuf"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/render"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// module declares the generator settings and the executable of a test project. The declarations of the bounded
+// context are inserted at the verb, so that their first line is line 16 of demo.adl.
+const module = `project demo "...is a test project."
+
+module demo-srv "...is a test module." {
+    generator {
+        out "server"
+        go {
+            module "example.com/demo"
+        }
+    }
+
+    executable demo-server "...is the test server." {
+        application "$MOD/internal/tickets"
+    }
+
+    context Tickets "$MOD/internal/tickets" {
+%s
+    }
+}
+`
+
+// renderFiles parses the bounded context declaration and returns the generated files of the server module by
+// their slash separated path. Files which cannot be formatted fail the test.
+func renderFiles(t *testing.T, context string) map[string]string {
+	t.Helper()
+
+	return renderProject(t, strings.Replace(module, "%s", context, 1))
+}
+
+// renderProject is like renderFiles but parses the entire project source.
+func renderProject(t *testing.T, src string) map[string]string {
+	t.Helper()
+
+	a, err := renderSource(src)
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	files := map[string]string{}
+	collectFiles(t, files, "", a.(*render.Dir))
+
+	return files
+}
+
+// renderContext parses and renders the bounded context declaration and returns the artifact or the error.
+func renderContext(context string) (render.Artifact, error) {
+	return renderSource(strings.Replace(module, "%s", context, 1))
+}
+
+func renderSource(src string) (render.Artifact, error) {
+	prj, err := parser.Parse("demo.adl", []byte(src))
+	if err != nil {
+		return nil, err
+	}
+
+	// every test renders the first generation, which starts without any protobuf field numbers
+	locks := arc.ProtoLocks{}
+	for _, module := range prj.Modules {
+		locks[module.Name.String()] = protobuf.NewLock()
+	}
+
+	return arc.Render(prj, locks)
+}
+
+func collectFiles(t *testing.T, files map[string]string, parent string, dir *render.Dir) {
+	for _, file := range dir.Files {
+		name := strings.TrimPrefix(path.Join(parent, file.FileName), "server/")
+		if file.Error != nil {
+			t.Fatalf("unable to render %s: %v\n%s", name, file.Error, render.WithLineNumbers(string(file.Buf)))
+		}
+
+		files[name] = string(file.Buf)
+	}
+
+	for _, d := range dir.Dirs {
+		collectFiles(t, files, path.Join(parent, d.DirName), d)
+	}
+}
+
+// buildFiles writes the files into a temporary directory, resolves the dependencies and runs all tests of the
+// generated module. Additionally, the module is compiled for each given os/arch target. Resolving the
+// dependencies requires a module proxy, so run the tests in short mode to skip the compilation, e.g. without
+// network access and without a local GOPROXY.
+func buildFiles(t *testing.T, files map[string]string, targets ...string) {
+	t.Helper()
+
+	buildDir(t, writeFiles(t, files), targets...)
+}
+
+// writeFiles writes the files into a temporary directory and returns it. It is skipped in short mode.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("compiling the generated module is not short")
+	}
+
+	dir := t.TempDir()
+	for name, buf := range files {
+		fname := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fname), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(fname, []byte(buf), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// buildDir is like buildFiles but expects the files of the generated module in the given directory.
+func buildDir(t *testing.T, dir string, targets ...string) {
+	t.Helper()
+
+	if out, err := goCmd(dir, nil, "mod", "tidy"); err != nil {
+		t.Fatalf("unable to resolve the dependencies of the generated module, use -short to skip: %v\n%s", err, out)
+	}
+
+	if out, err := goCmd(dir, nil, "test", "./..."); err != nil {
+		t.Fatalf("generated module is broken: %v\n%s", err, out)
+	}
+
+	for _, target := range targets {
+		goos, goarch := path.Split(target)
+		env := []string{"GOOS=" + strings.TrimSuffix(goos, "/"), "GOARCH=" + goarch}
+		if out, err := goCmd(dir, env, "build", "./..."); err != nil {
+			t.Fatalf("generated module is broken for %s: %v\n%s", target, err, out)
+		}
+	}
+}
+
+func goCmd(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+
+	return string(out), err
+}
+
+// assertContains fails, if the generated file does not contain each snippet.
+func assertContains(t *testing.T, files map[string]string, name string, snippets ...string) {
+	t.Helper()
+
+	buf, ok := files[name]
+	if !ok {
+		t.Fatalf("expected generated file %s", name)
+	}
+
+	for _, snippet := range snippets {
+		if !strings.Contains(buf, snippet) {
+			t.Fatalf("expected %s to contain %q:\n%s", name, snippet, render.WithLineNumbers(buf))
+		}
+	}
+}
+
+// assertPosError fails, if the error does not explain the message at the given line of the test project.
+func assertPosError(t *testing.T, err error, line int, msg string) {
+	t.Helper()
+
+	for cause := err; cause != nil; {
+		var posErr *token.PosError
+		if !errors.As(cause, &posErr) {
+			break
+		}
+
+		for _, detail := range posErr.Details {
+			pos := detail.Node.Begin()
+			if strings.Contains(detail.Message, msg) && pos.File == "demo.adl" && pos.Line == line {
+				return
+			}
+		}
+
+		cause = posErr.Unwrap()
+	}
+
+	t.Fatalf("expected '%s' at line %d but got\n%s", msg, line, token.Explain(err))
+}
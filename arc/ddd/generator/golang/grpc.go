@@ -0,0 +1,376 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/protobuf"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/render"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+const (
+	pkgRpc        = "internal/rpc"
+	rpcConfigType = "Config"
+	mimeProtobuf  = "text/x-protobuf"
+)
+
+// renderGrpc emits the proto files of the bounded contexts, the generated driver adapters which implement the
+// protoc services and the shared status helpers, if any executable exposes a use case service method by grpc.
+func renderGrpc(dst *ast.Mod, src *adl.Module, lock *protobuf.Lock) error {
+	files, err := buildProtoFiles(src, lock)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	dst.Require("google.golang.org/grpc latest")
+	dst.Require("google.golang.org/protobuf latest")
+	dst.Require("google.golang.org/genproto/googleapis/rpc latest")
+
+	if err := renderRpcPkg(dst, src); err != nil {
+		return err
+	}
+
+	rpcPkg := golang.MakePkgPath(dst.Name, pkgRpc)
+	for _, f := range files {
+		name := f.file.Package
+		pb := astutil.MkPkg(dst, f.pbPkg)
+		pb.SetPreamble(makePreamble(src.Preamble)).
+			SetComment("...contains the protobuf messages and grpc stubs of the bounded context " + f.bc.Name.String() + ".\nThe go sources are created from " + name + ".proto by go generate, which requires protoc, protoc-gen-go and protoc-gen-go-grpc.")
+		pb.AddRawFiles(ast.NewRawFile(name+".proto", mimeProtobuf, []byte(f.file.String())))
+		pb.AddFiles(ast.NewFile("generate.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(ast.NewTpl("//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative " + name + ".proto\n")))
+
+		server := astutil.MkPkg(dst, golang.MakePkgPath(rpcPkg, name+"server"))
+		server.SetPreamble(makePreamble(src.Preamble)).
+			SetComment("...contains the grpc driver adapters of the bounded context " + f.bc.Name.String() + ", which convert the messages and invoke the use case services.")
+
+		body := ""
+		for _, s := range uniqueServices(f.rpcs) {
+			adapter, err := grpcAdapter(src, f, s)
+			if err != nil {
+				return fmt.Errorf("cannot create grpc adapter of '%s': %w", s.service.Component.Name.String(), err)
+			}
+
+			body += adapter
+		}
+
+		server.AddFiles(
+			ast.NewFile("server.go").SetPreamble(makePreamble(src.Preamble)).AddNodes(ast.NewTpl(body)),
+			ast.NewFile("convert.go").SetPreamble(makePreamble(src.Preamble)).AddNodes(ast.NewTpl(f.convert(body))),
+		)
+	}
+
+	return nil
+}
+
+// renderRpcPkg emits the shared package, which contains the server options and the status helpers.
+func renderRpcPkg(dst *ast.Mod, src *adl.Module) error {
+	rpc := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgRpc))
+	rpc.SetPreamble(makePreamble(src.Preamble)).
+		SetComment("...provides the options and the status encoding of the generated grpc driver adapters.")
+
+	rpc.AddFiles(
+		ast.NewFile("rpc.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					// Status returns the error of the status code, whose details describe the error case of a bounded
+					// context. The properties of the case are formatted as the metadata of an ErrorInfo.
+					func Status(code {{.Use "google.golang.org/grpc/codes.Code"}}, domain, reason, message string, properties map[string]interface{}) error {
+						metadata := make(map[string]string, len(properties))
+						for key, value := range properties {
+							metadata[key] = {{.Use "fmt.Sprint"}}(value)
+						}
+
+						st := {{.Use "google.golang.org/grpc/status.New"}}(code, message)
+						detailed, err := st.WithDetails(&{{.Use "google.golang.org/genproto/googleapis/rpc/errdetails.ErrorInfo"}}{
+							Reason:   reason,
+							Domain:   domain,
+							Metadata: metadata,
+						})
+
+						if err != nil {
+							return st.Err() // the details are optional
+						}
+
+						return detailed.Err()
+					}
+
+					// InvalidArgument returns the error of a request field, which cannot be decoded.
+					func InvalidArgument(field string, err error) error {
+						return {{.Use "google.golang.org/grpc/status.Errorf"}}({{.Use "google.golang.org/grpc/codes.InvalidArgument"}}, "invalid field '%s': %v", field, err)
+					}
+
+					// Internal returns the error of an undeclared error case. The cause is not disclosed.
+					func Internal() error {
+						return {{.Use "google.golang.org/grpc/status.Error"}}({{.Use "google.golang.org/grpc/codes.Internal"}}, "internal error")
+					}
+				`),
+			),
+	)
+
+	file := ast.NewFile("config.go").SetPreamble(makePreamble(src.Preamble))
+	rpc.AddFiles(file)
+
+	cfg := ast.NewStruct(rpcConfigType).
+		SetComment("...contains the options of the grpc server.").
+		AddFields(
+			ast.NewField("Address", ast.NewSimpleTypeDecl(stdlib.String)).
+				SetComment("...is the host and port to listen at.").
+				SetDefault(ast.NewStrLit(":9090")),
+			ast.NewField("ShutdownTimeout", ast.NewSimpleTypeDecl(stdlib.Duration)).
+				SetComment("...is the maximum duration to complete pending calls at shutdown.").
+				SetDefault(ast.NewIdentLit("10s")),
+		)
+
+	for _, field := range cfg.Fields() {
+		stereotype.FieldFrom(field).SetProgramFlag(true)
+	}
+
+	file.AddNodes(cfg) // add it early, functions may need contextual information like package path
+	cfg.DefaultRecName = "c"
+
+	if _, err := golang.AddResetFunc(cfg); err != nil {
+		return fmt.Errorf("unable to add reset func: %w", err)
+	}
+
+	if _, err := golang.AddParseEnvFunc("grpc", cfg); err != nil {
+		return fmt.Errorf("unable to add parse-env func: %w", err)
+	}
+
+	if _, err := golang.AddParseFlagFunc("grpc", cfg); err != nil {
+		return fmt.Errorf("unable to add parse-flag func: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueServices returns the services of the rpcs in declaration order.
+func uniqueServices(rpcs []protoRpc) []exposedService {
+	var res []exposedService
+	for _, rpc := range rpcs {
+		if !containsService(res, rpc.service) {
+			res = append(res, rpc.service)
+		}
+	}
+
+	return res
+}
+
+// grpcAdapter returns the template source of the struct, which implements the protoc service by delegating to
+// the use case service.
+func grpcAdapter(src *adl.Module, f *protoFile, s exposedService) (string, error) {
+	name := s.service.Component.Name.String()
+	usecase := `{{.Use "` + s.pkgPath + "." + name + `"}}`
+	res := "// " + name + " implements the grpc service by delegating to the use case service.\n" +
+		"type " + name + " struct {\n" +
+		`{{.Use "` + f.pbPkg + ".Unimplemented" + name + `Server"}}` + "\n" +
+		"service *" + usecase + "\n}\n\n" +
+		"// New" + name + " creates the grpc driver adapter of the use case service.\n" +
+		"func New" + name + "(service *" + usecase + ") *" + name + " {\nreturn &" + name + "{service: service}\n}\n\n"
+
+	for _, rpc := range f.rpcs {
+		if rpc.service.service != s.service {
+			continue
+		}
+
+		method, err := grpcMethod(src, f, rpc)
+		if err != nil {
+			return "", fmt.Errorf("cannot create rpc '%s': %w", rpc.method.Name.String(), err)
+		}
+
+		res += method
+	}
+
+	return res, nil
+}
+
+// grpcMethod returns the template source of the rpc implementation, which decodes the request into the method
+// parameters, invokes the service method and encodes either the results or the status of the error.
+func grpcMethod(src *adl.Module, f *protoFile, rpc protoRpc) (string, error) {
+	rpcPkg := golang.MakePkgPath(src.Generator.Go.Module.String(), pkgRpc)
+	method := rpc.method
+	name := method.Name.String()
+	fun := "// " + golang2.DeEllipsis(name, method.Comment.String()) + "\n" +
+		"func (s *" + rpc.service.service.Component.Name.String() + ") " + name + "(ctx {{.Use \"context.Context\"}}, req *{{.Use \"" + f.pbPkg + "." + name + "Request\"}}) (*{{.Use \"" + f.pbPkg + "." + name + "Response\"}}, error) {\n"
+
+	errDeclared := false
+	var args []string
+	fields := rpc.request
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			args = append(args, "ctx")
+			continue
+		}
+
+		field := fields[0]
+		fields = fields[1:]
+		arg := golang.MakePrivate(param.Name.String())
+		args = append(args, arg)
+		if !field.typ.fails {
+			fun += arg + " := " + field.typ.from("req."+field.goName()) + "\n\n"
+			continue
+		}
+
+		errDeclared = true
+		fun += arg + ", err := " + field.typ.from("req."+field.goName()) + "\n" +
+			"if err != nil {\nreturn nil, {{.Use \"" + rpcPkg + ".InvalidArgument\"}}(" + strconv.Quote(field.field.Name) + ", err)\n}\n\n"
+	}
+
+	var results []string
+	hasErr := false
+	for i, param := range method.Out {
+		if param.Type.Name.String() == stdlib.Error {
+			results = append(results, "err")
+			hasErr = true
+			continue
+		}
+
+		results = append(results, "res"+strconv.Itoa(i))
+	}
+
+	call := "s.service." + name + "(" + strings.Join(args, ", ") + ")"
+	switch {
+	case len(results) == 1 && hasErr && errDeclared:
+		call = "err = " + call
+	case len(results) > 0:
+		call = strings.Join(results, ", ") + " := " + call
+	}
+
+	fun += call + "\n"
+	if !hasErr {
+		fun += "\n"
+	}
+
+	if hasErr {
+		fun += "if err != nil {\n"
+		for _, decl := range method.Errors {
+			anErr, pkgPath := findError(rpc.service, decl)
+			if anErr == nil {
+				return "", fmt.Errorf("error '%s' is not declared", decl.Name.String())
+			}
+
+			props := "nil"
+			if len(anErr.Fields) > 0 {
+				props = "map[string]interface{}{"
+				for _, field := range anErr.Fields {
+					getter := golang2.MakePublic(golang2.MakePrivate(field.Name.String()))
+					props += strconv.Quote(getter) + ": e." + getter + "(),"
+				}
+
+				props += "}"
+			}
+
+			fun += "if e := {{.Use \"" + pkgPath + ".As" + errorCaseName(rpc.service.bc.Name.String(), anErr.Name.String()) + "\"}}(err); e != nil {\n" +
+				"return nil, {{.Use \"" + rpcPkg + ".Status\"}}({{.Use \"google.golang.org/grpc/codes." + method.Grpc.CodeOf(decl.Name.String()) + "\"}}, " +
+				strconv.Quote(rpc.service.bc.Name.String()) + ", " + strconv.Quote(anErr.Name.String()) + ", e.Error(), " + props + ")\n}\n\n"
+		}
+
+		fun += "return nil, {{.Use \"" + rpcPkg + ".Internal\"}}()\n}\n\n"
+	}
+
+	fun += "return &{{.Use \"" + f.pbPkg + "." + name + "Response\"}}{\n"
+	i := 0
+	for j, param := range method.Out {
+		if param.Type.Name.String() == stdlib.Error {
+			continue
+		}
+
+		field := rpc.response[i]
+		i++
+		fun += field.goName() + ": " + field.typ.to("res"+strconv.Itoa(j)) + ",\n"
+	}
+
+	return fun + "}, nil\n}\n\n", nil
+}
+
+// makeGrpcServer declares the getter of the grpc server, which has registered the driver adapters of the exposed
+// use case services.
+func makeGrpcServer(app *ast.Struct, src *adl.Module, services []exposedService) error {
+	rpcPkg := golang.MakePkgPath(astutil.Mod(app).Name, pkgRpc)
+	body := "server := {{.Use \"google.golang.org/grpc.NewServer\"}}()\n"
+	for _, s := range services {
+		getter := "get" + golang.GlobalFlatName2(ast.NewSimpleTypeDecl(ast.Name(s.pkgPath+"."+s.service.Component.Name.String())))
+		if astutil.MethodByName(app, getter) == nil {
+			return fmt.Errorf("service '%s' has no getter", s.service.Component.Name.String())
+		}
+
+		name := protoPkgName(s.bc)
+		varName := golang.MakePrivate(getter[len("get"):])
+		body += "\n" + varName + ", err := {{.Get \"rec\"}}.self." + getter + "()\n" +
+			"if err != nil {\nreturn nil, {{.Use \"fmt.Errorf\"}}(\"cannot get service '" + s.service.Component.Name.String() + "': %w\", err)\n}\n\n" +
+			"{{.Use \"" + golang.MakePkgPath(rpcPkg, name+"pb") + ".Register" + s.service.Component.Name.String() + "Server\"}}(server, " +
+			"{{.Use \"" + golang.MakePkgPath(rpcPkg, name+"server") + ".New" + s.service.Component.Name.String() + "\"}}(" + varName + "))\n"
+	}
+
+	app.AddMethods(ast.NewFunc("getGrpcServer").
+		SetComment("...returns the grpc server with all exposed use case services.\nShadow this method at the Application to install interceptors.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(
+			ast.NewParam("", ast.NewTypeDeclPtr(ast.NewSimpleTypeDecl("google.golang.org/grpc.Server"))),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		).
+		SetBody(ast.NewBlock(ast.NewTpl(body+"\nreturn server, nil\n").Put("rec", app.DefaultRecName))))
+
+	return nil
+}
+
+// RenderProtoLock writes the lock file of the protobuf field numbers, after the module has been rendered with the
+// same lock. Nothing is written, if the module exposes no grpc methods.
+func RenderProtoLock(dst *render.Dir, src *adl.Module, lock *protobuf.Lock) error {
+	normalizeNames(src)
+
+	files, err := buildProtoFiles(src, lock)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	buf, err := lock.JSON()
+	if err != nil {
+		return err
+	}
+
+	path := src.Generator.Go.ProtoLock.String()
+	dir := dst
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		for _, name := range strings.Split(path[:idx], "/") {
+			if name == "" || name == "." {
+				continue
+			}
+
+			sub := dir.Directory(name)
+			if sub == nil {
+				sub = &render.Dir{DirName: name}
+				dir.Dirs = append(dir.Dirs, sub)
+			}
+
+			dir = sub
+		}
+	}
+
+	dir.Files = append(dir.Files, &render.File{
+		FileName: path[strings.LastIndex(path, "/")+1:],
+		MimeType: "application/json",
+		Buf:      buf,
+	})
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package golang_test
+
+import (
+	"github.com/golangee/architecture/arc"
+	"github.com/golangee/architecture/arc/adl/parser"
+	"github.com/golangee/architecture/arc/token"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// grpcSource returns a project whose use case service exposes a method by grpc.
+func grpcSource() string {
+	src := strings.Replace(module, "%s", `
+        core {
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the ticket id."
+                field Title string! "...is the title."
+            }
+        }
+
+        usecase {
+            error NotFound "...if no such ticket exists."
+
+            service Tickets "...manages tickets." {
+                func Find "...finds a ticket." {
+                    in id uuid! "...is the ticket id."
+                    out ticket $BC/core.Ticket "...is the ticket."
+                    out found bool! "...is true, if the ticket exists."
+                    out error! "...if anything goes wrong."
+                    error NotFound
+                    grpc { status NotFound NotFound }
+                }
+            }
+        }`, 1)
+
+	src = strings.Replace(src, `module "example.com/demo"`, `module "example.com/demo"
+            protolock "server/demo.protolock.json"`, 1)
+
+	return src
+}
+
+func TestRenderGrpc(t *testing.T) {
+	files := renderProject(t, grpcSource())
+	assertContains(t, files, "internal/rpc/ticketspb/tickets.proto",
+		"service Tickets {",
+		"rpc Find(FindRequest) returns (FindResponse);",
+	)
+	assertContains(t, files, "internal/tickets/usecase/services.go",
+		"Find(id uuid.UUID) (ticket core.Ticket, found bool, err error)",
+	)
+	assertContains(t, files, "internal/rpc/ticketsserver/server.go",
+		"res0, res1, err := s.service.Find(id)",
+		`return nil, rpc.Status(codes.NotFound, "Tickets", "NotFound", e.Error(), nil)`,
+	)
+	assertContains(t, files, "demo.protolock.json", `"tickets.FindResponse"`)
+
+	for _, tool := range []string{"protoc", "protoc-gen-go", "protoc-gen-go-grpc"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("compiling the grpc module requires %s", tool)
+		}
+	}
+
+	dir := writeFiles(t, files)
+	if out, err := goCmd(dir, nil, "generate", "./..."); err != nil {
+		t.Fatalf("unable to generate the protobuf sources: %v\n%s", err, out)
+	}
+
+	buildDir(t, dir)
+}
+
+func TestRenderGrpcWithoutLock(t *testing.T) {
+	prj, err := parser.Parse("demo.adl", []byte(grpcSource()))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	_, err = arc.Render(prj, nil)
+	if err == nil || !strings.Contains(token.Explain(err), "protobuf lock 'server/demo.protolock.json' has not been loaded") {
+		t.Fatalf("expected a missing protobuf lock but got %v", err)
+	}
+}
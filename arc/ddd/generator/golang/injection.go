@@ -0,0 +1,304 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/doc"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+const docInjection = "dependency-injection.md"
+
+// the kinds of the injected types.
+const (
+	injectService        = "service"
+	injectImplementation = "implementation"
+	injectInterface      = "interface"
+	injectPublisher      = "publisher"
+	injectConfiguration  = "configuration"
+	injectDatabase       = "database"
+	injectExternal       = "external"
+)
+
+// injectionNode is a type within the injection graph. Services, implementations and databases are singletons,
+// which are constructed and cached by their getter.
+type injectionNode struct {
+	fqn    string
+	kind   string
+	getter string // the getter of a singleton, otherwise empty.
+	edges  []*injectionEdge
+}
+
+// name returns the type name relative to the internal package of the module.
+func (n *injectionNode) name() string {
+	const marker = "internal/"
+	if pos := strings.Index(n.fqn, marker); pos >= 0 {
+		return n.fqn[pos+len(marker):]
+	}
+
+	return n.fqn
+}
+
+// injectionEdge is either the injection of a parameter into a singleton or the binding of an interface.
+type injectionEdge struct {
+	src, dst *injectionNode
+	label    string     // the parameter name or 'bound'.
+	pos      token.Node // the declaring injection or binding.
+}
+
+// String describes the edge for error details.
+func (e *injectionEdge) String() string {
+	if e.src.kind == injectInterface {
+		return "'" + e.src.name() + "' is bound to '" + e.dst.name() + "'"
+	}
+
+	return "'" + e.src.name() + "' injects '" + e.dst.name() + "' as '" + e.label + "'"
+}
+
+// injectionGraph records the dependencies between the injected types of a single executable.
+type injectionGraph struct {
+	nodes  []*injectionNode // in order of their creation, which keeps the diagrams stable.
+	byName map[string]*injectionNode
+}
+
+func newInjectionGraph() *injectionGraph {
+	return &injectionGraph{byName: map[string]*injectionNode{}}
+}
+
+// node returns the node of the full qualified type name and creates it, if required.
+func (g *injectionGraph) node(ctx ast.Node, fqn string) *injectionNode {
+	if n, ok := g.byName[fqn]; ok {
+		return n
+	}
+
+	n := &injectionNode{fqn: fqn, kind: injectExternal}
+	switch t := astutil.Resolve(ctx, fqn).(type) {
+	case *ast.Struct:
+		if stereotype.StructFrom(t).IsService() {
+			n.kind = injectService
+		} else {
+			n.kind = injectConfiguration
+		}
+	case *ast.Interface:
+		if stereotype.InterfaceFrom(t).IsEventPublisher() {
+			n.kind = injectPublisher
+		} else if stereotype.InterfaceFrom(t).IsDatabase() {
+			n.kind = injectDatabase
+		} else {
+			n.kind = injectInterface
+		}
+	}
+
+	g.nodes = append(g.nodes, n)
+	g.byName[fqn] = n
+
+	return n
+}
+
+// singleton declares the node of the struct as constructed by the given getter.
+func (g *injectionGraph) singleton(s *ast.Struct, getter string) *injectionNode {
+	n := g.node(s, astutil.FullQualifiedName(s))
+	n.getter = getter
+	if n.kind != injectService {
+		n.kind = injectImplementation
+	}
+
+	return n
+}
+
+// edge records a dependency from src to dst.
+func (g *injectionGraph) edge(src, dst *injectionNode, label string, pos token.Node) {
+	src.edges = append(src.edges, &injectionEdge{src: src, dst: dst, label: label, pos: pos})
+}
+
+// cycle returns the edges of the first cyclic injection or nil, if the graph is acyclic.
+func (g *injectionGraph) cycle() []*injectionEdge {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := map[*injectionNode]int{}
+	var path []*injectionEdge
+	var visit func(n *injectionNode) []*injectionEdge
+	visit = func(n *injectionNode) []*injectionEdge {
+		state[n] = visiting
+		for _, e := range n.edges {
+			path = append(path, e)
+			switch state[e.dst] {
+			case visiting:
+				// the cycle starts at the edge, which leaves the revisited node
+				for i, pe := range path {
+					if pe.src == e.dst {
+						return path[i:]
+					}
+				}
+			case 0:
+				if res := visit(e.dst); res != nil {
+					return res
+				}
+			}
+
+			path = path[:len(path)-1]
+		}
+
+		state[n] = visited
+
+		return nil
+	}
+
+	for _, n := range g.nodes {
+		if state[n] == 0 {
+			if res := visit(n); res != nil {
+				return res
+			}
+		}
+	}
+
+	return nil
+}
+
+// singletons returns the singletons in topological order, so that each singleton follows its dependencies.
+// The graph must be acyclic.
+func (g *injectionGraph) singletons() []*injectionNode {
+	var res []*injectionNode
+	visited := map[*injectionNode]bool{}
+	var visit func(n *injectionNode)
+	visit = func(n *injectionNode) {
+		if visited[n] {
+			return
+		}
+
+		visited[n] = true
+		for _, e := range n.edges {
+			visit(e.dst)
+		}
+
+		if n.getter != "" {
+			res = append(res, n)
+		}
+	}
+
+	for _, n := range g.nodes {
+		visit(n)
+	}
+
+	return res
+}
+
+// checkInjectionCycles returns a PosError, which lists the whole path of the first cyclic injection.
+func checkInjectionCycles(g *injectionGraph, executable *adl.Executable) error {
+	cycle := g.cycle()
+	if cycle == nil {
+		return nil
+	}
+
+	names := []string{cycle[0].src.name()}
+	var details []token.ErrDetail
+	for _, e := range cycle {
+		names = append(names, e.dst.name())
+		pos := e.pos
+		if pos == nil {
+			pos = executable.Name
+		}
+
+		details = append(details, token.NewErrDetail(pos, e.String()))
+	}
+
+	return token.NewPosError(details[0].Node, "cyclic injection "+strings.Join(names, " -> "), details...).
+		SetHint("break the cycle, e.g. by publishing a domain event instead of injecting the dependency")
+}
+
+// makeResolveSingletons declares the method, which constructs all singletons of the executable in the order of
+// their dependencies, so that wiring errors fail at startup and not at the first request. It is invoked by the
+// startServices method of the lifecycle, as long as the Eager option of the LifecycleConfig is set, which is the
+// default. Otherwise the singletons are constructed lazily by their first getter call.
+func makeResolveSingletons(app *ast.Struct, g *injectionGraph) {
+	body := ""
+	for _, n := range g.singletons() {
+		body += "if _, err := {{.Get \"rec\"}}.self." + n.getter + "(); err != nil {\n" +
+			"return {{.Use \"fmt.Errorf\"}}(\"cannot resolve '" + n.name() + "': %w\", err)\n}\n\n"
+	}
+
+	app.AddMethods(ast.NewFunc("resolveSingletons").
+		SetComment("...eagerly constructs all services and implementations in the order of their dependencies.\nIt is invoked by startServices, if the Lifecycle.Eager option is set.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+		SetBody(ast.NewBlock(ast.NewTpl(body+"return nil\n").Put("rec", app.DefaultRecName))))
+}
+
+// injectionDoc returns the documentation of the injection graph of the executable as mermaid and DOT diagram.
+func injectionDoc(g *injectionGraph, executable *adl.Executable) []doc.Node {
+	ids := map[*injectionNode]string{}
+	for i, n := range g.nodes {
+		ids[n] = "n" + strconv.Itoa(i)
+	}
+
+	var mermaid, dot strings.Builder
+	mermaid.WriteString("graph LR\n")
+	dot.WriteString("digraph " + strconv.Quote(executable.Name.String()) + " {\n")
+	dot.WriteString("    rankdir=LR;\n")
+	for _, n := range g.nodes {
+		label := n.name() + " (" + n.kind + ")"
+		switch n.kind {
+		case injectService, injectImplementation, injectDatabase:
+			mermaid.WriteString(fmt.Sprintf("    %s[%s]\n", ids[n], strconv.Quote(label)))
+			dot.WriteString(fmt.Sprintf("    %s [label=%s, shape=box];\n", ids[n], strconv.Quote(label)))
+		case injectInterface, injectPublisher:
+			mermaid.WriteString(fmt.Sprintf("    %s([%s])\n", ids[n], strconv.Quote(label)))
+			dot.WriteString(fmt.Sprintf("    %s [label=%s, shape=ellipse];\n", ids[n], strconv.Quote(label)))
+		default:
+			mermaid.WriteString(fmt.Sprintf("    %s[/%s/]\n", ids[n], strconv.Quote(label)))
+			dot.WriteString(fmt.Sprintf("    %s [label=%s, shape=note];\n", ids[n], strconv.Quote(label)))
+		}
+	}
+
+	for _, n := range g.nodes {
+		for _, e := range n.edges {
+			if n.kind == injectInterface {
+				mermaid.WriteString(fmt.Sprintf("    %s -.->|%s| %s\n", ids[n], e.label, ids[e.dst]))
+				dot.WriteString(fmt.Sprintf("    %s -> %s [label=%s, style=dashed];\n", ids[n], ids[e.dst], strconv.Quote(e.label)))
+			} else {
+				mermaid.WriteString(fmt.Sprintf("    %s -->|%s| %s\n", ids[n], e.label, ids[e.dst]))
+				dot.WriteString(fmt.Sprintf("    %s -> %s [label=%s];\n", ids[n], ids[e.dst], strconv.Quote(e.label)))
+			}
+		}
+	}
+
+	dot.WriteString("}")
+
+	return []doc.Node{
+		doc.NewElement("h3").SetAttr("id", anchor("executable", executable.Name.String())).Append(doc.NewText(executable.Name.String())),
+		doc.NewElement("p").Append(doc.NewText(executable.Comment.String())),
+		doc.NewElement("pre").SetAttr("lang", "mermaid").Append(doc.NewText(strings.TrimSuffix(mermaid.String(), "\n"))),
+		doc.NewElement("p").Append(doc.NewText("The same graph in the DOT language:")),
+		doc.NewElement("pre").SetAttr("lang", "dot").Append(doc.NewText(dot.String())),
+	}
+}
+
+// renderInjectionDoc emits the injection graphs of all executables into the documentation.
+func renderInjectionDoc(dst *ast.Mod, nodes []doc.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	stereotype.Doc(dst, "", docInjection, append([]doc.Node{
+		doc.NewElement("h2").Append(doc.NewText("dependency injection")),
+		doc.NewElement("p").Append(doc.NewText("the types, which are injected into the services and implementations of each executable. " +
+			"Services and implementations are singletons, interfaces are resolved by their binding. " +
+			"All singletons are constructed at startup, before the lifecycle services are started, unless the Eager option of the lifecycle configuration is disabled.")),
+	}, nodes...)...)
+}
+
+// injectionKey identifies an injected parameter of a service.
+func injectionKey(serviceFQN, param string) string {
+	return serviceFQN + "#" + param
+}
@@ -0,0 +1,89 @@
+package golang_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderServiceInjection(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            service lifecycle Indexer "...indexes tickets." {
+            }
+        }
+
+        usecase {
+            service Board "...shows tickets." {
+                inject indexer *$BC/core.Indexer "...is shared with the tickets."
+            }
+
+            service lifecycle Tickets "...manages tickets." {
+                inject indexer *$BC/core.Indexer "...keeps the index up to date."
+                inject board $BC/usecase.Board "...is a copy of the board."
+            }
+        }`)
+
+	app := "internal/application/demoserver/application.go"
+	assertContains(t, files, app,
+		"indexer, err := d.self.getTicketsCoreIndexer()",
+		"board, err := d.self.getTicketsUsecaseBoardValue()",
+		"func (d *defaultApplication) getTicketsUsecaseBoardValue() (usecase.Board, error) {",
+		"return *s, nil",
+	)
+	if strings.Index(files[app], `d.services.Add("Indexer", s)`) > strings.Index(files[app], `d.services.Add("Tickets", s)`) {
+		t.Fatalf("expected the injected service to be started first:\n%s", files[app])
+	}
+
+	buildFiles(t, files)
+}
+
+func TestInjectionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		context string
+		line    int
+		msg     string
+	}{
+		{
+			name: "mutual injection",
+			context: `
+        usecase {
+            service Tickets "...manages tickets." {
+                inject board *$BC/usecase.Board "...shows tickets."
+            }
+
+            service Board "...shows tickets." {
+                inject tickets *$BC/usecase.Tickets "...are the tickets."
+            }
+        }`,
+			line: 19,
+			msg:  "'tickets/usecase.Tickets' injects 'tickets/usecase.Board' as 'board'",
+		},
+		{
+			name: "mutual injection by value",
+			context: `
+        usecase {
+            service Tickets "...manages tickets." {
+                inject board $BC/usecase.Board "...shows tickets."
+            }
+
+            service Board "...shows tickets." {
+                inject tickets *$BC/usecase.Tickets "...are the tickets."
+            }
+        }`,
+			line: 19,
+			msg:  "'tickets/usecase.Tickets' injects 'tickets/usecase.Board' as 'board'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := renderContext(test.context)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			assertPosError(t, err, test.line, test.msg)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+)
+
+const lifecycleConfigType = "LifecycleConfig"
+
+// serviceWithLifecycle declares the Start and Stop hooks at the given service component.
+func serviceWithLifecycle(compo *adl.Struct) {
+	compo.Methods = append(compo.Methods,
+		adl.NewMethod(adl.LifecycleStart, "...is invoked by the application, before any driver adapter is served.\nThe services are started in the order of their dependencies.").
+			AddIn("ctx", "...is cancelled, when the application shuts down.", adl.NewTypeDecl("context.Context")).
+			AddOut("", "...if the service cannot be started, which terminates the application.", adl.NewTypeDecl(stdlib.Error)),
+		adl.NewMethod(adl.LifecycleStop, "...is invoked by the application, after all driver adapters have been shut down.\nThe services are stopped in the reverse order of their start.").
+			AddIn("ctx", "...is cancelled, when the configured shutdown timeout has been exceeded.", adl.NewTypeDecl("context.Context")).
+			AddOut("", "...if the service cannot be stopped properly.", adl.NewTypeDecl(stdlib.Error)),
+	)
+}
+
+// renderLifecycle emits the shared lifecycle types into the application package, which start and stop the
+// services of all executables.
+func renderLifecycle(dst *ast.Mod, src *adl.Module) error {
+	if len(src.Executables) == 0 {
+		return nil
+	}
+
+	app := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgInternalApp))
+	app.AddFiles(
+		ast.NewFile("lifecycle.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					// Lifecycle is implemented by the services, which are started and stopped together with the application.
+					type Lifecycle interface {
+						// Start is invoked before any driver adapter is served.
+						Start(ctx {{.Use "context.Context"}}) error
+
+						// Stop is invoked after all driver adapters have been shut down.
+						Stop(ctx context.Context) error
+					}
+
+					// Services keeps the lifecycle services in the order of their construction. A service is always
+					// constructed after its dependencies, so this is also the order of their dependencies.
+					type Services struct {
+						names    []string
+						services []Lifecycle
+						started  int
+					}
+
+					// Add registers a constructed service.
+					func (s *Services) Add(name string, service Lifecycle) {
+						s.names = append(s.names, name)
+						s.services = append(s.services, service)
+					}
+
+					// Start starts all services in the order of their registration and returns the first error.
+					// The services, which have been started successfully, must be stopped anyway.
+					func (s *Services) Start(ctx context.Context) error {
+						for ; s.started < len(s.services); s.started++ {
+							if err := s.services[s.started].Start(ctx); err != nil {
+								return {{.Use "fmt.Errorf"}}("cannot start service '%s': %w", s.names[s.started], err)
+							}
+						}
+
+						return nil
+					}
+
+					// Stop stops all started services in reverse order. Each service is stopped, even if another one
+					// fails, but only the first error is returned. The timeout limits the duration of all services.
+					func (s *Services) Stop(timeout {{.Use "time.Duration"}}) error {
+						ctx, cancel := {{.Use "context.WithTimeout"}}({{.Use "context.Background"}}(), timeout)
+						defer cancel()
+
+						var res error
+						for ; s.started > 0; s.started-- {
+							idx := s.started - 1
+							if err := s.services[idx].Stop(ctx); err != nil && res == nil {
+								res = fmt.Errorf("cannot stop service '%s': %w", s.names[idx], err)
+							}
+						}
+
+						return res
+					}
+				`),
+			),
+	)
+
+	file := ast.NewFile("config.go").SetPreamble(makePreamble(src.Preamble))
+	app.AddFiles(file)
+
+	cfg := ast.NewStruct(lifecycleConfigType).
+		SetComment("...contains the options of the service lifecycle.").
+		AddFields(
+			ast.NewField("ShutdownTimeout", ast.NewSimpleTypeDecl(stdlib.Duration)).
+				SetComment("...is the maximum duration to stop all services.").
+				SetDefault(ast.NewIdentLit("10s")),
+			ast.NewField("Eager", ast.NewSimpleTypeDecl(stdlib.Bool)).
+				SetComment("...constructs all services and implementations at startup, so that wiring errors fail fast, instead of lazily at their first injection.").
+				SetDefault(ast.NewIdentLit("true")),
+		)
+
+	for _, field := range cfg.Fields() {
+		stereotype.FieldFrom(field).SetProgramFlag(true)
+	}
+
+	file.AddNodes(cfg) // add it early, functions may need contextual information like package path
+	cfg.DefaultRecName = "c"
+
+	if _, err := golang.AddResetFunc(cfg); err != nil {
+		return fmt.Errorf("unable to add reset func: %w", err)
+	}
+
+	if _, err := golang.AddParseEnvFunc("lifecycle", cfg); err != nil {
+		return fmt.Errorf("unable to add parse-env func: %w", err)
+	}
+
+	if _, err := golang.AddParseFlagFunc("lifecycle", cfg); err != nil {
+		return fmt.Errorf("unable to add parse-flag func: %w", err)
+	}
+
+	return nil
+}
+
+// makeStartServices declares the method, which constructs all lifecycle services of the executable and starts
+// them in the order of their dependencies. If configured, all other singletons are resolved before.
+func makeStartServices(app *ast.Struct, services []*ast.Struct) error {
+	body := "if {{.Get \"rec\"}}.cfg.Lifecycle.Eager {\n" +
+		"if err := {{.Get \"rec\"}}.self.resolveSingletons(); err != nil {\n" +
+		"return err\n}\n}\n\n"
+	for _, service := range services {
+		if !stereotype.StructFrom(service).IsLifecycle() {
+			continue
+		}
+
+		getter := "get" + golang.GlobalFlatName(service)
+		if astutil.MethodByName(app, getter) == nil {
+			return fmt.Errorf("service '%s' has no getter", service.TypeName)
+		}
+
+		body += "if _, err := {{.Get \"rec\"}}.self." + getter + "(); err != nil {\n" +
+			"return {{.Use \"fmt.Errorf\"}}(\"cannot get service '" + service.TypeName + "': %w\", err)\n}\n\n"
+	}
+
+	app.AddFields(ast.NewField("services", ast.NewSimpleTypeDecl(ast.Name(golang.MakePkgPath(astutil.Mod(app).Name, pkgInternalApp)+".Services"))).
+		SetVisibility(ast.Private).
+		SetComment("...contains the constructed lifecycle services in the order of their dependencies."))
+
+	app.AddMethods(ast.NewFunc("startServices").
+		SetComment("...constructs all lifecycle services and starts them in the order of their dependencies.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddParams(ast.NewParam("ctx", ast.NewSimpleTypeDecl("context.Context"))).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+		SetBody(ast.NewBlock(ast.NewTpl(body+"return {{.Get \"rec\"}}.services.Start(ctx)\n").Put("rec", app.DefaultRecName))))
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderLifecycle(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            service lifecycle Indexer "...indexes tickets." {
+            }
+        }
+
+        usecase {
+            service lifecycle Tickets "...manages tickets." {
+                func Find "...finds tickets." {
+                    in query string! "...is the query."
+                    out []string! "...are the ids."
+                }
+            }
+        }`)
+
+	assertContains(t, files, "internal/application/demoserver/application.go",
+		`d.services.Add("Indexer", s)`,
+		`d.services.Add("Tickets", s)`,
+		"return d.services.Start(ctx)",
+		"d.services.Stop(d.cfg.Lifecycle.ShutdownTimeout)",
+	)
+	assertContains(t, files, "internal/tickets/usecase/services.go", "func (_ defaultTickets) Start(ctx context.Context) error {")
+	assertContains(t, files, "internal/tickets/core/services.go", "func (_ defaultIndexer) Stop(ctx context.Context) error {")
+	// started services are stopped in reverse order, even if a later one fails to start
+	files["internal/application/lifecycle_test.go"] = `package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	name  string
+	fail  bool
+	calls *[]string
+}
+
+func (r recorder) Start(ctx context.Context) error {
+	*r.calls = append(*r.calls, "start "+r.name)
+	if r.fail {
+		return errors.New("failed")
+	}
+
+	return nil
+}
+
+func (r recorder) Stop(ctx context.Context) error {
+	*r.calls = append(*r.calls, "stop "+r.name)
+	return nil
+}
+
+func TestServices(t *testing.T) {
+	var calls []string
+	services := &Services{}
+	services.Add("a", recorder{name: "a", calls: &calls})
+	services.Add("b", recorder{name: "b", calls: &calls})
+	services.Add("c", recorder{name: "c", fail: true, calls: &calls})
+
+	if err := services.Start(context.Background()); err == nil {
+		t.Fatal("expected service c to fail")
+	}
+
+	if err := services.Stop(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[start a start b start c stop b stop a]"
+	if got := fmt.Sprint(calls); got != want {
+		t.Fatalf("expected %s but got %s", want, got)
+	}
+}
+`
+
+	buildFiles(t, files)
+}
@@ -110,7 +110,7 @@ LDFLAGS = -X $(buildInfo).JobID=${CI_JOB_ID} -X $(buildInfo).CommitTag=${CI_COMM
 
 # doc: #go install --tags=extended github.com/gohugoio/hugo@latest 
 
-{{- range .Get "install"}}
+{{- range .Get "cmds"}}
 {{.VarName}} = "{{.Path}}"
 {{- end}}
 
@@ -150,6 +150,9 @@ dist: ## builds for all major platforms, example: make DESTDIR=/tmp/stage dist
 {{- range .Get "dists"}}
 	GOOS={{.Os}} GOARCH={{.Arch}} go build -ldflags "${LDFLAGS}" -o $(DESTDIR)/bin/{{.Os}}_{{.Arch}}/{{.Filename}} $({{.VarName}})
 {{- end}}
+{{- if .Get "wasm"}}
+	cp "$$(go env GOROOT)/lib/wasm/wasm_exec.js" $(DESTDIR)/bin/js_wasm/ 2>/dev/null || cp "$$(go env GOROOT)/misc/wasm/wasm_exec.js" $(DESTDIR)/bin/js_wasm/
+{{- end}}
 .PHONY: dist
 
 
@@ -201,15 +204,30 @@ all: generate check dist html ## generate, check and build dist
 	}
 
 	var targets []makeTarget
+	var cmdTargets []makeTarget
 	var currentOsTargets []makeTarget
+	wasm := false
 	for _, cmd := range stereotype.FindCMDPkgs(dst) {
-		currentOsTargets = append(currentOsTargets, makeTarget{
+		target := makeTarget{
 			Path:       cmd.Path,
 			Filename:   fnameFunc("", "", cmd.Path),
 			Foldername: astutil.LastPathSegment(cmd.Path),
-		})
+		}
+
+		cmdTargets = append(cmdTargets, target)
+
+		// js/wasm only packages cannot run on the current system and nothing else is build for js/wasm
+		isWasm := stereotype.PkgFrom(cmd).IsWasmPkg()
+		if !isWasm {
+			currentOsTargets = append(currentOsTargets, target)
+		}
 
 		for _, dist := range src.Generator.Go.GoDist {
+			if isWasm != (strings.ToLower(dist.Os.String()) == "js" && strings.ToLower(dist.Arch.String()) == "wasm") {
+				continue
+			}
+
+			wasm = wasm || isWasm
 			targets = append(targets, makeTarget{
 				Arch:       dist.Arch.String(),
 				Os:         dist.Os.String(),
@@ -226,7 +244,9 @@ all: generate check dist html ## generate, check and build dist
 			makeEscapedPreamble(src.Preamble, "# ")+mkfile,
 		).
 			Put("dists", targets).
+			Put("cmds", cmdTargets).
 			Put("install", currentOsTargets).
+			Put("wasm", wasm).
 			Put("modPath", dst.Name)),
 	)
 
@@ -0,0 +1,455 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/openapi"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	openAPIVersion   = "1.0.0"
+	openAPIJSON      = "application/json"
+	openAPIRestError = "rest.Error"
+)
+
+// renderOpenAPI emits an OpenAPI document in yaml and json format next to the Makefile for each executable,
+// which exposes use case service methods by http.
+func renderOpenAPI(dst *ast.Mod, src *adl.Module) error {
+	pkg := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name))
+	for _, executable := range src.Executables {
+		services := httpServices(src, executable)
+		if len(services) == 0 {
+			continue
+		}
+
+		doc, err := newOpenAPIBuilder(src).build(executable, services)
+		if err != nil {
+			return fmt.Errorf("cannot build openapi document of '%s': %w", executable.Name.String(), err)
+		}
+
+		yml, err := doc.YAML()
+		if err != nil {
+			return fmt.Errorf("cannot encode openapi yaml: %w", err)
+		}
+
+		js, err := doc.JSON()
+		if err != nil {
+			return fmt.Errorf("cannot encode openapi json: %w", err)
+		}
+
+		name := executable.Name.String() + ".openapi"
+		pkg.AddRawFiles(
+			ast.NewRawFile(name+".yaml", "text/x-yaml", append([]byte(makeEscapedPreamble(src.Preamble, "# ")), yml...)),
+			ast.NewRawFile(name+".json", openAPIJSON, js),
+		)
+	}
+
+	return nil
+}
+
+// declaredType is a type declaration of a bounded context, which can be described by a component schema or a
+// protobuf message.
+type declaredType struct {
+	bc      *adl.BoundedContext
+	pkgPath string
+	name    string
+	comment string
+	fields  []*adl.Field
+	enum    *adl.Enum
+}
+
+// openAPIBuilder creates the component schemas on demand, while the paths are added.
+type openAPIBuilder struct {
+	src   *adl.Module
+	decls map[string]declaredType // by full qualified name
+	doc   *openapi.Document
+}
+
+func newOpenAPIBuilder(src *adl.Module) *openAPIBuilder {
+	return &openAPIBuilder{src: src, decls: declaredTypes(src)}
+}
+
+// declaredTypes indexes the structs, events and enums of all bounded contexts by their full qualified names.
+func declaredTypes(src *adl.Module) map[string]declaredType {
+	decls := map[string]declaredType{}
+	for _, bc := range src.BoundedContexts {
+		for _, layer := range []struct {
+			name string
+			pkgs []*adl.Package
+		}{{pkgCore, bc.Core}, {pkgUsecase, bc.Usecase}} {
+			for _, p := range layer.pkgs {
+				pkgPath := bcPkgPath(bc, layer.name, p)
+				addStruct := func(s *adl.Struct) {
+					decls[pkgPath+"."+s.Name.String()] = declaredType{bc: bc, pkgPath: pkgPath, name: s.Name.String(), comment: describe(s.Name.String(), s.Comment.String()), fields: s.Fields}
+				}
+
+				for _, dto := range p.DTOs {
+					addStruct(dto)
+				}
+
+				for _, value := range p.ValueObjects {
+					addStruct(value.Component)
+				}
+
+				for _, aggregate := range p.Aggregates {
+					if aggregate.Root != nil {
+						addStruct(aggregate.Root.Component)
+					}
+
+					for _, entity := range aggregate.Entities {
+						addStruct(entity.Component)
+					}
+
+					for _, value := range aggregate.ValueObjects {
+						addStruct(value.Component)
+					}
+				}
+
+				for _, event := range p.Events {
+					decls[pkgPath+"."+event.Name.String()] = declaredType{bc: bc, pkgPath: pkgPath, name: event.Name.String(), comment: describe(event.Name.String(), event.Comment.String()), fields: event.Fields}
+				}
+
+				for _, enum := range p.Enums {
+					decls[pkgPath+"."+enum.Name.String()] = declaredType{bc: bc, pkgPath: pkgPath, name: enum.Name.String(), comment: describe(enum.Name.String(), enum.Comment.String()), enum: enum}
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+// build creates the document of the exposed methods of the given services.
+func (b *openAPIBuilder) build(executable *adl.Executable, services []exposedService) (*openapi.Document, error) {
+	b.doc = openapi.NewDocument(executable.Name.String(), describe(executable.Name.String(), executable.Comment.String()), openAPIVersion)
+	b.doc.Components.Schemas[openAPIRestError] = b.errorSchema("Error", "...is the json encoding of any error response.", nil)
+	b.doc.Components.Responses["BadRequest"] = b.errorResponse("the request cannot be decoded.", openapi.Ref(openAPIRestError))
+	b.doc.Components.Responses["InternalServerError"] = b.errorResponse("an undeclared error occurred.", openapi.Ref(openAPIRestError))
+
+	for _, s := range services {
+		for _, method := range s.service.Component.Methods {
+			if method.Http == nil {
+				continue
+			}
+
+			op, err := b.operation(s, method)
+			if err != nil {
+				return nil, fmt.Errorf("cannot describe '%s': %w", method.Name.String(), err)
+			}
+
+			item := b.doc.Path(method.Http.Path.String())
+			switch method.Http.Verb.String() {
+			case adl.HttpGet:
+				item.Get = op
+			case adl.HttpPost:
+				item.Post = op
+			case adl.HttpPut:
+				item.Put = op
+			case adl.HttpPatch:
+				item.Patch = op
+			case adl.HttpDelete:
+				item.Delete = op
+			default:
+				return nil, fmt.Errorf("unsupported http verb '%s'", method.Http.Verb.String())
+			}
+		}
+	}
+
+	return b.doc, nil
+}
+
+// operation describes the request and all responses of the method.
+func (b *openAPIBuilder) operation(s exposedService, method *adl.Method) (*openapi.Operation, error) {
+	e := method.Http
+	vars, err := e.PathVars()
+	if err != nil {
+		return nil, err
+	}
+
+	op := &openapi.Operation{
+		OperationID: golang2.MakePrivate(s.bc.Name.String()) + s.service.Component.Name.String() + method.Name.String(),
+		Tags:        []string{s.bc.Name.String()},
+		Description: describe(method.Name.String(), method.Comment.String()),
+		Responses:   map[string]*openapi.Response{},
+	}
+
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			continue
+		}
+
+		comment := describe(param.Name.String(), param.Comment.String())
+		switch {
+		case containsName(vars, param.Name.String()):
+			op.Parameters = append(op.Parameters, &openapi.Parameter{Name: param.Name.String(), In: "path", Description: comment, Required: true, Schema: b.paramSchema(s.pkgPath, param.Type)})
+		case containsName(e.Query, param.Name.String()):
+			op.Parameters = append(op.Parameters, &openapi.Parameter{Name: param.Name.String(), In: "query", Description: comment, Schema: b.paramSchema(s.pkgPath, param.Type)})
+		case e.Body.String() == param.Name.String():
+			op.RequestBody = &openapi.RequestBody{
+				Description: comment,
+				Required:    true,
+				Content:     map[string]*openapi.MediaType{openAPIJSON: {Schema: b.schema(s.pkgPath, param.Type)}},
+			}
+		}
+	}
+
+	var results []*adl.Param
+	returnsErr := false
+	for _, param := range method.Out {
+		if param.Type.Name.String() == stdlib.Error {
+			returnsErr = true
+			continue
+		}
+
+		results = append(results, param)
+	}
+
+	switch len(results) {
+	case 0:
+		op.Responses[strconv.Itoa(http.StatusNoContent)] = &openapi.Response{Description: "the method has been applied."}
+	case 1:
+		op.Responses[strconv.Itoa(http.StatusOK)] = &openapi.Response{
+			Description: describe(results[0].Name.String(), results[0].Comment.String()),
+			Content:     map[string]*openapi.MediaType{openAPIJSON: {Schema: b.schema(s.pkgPath, results[0].Type)}},
+		}
+	default:
+		obj := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}}
+		for _, param := range results {
+			schema := b.schema(s.pkgPath, param.Type)
+			if schema.Ref == "" {
+				schema.Description = describe(param.Name.String(), param.Comment.String())
+			}
+
+			obj.Properties[param.Name.String()] = schema
+			obj.Required = append(obj.Required, param.Name.String())
+		}
+
+		op.Responses[strconv.Itoa(http.StatusOK)] = &openapi.Response{
+			Description: "the results of " + method.Name.String() + ".",
+			Content:     map[string]*openapi.MediaType{openAPIJSON: {Schema: obj}},
+		}
+	}
+
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		op.Responses[strconv.Itoa(http.StatusBadRequest)] = &openapi.Response{Ref: "#/components/responses/BadRequest"}
+	}
+
+	if !returnsErr {
+		return op, nil
+	}
+
+	// the declared error cases are grouped by their status codes
+	cases := map[int][]string{}
+	for _, decl := range method.Errors {
+		anErr, _ := findError(s, decl)
+		if anErr == nil {
+			return nil, fmt.Errorf("error '%s' is not declared", decl.Name.String())
+		}
+
+		code := e.StatusOf(decl.Name.String())
+		cases[code] = append(cases[code], describe(anErr.Name.String(), anErr.Comment.String()))
+	}
+
+	for code, descriptions := range cases {
+		op.Responses[strconv.Itoa(code)] = b.errorResponse(strings.Join(descriptions, " "), openapi.Ref(b.bcErrorSchema(s.bc)))
+	}
+
+	op.Responses[strconv.Itoa(http.StatusInternalServerError)] = &openapi.Response{Ref: "#/components/responses/InternalServerError"}
+
+	return op, nil
+}
+
+// schema returns the schema of the type declaration, as it is encoded by encoding/json. Declared types of the
+// bounded contexts are referenced as component schemas.
+func (b *openAPIBuilder) schema(pkgPath string, t *adl.TypeDecl) *openapi.Schema {
+	switch t.Name.String() {
+	case stdlib.String:
+		return &openapi.Schema{Type: "string"}
+	case stdlib.Bool:
+		return &openapi.Schema{Type: "boolean"}
+	case stdlib.Int, stdlib.Int64, stdlib.Duration:
+		return &openapi.Schema{Type: "integer", Format: "int64"}
+	case stdlib.Byte, stdlib.Rune, stdlib.Int16, stdlib.Int32:
+		return &openapi.Schema{Type: "integer", Format: "int32"}
+	case stdlib.Float32:
+		return &openapi.Schema{Type: "number", Format: "float"}
+	case stdlib.Float64:
+		return &openapi.Schema{Type: "number", Format: "double"}
+	case stdlib.UUID:
+		return &openapi.Schema{Type: "string", Format: "uuid"}
+	case stdlib.Time:
+		return &openapi.Schema{Type: "string", Format: "date-time"}
+	case stdlib.URL:
+		return &openapi.Schema{Type: "string", Format: "uri"}
+	case "*":
+		schema := b.schema(pkgPath, t.TypeParams[0])
+		if schema.Ref != "" {
+			schema = &openapi.Schema{AllOf: []*openapi.Schema{schema}}
+		}
+
+		schema.Nullable = true
+
+		return schema
+	case "[]", stdlib.List:
+		// byte slices are base64 encoded but byte arrays are not
+		elem := t.TypeParams[len(t.TypeParams)-1]
+		if len(t.TypeParams) == 1 && elem.Name.String() == stdlib.Byte {
+			return &openapi.Schema{Type: "string", Format: "byte"}
+		}
+
+		return &openapi.Schema{Type: "array", Items: b.schema(pkgPath, elem)}
+	case stdlib.Map:
+		return &openapi.Schema{Type: "object", AdditionalProperties: b.schema(pkgPath, t.TypeParams[1])}
+	}
+
+	fqn := t.Name.String()
+	if idx := strings.LastIndex(fqn, "."); idx < 0 || strings.Contains(fqn[idx:], "/") {
+		fqn = pkgPath + "." + fqn
+	}
+
+	decl, ok := b.decls[fqn]
+	if !ok {
+		// foreign types have no known json representation
+		return &openapi.Schema{Description: "the json encoding of " + t.Name.String() + "."}
+	}
+
+	name := b.componentName(fqn)
+	if _, ok := b.doc.Components.Schemas[name]; !ok {
+		// register first, to terminate recursive declarations
+		schema := &openapi.Schema{}
+		b.doc.Components.Schemas[name] = schema
+		*schema = *b.declSchema(decl)
+	}
+
+	return openapi.Ref(name)
+}
+
+// paramSchema returns the schema of a path or query parameter. Contrary to the json encoding, durations are
+// parsed from their textual representation, e.g. 1h30m.
+func (b *openAPIBuilder) paramSchema(pkgPath string, t *adl.TypeDecl) *openapi.Schema {
+	if t.Name.String() == stdlib.Duration {
+		return &openapi.Schema{Type: "string", Format: "duration"}
+	}
+
+	return b.schema(pkgPath, t)
+}
+
+// declSchema creates the component schema of a declared type.
+func (b *openAPIBuilder) declSchema(decl declaredType) *openapi.Schema {
+	if decl.enum == nil {
+		schema := b.fieldsSchema(decl.pkgPath, decl.fields)
+		schema.Description = decl.comment
+
+		return schema
+	}
+
+	if !decl.enum.IsUnion() {
+		schema := &openapi.Schema{Type: "string", Description: decl.comment}
+		for _, c := range decl.enum.Cases {
+			schema.Enum = append(schema.Enum, c.Name.String())
+		}
+
+		return schema
+	}
+
+	schema := &openapi.Schema{Description: decl.comment}
+	for _, c := range decl.enum.Cases {
+		caseSchema := b.fieldsSchema(decl.pkgPath, c.Fields)
+		caseSchema.Description = describe(c.Name.String(), c.Comment.String())
+		schema.OneOf = append(schema.OneOf, caseSchema)
+	}
+
+	return schema
+}
+
+// fieldsSchema describes an object by its public fields.
+func (b *openAPIBuilder) fieldsSchema(pkgPath string, fields []*adl.Field) *openapi.Schema {
+	schema := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}}
+	for _, field := range fields {
+		if field.Private {
+			continue
+		}
+
+		prop := b.schema(pkgPath, field.Type)
+		if prop.Ref == "" {
+			prop.Description = describe(field.Name.String(), field.Comment.String())
+		}
+
+		schema.Properties[field.Name.String()] = prop
+		for _, c := range field.Constraints {
+			if c.Kind.String() == adl.ConstraintRequired {
+				schema.Required = append(schema.Required, field.Name.String())
+			}
+		}
+	}
+
+	return schema
+}
+
+// bcErrorSchema registers the schema of the error sum type of the bounded context and returns its name.
+func (b *openAPIBuilder) bcErrorSchema(bc *adl.BoundedContext) string {
+	name := b.componentName(golang.MakePkgPath(bc.Path.String()) + ".Error")
+	if _, ok := b.doc.Components.Schemas[name]; ok {
+		return name
+	}
+
+	var cases []string
+	for _, p := range append(append([]*adl.Package{}, bc.Core...), bc.Usecase...) {
+		for _, anErr := range p.AllErrors() {
+			cases = append(cases, anErr.Name.String())
+		}
+	}
+
+	sort.Strings(cases)
+	b.doc.Components.Schemas[name] = b.errorSchema("Error", "...is the json encoding of the error cases of the "+bc.Name.String()+" bounded context. The properties depend on the case.", cases)
+
+	return name
+}
+
+// errorSchema describes the json encoding of a rest.Error.
+func (b *openAPIBuilder) errorSchema(name, comment string, cases []string) *openapi.Schema {
+	return &openapi.Schema{
+		Type:        "object",
+		Description: describe(name, comment),
+		Properties: map[string]*openapi.Schema{
+			"status":     {Type: "integer", Description: "the http status code."},
+			"case":       {Type: "string", Description: "the name of the declared error case.", Enum: cases},
+			"message":    {Type: "string", Description: "the human readable error message."},
+			"properties": {Type: "object", Description: "the properties of the error case."},
+		},
+		Required: []string{"status", "message"},
+	}
+}
+
+func (b *openAPIBuilder) errorResponse(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{openAPIJSON: {Schema: schema}},
+	}
+}
+
+// describe removes the ellipsis of the comment, even if the name is empty.
+func describe(name, comment string) string {
+	if name == "" {
+		return strings.TrimSpace(strings.TrimPrefix(comment, "..."))
+	}
+
+	return golang2.DeEllipsis(name, comment)
+}
+
+// componentName returns the module relative, dot separated name of the declared type, e.g. tickets.core.Ticket.
+func (b *openAPIBuilder) componentName(fqn string) string {
+	name := strings.TrimPrefix(fqn, golang.MakePkgPath(b.src.Generator.Go.Module.String())+"/")
+	name = strings.TrimPrefix(name, "internal/")
+
+	return strings.ReplaceAll(name, "/", ".")
+}
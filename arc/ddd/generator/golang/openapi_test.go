@@ -0,0 +1,132 @@
+package golang_test
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderOpenAPI(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            error NotFound "...indicates a missing ticket."
+
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+        }
+
+        usecase {
+            service Tickets "...manages tickets." {
+                func Find "...finds a ticket." {
+                    in id uuid! "...is the id."
+                    out $BC/core.Ticket "...is the ticket."
+                    out error! "...if anything goes wrong."
+                    error $BC/core.NotFound
+                    http GET "/tickets/{id}" {
+                        status $BC/core.NotFound 404
+                    }
+                }
+            }
+        }`)
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationID string
+			Responses   map[string]interface{}
+		}
+	}
+
+	if err := json.Unmarshal([]byte(files["demo-server.openapi.json"]), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	op := doc.Paths["/tickets/{id}"]["get"]
+	if op.OperationID != "ticketsTicketsFind" || op.Responses["200"] == nil || op.Responses["404"] == nil {
+		t.Fatalf("unexpected operation: %#v", op)
+	}
+
+	assertContains(t, files, "demo-server.openapi.yaml", "operationId: ticketsTicketsFind")
+}
+
+func TestRenderOpenAPITypes(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            dto Ticket "...is a ticket." {
+                field Initial rune! "...is the first letter."
+                field Avatar []byte! "...is an image."
+                field Checksum [4]byte! "...is a checksum."
+                field Timeout duration! "...are the nanoseconds until it expires."
+            }
+        }
+
+        usecase {
+            service Tickets "...manages tickets." {
+                func Find "...finds a ticket." {
+                    in timeout duration! "...is the path parameter."
+                    in after duration! "...is the query parameter."
+                    out $BC/core.Ticket "...is the ticket."
+                    http GET "/tickets/{timeout}" {
+                        query after
+                    }
+                }
+            }
+        }`)
+
+	type schema struct {
+		Type   string
+		Format string
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name   string
+				Schema schema
+			}
+		}
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]schema
+			}
+		}
+	}
+
+	if err := json.Unmarshal([]byte(files["demo-server.openapi.json"]), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	params := doc.Paths["/tickets/{timeout}"]["get"].Parameters
+	if len(params) != 2 {
+		t.Fatalf("expected the path and the query parameter but got %v", params)
+	}
+
+	for _, param := range params {
+		if param.Schema != (schema{Type: "string", Format: "duration"}) {
+			t.Errorf("expected parameter %s to be a textual duration but got %v", param.Name, param.Schema)
+		}
+	}
+
+	var ticket map[string]schema
+	for _, component := range doc.Components.Schemas {
+		if _, ok := component.Properties["Initial"]; ok {
+			ticket = component.Properties
+		}
+	}
+
+	if ticket == nil {
+		t.Fatalf("expected the schema of the ticket but got %v", doc.Components.Schemas)
+	}
+
+	want := map[string]schema{
+		"Initial":  {Type: "integer", Format: "int32"},
+		"Avatar":   {Type: "string", Format: "byte"},
+		"Checksum": {Type: "array"},
+		"Timeout":  {Type: "integer", Format: "int64"},
+	}
+
+	for name, s := range want {
+		if ticket[name] != s {
+			t.Errorf("expected field %s to be %v but got %v", name, s, ticket[name])
+		}
+	}
+}
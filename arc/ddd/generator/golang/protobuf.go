@@ -0,0 +1,821 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/protobuf"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"strings"
+	"unicode"
+)
+
+// protoType describes the protobuf representation of a type declaration and how its values are converted.
+type protoType struct {
+	proto    string // the type of the field, e.g. int64 or google.protobuf.Timestamp.
+	imports  string // the proto file, which declares the type, if any.
+	repeated bool
+	optional bool
+	message  bool   // the type is a message, whose go field is nil-able.
+	enum     bool   // the type is an enum.
+	isMap    bool   // the type is a map.
+	domain   string // template source of the domain type.
+	goType   string // template source of the go type of the generated field.
+	key      string // identifies the type within the names of the conversion functions, e.g. SliceTicket.
+	toPb     string // format of the expression, which converts a domain value into a field value.
+	fromPb   string // format of the expression, which converts a field value into a domain value.
+	fails    bool   // if true, the fromPb expression returns also an error.
+}
+
+func (t protoType) to(expr string) string {
+	return fmt.Sprintf(t.toPb, expr)
+}
+
+func (t protoType) from(expr string) string {
+	return fmt.Sprintf(t.fromPb, expr)
+}
+
+// protoScalars are the stdlib types, which are represented by a scalar or a well known type.
+var protoScalars = map[string]protoType{
+	stdlib.String:  {proto: "string", goType: "string", key: "String", toPb: "%s", fromPb: "%s"},
+	stdlib.Bool:    {proto: "bool", goType: "bool", key: "Bool", toPb: "%s", fromPb: "%s"},
+	stdlib.Int:     {proto: "int64", goType: "int64", key: "Int", toPb: "int64(%s)", fromPb: "int(%s)"},
+	stdlib.Int64:   {proto: "int64", goType: "int64", key: "Int64", toPb: "%s", fromPb: "%s"},
+	stdlib.Int32:   {proto: "int32", goType: "int32", key: "Int32", toPb: "%s", fromPb: "%s"},
+	stdlib.Int16:   {proto: "int32", goType: "int32", key: "Int16", toPb: "int32(%s)", fromPb: "int16(%s)"},
+	stdlib.Byte:    {proto: "uint32", goType: "uint32", key: "Byte", toPb: "uint32(%s)", fromPb: "byte(%s)"},
+	stdlib.Rune:    {proto: "int32", goType: "int32", key: "Rune", toPb: "%s", fromPb: "%s"},
+	stdlib.Float32: {proto: "float", goType: "float32", key: "Float32", toPb: "%s", fromPb: "%s"},
+	stdlib.Float64: {proto: "double", goType: "float64", key: "Float64", toPb: "%s", fromPb: "%s"},
+	stdlib.UUID:    {proto: "string", goType: "string", key: "UUID", toPb: "%s.String()", fromPb: "fromPbUUID(%s)", fails: true},
+	stdlib.URL:     {proto: "string", goType: "string", key: "URL", toPb: "toPbURL(%s)", fromPb: "fromPbURL(%s)", fails: true},
+	stdlib.Time: {
+		proto:   "google.protobuf.Timestamp",
+		imports: "google/protobuf/timestamp.proto",
+		message: true,
+		goType:  `*{{.Use "google.golang.org/protobuf/types/known/timestamppb.Timestamp"}}`,
+		key:     "Time",
+		toPb:    `{{.Use "google.golang.org/protobuf/types/known/timestamppb.New"}}(%s)`,
+		fromPb:  "fromPbTime(%s)",
+	},
+	stdlib.Duration: {
+		proto:   "google.protobuf.Duration",
+		imports: "google/protobuf/duration.proto",
+		message: true,
+		goType:  `*{{.Use "google.golang.org/protobuf/types/known/durationpb.Duration"}}`,
+		key:     "Duration",
+		toPb:    `{{.Use "google.golang.org/protobuf/types/known/durationpb.New"}}(%s)`,
+		fromPb:  "%s.AsDuration()",
+	},
+}
+
+// protoScalarHelpers contains the conversion functions, which are referred to by protoScalars.
+var protoScalarHelpers = map[string]string{
+	"fromPbUUID": `// fromPbUUID parses the uuid. The empty string results in the zero uuid.
+		func fromPbUUID(v string) ({{.Use "github.com/golangee/uuid.UUID"}}, error) {
+			var res {{.Use "github.com/golangee/uuid.UUID"}}
+			if v == "" {
+				return res, nil
+			}
+
+			err := res.UnmarshalText([]byte(v))
+
+			return res, err
+		}
+
+		`,
+	"toPbURL": `// toPbURL formats the url.
+		func toPbURL(v {{.Use "net/url.URL"}}) string {
+			return v.String()
+		}
+
+		`,
+	"fromPbURL": `// fromPbURL parses the url.
+		func fromPbURL(v string) ({{.Use "net/url.URL"}}, error) {
+			res, err := {{.Use "net/url.Parse"}}(v)
+			if err != nil {
+				return {{.Use "net/url.URL"}}{}, err
+			}
+
+			return *res, nil
+		}
+
+		`,
+	"fromPbTime": `// fromPbTime returns the time of the timestamp. A nil timestamp results in the zero time.
+		func fromPbTime(v *{{.Use "google.golang.org/protobuf/types/known/timestamppb.Timestamp"}}) {{.Use "time.Time"}} {
+			if v == nil {
+				return {{.Use "time.Time"}}{}
+			}
+
+			return v.AsTime()
+		}
+
+		`,
+}
+
+// protoMapKeys are the scalar types, which protobuf accepts as map keys.
+var protoMapKeys = map[string]bool{"string": true, "bool": true, "int32": true, "int64": true, "uint32": true}
+
+// protoFile is the protobuf package of a bounded context together with the go sources, which convert between
+// the domain types and the messages generated by protoc.
+type protoFile struct {
+	bc      *adl.BoundedContext
+	file    *protobuf.File
+	pbPkg   string            // the path of the go package generated by protoc.
+	rpcs    []protoRpc        // in declaration order.
+	owners  map[string]string // full qualified domain name or rpc by message or enum name, to detect conflicts.
+	types   map[string]protoType
+	helpers []string          // names of the conversion functions in emission order.
+	sources map[string]string // template source of the conversion functions by name.
+}
+
+// protoRpc is an exposed method together with its request and response fields.
+type protoRpc struct {
+	service  exposedService
+	method   *adl.Method
+	request  []protoField
+	response []protoField
+}
+
+// protoField is a field of a generated message and the domain field or parameter, which it represents.
+type protoField struct {
+	domain string
+	field  *protobuf.Field
+	typ    protoType
+}
+
+// goName returns the name of the go field, which is generated by protoc-gen-go.
+func (f protoField) goName() string {
+	return goCamelCase(f.field.Name)
+}
+
+// protoSource is a field or parameter, which becomes a field of a message.
+type protoSource struct {
+	name    string
+	comment string
+	typ     *adl.TypeDecl
+}
+
+// protoBuilder creates the messages and enums of the bounded contexts on demand, while the rpcs are added.
+type protoBuilder struct {
+	src   *adl.Module
+	lock  *protobuf.Lock
+	decls map[string]declaredType
+}
+
+// grpcServices returns the use case services of the bounded contexts of the executable, which expose methods by
+// grpc. A command line tool serves no grpc.
+func grpcServices(src *adl.Module, executable *adl.Executable) []exposedService {
+	if executable.IsCli() {
+		return nil
+	}
+
+	return exposedServices(src, executable, func(method *adl.Method) bool {
+		return method.Grpc != nil
+	})
+}
+
+// buildProtoFiles creates a proto file for each bounded context with use case services, which are exposed by grpc.
+// The numbers of the fields are taken from the lock and new fields are registered in it.
+func buildProtoFiles(src *adl.Module, lock *protobuf.Lock) ([]*protoFile, error) {
+	var services []exposedService
+	for _, executable := range src.Executables {
+		for _, s := range grpcServices(src, executable) {
+			if !containsService(services, s) {
+				services = append(services, s)
+			}
+		}
+	}
+
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	if lock == nil {
+		return nil, fmt.Errorf("module exposes grpc methods but its protobuf lock '%s' has not been loaded", src.Generator.Go.ProtoLock.String())
+	}
+
+	b := &protoBuilder{src: src, lock: lock, decls: declaredTypes(src)}
+	var files []*protoFile
+	for _, bc := range src.BoundedContexts {
+		var bcServices []exposedService
+		for _, s := range services {
+			if s.bc == bc {
+				bcServices = append(bcServices, s)
+			}
+		}
+
+		if len(bcServices) == 0 {
+			continue
+		}
+
+		f, err := b.build(bc, bcServices)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build protobuf package of '%s': %w", bc.Name.String(), err)
+		}
+
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// protoPkgName returns the name of the protobuf package of the bounded context, e.g. tickets.
+func protoPkgName(bc *adl.BoundedContext) string {
+	return strings.ToLower(golang2.MakeIdentifier(bc.Name.String()))
+}
+
+// build creates the services of the bounded context and all messages and enums, which they refer to.
+func (b *protoBuilder) build(bc *adl.BoundedContext, services []exposedService) (*protoFile, error) {
+	name := protoPkgName(bc)
+	f := &protoFile{
+		bc:      bc,
+		pbPkg:   golang.MakePkgPath(golang.MakePkgPath(b.src.Generator.Go.Module.String()), pkgRpc, name+"pb"),
+		owners:  map[string]string{},
+		types:   map[string]protoType{},
+		sources: map[string]string{},
+	}
+
+	f.file = &protobuf.File{Preamble: makePreamble(b.src.Preamble), Package: name, GoPackage: f.pbPkg}
+
+	for _, s := range services {
+		service := &protobuf.Service{
+			Comment: describe(s.service.Component.Name.String(), s.service.Component.Comment.String()),
+			Name:    s.service.Component.Name.String(),
+		}
+
+		f.file.Services = append(f.file.Services, service)
+		for _, method := range s.service.Component.Methods {
+			if method.Grpc == nil {
+				continue
+			}
+
+			rpc, err := b.rpc(f, s, method)
+			if err != nil {
+				return nil, fmt.Errorf("cannot build rpc '%s': %w", method.Name.String(), err)
+			}
+
+			f.rpcs = append(f.rpcs, rpc)
+			service.Rpcs = append(service.Rpcs, &protobuf.Rpc{
+				Comment:  describe(method.Name.String(), method.Comment.String()),
+				Name:     method.Name.String(),
+				Request:  method.Name.String() + "Request",
+				Response: method.Name.String() + "Response",
+			})
+		}
+	}
+
+	return f, nil
+}
+
+// rpc creates the request and response messages of the method.
+func (b *protoBuilder) rpc(f *protoFile, s exposedService, method *adl.Method) (protoRpc, error) {
+	rpc := protoRpc{service: s, method: method}
+	owner := "rpc " + s.service.Component.Name.String() + "." + method.Name.String()
+
+	var in, out []protoSource
+	for _, param := range method.In {
+		if param.Type.Name.String() != "context.Context" {
+			in = append(in, protoSource{name: param.Name.String(), comment: param.Comment.String(), typ: param.Type})
+		}
+	}
+
+	for _, param := range method.Out {
+		if param.Type.Name.String() != stdlib.Error {
+			out = append(out, protoSource{name: param.Name.String(), comment: param.Comment.String(), typ: param.Type})
+		}
+	}
+
+	if len(out) == 1 && out[0].name == "" {
+		out[0].name = "result"
+	}
+
+	for _, msg := range []struct {
+		name    string
+		comment string
+		sources []protoSource
+		fields  *[]protoField
+	}{
+		{method.Name.String() + "Request", "...contains the parameters of " + method.Name.String() + ".", in, &rpc.request},
+		{method.Name.String() + "Response", "...contains the results of " + method.Name.String() + ".", out, &rpc.response},
+	} {
+		if err := f.declare(msg.name, owner); err != nil {
+			return rpc, err
+		}
+
+		m := &protobuf.Message{Comment: describe(msg.name, msg.comment), Name: msg.name}
+		f.file.Messages = append(f.file.Messages, m)
+		fields, err := b.fields(f, m, s.pkgPath, msg.sources)
+		if err != nil {
+			return rpc, err
+		}
+
+		*msg.fields = fields
+	}
+
+	return rpc, nil
+}
+
+// fields adds a field to the message for each source.
+func (b *protoBuilder) fields(f *protoFile, msg *protobuf.Message, pkgPath string, sources []protoSource) ([]protoField, error) {
+	key := f.file.Package + "." + msg.Name
+	var res []protoField
+	var names []string
+	declared := map[string]string{}
+	for _, src := range sources {
+		typ, err := b.typeOf(f, pkgPath, src.typ)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s' of '%s': %w", src.name, msg.Name, err)
+		}
+
+		name := snakeCase(src.name)
+		if other, ok := declared[name]; ok {
+			return nil, fmt.Errorf("field '%s' of '%s' conflicts with '%s'", src.name, msg.Name, other)
+		}
+
+		declared[name] = src.name
+		names = append(names, name)
+		field := &protobuf.Field{
+			Comment:  describe(src.name, src.comment),
+			Name:     name,
+			Type:     typ.proto,
+			Number:   b.lock.Number(key, name),
+			Repeated: typ.repeated,
+			Optional: typ.optional,
+		}
+
+		msg.Fields = append(msg.Fields, field)
+		res = append(res, protoField{domain: src.name, field: field, typ: typ})
+	}
+
+	msg.Reserved = b.lock.Retain(key, names)
+
+	return res, nil
+}
+
+// typeOf returns the protobuf representation of the type declaration and emits the required messages, enums and
+// conversion functions.
+func (b *protoBuilder) typeOf(f *protoFile, pkgPath string, t *adl.TypeDecl) (protoType, error) {
+	domain := useType(astutil.MakeTypeDecl(qualifyTypeDecl(pkgPath, t)))
+
+	if scalar, ok := protoScalars[t.Name.String()]; ok {
+		if scalar.imports != "" {
+			f.file.Import(scalar.imports)
+		}
+
+		for name, src := range protoScalarHelpers {
+			if strings.Contains(scalar.toPb+scalar.fromPb, name+"(") {
+				f.helper(name, src)
+			}
+		}
+
+		scalar.domain = domain
+
+		return scalar, nil
+	}
+
+	switch {
+	case t.IsPtr():
+		return b.ptrOf(f, pkgPath, domain, t.TypeParams[0])
+	case t.IsSlice() && t.TypeParams[0].Name.String() == stdlib.Byte:
+		return protoType{proto: "bytes", domain: domain, goType: "[]byte", key: "Bytes", toPb: "%s", fromPb: "%s"}, nil
+	case t.IsSlice() || t.Name.String() == stdlib.List && len(t.TypeParams) == 1:
+		return b.sliceOf(f, pkgPath, domain, t.TypeParams[0])
+	case t.IsMap():
+		return b.mapOf(f, pkgPath, domain, t.TypeParams[0], t.TypeParams[1])
+	case t.IsArray():
+		return protoType{}, fmt.Errorf("the array '%s' has no protobuf representation", t.String())
+	}
+
+	fqn := t.Name.String()
+	if idx := strings.LastIndex(fqn, "."); idx < 0 || strings.Contains(fqn[idx:], "/") {
+		fqn = pkgPath + "." + fqn
+	}
+
+	if typ, ok := f.types[fqn]; ok {
+		return typ, nil
+	}
+
+	decl, ok := b.decls[fqn]
+	if !ok || len(t.TypeParams) > 0 {
+		return protoType{}, fmt.Errorf("the type '%s' has no protobuf representation", t.String())
+	}
+
+	if decl.bc != f.bc {
+		return protoType{}, fmt.Errorf("the type '%s' belongs to another bounded context", t.String())
+	}
+
+	if err := f.declare(decl.name, fqn); err != nil {
+		return protoType{}, err
+	}
+
+	pb := `{{.Use "` + f.pbPkg + "." + decl.name + `"}}`
+	typ := protoType{
+		proto:   decl.name,
+		message: true,
+		domain:  domain,
+		goType:  "*" + pb,
+		key:     decl.name,
+		toPb:    "toPb" + decl.name + "(%s)",
+		fromPb:  "fromPb" + decl.name + "(%s)",
+		fails:   true,
+	}
+
+	if decl.enum != nil && !decl.enum.IsUnion() {
+		typ.message = false
+		typ.enum = true
+		typ.goType = pb
+	}
+
+	// register first, to terminate recursive declarations
+	f.types[fqn] = typ
+
+	switch {
+	case decl.enum == nil:
+		return typ, b.structMessage(f, decl.pkgPath, decl.name, describe(decl.name, decl.comment), domain, decl.fields)
+	case decl.enum.IsUnion():
+		return typ, b.unionMessage(f, decl, domain)
+	default:
+		return typ, b.enum(f, decl, domain)
+	}
+}
+
+// ptrOf represents a pointer to a message by the nil-able message and a pointer to a scalar by an optional field.
+func (b *protoBuilder) ptrOf(f *protoFile, pkgPath, domain string, elem *adl.TypeDecl) (protoType, error) {
+	e, err := b.typeOf(f, pkgPath, elem)
+	if err != nil {
+		return protoType{}, err
+	}
+
+	if e.repeated || e.optional || e.isMap {
+		return protoType{}, fmt.Errorf("the pointer to '%s' has no protobuf representation", elem.String())
+	}
+
+	typ := protoType{
+		proto:    e.proto,
+		message:  e.message,
+		enum:     e.enum,
+		optional: !e.message,
+		domain:   domain,
+		goType:   e.goType,
+		key:      "Ptr" + e.key,
+		toPb:     "toPbPtr" + e.key + "(%s)",
+		fromPb:   "fromPbPtr" + e.key + "(%s)",
+		fails:    true,
+	}
+
+	value, to := "v", "return "+e.to("*v")+"\n"
+	if typ.optional {
+		typ.goType = "*" + e.goType
+		value, to = "*v", "res := "+e.to("*v")+"\n\nreturn &res\n"
+	}
+
+	f.helper("toPb"+typ.key, "// toPb"+typ.key+" converts the pointer. Nil is retained.\n"+
+		"func toPb"+typ.key+"(v "+domain+") "+typ.goType+" {\nif v == nil {\nreturn nil\n}\n\n"+to+"}\n\n")
+
+	f.helper("fromPb"+typ.key, "// fromPb"+typ.key+" converts the pointer. Nil is retained.\n"+
+		"func fromPb"+typ.key+"(v "+typ.goType+") ("+domain+", error) {\nif v == nil {\nreturn nil, nil\n}\n\n"+
+		decode(e, "res", value, "return nil, err")+"\nreturn &res, nil\n}\n\n")
+
+	return typ, nil
+}
+
+// sliceOf represents a slice by a repeated field.
+func (b *protoBuilder) sliceOf(f *protoFile, pkgPath, domain string, elem *adl.TypeDecl) (protoType, error) {
+	e, err := b.typeOf(f, pkgPath, elem)
+	if err != nil {
+		return protoType{}, err
+	}
+
+	if e.repeated || e.optional || e.isMap {
+		return protoType{}, fmt.Errorf("the slice of '%s' has no protobuf representation", elem.String())
+	}
+
+	typ := protoType{
+		proto:    e.proto,
+		repeated: true,
+		domain:   domain,
+		goType:   "[]" + e.goType,
+		key:      "Slice" + e.key,
+		toPb:     "toPbSlice" + e.key + "(%s)",
+		fromPb:   "fromPbSlice" + e.key + "(%s)",
+		fails:    true,
+	}
+
+	f.helper("toPb"+typ.key, "// toPb"+typ.key+" converts each element.\n"+
+		"func toPb"+typ.key+"(v "+domain+") "+typ.goType+" {\nif v == nil {\nreturn nil\n}\n\n"+
+		"res := make("+typ.goType+", 0, len(v))\nfor _, e := range v {\nres = append(res, "+e.to("e")+")\n}\n\nreturn res\n}\n\n")
+
+	loop := "for _, e := range v {\nres = append(res, " + e.from("e") + ")\n}\n\n"
+	if e.fails {
+		loop = "for i, e := range v {\nr, err := " + e.from("e") + "\nif err != nil {\n" +
+			"return nil, {{.Use \"fmt.Errorf\"}}(\"invalid element %d: %w\", i, err)\n}\n\nres = append(res, r)\n}\n\n"
+	}
+
+	f.helper("fromPb"+typ.key, "// fromPb"+typ.key+" converts each element.\n"+
+		"func fromPb"+typ.key+"(v "+typ.goType+") ("+domain+", error) {\nif v == nil {\nreturn nil, nil\n}\n\n"+
+		"res := make("+domain+", 0, len(v))\n"+loop+"return res, nil\n}\n\n")
+
+	return typ, nil
+}
+
+// mapOf represents a map by a map field, whose keys must be strings, integers or booleans.
+func (b *protoBuilder) mapOf(f *protoFile, pkgPath, domain string, key, value *adl.TypeDecl) (protoType, error) {
+	k, err := b.typeOf(f, pkgPath, key)
+	if err != nil {
+		return protoType{}, err
+	}
+
+	v, err := b.typeOf(f, pkgPath, value)
+	if err != nil {
+		return protoType{}, err
+	}
+
+	if k.enum || !protoMapKeys[k.proto] {
+		return protoType{}, fmt.Errorf("the map key '%s' has no protobuf representation", key.String())
+	}
+
+	if v.repeated || v.optional || v.isMap {
+		return protoType{}, fmt.Errorf("the map value '%s' has no protobuf representation", value.String())
+	}
+
+	typ := protoType{
+		proto:  "map<" + k.proto + ", " + v.proto + ">",
+		isMap:  true,
+		domain: domain,
+		goType: "map[" + k.goType + "]" + v.goType,
+		key:    "Map" + k.key + v.key,
+		fails:  true,
+	}
+
+	typ.toPb = "toPb" + typ.key + "(%s)"
+	typ.fromPb = "fromPb" + typ.key + "(%s)"
+
+	f.helper("toPb"+typ.key, "// toPb"+typ.key+" converts each key and value.\n"+
+		"func toPb"+typ.key+"(v "+domain+") "+typ.goType+" {\nif v == nil {\nreturn nil\n}\n\n"+
+		"res := make("+typ.goType+", len(v))\nfor key, value := range v {\nres["+k.to("key")+"] = "+v.to("value")+"\n}\n\nreturn res\n}\n\n")
+
+	f.helper("fromPb"+typ.key, "// fromPb"+typ.key+" converts each key and value.\n"+
+		"func fromPb"+typ.key+"(v "+typ.goType+") ("+domain+", error) {\nif v == nil {\nreturn nil, nil\n}\n\n"+
+		"res := make("+domain+", len(v))\nfor key, value := range v {\n"+
+		decode(k, "k", "key", "return nil, {{.Use \"fmt.Errorf\"}}(\"invalid key %v: %w\", key, err)")+
+		decode(v, "e", "value", "return nil, {{.Use \"fmt.Errorf\"}}(\"invalid value of %v: %w\", key, err)")+
+		"res[k] = e\n}\n\nreturn res, nil\n}\n\n")
+
+	return typ, nil
+}
+
+// structMessage creates the message of a struct and its conversion functions. Private fields are ignored.
+func (b *protoBuilder) structMessage(f *protoFile, pkgPath, name, comment, domain string, fields []*adl.Field) error {
+	msg := &protobuf.Message{Comment: comment, Name: name}
+	f.file.Messages = append(f.file.Messages, msg)
+
+	var sources []protoSource
+	for _, field := range fields {
+		if !field.Private {
+			sources = append(sources, protoSource{name: field.Name.String(), comment: field.Comment.String(), typ: field.Type})
+		}
+	}
+
+	res, err := b.fields(f, msg, pkgPath, sources)
+	if err != nil {
+		return err
+	}
+
+	pb := `{{.Use "` + f.pbPkg + "." + name + `"}}`
+	var to, from strings.Builder
+	to.WriteString("// toPb" + name + " converts the " + name + " into its message.\n")
+	to.WriteString("func toPb" + name + "(v " + domain + ") *" + pb + " {\nreturn &" + pb + "{\n")
+	from.WriteString("// fromPb" + name + " converts the message into a " + name + ". A nil message results in the zero value.\n")
+	from.WriteString("func fromPb" + name + "(v *" + pb + ") (" + domain + ", error) {\nvar res " + domain + "\nif v == nil {\nreturn res, nil\n}\n\n")
+	fails := false
+	for _, field := range res {
+		to.WriteString(field.goName() + ": " + field.typ.to("v."+field.domain) + ",\n")
+		if !field.typ.fails {
+			from.WriteString("res." + field.domain + " = " + field.typ.from("v."+field.goName()) + "\n")
+			continue
+		}
+
+		if !fails {
+			fails = true
+			from.WriteString("var err error\n")
+		}
+
+		from.WriteString("if res." + field.domain + ", err = " + field.typ.from("v."+field.goName()) + "; err != nil {\n" +
+			"return res, {{.Use \"fmt.Errorf\"}}(\"invalid field '" + field.field.Name + "': %w\", err)\n}\n\n")
+	}
+
+	to.WriteString("}\n}\n\n")
+	from.WriteString("\nreturn res, nil\n}\n\n")
+	f.helper("toPb"+name, to.String())
+	f.helper("fromPb"+name, from.String())
+
+	return nil
+}
+
+// unionMessage creates a message with a oneof field of the case messages of a tagged union.
+func (b *protoBuilder) unionMessage(f *protoFile, decl declaredType, domain string) error {
+	name := decl.name
+	key := f.file.Package + "." + name
+	oneof := &protobuf.Oneof{Name: "kind"}
+	msg := &protobuf.Message{Comment: describe(name, decl.comment), Name: name, Oneofs: []*protobuf.Oneof{oneof}}
+	f.file.Messages = append(f.file.Messages, msg)
+
+	pb := `{{.Use "` + f.pbPkg + "." + name + `"}}`
+	var to, from strings.Builder
+	to.WriteString("// toPb" + name + " converts the case of the " + name + " into its message.\n")
+	to.WriteString("func toPb" + name + "(v " + domain + ") *" + pb + " {\nswitch v := v.(type) {\n")
+	from.WriteString("// fromPb" + name + " converts the message into the case of the " + name + ". An unset case results in nil.\n")
+	from.WriteString("func fromPb" + name + "(v *" + pb + ") (" + domain + ", error) {\nswitch k := v.GetKind().(type) {\n")
+
+	var names []string
+	for _, c := range decl.enum.Cases {
+		caseName := name + golang.MakePublic(c.Name.String())
+		caseFqn := decl.pkgPath + "." + caseName
+		if err := f.declare(caseName, caseFqn); err != nil {
+			return err
+		}
+
+		caseDomain := `{{.Use "` + caseFqn + `"}}`
+		if err := b.structMessage(f, decl.pkgPath, caseName, describe(caseName, c.Comment.String()), caseDomain, c.Fields); err != nil {
+			return err
+		}
+
+		field := &protobuf.Field{
+			Comment: describe(c.Name.String(), c.Comment.String()),
+			Name:    snakeCase(c.Name.String()),
+			Type:    caseName,
+			Number:  b.lock.Number(key, snakeCase(c.Name.String())),
+		}
+
+		names = append(names, field.Name)
+		oneof.Fields = append(oneof.Fields, field)
+
+		wrapper := `{{.Use "` + f.pbPkg + "." + name + "_" + goCamelCase(field.Name) + `"}}`
+		to.WriteString("case " + caseDomain + ":\nreturn &" + pb + "{Kind: &" + wrapper + "{" + goCamelCase(field.Name) + ": toPb" + caseName + "(v)}}\n")
+		from.WriteString("case *" + wrapper + ":\nreturn fromPb" + caseName + "(k." + goCamelCase(field.Name) + ")\n")
+	}
+
+	msg.Reserved = b.lock.Retain(key, names)
+
+	to.WriteString("default:\nreturn nil\n}\n}\n\n")
+	from.WriteString("default:\nreturn nil, nil\n}\n}\n\n")
+	f.helper("toPb"+name, to.String())
+	f.helper("fromPb"+name, from.String())
+
+	return nil
+}
+
+// enum creates the enum of the cases, whose zero value is unspecified and decoded as the first case.
+func (b *protoBuilder) enum(f *protoFile, decl declaredType, domain string) error {
+	name := decl.name
+	key := f.file.Package + "." + name
+	prefix := strings.ToUpper(snakeCase(name)) + "_"
+	enum := &protobuf.Enum{
+		Comment: describe(name, decl.comment),
+		Name:    name,
+		Values:  []*protobuf.EnumValue{{Comment: "The zero value is unspecified and decoded as the first case.", Name: prefix + "UNSPECIFIED"}},
+	}
+
+	f.file.Enums = append(f.file.Enums, enum)
+
+	pb := `{{.Use "` + f.pbPkg + "." + name + `"}}`
+	var to, from strings.Builder
+	to.WriteString("// toPb" + name + " converts the " + name + " into its enum value.\n")
+	to.WriteString("func toPb" + name + "(v " + domain + ") " + pb + " {\nswitch v {\n")
+	from.WriteString("// fromPb" + name + " converts the enum value into a " + name + ".\n")
+	from.WriteString("func fromPb" + name + "(v " + pb + ") (" + domain + ", error) {\nswitch v {\n")
+	from.WriteString("case {{.Use \"" + f.pbPkg + "." + name + "_" + prefix + "UNSPECIFIED\"}}:\nreturn 0, nil\n")
+
+	var names []string
+	for _, c := range decl.enum.Cases {
+		value := &protobuf.EnumValue{
+			Comment: describe(c.Name.String(), c.Comment.String()),
+			Name:    prefix + strings.ToUpper(snakeCase(c.Name.String())),
+		}
+
+		value.Number = b.lock.Number(key, value.Name)
+		names = append(names, value.Name)
+		enum.Values = append(enum.Values, value)
+
+		pbValue := `{{.Use "` + f.pbPkg + "." + name + "_" + value.Name + `"}}`
+		domainValue := `{{.Use "` + decl.pkgPath + "." + name + golang.MakePublic(c.Name.String()) + `"}}`
+		to.WriteString("case " + domainValue + ":\nreturn " + pbValue + "\n")
+		from.WriteString("case " + pbValue + ":\nreturn " + domainValue + ", nil\n")
+	}
+
+	enum.Reserved = b.lock.Retain(key, names)
+
+	to.WriteString("default:\nreturn " + `{{.Use "` + f.pbPkg + "." + name + "_" + prefix + `UNSPECIFIED"}}` + "\n}\n}\n\n")
+	from.WriteString("default:\nreturn 0, {{.Use \"fmt.Errorf\"}}(\"unknown value %d\", v)\n}\n}\n\n")
+	f.helper("toPb"+name, to.String())
+	f.helper("fromPb"+name, from.String())
+
+	return nil
+}
+
+// declare registers the name of a message or enum. Distinct owners of the same name are a conflict.
+func (f *protoFile) declare(name, owner string) error {
+	if other, ok := f.owners[name]; ok && other != owner {
+		return fmt.Errorf("the protobuf name '%s' of '%s' conflicts with '%s'", name, owner, other)
+	}
+
+	f.owners[name] = owner
+
+	return nil
+}
+
+// helper emits the source of the named conversion function once.
+func (f *protoFile) helper(name, src string) {
+	if _, ok := f.sources[name]; ok {
+		return
+	}
+
+	f.helpers = append(f.helpers, name)
+	f.sources[name] = src
+}
+
+// convert returns the template source of the conversion functions, which are referred to by the given source,
+// either directly or by other conversion functions. Unused functions are omitted.
+func (f *protoFile) convert(src string) string {
+	used := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, name := range f.helpers {
+			if used[name] || !strings.Contains(src, name+"(") {
+				continue
+			}
+
+			used[name] = true
+			changed = true
+			src += f.sources[name]
+		}
+	}
+
+	var res strings.Builder
+	for _, name := range f.helpers {
+		if used[name] {
+			res.WriteString(f.sources[name])
+		}
+	}
+
+	return res.String()
+}
+
+// decode returns the statements, which declare the variable as the domain value of the expression.
+func decode(t protoType, name, expr, onErr string) string {
+	if !t.fails {
+		return name + " := " + t.from(expr) + "\n"
+	}
+
+	return name + ", err := " + t.from(expr) + "\nif err != nil {\n" + onErr + "\n}\n\n"
+}
+
+// snakeCase converts the go identifier into a field name of the protobuf style guide, e.g. ParentID into
+// parent_id.
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var tmp strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			tmp.WriteByte('_')
+		}
+
+		tmp.WriteRune(unicode.ToLower(r))
+	}
+
+	return tmp.String()
+}
+
+// goCamelCase returns the go name of a protobuf field, just like protoc-gen-go does.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLowerASCII(s[i+1]):
+			// the next letter becomes upper case
+		case c >= '0' && c <= '9':
+			b = append(b, c)
+		default:
+			if isLowerASCII(c) {
+				c -= 'a' - 'A'
+			}
+
+			b = append(b, c)
+			for ; i+1 < len(s) && isLowerASCII(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+
+	return string(b)
+}
+
+func isLowerASCII(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
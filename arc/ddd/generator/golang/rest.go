@@ -0,0 +1,545 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+const (
+	pkgRest        = "internal/rest"
+	restConfigType = "Config"
+)
+
+// renderRest emits the package which contains the router and the json encoding helpers of the generated http
+// handlers, if any executable exposes a use case service method.
+func renderRest(dst *ast.Mod, src *adl.Module) error {
+	exposed := false
+	for _, executable := range src.Executables {
+		if len(httpServices(src, executable)) > 0 {
+			exposed = true
+			break
+		}
+	}
+
+	if !exposed {
+		return nil
+	}
+
+	rest := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgRest))
+	rest.SetPreamble(makePreamble(src.Preamble)).
+		SetComment("...provides the router and the json encoding of the generated http driver adapters.")
+
+	rest.AddFiles(
+		ast.NewFile("rest.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					// Params contains the values of the path variables of a matched route.
+					type Params map[string]string
+
+					// HandlerFunc handles a request, whose path has been matched by the Router.
+					type HandlerFunc func(w {{.Use "net/http.ResponseWriter"}}, r *{{.Use "net/http.Request"}}, params Params)
+
+					type route struct {
+						method   string
+						segments []string
+						handler  HandlerFunc
+					}
+
+					// Router dispatches requests by their method and path template. Each {name} segment of a template
+					// binds the according segment of the request path.
+					type Router struct {
+						routes []route
+					}
+
+					// NewRouter creates an empty Router.
+					func NewRouter() *Router {
+						return &Router{}
+					}
+
+					// Handle registers the handler for the given method and path template, e.g. /tickets/{id}.
+					func (t *Router) Handle(method, pattern string, handler HandlerFunc) {
+						t.routes = append(t.routes, route{method: method, segments: split(pattern), handler: handler})
+					}
+
+					// ServeHTTP dispatches the request to the first matching route.
+					func (t *Router) ServeHTTP(w {{.Use "net/http.ResponseWriter"}}, r *{{.Use "net/http.Request"}}) {
+						segments := split(r.URL.Path)
+						allowed := false
+						for _, route := range t.routes {
+							params, ok := match(route.segments, segments)
+							if !ok {
+								continue
+							}
+
+							if route.method != r.Method {
+								allowed = true
+								continue
+							}
+
+							route.handler(w, r, params)
+							return
+						}
+
+						if allowed {
+							WriteError(w, Error{Status: {{.Use "net/http.StatusMethodNotAllowed"}}, Message: "method not allowed"})
+							return
+						}
+
+						WriteError(w, Error{Status: {{.Use "net/http.StatusNotFound"}}, Message: "not found"})
+					}
+
+					func split(path string) []string {
+						return {{.Use "strings.Split"}}({{.Use "strings.Trim"}}(path, "/"), "/")
+					}
+
+					func match(pattern, segments []string) (Params, bool) {
+						if len(pattern) != len(segments) {
+							return nil, false
+						}
+
+						params := Params{}
+						for i, p := range pattern {
+							if {{.Use "strings.HasPrefix"}}(p, "{") && {{.Use "strings.HasSuffix"}}(p, "}") {
+								value, err := {{.Use "net/url.PathUnescape"}}(segments[i])
+								if err != nil {
+									return nil, false
+								}
+
+								params[p[1:len(p)-1]] = value
+								continue
+							}
+
+							if p != segments[i] {
+								return nil, false
+							}
+						}
+
+						return params, true
+					}
+
+					// Error is the json body of each failed request.
+					type Error struct {
+						// Status is the http status code.
+						Status int ` + "`json:\"status\"`" + `
+						// Case is the name of the error case of a bounded context, if any.
+						Case string ` + "`json:\"case,omitempty\"`" + `
+						// Message describes the error.
+						Message string ` + "`json:\"message\"`" + `
+						// Properties contains the values of the error case properties, if any.
+						Properties map[string]interface{} ` + "`json:\"properties,omitempty\"`" + `
+					}
+
+					// Error returns the message.
+					func (e Error) Error() string {
+						return e.Message
+					}
+
+					// WriteJSON encodes the value with the given status.
+					func WriteJSON(w {{.Use "net/http.ResponseWriter"}}, status int, v interface{}) {
+						w.Header().Set("Content-Type", "application/json")
+						w.WriteHeader(status)
+						_ = {{.Use "encoding/json.NewEncoder"}}(w).Encode(v) // the client has gone, if this fails
+					}
+
+					// WriteError encodes the error with its status.
+					func WriteError(w {{.Use "net/http.ResponseWriter"}}, e Error) {
+						WriteJSON(w, e.Status, e)
+					}
+
+					// ReadJSON decodes the request body into the value.
+					func ReadJSON(r *{{.Use "net/http.Request"}}, v interface{}) error {
+						defer r.Body.Close() // intentionally ignoring read-only error on close
+
+						if err := {{.Use "encoding/json.NewDecoder"}}(r.Body).Decode(v); err != nil {
+							return {{.Use "fmt.Errorf"}}("cannot decode json body: %w", err)
+						}
+
+						return nil
+					}
+`),
+			),
+	)
+
+	file := ast.NewFile("config.go").SetPreamble(makePreamble(src.Preamble))
+	rest.AddFiles(file)
+
+	cfg := ast.NewStruct(restConfigType).
+		SetComment("...contains the options of the http server.").
+		AddFields(
+			ast.NewField("Address", ast.NewSimpleTypeDecl(stdlib.String)).
+				SetComment("...is the host and port to listen at.").
+				SetDefault(ast.NewStrLit(":8080")),
+			ast.NewField("ReadTimeout", ast.NewSimpleTypeDecl(stdlib.Duration)).
+				SetComment("...is the maximum duration for reading an entire request.").
+				SetDefault(ast.NewIdentLit("30s")),
+			ast.NewField("WriteTimeout", ast.NewSimpleTypeDecl(stdlib.Duration)).
+				SetComment("...is the maximum duration for writing an entire response.").
+				SetDefault(ast.NewIdentLit("30s")),
+			ast.NewField("ShutdownTimeout", ast.NewSimpleTypeDecl(stdlib.Duration)).
+				SetComment("...is the maximum duration to complete pending requests at shutdown.").
+				SetDefault(ast.NewIdentLit("10s")),
+		)
+
+	for _, field := range cfg.Fields() {
+		stereotype.FieldFrom(field).SetProgramFlag(true)
+	}
+
+	file.AddNodes(cfg) // add it early, functions may need contextual information like package path
+	cfg.DefaultRecName = "c"
+
+	if _, err := golang.AddResetFunc(cfg); err != nil {
+		return fmt.Errorf("unable to add reset func: %w", err)
+	}
+
+	if _, err := golang.AddParseEnvFunc("http", cfg); err != nil {
+		return fmt.Errorf("unable to add parse-env func: %w", err)
+	}
+
+	if _, err := golang.AddParseFlagFunc("http", cfg); err != nil {
+		return fmt.Errorf("unable to add parse-flag func: %w", err)
+	}
+
+	return nil
+}
+
+// exposedService is a use case service of a bounded context, which exposes at least one method by http or grpc.
+type exposedService struct {
+	bc      *adl.BoundedContext
+	pkgPath string // the full qualified path of the declaring package.
+	service *adl.Service
+}
+
+// httpServices returns the exposed use case services of the bounded contexts of the executable. A command line
+// tool serves no http.
+func httpServices(src *adl.Module, executable *adl.Executable) []exposedService {
+	if executable.IsCli() {
+		return nil
+	}
+
+	return exposedServices(src, executable, func(method *adl.Method) bool {
+		return method.Http != nil
+	})
+}
+
+// exposedServices returns the use case services of the bounded contexts of the executable, which expose at least
+// a single method.
+func exposedServices(src *adl.Module, executable *adl.Executable, exposes func(method *adl.Method) bool) []exposedService {
+	var res []exposedService
+	for _, bc := range executableContexts(src, executable) {
+		for _, p := range bc.Usecase {
+			for _, service := range p.Services {
+				for _, method := range service.Component.Methods {
+					if exposes(method) {
+						res = append(res, exposedService{bc: bc, pkgPath: bcPkgPath(bc, pkgUsecase, p), service: service})
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// makeHttpHandler declares the getter of the http router, which dispatches the requests to the exposed methods of
+// the services.
+func makeHttpHandler(app *ast.Struct, src *adl.Module, services []exposedService) error {
+	body := "router := {{.Use \"" + golang.MakePkgPath(astutil.Mod(app).Name, pkgRest) + ".NewRouter\"}}()\n"
+	for _, s := range services {
+		getter := "get" + golang.GlobalFlatName2(ast.NewSimpleTypeDecl(ast.Name(s.pkgPath+"."+s.service.Component.Name.String())))
+		if astutil.MethodByName(app, getter) == nil {
+			return fmt.Errorf("service '%s' has no getter", s.service.Component.Name.String())
+		}
+
+		varName := golang.MakePrivate(getter[len("get"):])
+		body += "\n" + varName + ", err := {{.Get \"rec\"}}.self." + getter + "()\n" +
+			"if err != nil {\nreturn nil, {{.Use \"fmt.Errorf\"}}(\"cannot get service '" + s.service.Component.Name.String() + "': %w\", err)\n}\n\n"
+
+		for _, method := range s.service.Component.Methods {
+			if method.Http == nil {
+				continue
+			}
+
+			handler, err := httpHandlerFunc(src, s, varName, method)
+			if err != nil {
+				return fmt.Errorf("cannot create handler of '%s': %w", method.Name.String(), err)
+			}
+
+			body += "// " + golang2.DeEllipsis(method.Name.String(), method.Comment.String()) + "\n"
+			body += "router.Handle(" + strconv.Quote(method.Http.Verb.String()) + ", " + strconv.Quote(method.Http.Path.String()) + ", " + handler + ")\n\n"
+		}
+	}
+
+	app.AddMethods(ast.NewFunc("getHttpHandler").
+		SetComment("...returns the router of all exposed use case service methods.\nShadow this method at the Application to install a middleware.").
+		SetVisibility(ast.Private).
+		SetRecName(app.DefaultRecName).
+		SetPtrReceiver(true).
+		AddResults(
+			ast.NewParam("", ast.NewSimpleTypeDecl("net/http.Handler")),
+			ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error)),
+		).
+		SetBody(ast.NewBlock(ast.NewTpl(body+"return router, nil\n").Put("rec", app.DefaultRecName))))
+
+	return nil
+}
+
+// httpHandlerFunc returns the source of a rest.HandlerFunc, which decodes the request into the method parameters,
+// invokes the service method and encodes either the results or the error.
+func httpHandlerFunc(src *adl.Module, s exposedService, service string, method *adl.Method) (string, error) {
+	restPkg := golang.MakePkgPath(src.Generator.Go.Module.String(), pkgRest)
+	e := method.Http
+	vars, err := e.PathVars()
+	if err != nil {
+		return "", err
+	}
+
+	badRequest := func(what, name string) string {
+		return "{{.Use \"" + restPkg + ".WriteError\"}}(w, {{.Use \"" + restPkg + ".Error\"}}{Status: {{.Use \"net/http.StatusBadRequest\"}}, Message: \"invalid " + what + " '" + name + "': \" + err.Error()})\nreturn\n"
+	}
+
+	fun := "func(w {{.Use \"net/http.ResponseWriter\"}}, r *{{.Use \"net/http.Request\"}}, params {{.Use \"" + restPkg + ".Params\"}}) {\n"
+	var args []string
+	for _, param := range method.In {
+		name := golang.MakePrivate(param.Name.String())
+		if param.Type.Name.String() == "context.Context" {
+			args = append(args, "r.Context()")
+			continue
+		}
+
+		args = append(args, name)
+		fun += "var " + name + " " + useType(astutil.MakeTypeDecl(qualifyTypeDecl(s.pkgPath, param.Type))) + "\n"
+
+		switch {
+		case containsName(vars, param.Name.String()):
+			fun += parseValue(param.Type, name, "params["+strconv.Quote(param.Name.String())+"]", badRequest("path parameter", param.Name.String()), false)
+		case containsName(e.Query, param.Name.String()):
+			fun += "if value := r.URL.Query().Get(" + strconv.Quote(param.Name.String()) + "); value != \"\" {\n" +
+				parseValue(param.Type, name, "value", badRequest("query parameter", param.Name.String()), true) +
+				"}\n"
+		case e.Body.String() == param.Name.String():
+			fun += "if err := {{.Use \"" + restPkg + ".ReadJSON\"}}(r, &" + name + "); err != nil {\n" + badRequest("body", param.Name.String()) + "}\n"
+		}
+
+		fun += "\n"
+	}
+
+	var results []string
+	var resultNames []string
+	errIdx := -1
+	for i, param := range method.Out {
+		if param.Type.Name.String() == stdlib.Error {
+			results = append(results, "err")
+			errIdx = i
+			continue
+		}
+
+		resultNames = append(resultNames, param.Name.String())
+		results = append(results, "res"+strconv.Itoa(len(resultNames)-1))
+	}
+
+	call := service + "." + method.Name.String() + "(" + strings.Join(args, ", ") + ")"
+	if len(results) > 0 {
+		call = strings.Join(results, ", ") + " := " + call
+	}
+
+	fun += call + "\n"
+
+	if errIdx >= 0 {
+		fun += "if err != nil {\n"
+		for _, decl := range method.Errors {
+			anErr, pkgPath := findError(s, decl)
+			if anErr == nil {
+				return "", fmt.Errorf("error '%s' is not declared", decl.Name.String())
+			}
+
+			props := ""
+			for _, field := range anErr.Fields {
+				getter := golang2.MakePublic(golang2.MakePrivate(field.Name.String()))
+				props += strconv.Quote(getter) + ": e." + getter + "(),"
+			}
+
+			if props != "" {
+				props = ", Properties: map[string]interface{}{" + props + "}"
+			}
+
+			fun += "if e := {{.Use \"" + pkgPath + ".As" + errorCaseName(s.bc.Name.String(), anErr.Name.String()) + "\"}}(err); e != nil {\n" +
+				"{{.Use \"" + restPkg + ".WriteError\"}}(w, {{.Use \"" + restPkg + ".Error\"}}{Status: " + strconv.Itoa(e.StatusOf(decl.Name.String())) + ", Case: " + strconv.Quote(anErr.Name.String()) + ", Message: e.Error()" + props + "})\n" +
+				"return\n}\n\n"
+		}
+
+		fun += "{{.Use \"" + restPkg + ".WriteError\"}}(w, {{.Use \"" + restPkg + ".Error\"}}{Status: {{.Use \"net/http.StatusInternalServerError\"}}, Message: {{.Use \"net/http.StatusText\"}}({{.Use \"net/http.StatusInternalServerError\"}})})\n" +
+			"return\n}\n\n"
+	}
+
+	switch len(resultNames) {
+	case 0:
+		fun += "w.WriteHeader({{.Use \"net/http.StatusNoContent\"}})\n"
+	case 1:
+		fun += "{{.Use \"" + restPkg + ".WriteJSON\"}}(w, {{.Use \"net/http.StatusOK\"}}, res0)\n"
+	default:
+		fun += "{{.Use \"" + restPkg + ".WriteJSON\"}}(w, {{.Use \"net/http.StatusOK\"}}, map[string]interface{}{\n"
+		for i, name := range resultNames {
+			fun += strconv.Quote(name) + ": res" + strconv.Itoa(i) + ",\n"
+		}
+
+		fun += "})\n"
+	}
+
+	return fun + "}", nil
+}
+
+// parseValue returns the source which parses the string expression into the declared variable. The temporary
+// variables are declared in a block of their own, unless the source is already scoped, so that the values of
+// multiple parameters can be parsed by the same handler.
+func parseValue(t *adl.TypeDecl, dst, value, onErr string, scoped bool) string {
+	var parse string
+	switch t.Name.String() {
+	case stdlib.String:
+		return dst + " = " + value + "\n"
+	case stdlib.Bool:
+		parse = "{{.Use \"strconv.ParseBool\"}}(" + value + ")"
+	case stdlib.Int:
+		parse = "{{.Use \"strconv.Atoi\"}}(" + value + ")"
+	case stdlib.Int16:
+		parse = "{{.Use \"strconv.ParseInt\"}}(" + value + ", 10, 16)"
+	case stdlib.Int32:
+		parse = "{{.Use \"strconv.ParseInt\"}}(" + value + ", 10, 32)"
+	case stdlib.Int64:
+		parse = "{{.Use \"strconv.ParseInt\"}}(" + value + ", 10, 64)"
+	case stdlib.Float32:
+		parse = "{{.Use \"strconv.ParseFloat\"}}(" + value + ", 32)"
+	case stdlib.Float64:
+		parse = "{{.Use \"strconv.ParseFloat\"}}(" + value + ", 64)"
+	case stdlib.Duration:
+		parse = "{{.Use \"time.ParseDuration\"}}(" + value + ")"
+	default:
+		// uuids, times, enums and anything else which can be unmarshalled from text
+		return "if err := " + dst + ".UnmarshalText([]byte(" + value + ")); err != nil {\n" + onErr + "}\n"
+	}
+
+	src := "parsed, err := " + parse + "\nif err != nil {\n" + onErr + "}\n\n" + dst + " = " + useType(astutil.MakeTypeDecl(t)) + "(parsed)\n"
+	if scoped {
+		return src
+	}
+
+	return "{\n" + src + "}\n"
+}
+
+// useType returns the template source of the type declaration, which imports all used packages.
+func useType(t ast.TypeDecl) string {
+	switch t := t.(type) {
+	case *ast.SimpleTypeDecl:
+		return "{{.Use \"" + string(t.SimpleName) + "\"}}"
+	case *ast.TypeDeclPtr:
+		return "*" + useType(t.Decl)
+	case *ast.SliceTypeDecl:
+		return "[]" + useType(t.TypeDecl)
+	case *ast.ArrayTypeDecl:
+		return "[" + strconv.Itoa(t.ArrayLen) + "]" + useType(t.ArrayTypeDecl)
+	case *ast.GenericTypeDecl:
+		if t.TypeDecl.String() == stdlib.Map {
+			return "map[" + useType(t.TypeParams[0]) + "]" + useType(t.TypeParams[1])
+		}
+
+		if t.TypeDecl.String() == stdlib.List {
+			return "[]" + useType(t.TypeParams[0])
+		}
+
+		return useType(t.TypeDecl)
+	default:
+		panic(fmt.Sprintf("unsupported type declaration: %T", t))
+	}
+}
+
+// findError resolves the declared error case of the exposed method and returns it together with the path of its
+// declaring package.
+func findError(s exposedService, decl *adl.TypeDecl) (*adl.Error, string) {
+	pkgPath := s.pkgPath
+	name := decl.Name.String()
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		pkgPath = golang.MakePkgPath(name[:idx])
+		name = name[idx+1:]
+	}
+
+	for _, layer := range []struct {
+		name string
+		pkgs []*adl.Package
+	}{{pkgCore, s.bc.Core}, {pkgUsecase, s.bc.Usecase}} {
+		for _, p := range layer.pkgs {
+			if bcPkgPath(s.bc, layer.name, p) != pkgPath {
+				continue
+			}
+
+			for _, anErr := range p.AllErrors() {
+				if anErr.Name.String() == name {
+					return anErr, pkgPath
+				}
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+// bcPkgPath returns the full qualified path of the package within the layer of the bounded context, as declared
+// by renderUserTypes.
+func bcPkgPath(bc *adl.BoundedContext, layer string, p *adl.Package) string {
+	path := golang.MakePkgPath(golang.MakePkgPath(bc.Path.String()), layer)
+	if p.Name.String() != "" {
+		path = golang.MakePkgPath(path, p.Name.String())
+	}
+
+	return path
+}
+
+// errorCaseName returns the name of the public interface of an error case of the bounded contexts sum type.
+func errorCaseName(bcName, caseName string) string {
+	name := golang2.MakePrivate(bcName) + golang2.MakePublic(caseName)
+	if !strings.HasSuffix(name, "Error") {
+		name += "Error"
+	}
+
+	return golang2.MakePublic(name)
+}
+
+// qualifyTypeDecl returns a copy of the type declaration, whose names of declared types are full qualified by
+// the given package path, so that it can be used from other packages.
+func qualifyTypeDecl(pkgPath string, t *adl.TypeDecl) *adl.TypeDecl {
+	res := &adl.TypeDecl{Name: t.Name}
+	name := t.Name.String()
+	if name != "*" && name != "[]" && !strings.HasSuffix(name, "!") && !strings.Contains(name, ".") {
+		res.Name.Val = pkgPath + "." + name
+	}
+
+	for i, param := range t.TypeParams {
+		if t.IsArray() && i == 0 {
+			res.TypeParams = append(res.TypeParams, param)
+			continue
+		}
+
+		res.TypeParams = append(res.TypeParams, qualifyTypeDecl(pkgPath, param))
+	}
+
+	return res
+}
+
+func containsName(names []token.String, name string) bool {
+	for _, n := range names {
+		if n.String() == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package golang_test
+
+import (
+	"testing"
+)
+
+func TestRenderRest(t *testing.T) {
+	files := renderFiles(t, `
+        core {
+            error NotFound "...indicates a missing ticket."
+
+            dto Ticket "...is a ticket." {
+                field ID uuid! "...is the id."
+            }
+        }
+
+        usecase {
+            service Tickets "...manages tickets." {
+                func Find "...finds a ticket." {
+                    in id uuid! "...is the id."
+                    out $BC/core.Ticket "...is the ticket."
+                    out error! "...if anything goes wrong."
+                    error $BC/core.NotFound
+                    http GET "/tickets/{id}" {
+                        status $BC/core.NotFound 404
+                    }
+                }
+
+                func List "...lists tickets." {
+                    in limit int! "...is the page size."
+                    out items []$BC/core.Ticket "...are the tickets."
+                    out total int64! "...is the total count."
+                    out error! "...if anything goes wrong."
+                    http GET "/tickets" {
+                        query limit
+                    }
+                }
+
+                func Move "...moves a ticket between two columns." {
+                    in from int! "...is the source column."
+                    in to int! "...is the target column."
+                    out error! "...if anything goes wrong."
+                    http POST "/columns/{from}/{to}"
+                }
+            }
+        }`)
+
+	assertContains(t, files, "internal/tickets/usecase/services.go", "List(limit int) (items []core.Ticket, total int64, err error)")
+	assertContains(t, files, "internal/application/demoserver/application.go",
+		`router.Handle("POST", "/columns/{from}/{to}"`,
+		"{\n\t\t\tparsed, err := strconv.Atoi(params[\"from\"])",
+		"{\n\t\t\tparsed, err := strconv.Atoi(params[\"to\"])",
+		"err := ticketsUsecaseTickets.Move(from, to)",
+		"w.WriteHeader(http.StatusNoContent)",
+	)
+	// invalid parameters are rejected, before the service is invoked
+	files["internal/application/demoserver/application_test.go"] = `package demoserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInvalidParameters(t *testing.T) {
+	app := &Application{}
+	app.self = app
+	handler, err := app.getHttpHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []struct {
+		method, target string
+	}{
+		{"GET", "/tickets/42"},
+		{"GET", "/tickets?limit=ten"},
+		{"POST", "/columns/1/last"},
+		{"POST", "/columns/first/2"},
+	}
+
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(req.method, req.target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected %s %s to be a bad request but got %d: %s", req.method, req.target, rec.Code, rec.Body.String())
+		}
+	}
+}
+`
+
+	buildFiles(t, files)
+}
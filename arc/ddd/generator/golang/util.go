@@ -11,12 +11,12 @@ func makePreamble(p adl.Preamble) string {
 		tmp = p.Generator
 	}
 
-	if tmp != "" && p.License != "" {
+	if tmp != "" && p.License.String() != "" {
 		tmp += "\n\n"
 	}
 
-	if p.License != "" {
-		tmp += p.License
+	if p.License.String() != "" {
+		tmp += p.License.String()
 	}
 
 	return tmp
@@ -0,0 +1,115 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+)
+
+const pkgValidation = "internal/validation"
+
+// renderValidation emits the package which declares the violation types of the generated Validate methods, if the
+// module declares any field constraints.
+func renderValidation(dst *ast.Mod, src *adl.Module) error {
+	if !hasConstraints(src) {
+		return nil
+	}
+
+	validation := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, pkgValidation))
+	validation.SetPreamble(makePreamble(src.Preamble)).
+		SetComment("...provides the structured errors of the generated Validate methods.")
+
+	validation.AddFiles(
+		ast.NewFile("validation.go").
+			SetPreamble(makePreamble(src.Preamble)).
+			AddNodes(
+				ast.NewTpl(`
+					// Violation describes a field value, which does not satisfy a constraint.
+					type Violation struct {
+						// Field is the name of the rejected field.
+						Field string
+						// Message describes the violated constraint.
+						Message string
+					}
+
+					// Error lists all violations of a validated instance.
+					type Error struct {
+						// Type is the name of the validated type.
+						Type string
+						// Violations contains at least one violation.
+						Violations []Violation
+					}
+
+					// Error returns all violations in a single line.
+					func (e *Error) Error() string {
+						var sb {{.Use "strings.Builder"}}
+						sb.WriteString("invalid ")
+						sb.WriteString(e.Type)
+						sb.WriteString(": ")
+						for i, v := range e.Violations {
+							if i > 0 {
+								sb.WriteString("; ")
+							}
+
+							sb.WriteString(v.Field)
+							sb.WriteString(" ")
+							sb.WriteString(v.Message)
+						}
+
+						return sb.String()
+					}
+`),
+			),
+	)
+
+	stereotype.ModFrom(dst).SetValidationPkg(validation.Path)
+
+	return nil
+}
+
+// hasConstraints returns true, if any field of the module declares a constraint.
+func hasConstraints(src *adl.Module) bool {
+	for _, bc := range src.BoundedContexts {
+		for _, p := range append(append([]*adl.Package{}, bc.Core...), bc.Usecase...) {
+			var fields []*adl.Field
+			for _, dto := range p.DTOs {
+				fields = append(fields, dto.Fields...)
+			}
+
+			for _, service := range p.Services {
+				fields = append(fields, service.Component.Fields...)
+			}
+
+			values := append([]*adl.ValueObject{}, p.ValueObjects...)
+			var entities []*adl.Entity
+			for _, aggregate := range p.Aggregates {
+				if aggregate.Root != nil {
+					entities = append(entities, aggregate.Root)
+				}
+
+				entities = append(entities, aggregate.Entities...)
+				values = append(values, aggregate.ValueObjects...)
+			}
+
+			for _, entity := range entities {
+				if entity.Identity != nil {
+					fields = append(fields, entity.Identity)
+				}
+
+				fields = append(fields, entity.Component.Fields...)
+			}
+
+			for _, value := range values {
+				fields = append(fields, value.Component.Fields...)
+			}
+
+			if golang.HasConstraints(fields) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
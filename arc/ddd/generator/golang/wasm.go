@@ -0,0 +1,294 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+// hasWasmDist returns true, if the module shall also be distributed for js/wasm.
+func hasWasmDist(src *adl.Module) bool {
+	for _, dist := range src.Generator.Go.GoDist {
+		if strings.ToLower(dist.Os.String()) == "js" && strings.ToLower(dist.Arch.String()) == "wasm" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// asyncService returns the source of the non-blocking variant of the client of the service. Each method starts
+// the request in a new goroutine and passes the results to a callback. This is required by js/wasm, because
+// blocking the js event loop while waiting for the fetch api would dead lock.
+func asyncService(s exposedService) string {
+	service := s.service.Component.Name.String()
+	async := service + "Async"
+	var tmp strings.Builder
+	tmp.WriteString("// " + async + " performs the requests of the " + service + " service without blocking the caller.\n")
+	tmp.WriteString("// Each method returns immediately and invokes the callback from another goroutine.\n")
+	tmp.WriteString("type " + async + " struct {\nservice *" + service + "\n}\n\n")
+	tmp.WriteString("// " + async + " returns the non-blocking client of the " + service + " service.\n")
+	tmp.WriteString("func (c *Client) " + async + "() *" + async + " {\nreturn &" + async + "{service: c." + service + "()}\n}\n\n")
+
+	for _, method := range s.service.Component.Methods {
+		if method.Http == nil {
+			continue
+		}
+
+		params, args := asyncParams(s, method)
+		tmp.WriteString("// " + method.Name.String() + " invokes " + service + "." + method.Name.String() + " in a new goroutine and passes its results to the callback.\n")
+		tmp.WriteString("func (c *" + async + ") " + method.Name.String() + "(" + strings.Join(params, ", ") + ", callback func(" + strings.Join(asyncResults(s, method), ", ") + ")) {\n")
+		tmp.WriteString("go func() {\ncallback(c.service." + method.Name.String() + "(" + strings.Join(args, ", ") + "))\n}()\n}\n\n")
+	}
+
+	return tmp.String()
+}
+
+// asyncParams returns the parameter declarations and the argument names of the client method, which always
+// accepts a context first.
+func asyncParams(s exposedService, method *adl.Method) (params, args []string) {
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			params = append(params, golang.MakePrivate(param.Name.String())+" {{.Use \"context.Context\"}}")
+			args = append(args, golang.MakePrivate(param.Name.String()))
+		}
+	}
+
+	if len(params) == 0 {
+		params = append(params, "ctx {{.Use \"context.Context\"}}")
+		args = append(args, "ctx")
+	}
+
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			continue
+		}
+
+		name := golang.MakePrivate(param.Name.String())
+		params = append(params, name+" "+useType(astutil.MakeTypeDecl(qualifyTypeDecl(s.pkgPath, param.Type))))
+		args = append(args, name)
+	}
+
+	return params, args
+}
+
+// asyncResults returns the result types of the client method, which always returns an error last.
+func asyncResults(s exposedService, method *adl.Method) []string {
+	var results []string
+	for _, param := range method.Out {
+		if param.Type.Name.String() != stdlib.Error {
+			results = append(results, useType(astutil.MakeTypeDecl(qualifyTypeDecl(s.pkgPath, param.Type))))
+		}
+	}
+
+	return append(results, "error")
+}
+
+// renderWasmClient emits a js/wasm main package, which exports the client of the bounded context as a global js
+// object. Its functions return promises and use the same json representation as the http api.
+func renderWasmClient(dst *ast.Mod, src *adl.Module, bc *adl.BoundedContext, clientPkg string, services []exposedService) {
+	name := clientPkgName(bc)
+	preamble := makePreamble(src.Preamble) + "\n\n+build js,wasm"
+	pkg := astutil.MkPkg(dst, golang.MakePkgPath(dst.Name, "cmd", name+"-wasm"))
+	pkg.Name = "main"
+	pkg.SetPreamble(preamble).
+		SetComment("...exports the " + bc.Name.String() + " http client to the browser. Load the wasm file using wasm_exec.js\n" +
+			"and create a client by\n\n  const client = await " + name + ".newClient(\"http://localhost:8080\");\n\n" +
+			"Each service is a property of the client and each method returns a promise, e.g.\n\n" +
+			"  const res = await client." + golang2.MakePrivate(services[0].service.Component.Name.String()) + "." + golang2.MakePrivate(firstHttpMethod(services[0].service).Name.String()) + "(...);\n\n" +
+			"Rejected promises carry the status, case and properties of the error response.")
+	stereotype.PkgFrom(pkg).SetIsCMDPkg(true)
+	stereotype.PkgFrom(pkg).SetIsWasmPkg(true)
+
+	var props, funcs strings.Builder
+	for _, s := range services {
+		service := s.service.Component.Name.String()
+		getter := golang2.MakePrivate(service)
+		props.WriteString(strconv.Quote(getter) + ": " + getter + "(c." + service + "Async()),\n")
+
+		funcs.WriteString("// " + getter + " returns the js object of the " + service + " service.\n")
+		funcs.WriteString("func " + getter + "(s *{{.Use \"" + clientPkg + "." + service + "Async\"}}) {{.Use \"syscall/js.Value\"}} {\n")
+		funcs.WriteString("return {{.Use \"syscall/js.ValueOf\"}}(map[string]interface{}{\n")
+		for _, method := range s.service.Component.Methods {
+			if method.Http == nil {
+				continue
+			}
+
+			funcs.WriteString(strconv.Quote(golang2.MakePrivate(method.Name.String())) + ": {{.Use \"syscall/js.FuncOf\"}}(func(this {{.Use \"syscall/js.Value\"}}, args []{{.Use \"syscall/js.Value\"}}) interface{} {\n")
+			funcs.WriteString("return promise(func(settle func(interface{}, error)) {\n")
+			params, args := asyncParams(s, method)
+			args[0] = "{{.Use \"context.Background\"}}()"
+			for i, param := range params[1:] {
+				funcs.WriteString("var " + param + "\n")
+				funcs.WriteString("if err := decodeArg(args, " + strconv.Itoa(i) + ", &" + args[i+1] + "); err != nil {\n" +
+					"settle(nil, {{.Use \"fmt.Errorf\"}}(\"invalid argument '" + args[i+1] + "': %w\", err))\nreturn\n}\n\n")
+			}
+
+			results := asyncResults(s, method)
+			var names, resolved []string
+			switch len(results) {
+			case 1:
+				resolved = []string{"nil"}
+			case 2:
+				resolved = []string{"res0"}
+			default:
+				var values []string
+				i := 0
+				for _, param := range method.Out {
+					if param.Type.Name.String() == stdlib.Error {
+						continue
+					}
+
+					values = append(values, strconv.Quote(param.Name.String())+": res"+strconv.Itoa(i))
+					i++
+				}
+
+				resolved = []string{"map[string]interface{}{" + strings.Join(values, ", ") + "}"}
+			}
+
+			for i, result := range results[:len(results)-1] {
+				names = append(names, "res"+strconv.Itoa(i)+" "+result)
+			}
+
+			names = append(names, "err error")
+			funcs.WriteString("s." + method.Name.String() + "(" + strings.Join(args, ", ") + ", func(" + strings.Join(names, ", ") + ") {\n")
+			funcs.WriteString("settle(" + resolved[0] + ", err)\n})\n})\n}),\n")
+		}
+
+		funcs.WriteString("})\n}\n\n")
+	}
+
+	pkg.AddFiles(
+		ast.NewFile("main.go").
+			SetPreamble(preamble).
+			AddNodes(
+				ast.NewTpl(`
+					// main exports the global `+name+` object and blocks forever, so that its functions remain callable.
+					func main() {
+						{{.Use "syscall/js.Global"}}().Set(`+strconv.Quote(name)+`, {{.Use "syscall/js.ValueOf"}}(map[string]interface{}{
+							"newClient": {{.Use "syscall/js.FuncOf"}}(newClient),
+						}))
+
+						select {}
+					}
+
+					// newClient resolves the client of the base url, which is the first argument.
+					func newClient(this {{.Use "syscall/js.Value"}}, args []{{.Use "syscall/js.Value"}}) interface{} {
+						return promise(func(settle func(interface{}, error)) {
+							var baseURL string
+							if err := decodeArg(args, 0, &baseURL); err != nil {
+								settle(nil, {{.Use "fmt.Errorf"}}("invalid base url: %w", err))
+								return
+							}
+
+							c, err := {{.Use "`+clientPkg+`.NewClient"}}({{.Use "`+clientPkg+`.Options"}}{BaseURL: baseURL})
+							if err != nil {
+								settle(nil, err)
+								return
+							}
+
+							settle({{.Use "syscall/js.ValueOf"}}(map[string]interface{}{
+								`+props.String()+`
+							}), nil)
+						})
+					}
+
+					`+funcs.String()+`
+
+					// promise returns a Promise, which is settled by f. Results are passed through json, so that they
+					// have the same representation as in the http api.
+					func promise(f func(settle func(interface{}, error))) {{.Use "syscall/js.Value"}} {
+						executor := {{.Use "syscall/js.FuncOf"}}(func(this {{.Use "syscall/js.Value"}}, args []{{.Use "syscall/js.Value"}}) interface{} {
+							resolve, reject := args[0], args[1]
+							f(func(res interface{}, err error) {
+								if err != nil {
+									reject.Invoke(jsError(err))
+									return
+								}
+
+								v, err := encodeValue(res)
+								if err != nil {
+									reject.Invoke(jsError(err))
+									return
+								}
+
+								resolve.Invoke(v)
+							})
+
+							return nil
+						})
+
+						// the executor is invoked synchronously by the constructor
+						defer executor.Release()
+
+						return {{.Use "syscall/js.Global"}}().Get("Promise").New(executor)
+					}
+
+					// decodeArg decodes the json representation of the argument at the index into dst. Missing and
+					// undefined arguments are left untouched.
+					func decodeArg(args []{{.Use "syscall/js.Value"}}, idx int, dst interface{}) error {
+						if idx >= len(args) || args[idx].IsUndefined() {
+							return nil
+						}
+
+						str := {{.Use "syscall/js.Global"}}().Get("JSON").Call("stringify", args[idx]).String()
+
+						return {{.Use "encoding/json.Unmarshal"}}([]byte(str), dst)
+					}
+
+					// encodeValue returns the js representation of the json encoding of v. Nil becomes undefined and
+					// js values are returned as is.
+					func encodeValue(v interface{}) ({{.Use "syscall/js.Value"}}, error) {
+						switch v := v.(type) {
+						case nil:
+							return {{.Use "syscall/js.Undefined"}}(), nil
+						case {{.Use "syscall/js.Value"}}:
+							return v, nil
+						}
+
+						buf, err := {{.Use "encoding/json.Marshal"}}(v)
+						if err != nil {
+							return {{.Use "syscall/js.Undefined"}}(), {{.Use "fmt.Errorf"}}("cannot encode result: %w", err)
+						}
+
+						return {{.Use "syscall/js.Global"}}().Get("JSON").Call("parse", string(buf)), nil
+					}
+
+					// jsError creates an Error, which carries the status, case and properties of an error response.
+					func jsError(err error) {{.Use "syscall/js.Value"}} {
+						e := {{.Use "syscall/js.Global"}}().Get("Error").New(err.Error())
+						res := {{.Use "`+clientPkg+`.AsResponseError"}}(err)
+						if res == nil {
+							return e
+						}
+
+						e.Set("message", res.Message)
+						e.Set("status", res.Status)
+						e.Set("case", res.Case)
+						if props, err := encodeValue(res.Properties); err == nil {
+							e.Set("properties", props)
+						}
+
+						return e
+					}
+				`),
+			),
+	)
+}
+
+// firstHttpMethod returns the first exposed method of the service.
+func firstHttpMethod(service *adl.Service) *adl.Method {
+	for _, method := range service.Component.Methods {
+		if method.Http != nil {
+			return method
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package golang_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderWasm(t *testing.T) {
+	src := strings.Replace(module, "%s", `
+        usecase {
+            service Tickets "...manages tickets." {
+                func Hello "...says hello." {
+                    in name string! "...is the name."
+                    out greeting string! "...is the greeting."
+                    out error! "...if anything goes wrong."
+                    http GET "/hello/{name}"
+                }
+            }
+        }`, 1)
+
+	src = strings.Replace(src, `module "example.com/demo"`, `module "example.com/demo"
+            dist js wasm`, 1)
+
+	files := renderProject(t, src)
+	assertContains(t, files, "pkg/ticketsclient/async.go",
+		"func (c *TicketsAsync) Hello(ctx context.Context, name string, callback func(string, error)) {",
+	)
+	assertContains(t, files, "Makefile", "GOOS=js GOARCH=wasm go build")
+	buildFiles(t, files, "js/wasm")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/golangee/architecture/arc/generator/stereotype"
 	"github.com/golangee/src/ast"
 	"io/fs"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -23,7 +24,16 @@ func RenderModule(dst *ast.Prj, prj *adl.Project, src *adl.Module) error {
 	for _, mod := range dst.Mods {
 		docs := stereotype.ModFrom(mod)
 		if len(docs.Docs().Files) > 0 {
-			for path, nodes := range docs.Docs().Files {
+			paths := make([]string, 0, len(docs.Docs().Files))
+			for path := range docs.Docs().Files {
+				paths = append(paths, path)
+			}
+
+			// keep the rendering order stable, so that equal architectures result in equal artifacts
+			sort.Strings(paths)
+
+			for _, path := range paths {
+				nodes := docs.Docs().Files[path]
 				var buf bytes.Buffer
 				pkg := astutil.MkPkg(mod, golang.MakePkgPath(mod.Name, golang.PkgPathDir(path)))
 				buf.Write(markdown.Render(doc.NewComment(src.Preamble.Generator)))
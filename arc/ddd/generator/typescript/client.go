@@ -0,0 +1,353 @@
+package typescript
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/token"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+)
+
+// restSource is the shared runtime of all clients. It decodes the responses of the generated rest package.
+const restSource = `/** Options configures a client. */
+export interface Options {
+  /** baseUrl is the address of the server, e.g. http://localhost:8080. */
+  baseUrl: string;
+  /** headers are sent with each request, e.g. to authorize it. */
+  headers?: Record<string, string>;
+  /** fetch performs the requests. If undefined, the global fetch is used. */
+  fetch?: typeof fetch;
+}
+
+/** ErrorResponse is the json encoding of any error response. */
+export interface ErrorResponse {
+  /** status is the http status code. */
+  status: number;
+  /** case is the name of the declared error case, if any. */
+  case?: string;
+  /** message is the human readable error message. */
+  message: string;
+  /** properties contains the values of the error case, if any. */
+  properties?: Record<string, unknown>;
+}
+
+/** ApiError is thrown for any error response. */
+export class ApiError<R extends ErrorResponse = ErrorResponse> extends Error {
+  /** status is the http status code. */
+  readonly status: number;
+  /** response is the decoded error response. */
+  readonly response: R;
+
+  constructor(response: R) {
+    super(response.message);
+    this.name = "ApiError";
+    this.status = response.status;
+    this.response = response;
+  }
+}
+
+/** request sends the json encoded body, if defined, and decodes the json response. */
+export async function request<T>(options: Options, method: string, path: string, query?: URLSearchParams, body?: unknown, signal?: AbortSignal): Promise<T> {
+  let url = options.baseUrl.replace(/\/+$/, "") + path;
+  if (query !== undefined && query.toString() !== "") {
+    url += "?" + query.toString();
+  }
+
+  const headers: Record<string, string> = {Accept: "application/json", ...options.headers};
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+
+  const doFetch = options.fetch ?? fetch;
+  const res = await doFetch(url, {method, headers, body: body === undefined ? undefined : JSON.stringify(body), signal});
+  if (!res.ok) {
+    let response: ErrorResponse;
+    try {
+      response = await res.json();
+    } catch {
+      response = {status: res.status, message: res.statusText};
+    }
+
+    throw new ApiError({...response, status: res.status});
+  }
+
+  if (res.status === 204) {
+    return undefined as unknown as T;
+  }
+
+  return await res.json() as T;
+}`
+
+// httpService is a use case service of a bounded context, which has exposed methods.
+type httpService struct {
+	bc      *adl.BoundedContext
+	pkgPath string
+	service *adl.Service
+}
+
+// renderErrors emits the error cases of each bounded context as a discriminated union of error responses.
+func (m *module) renderErrors() {
+	for _, bc := range m.src.BoundedContexts {
+		m.renderRest()
+		f := m.file(m.bcFilePath(bc, fileErrors))
+		sumType := golang2.MakePublic(bc.Name.String()) + "Error"
+		var names, cases []string
+		seen := map[string]bool{}
+		for _, layer := range []struct {
+			name string
+			pkgs []*adl.Package
+		}{{pkgCore, bc.Core}, {pkgUsecase, bc.Usecase}} {
+			for _, p := range layer.pkgs {
+				pkgPath := bcPkgPath(bc, layer.name, p)
+				for _, anErr := range p.AllErrors() {
+					if seen[anErr.Name.String()] {
+						continue
+					}
+
+					seen[anErr.Name.String()] = true
+					caseType := errorCaseName(bc.Name.String(), anErr.Name.String())
+					names = append(names, caseType)
+					cases = append(cases, strconv.Quote(anErr.Name.String()))
+
+					f.writeComment("", caseType, anErr.Comment.String())
+					f.body.WriteString("export interface " + caseType + " extends " + f.use(fileRest, "ErrorResponse", true) + " {\n")
+					f.body.WriteString("  case: " + strconv.Quote(anErr.Name.String()) + ";\n")
+					if len(anErr.Fields) > 0 {
+						f.body.WriteString("  properties: {\n")
+						for _, field := range anErr.Fields {
+							getter := golang2.MakePublic(golang2.MakePrivate(field.Name.String()))
+							if comment := describe(field.Name.String(), field.Comment.String()); comment != "" {
+								f.body.WriteString("    /** " + comment + " */\n")
+							}
+
+							f.body.WriteString("    " + getter + ": " + m.typeOf(f, pkgPath, field.Type) + ";\n")
+						}
+
+						f.body.WriteString("  };\n")
+					}
+
+					f.body.WriteString("}\n\n")
+				}
+			}
+		}
+
+		if len(names) == 0 {
+			delete(m.files, f.path)
+			continue
+		}
+
+		f.body.WriteString("/** " + sumType + " is the error response of any declared error case of the " + bc.Name.String() + " bounded context. */\n")
+		f.body.WriteString("export type " + sumType + " = " + strings.Join(names, " | ") + ";\n\n")
+		f.body.WriteString("/** " + sumType + "Case is the name of any declared error case of the " + bc.Name.String() + " bounded context. */\n")
+		f.body.WriteString("export type " + sumType + "Case = " + strings.Join(cases, " | ") + ";\n\n")
+		f.body.WriteString("/** " + sumType + "Cases contains all cases of " + sumType + "Case in declaration order. */\n")
+		f.body.WriteString("export const " + sumType + "Cases: readonly " + sumType + "Case[] = [" + strings.Join(cases, ", ") + "];\n\n")
+
+		apiError := f.use(fileRest, "ApiError", false)
+		f.body.WriteString("/** is" + sumType + " returns true, if err has been thrown for a declared error case. */\n")
+		f.body.WriteString("export function is" + sumType + "(err: unknown): err is " + apiError + "<" + sumType + "> {\n")
+		f.body.WriteString("  return err instanceof " + apiError + " && (" + sumType + "Cases as readonly string[]).includes(err.response.case ?? \"\");\n}\n\n")
+	}
+}
+
+// renderRest emits the shared runtime once.
+func (m *module) renderRest() {
+	if _, ok := m.files[fileRest]; !ok {
+		m.file(fileRest).body.WriteString(restSource)
+	}
+}
+
+// renderClients emits a client class for each exposed use case service and the shared rest runtime.
+func (m *module) renderClients() error {
+	var services []httpService
+	for _, executable := range m.src.Executables {
+		for _, s := range httpServices(m.src, executable) {
+			if !containsService(services, s) {
+				services = append(services, s)
+			}
+		}
+	}
+
+	if len(services) == 0 {
+		return nil
+	}
+
+	m.renderRest()
+
+	for _, s := range services {
+		f := m.file(m.bcFilePath(s.bc, fileClient))
+		if err := m.renderClient(f, s); err != nil {
+			return fmt.Errorf("cannot render client of '%s': %w", s.service.Component.Name.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// renderClient emits the class, whose methods perform the requests against the exposed methods of the service.
+func (m *module) renderClient(f *file, s httpService) error {
+	options := f.use(fileRest, "Options", true)
+	name := s.service.Component.Name.String() + "Client"
+	f.writeComment("", name, s.service.Component.Comment.String()+"\n\nEach method performs a request against the according http endpoint.")
+	f.body.WriteString("export class " + name + " {\n")
+	f.body.WriteString("  private readonly options: " + options + ";\n\n")
+	f.body.WriteString("  constructor(options: " + options + ") {\n    this.options = options;\n  }\n")
+
+	for _, method := range s.service.Component.Methods {
+		if method.Http == nil {
+			continue
+		}
+
+		if err := m.renderMethod(f, s, method); err != nil {
+			return fmt.Errorf("cannot render method '%s': %w", method.Name.String(), err)
+		}
+	}
+
+	f.body.WriteString("}\n\n")
+
+	return nil
+}
+
+// renderMethod emits the method, which has the same parameters and results as the exposed method, except the
+// context, which is replaced by an optional AbortSignal.
+func (m *module) renderMethod(f *file, s httpService, method *adl.Method) error {
+	e := method.Http
+	vars, err := e.PathVars()
+	if err != nil {
+		return err
+	}
+
+	path := escapeTemplate(e.Path.String())
+	var params, query []string
+	body := "undefined"
+	for _, param := range method.In {
+		if param.Type.Name.String() == "context.Context" {
+			continue
+		}
+
+		name := param.Name.String()
+		params = append(params, name+": "+m.typeOf(f, s.pkgPath, param.Type))
+		switch {
+		case containsName(vars, name):
+			path = strings.ReplaceAll(path, "{"+name+"}", "${encodeURIComponent("+formatValue(param.Type, name)+")}")
+		case containsName(e.Query, name):
+			query = append(query, "["+strconv.Quote(name)+", "+formatValue(param.Type, name)+"]")
+		case e.Body.String() == name:
+			body = name
+		}
+	}
+
+	params = append(params, "signal?: AbortSignal")
+
+	var results []*adl.Param
+	for _, param := range method.Out {
+		if param.Type.Name.String() != stdlib.Error {
+			results = append(results, param)
+		}
+	}
+
+	result := "void"
+	switch len(results) {
+	case 0:
+	case 1:
+		result = m.typeOf(f, s.pkgPath, results[0].Type)
+	default:
+		var props []string
+		for _, param := range results {
+			props = append(props, param.Name.String()+": "+m.typeOf(f, s.pkgPath, param.Type))
+		}
+
+		result = "{" + strings.Join(props, "; ") + "}"
+	}
+
+	queryArg := "undefined"
+	if len(query) > 0 {
+		queryArg = "new URLSearchParams([" + strings.Join(query, ", ") + "])"
+	}
+
+	f.body.WriteString("\n")
+	f.writeComment("  ", golang2.MakePrivate(method.Name.String()), method.Comment.String())
+	f.body.WriteString("  async " + golang2.MakePrivate(method.Name.String()) + "(" + strings.Join(params, ", ") + "): Promise<" + result + "> {\n")
+	f.body.WriteString("    return " + f.use(fileRest, "request", false) + "<" + result + ">(this.options, " + strconv.Quote(e.Verb.String()) + ", `" + path + "`, " + queryArg + ", " + body + ", signal);\n")
+	f.body.WriteString("  }\n")
+
+	return nil
+}
+
+// bcFilePath returns the output path of the named file of the bounded context, e.g. tickets/client.
+func (m *module) bcFilePath(bc *adl.BoundedContext, name string) string {
+	return golang.MakePkgPath(m.filePath(golang.MakePkgPath(bc.Path.String())), name)
+}
+
+// formatValue returns the expression, which formats a path or query value the same way, as the server parses it.
+func formatValue(t *adl.TypeDecl, name string) string {
+	if t.Name.String() == stdlib.Duration {
+		return "`${" + name + "}ns`"
+	}
+
+	return "String(" + name + ")"
+}
+
+// escapeTemplate escapes the literal text of a template string.
+func escapeTemplate(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "$", "\\$").Replace(s)
+}
+
+// httpServices returns the use case services of the bounded contexts of the executable, which have at least
+// a single exposed method.
+func httpServices(src *adl.Module, executable *adl.Executable) []httpService {
+	var res []httpService
+	for _, path := range executable.BoundedContextPaths {
+		for _, bc := range src.BoundedContexts {
+			if golang.MakePkgPath(bc.Path.String()) != golang.MakePkgPath(path.String()) {
+				continue
+			}
+
+			for _, p := range bc.Usecase {
+				for _, service := range p.Services {
+					for _, method := range service.Component.Methods {
+						if method.Http != nil {
+							res = append(res, httpService{bc: bc, pkgPath: bcPkgPath(bc, pkgUsecase, p), service: service})
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// errorCaseName returns the name of an error case of the bounded context, just like the Go sum type case.
+func errorCaseName(bcName, caseName string) string {
+	name := golang2.MakePrivate(bcName) + golang2.MakePublic(caseName)
+	if !strings.HasSuffix(name, "Error") {
+		name += "Error"
+	}
+
+	return golang2.MakePublic(name)
+}
+
+func containsService(services []httpService, s httpService) bool {
+	for _, other := range services {
+		if other.service == s.service {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsName(names []token.String, name string) bool {
+	for _, n := range names {
+		if n.String() == name {
+			return true
+		}
+	}
+
+	return false
+}
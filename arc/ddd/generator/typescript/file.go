@@ -0,0 +1,131 @@
+package typescript
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	golang2 "github.com/golangee/src/golang"
+	"sort"
+	"strings"
+)
+
+// imports are the names, which a file imports from another file.
+type imports struct {
+	types  map[string]string // local name => exported name
+	values map[string]string // local name => exported name
+}
+
+// file is a TypeScript source file, whose imports are collected while its body is written.
+type file struct {
+	path    string              // the output path without extension
+	imports map[string]*imports // by output path
+	locals  map[string]string   // local name => output path, to detect conflicts
+	body    strings.Builder
+}
+
+// use imports the exported name of the other file, if required, and returns its local name. If the name is
+// already used for another import, it is aliased by the file name.
+func (f *file) use(path, name string, isType bool) string {
+	if path == f.path {
+		return name
+	}
+
+	local := name
+	if other, ok := f.locals[local]; ok && other != path {
+		local = golang2.MakePublic(pathBase(path)) + name
+	}
+
+	f.locals[local] = path
+	imp, ok := f.imports[path]
+	if !ok {
+		imp = &imports{types: map[string]string{}, values: map[string]string{}}
+		f.imports[path] = imp
+	}
+
+	if isType {
+		if _, ok := imp.values[local]; !ok {
+			imp.types[local] = name
+		}
+	} else {
+		delete(imp.types, local)
+		imp.values[local] = name
+	}
+
+	return local
+}
+
+// writeComment writes the comment as a doc comment, prefixed by the identifier and indented by the prefix.
+func (f *file) writeComment(indent, ident, comment string) {
+	comment = describe(ident, comment)
+	if comment == "" {
+		return
+	}
+
+	lines := strings.Split(comment, "\n")
+	if len(lines) == 1 {
+		f.body.WriteString(indent + "/** " + comment + " */\n")
+		return
+	}
+
+	f.body.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		f.body.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+
+	f.body.WriteString(indent + " */\n")
+}
+
+// String returns the source code with the preamble and the imports.
+func (f *file) String(preamble adl.Preamble) string {
+	var tmp strings.Builder
+	for _, line := range strings.Split(makePreamble(preamble), "\n") {
+		tmp.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+	}
+
+	tmp.WriteString("\n")
+
+	paths := make([]string, 0, len(f.imports))
+	for path := range f.imports {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	for _, path := range paths {
+		imp := f.imports[path]
+		for _, names := range []struct {
+			keyword string
+			names   map[string]string
+		}{{"import type", imp.types}, {"import", imp.values}} {
+			if len(names.names) == 0 {
+				continue
+			}
+
+			var specs []string
+			for local, name := range names.names {
+				if local == name {
+					specs = append(specs, name)
+				} else {
+					specs = append(specs, name+" as "+local)
+				}
+			}
+
+			sort.Strings(specs)
+			tmp.WriteString(names.keyword + " {" + strings.Join(specs, ", ") + "} from \"" + relImport(f.path, path) + "\";\n")
+		}
+	}
+
+	if len(paths) > 0 {
+		tmp.WriteString("\n")
+	}
+
+	tmp.WriteString(strings.TrimRight(f.body.String(), "\n") + "\n")
+
+	return tmp.String()
+}
+
+func makePreamble(p adl.Preamble) string {
+	tmp := p.Generator
+	if tmp != "" && p.License.String() != "" {
+		tmp += "\n\n"
+	}
+
+	return tmp + p.License.String()
+}
@@ -0,0 +1,368 @@
+// Package typescript emits the models and a fetch based http client of a module as TypeScript sources, so that
+// a frontend can consume the exposed use cases with the same types as the generated Go server.
+package typescript
+
+import (
+	"fmt"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/golang"
+	golang2 "github.com/golangee/src/golang"
+	"github.com/golangee/src/render"
+	"github.com/golangee/src/stdlib"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	mimeTypeScript = "text/typescript"
+	pkgCore        = "core"
+	pkgUsecase     = "usecase"
+	fileRest       = "rest"
+	fileErrors     = "errors"
+	fileClient     = "client"
+)
+
+// builtinTypes maps the standard library types to the TypeScript types of their encoding/json representation.
+var builtinTypes = map[string]string{
+	stdlib.Bool:     "boolean",
+	stdlib.Int:      "number",
+	stdlib.Byte:     "number",
+	stdlib.Int16:    "number",
+	stdlib.Int32:    "number",
+	stdlib.Int64:    "number",
+	stdlib.Float32:  "number",
+	stdlib.Float64:  "number",
+	stdlib.Rune:     "number",
+	stdlib.Duration: "number", // nanoseconds
+	stdlib.String:   "string",
+	stdlib.UUID:     "string",
+	stdlib.Time:     "string", // RFC 3339
+	stdlib.URL:      "string",
+	stdlib.Error:    "unknown",
+}
+
+// RenderModule emits the TypeScript sources into the output directory of the TypeScript generator settings,
+// which is resolved relative to the given root directory, just like the output directory of a Go module.
+func RenderModule(dst *render.Dir, src *adl.Module) error {
+	if src.Generator == nil || src.Generator.TypeScript == nil {
+		return fmt.Errorf("cannot render a non-typescript module: %s", src.Name)
+	}
+
+	normalizeNames(src)
+
+	m := newModule(src)
+	m.renderTypes()
+	m.renderErrors()
+	if err := m.renderClients(); err != nil {
+		return err
+	}
+
+	dir := mkDir(dst, src.Generator.TypeScript.OutDir.String())
+	for _, path := range m.sortedPaths() {
+		f := m.files[path]
+		sub := mkDir(dir, pathDir(path))
+		sub.Files = append(sub.Files, &render.File{
+			FileName: pathBase(path) + ".ts",
+			MimeType: mimeTypeScript,
+			Buf:      []byte(f.String(src.Preamble)),
+		})
+	}
+
+	return nil
+}
+
+// normalizeNames replaces $MOD and $BC, so that all type names are full qualified. The Go module path is
+// preferred, so that normalizing an already rendered Go module is a no-op.
+func normalizeNames(src *adl.Module) {
+	ctx := adl.Ctx{Mod: src.Name.String()}
+	if src.Generator.Go != nil {
+		ctx.Mod = src.Generator.Go.Module.String()
+	}
+
+	for _, executable := range src.Executables {
+		executable.Normalize(ctx)
+	}
+
+	for _, bc := range src.BoundedContexts {
+		bc.Normalize(ctx)
+	}
+}
+
+// decl is a type declaration of a bounded context, which has a TypeScript representation.
+type decl struct {
+	name    string
+	comment string
+	path    string // the file which declares the type
+	pkgPath string
+	fields  []*adl.Field
+	enum    *adl.Enum
+}
+
+// module collects the declarations and the files to emit.
+type module struct {
+	src   *adl.Module
+	decls map[string]*decl // by full qualified name
+	order []string         // full qualified names in declaration order
+	files map[string]*file // by output path without extension
+}
+
+func newModule(src *adl.Module) *module {
+	m := &module{src: src, decls: map[string]*decl{}, files: map[string]*file{}}
+	for _, bc := range src.BoundedContexts {
+		for _, layer := range []struct {
+			name string
+			pkgs []*adl.Package
+		}{{pkgCore, bc.Core}, {pkgUsecase, bc.Usecase}} {
+			for _, p := range layer.pkgs {
+				pkgPath := bcPkgPath(bc, layer.name, p)
+				add := func(d *decl) {
+					d.path = m.filePath(pkgPath)
+					d.pkgPath = pkgPath
+					m.decls[pkgPath+"."+d.name] = d
+					m.order = append(m.order, pkgPath+"."+d.name)
+				}
+
+				for _, dto := range p.DTOs {
+					if dto.Stereotype.String() == adl.DTO {
+						add(&decl{name: dto.Name.String(), comment: dto.Comment.String(), fields: dto.Fields})
+					}
+				}
+
+				for _, value := range p.ValueObjects {
+					add(&decl{name: value.Component.Name.String(), comment: value.Component.Comment.String(), fields: value.Component.Fields})
+				}
+
+				for _, aggregate := range p.Aggregates {
+					var structs []*adl.Struct
+					if aggregate.Root != nil {
+						structs = append(structs, aggregate.Root.Component)
+					}
+
+					for _, entity := range aggregate.Entities {
+						structs = append(structs, entity.Component)
+					}
+
+					for _, value := range aggregate.ValueObjects {
+						structs = append(structs, value.Component)
+					}
+
+					for _, s := range structs {
+						add(&decl{name: s.Name.String(), comment: s.Comment.String(), fields: s.Fields})
+					}
+				}
+
+				for _, event := range p.Events {
+					add(&decl{name: event.Name.String(), comment: event.Comment.String(), fields: event.Fields})
+				}
+
+				for _, enum := range p.Enums {
+					add(&decl{name: enum.Name.String(), comment: enum.Comment.String(), enum: enum})
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+// file returns the file of the given output path and allocates it if required.
+func (m *module) file(path string) *file {
+	f, ok := m.files[path]
+	if !ok {
+		f = &file{path: path, imports: map[string]*imports{}, locals: map[string]string{}}
+		m.files[path] = f
+	}
+
+	return f
+}
+
+func (m *module) sortedPaths() []string {
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// filePath returns the module relative output path of the package without the internal segment, e.g.
+// tickets/core.
+func (m *module) filePath(pkgPath string) string {
+	mod := m.src.Name.String()
+	if m.src.Generator.Go != nil {
+		mod = m.src.Generator.Go.Module.String()
+	}
+
+	path := strings.TrimPrefix(pkgPath, golang.MakePkgPath(mod)+"/")
+
+	return strings.TrimPrefix(path, "internal/")
+}
+
+// renderTypes emits the interfaces of the structs and the types of the enums.
+func (m *module) renderTypes() {
+	for _, fqn := range m.order {
+		d := m.decls[fqn]
+		f := m.file(d.path)
+		if d.enum == nil {
+			f.writeComment("", d.name, d.comment)
+			f.body.WriteString("export interface " + d.name + " " + m.object(f, d.pkgPath, d.fields) + "\n\n")
+			continue
+		}
+
+		if !d.enum.IsUnion() {
+			var cases []string
+			for _, c := range d.enum.Cases {
+				cases = append(cases, strconv.Quote(c.Name.String()))
+			}
+
+			f.writeComment("", d.name, d.comment)
+			f.body.WriteString("export type " + d.name + " = " + strings.Join(cases, " | ") + ";\n\n")
+			f.body.WriteString("/** " + d.name + "Values contains all cases of " + d.name + " in declaration order. */\n")
+			f.body.WriteString("export const " + d.name + "Values: readonly " + d.name + "[] = [" + strings.Join(cases, ", ") + "];\n\n")
+			continue
+		}
+
+		var cases []string
+		for _, c := range d.enum.Cases {
+			name := d.name + golang2.MakePublic(c.Name.String())
+			cases = append(cases, name)
+			f.writeComment("", name, c.Comment.String()+"\n\nIt is the '"+c.Name.String()+"' case of the "+d.name+" union.")
+			f.body.WriteString("export interface " + name + " " + m.object(f, d.pkgPath, c.Fields) + "\n\n")
+		}
+
+		f.writeComment("", d.name, d.comment+"\n\nThis is a union and each case is declared by its own interface.")
+		f.body.WriteString("export type " + d.name + " = " + strings.Join(cases, " | ") + ";\n\n")
+	}
+}
+
+// object returns the TypeScript object type of the public fields.
+func (m *module) object(f *file, pkgPath string, fields []*adl.Field) string {
+	var tmp strings.Builder
+	tmp.WriteString("{\n")
+	for _, field := range fields {
+		if field.Private {
+			continue
+		}
+
+		if comment := describe(field.Name.String(), field.Comment.String()); comment != "" {
+			tmp.WriteString("  /** " + comment + " */\n")
+		}
+
+		tmp.WriteString("  " + field.Name.String() + ": " + m.typeOf(f, pkgPath, field.Type) + ";\n")
+	}
+
+	tmp.WriteString("}")
+
+	return tmp.String()
+}
+
+// typeOf returns the TypeScript type of the type declaration, as it is encoded by encoding/json. Declared types
+// of other files are imported and foreign types are unknown.
+func (m *module) typeOf(f *file, pkgPath string, t *adl.TypeDecl) string {
+	name := t.Name.String()
+	if ts, ok := builtinTypes[name]; ok {
+		return ts
+	}
+
+	switch {
+	case t.IsPtr():
+		return m.typeOf(f, pkgPath, t.TypeParams[0]) + " | null"
+	case t.IsSlice() && t.TypeParams[0].Name.String() == stdlib.Byte:
+		return "string" // base64
+	case name == "[]" || name == stdlib.List:
+		elem := m.typeOf(f, pkgPath, t.TypeParams[len(t.TypeParams)-1])
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+
+		return elem + "[]"
+	case t.IsMap():
+		key := m.typeOf(f, pkgPath, t.TypeParams[0])
+		if key != "number" {
+			key = "string"
+		}
+
+		return "Record<" + key + ", " + m.typeOf(f, pkgPath, t.TypeParams[1]) + ">"
+	}
+
+	fqn := name
+	if idx := strings.LastIndex(fqn, "."); idx < 0 || strings.Contains(fqn[idx:], "/") {
+		fqn = pkgPath + "." + fqn
+	}
+
+	d, ok := m.decls[fqn]
+	if !ok {
+		return "unknown"
+	}
+
+	return f.use(d.path, d.name, true)
+}
+
+// describe removes the ellipsis of the comment, even if the name is empty.
+func describe(name, comment string) string {
+	if name == "" {
+		return strings.TrimSpace(strings.TrimPrefix(comment, "..."))
+	}
+
+	return strings.TrimSpace(golang2.DeEllipsis(name, comment))
+}
+
+// bcPkgPath returns the Go package path of a core or use case package of the bounded context.
+func bcPkgPath(bc *adl.BoundedContext, layer string, p *adl.Package) string {
+	path := golang.MakePkgPath(golang.MakePkgPath(bc.Path.String()), layer)
+	if p.Name.String() != "" {
+		path = golang.MakePkgPath(path, p.Name.String())
+	}
+
+	return path
+}
+
+// mkDir returns the nested directory of the slash separated path and allocates it if required.
+func mkDir(parent *render.Dir, path string) *render.Dir {
+	for _, name := range strings.Split(path, "/") {
+		if name == "" || name == "." {
+			continue
+		}
+
+		dir := parent.Directory(name)
+		if dir == nil {
+			dir = &render.Dir{DirName: name}
+			parent.Dirs = append(parent.Dirs, dir)
+		}
+
+		parent = dir
+	}
+
+	return parent
+}
+
+// relImport returns the relative module specifier of the output path to, as seen from the output path from.
+func relImport(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(pathDir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+
+	return rel
+}
+
+func pathDir(path string) string {
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		return path[:idx]
+	}
+
+	return "."
+}
+
+func pathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
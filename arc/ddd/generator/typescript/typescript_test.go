@@ -0,0 +1,99 @@
+package typescript
+
+import (
+	. "github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/src/render"
+	"github.com/golangee/src/stdlib"
+	"strings"
+	"testing"
+)
+
+func TestRenderModule(t *testing.T) {
+	mod := NewModule("mod", "...is a test module.").
+		SetGenerator(NewGenerator().SetTypeScript(NewTypeScript().SetOutDir("../web"))).
+		AddExecutables(NewExecutable("srv", "...is a test server.").Application("$MOD/internal/tickets")).
+		AddBoundedContexts(NewBoundedContext("Tickets", "$MOD/internal/tickets").
+			AddCore(NewPackage("", "").
+				AddErrors(NewError("NotFound", "...indicates a missing ticket.").
+					AddFields(NewField("id", "...is the missing id.", NewTypeDecl(stdlib.UUID)))).
+				AddEnums(NewEnum("State", "...is the state of a ticket.").
+					AddCases(NewEnumCase("Open", "...is open."), NewEnumCase("Closed", "...is closed."))).
+				AddStructs(NewDTO("Ticket", "...is a ticket.").
+					AddFields(
+						NewField("ID", "...is the id.", NewTypeDecl(stdlib.UUID)),
+						NewField("State", "...is the state.", NewTypeDecl("State")),
+						NewField("Parent", "...is optional.", NewTypeDecl("*", NewTypeDecl("Ticket"))),
+						NewField("Attachment", "...is binary.", NewTypeDecl("[]", NewTypeDecl(stdlib.Byte))),
+						NewField("Tags", "...are tags.", NewTypeDecl(stdlib.Map, NewTypeDecl(stdlib.String), NewTypeDecl(stdlib.Time))),
+						NewPrivateField("mutex", "...is hidden.", NewTypeDecl("sync.Mutex")),
+					))).
+			AddUsecase(NewPackage("", "").
+				AddServices(NewService("Tickets", "...manages tickets.").
+					AddMethods(NewMethod("Find", "...finds a ticket.").
+						AddIn("ctx", "...is the context.", NewTypeDecl("context.Context")).
+						AddIn("id", "...is the id.", NewTypeDecl(stdlib.UUID)).
+						AddIn("timeout", "...is the timeout.", NewTypeDecl(stdlib.Duration)).
+						AddOut("", "...is the ticket.", NewTypeDecl("$BC/core.Ticket")).
+						AddOut("", "...if anything goes wrong.", NewTypeDecl(stdlib.Error)).
+						AddErrors(NewTypeDecl("$BC/core.NotFound")).
+						SetHttp(NewHttpEndpoint(HttpGet, "/tickets/{id}").BindQuery("timeout").MapError("$BC/core.NotFound", 404))))))
+
+	root := &render.Dir{}
+	if err := RenderModule(root, mod); err != nil {
+		t.Fatal(err)
+	}
+
+	web := root.Directory("..").Directory("web")
+	assertFile(t, web, "rest.ts", "export class ApiError<R extends ErrorResponse = ErrorResponse> extends Error {")
+
+	tickets := web.Directory("tickets")
+	assertFile(t, tickets, "core.ts",
+		`export type State = "Open" | "Closed";`,
+		"  ID: string;\n  /** State is the state. */\n  State: State;",
+		"  Parent: Ticket | null;",
+		"  Attachment: string;",
+		"  Tags: Record<string, string>;",
+	)
+
+	assertFile(t, tickets, "errors.ts",
+		`import {ApiError} from "../rest";`,
+		`export type TicketsErrorCase = "NotFound";`,
+		"  properties: {\n    /** id is the missing id. */\n    ID: string;\n  };",
+	)
+
+	assertFile(t, tickets, "client.ts",
+		`import type {Ticket} from "./core";`,
+		"async find(id: string, timeout: number, signal?: AbortSignal): Promise<Ticket> {",
+		"`/tickets/${encodeURIComponent(String(id))}`, new URLSearchParams([[\"timeout\", `${timeout}ns`]]), undefined, signal);",
+	)
+
+	for _, f := range tickets.Files {
+		if strings.Contains(string(f.Buf), "mutex") {
+			t.Fatalf("private field has been exported by %s", f.FileName)
+		}
+	}
+}
+
+func assertFile(t *testing.T, dir *render.Dir, name string, contains ...string) {
+	t.Helper()
+
+	if dir == nil {
+		t.Fatalf("missing directory of %s", name)
+	}
+
+	for _, f := range dir.Files {
+		if f.FileName != name {
+			continue
+		}
+
+		for _, s := range contains {
+			if !strings.Contains(string(f.Buf), s) {
+				t.Fatalf("expected %s to contain\n%s\nbut got\n%s", name, s, string(f.Buf))
+			}
+		}
+
+		return
+	}
+
+	t.Fatalf("missing file %s", name)
+}
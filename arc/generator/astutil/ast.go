@@ -246,7 +246,7 @@ func MakeTypeDecl(t *adl.TypeDecl) ast.TypeDecl {
 	}
 
 	if t.IsSlice() {
-		return ast.NewSliceTypeDecl(MakeTypeDecl(t.TypeParams[1]))
+		return ast.NewSliceTypeDecl(MakeTypeDecl(t.TypeParams[0]))
 	}
 
 	if t.IsArray() {
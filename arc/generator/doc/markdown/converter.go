@@ -70,10 +70,35 @@ func render(src doc.Node, dst *bytes.Buffer) {
 		fallthrough
 	case "h6":
 		writeHeading(e, dst)
+	case "p":
+		writeChildren(e, dst)
+		dst.WriteString("\n\n")
+	case "ul":
+		writeChildren(e, dst)
+		dst.WriteString("\n")
+	case "li":
+		dst.WriteString("- ")
+		writeChildren(e, dst)
+		dst.WriteString("\n")
+	case "a":
+		dst.WriteString("[")
+		writeChildren(e, dst)
+		dst.WriteString("](")
+		dst.WriteString(e.Attrs["href"])
+		dst.WriteString(")")
+	case "code":
+		dst.WriteString("`")
+		dst.WriteString(e.TextContent())
+		dst.WriteString("`")
+	case "pre":
+		// the lang attribute selects the highlighting or a diagram renderer like mermaid
+		dst.WriteString("```")
+		dst.WriteString(e.Attrs["lang"])
+		dst.WriteString("\n")
+		dst.WriteString(e.TextContent())
+		dst.WriteString("\n```\n\n")
 	default:
-		for _, child := range e.Children {
-			render(child, dst)
-		}
+		writeChildren(e, dst)
 	}
 
 }
@@ -90,5 +115,18 @@ func writeHeading(e *doc.Element, dst *bytes.Buffer) {
 
 	dst.WriteString(" ")
 	dst.WriteString(e.TextContent())
+	if id := e.Attrs["id"]; id != "" {
+		// custom heading ids are supported by hugos goldmark attributes
+		dst.WriteString(" {#")
+		dst.WriteString(id)
+		dst.WriteString("}")
+	}
+
 	dst.WriteString("\n")
 }
+
+func writeChildren(e *doc.Element, dst *bytes.Buffer) {
+	for _, child := range e.Children {
+		render(child, dst)
+	}
+}
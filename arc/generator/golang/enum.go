@@ -0,0 +1,184 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/golang"
+	"strconv"
+	"strings"
+)
+
+// AddEnum transpiles the given enum into an integer based type with a constant for each case. The type provides
+// String, MarshalText, UnmarshalText and Set (so it is also a flag.Value) and is accompanied by a Parse<Enum> and
+// an exhaustive <Enum>Values function. If the enum is a tagged union, the integer type becomes the <Enum>Kind
+// and the enum itself is a sealed interface, which is implemented by a struct for each case.
+func AddEnum(parent *ast.File, enum *adl.Enum) error {
+	name := enum.Name.String()
+	kindName := name
+	comment := enum.Comment.String()
+	if enum.IsUnion() {
+		kindName = name + "Kind"
+		comment = "...enumerates the cases of " + name + "."
+	}
+
+	var cases []string
+	for _, c := range enum.Cases {
+		cases = append(cases, c.Name.String())
+	}
+
+	parent.AddNodes(ast.NewTpl(enumSource(kindName, comment, enum.Cases)))
+	stereotype.PkgFrom(parent.Pkg()).AddEnum(kindName, cases)
+
+	if !enum.IsUnion() {
+		return nil
+	}
+
+	union := ast.NewInterface(name).
+		SetComment(enum.Comment.String()+"\n\nThis is a tagged union and each case is implemented by its own struct.").
+		AddMethods(
+			ast.NewFunc("Kind").
+				SetComment("...returns the case of this union.").
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(ast.Name(parent.Pkg().Path+"."+kindName)))),
+			ast.NewFunc("is"+name).
+				SetComment("...seals this union.").
+				SetVisibility(ast.Private),
+		)
+
+	parent.AddTypes(union)
+
+	for _, c := range enum.Cases {
+		typ := ast.NewStruct(name + MakePublic(c.Name.String())).
+			SetComment(c.Comment.String() + "\n\nIt is the '" + c.Name.String() + "' case of the " + name + " union.")
+
+		for _, field := range c.Fields {
+			f := ast.NewField(field.Name.String(), astutil.MakeTypeDecl(field.Type)).SetComment(field.Comment.String())
+			if field.Private {
+				f.SetVisibility(ast.Private)
+			}
+
+			typ.AddFields(f)
+		}
+
+		typ.AddMethods(
+			ast.NewFunc("Kind").
+				SetComment("...returns "+kindName+MakePublic(c.Name.String())+".").
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(ast.Name(parent.Pkg().Path+"."+kindName)))).
+				SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit(kindName+MakePublic(c.Name.String()))))),
+			ast.NewFunc("is"+name).
+				SetComment("...seals the "+name+" union.").
+				SetVisibility(ast.Private).
+				SetBody(ast.NewBlock()),
+		)
+
+		parent.AddTypes(typ)
+	}
+
+	return nil
+}
+
+// enumSource returns the Go source of the integer based enum type.
+func enumSource(typeName, comment string, cases []*adl.EnumCase) string {
+	rec := strings.ToLower(typeName[:1])
+	tmp := &strings.Builder{}
+	tmp.WriteString(formatComment(typeName, comment))
+	tmp.WriteString("type " + typeName + " int\n\n")
+
+	tmp.WriteString("const (\n")
+	for i, c := range cases {
+		tmp.WriteString(formatComment(typeName+MakePublic(c.Name.String()), c.Comment.String()))
+		tmp.WriteString(typeName + MakePublic(c.Name.String()))
+		if i == 0 {
+			tmp.WriteString(" " + typeName + " = iota")
+		}
+
+		tmp.WriteString("\n")
+	}
+
+	tmp.WriteString(")\n\n")
+
+	tmp.WriteString("// " + typeName + "Values returns all cases of " + typeName + " in declaration order.\n")
+	tmp.WriteString("func " + typeName + "Values() []" + typeName + " {\nreturn []" + typeName + "{\n")
+	for _, c := range cases {
+		tmp.WriteString(typeName + MakePublic(c.Name.String()) + ",\n")
+	}
+
+	tmp.WriteString("}\n}\n\n")
+
+	tmp.WriteString("// String returns the name of the case.\n")
+	tmp.WriteString("func (" + rec + " " + typeName + ") String() string {\nswitch " + rec + " {\n")
+	for _, c := range cases {
+		tmp.WriteString("case " + typeName + MakePublic(c.Name.String()) + ":\nreturn " + strconv.Quote(c.Name.String()) + "\n")
+	}
+
+	tmp.WriteString("default:\nreturn \"" + typeName + "(\" + {{.Use \"strconv.Itoa\"}}(int(" + rec + ")) + \")\"\n}\n}\n\n")
+
+	tmp.WriteString("// Parse" + typeName + " returns the case with the given name.\n")
+	tmp.WriteString("func Parse" + typeName + "(str string) (" + typeName + ", error) {\nswitch str {\n")
+	for _, c := range cases {
+		tmp.WriteString("case " + strconv.Quote(c.Name.String()) + ":\nreturn " + typeName + MakePublic(c.Name.String()) + ", nil\n")
+	}
+
+	tmp.WriteString("default:\nreturn 0, {{.Use \"fmt.Errorf\"}}(\"invalid " + typeName + " '%s'\", str)\n}\n}\n\n")
+
+	tmp.WriteString("// MarshalText encodes the case by its name and fails for undeclared values.\n")
+	tmp.WriteString("func (" + rec + " " + typeName + ") MarshalText() ([]byte, error) {\n")
+	tmp.WriteString("for _, v := range " + typeName + "Values() {\nif v == " + rec + " {\nreturn []byte(" + rec + ".String()), nil\n}\n}\n\n")
+	tmp.WriteString("return nil, {{.Use \"fmt.Errorf\"}}(\"invalid " + typeName + " %d\", int(" + rec + "))\n}\n\n")
+
+	tmp.WriteString("// UnmarshalText decodes the case from its name.\n")
+	tmp.WriteString("func (" + rec + " *" + typeName + ") UnmarshalText(text []byte) error {\n")
+	tmp.WriteString("v, err := Parse" + typeName + "(string(text))\nif err != nil {\nreturn err\n}\n\n*" + rec + " = v\n\nreturn nil\n}\n\n")
+
+	tmp.WriteString("// Set parses the case from its name and implements the flag.Value interface.\n")
+	tmp.WriteString("func (" + rec + " *" + typeName + ") Set(str string) error {\nreturn " + rec + ".UnmarshalText([]byte(str))\n}\n")
+
+	return tmp.String()
+}
+
+// formatComment returns the comment as a line comment block, prefixed by the identifier.
+func formatComment(ident, comment string) string {
+	comment = strings.TrimSpace(golang.DeEllipsis(ident, comment))
+	if comment == "" {
+		return ""
+	}
+
+	return "// " + strings.ReplaceAll(comment, "\n", "\n// ") + "\n"
+}
+
+// enumOf resolves the named type and returns its case names and the expressions of the according constants in
+// declaration order, if it is an enum. Qualified constants are imported, so that the expressions can only be used
+// within a template.
+func enumOf(ctx ast.Node, name ast.Name) (cases, consts []string, ok bool) {
+	pkg := astutil.Pkg(ctx)
+	if name.Qualifier() != "" {
+		pkg = nil
+		for _, p := range astutil.Mod(ctx).Pkgs {
+			if p.Path == name.Qualifier() {
+				pkg = p
+				break
+			}
+		}
+	}
+
+	if pkg == nil {
+		return nil, nil, false
+	}
+
+	cases, ok = stereotype.PkgFrom(pkg).EnumCases(name.Identifier())
+	if !ok {
+		return nil, nil, false
+	}
+
+	for _, c := range cases {
+		constName := name.Identifier() + MakePublic(c)
+		if name.Qualifier() != "" && name.Qualifier() != astutil.Pkg(ctx).Path {
+			constName = `{{.Use "` + name.Qualifier() + "." + constName + `"}}`
+		}
+
+		consts = append(consts, constName)
+	}
+
+	return cases, consts, true
+}
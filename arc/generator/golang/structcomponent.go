@@ -47,25 +47,34 @@ func AddComponent(parent *ast.File, compo *adl.Struct) (component *ast.Struct, _
 			injectFieldAssigns += shortName + "." + MakePrivate(injection.Name.String()) + "=" + injection.Name.String() + "\n"
 		}
 
-		c.SetBody(ast.NewBlock(ast.NewTpl(shortName + " := &" + compo.Name.String() + "{}\n" + injectFieldAssigns + "\nif err := " + shortName + ".init(); err != nil {\nreturn nil, {{.Use \"fmt.Errorf\"}}(\"cannot initialize '" + compo.Name.String() + "': %w\",err)}\n\n return " + shortName + ",nil\n")))
+		validate := ""
+		if HasConstraints(compo.Fields) {
+			validate = "if err := " + shortName + ".Validate(); err != nil {\nreturn nil, {{.Use \"fmt.Errorf\"}}(\"invalid '" + compo.Name.String() + "': %w\",err)}\n\n"
+		}
+
+		c.SetBody(ast.NewBlock(ast.NewTpl(shortName + " := &" + compo.Name.String() + "{}\n" + injectFieldAssigns + "\nif err := " + shortName + ".init(); err != nil {\nreturn nil, {{.Use \"fmt.Errorf\"}}(\"cannot initialize '" + compo.Name.String() + "': %w\",err)}\n\n" + validate + " return " + shortName + ",nil\n")))
 
 		component.AddFactoryRefs(c)
 		parent.AddNodes(c)
 	}
 
-	if requiresInitStub {
-		defaultComponent := ast.NewStruct(golang.MakePrivate("Default" + compo.Name.String())).
+	var defaultComponent *ast.Struct
+	if requiresInitStub || HasPredicates(compo.Fields) {
+		defaultComponent = ast.NewStruct(golang.MakePrivate("Default" + compo.Name.String())).
 			SetComment("...is an implementation stub for " + compo.Name.String() + ".\nThe sole purpose of this type is to mock the method contract and each method should be shadowed\nby a concrete implementation.").
 			SetVisibility(ast.Private)
 
+		component.AddEmbedded(ast.NewSimpleTypeDecl(ast.Name(defaultComponent.TypeName)))
+		parent.AddTypes(defaultComponent)
+	}
+
+	if requiresInitStub {
 		defaultComponent.AddMethods(ast.NewFunc("init").
 			AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
 			SetVisibility(ast.Private).
 			SetComment("...is invoked from the constructor/factory function to setup any pre-variants.\nShadow this method as required.").
 			SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit("nil")))))
 
-		component.AddEmbedded(ast.NewSimpleTypeDecl(ast.Name(defaultComponent.TypeName)))
-
 		for _, method := range compo.Methods {
 			aMethod := ast.NewFunc(method.Name.String()).SetComment(method.Comment.String() + "\nShadow this method as required.")
 			for _, param := range method.In {
@@ -73,15 +82,13 @@ func AddComponent(parent *ast.File, compo *adl.Struct) (component *ast.Struct, _
 			}
 
 			for _, param := range method.Out {
-				aMethod.AddResults(ast.NewParam(param.Name.String(), astutil.MakeTypeDecl(param.Type)).SetComment(param.Comment.String()))
+				aMethod.AddResults(ast.NewParam(ResultName(method, param), astutil.MakeTypeDecl(param.Type)).SetComment(param.Comment.String()))
 			}
 
 			aMethod.SetBody(ast.NewBlock(ast.NewTpl(`panic("not yet implemented")`)))
 			defaultComponent.AddMethods(aMethod)
 
 		}
-
-		parent.AddTypes(defaultComponent)
 	}
 
 	for _, decl := range compo.Inject {
@@ -117,6 +124,12 @@ func AddComponent(parent *ast.File, compo *adl.Struct) (component *ast.Struct, _
 		component.AddFields(f)
 	}
 
+	if HasConstraints(compo.Fields) {
+		if _, err := AddValidateFunc(component, compo.Fields, func(name string) string { return name }, defaultComponent); err != nil {
+			return nil, err
+		}
+	}
+
 	switch compo.Stereotype.String() {
 	case adl.Cfg:
 		stereotype.StructFrom(component).
@@ -125,3 +138,19 @@ func AddComponent(parent *ast.File, compo *adl.Struct) (component *ast.Struct, _
 
 	return component, nil
 }
+
+// ResultName returns the name of the result parameter. Go does not allow to mix named and unnamed results, so an
+// unnamed error is named err, if any other result of the method is named.
+func ResultName(method *adl.Method, param *adl.Param) string {
+	if param.Name.String() != "" || param.Type.Name.String() != stdlib.Error {
+		return param.Name.String()
+	}
+
+	for _, other := range method.Out {
+		if other.Name.String() != "" {
+			return "err"
+		}
+	}
+
+	return ""
+}
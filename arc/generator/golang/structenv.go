@@ -61,7 +61,14 @@ func AddParseEnvFunc(fieldPrefix string, node *ast.Struct) (*ast.Func, error) {
 					ast.NewAssign(ast.Exprs(lang.Attr(field.FieldName)), ast.AssignSimple, ast.Exprs(ast.NewIdent("parsed"))),
 				)
 			default:
-				return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct parse env function")
+				if _, _, ok := enumOf(node, t.SimpleName); !ok {
+					return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct parse env function")
+				}
+
+				parseBody = append(parseBody, ast.NewTpl(
+					"if err := "+fun.RecName()+"."+field.FieldName+".UnmarshalText([]byte(value)); err != nil {\n"+
+						"return {{.Use \"fmt.Errorf\"}}(\"unable to parse flag '"+flagName+"': %w\", err)\n}\n",
+				))
 			}
 
 		default:
@@ -14,7 +14,8 @@ import (
 // receiver and sets all defined struct variables to the flag ones. The go flags package to be
 // parsed by the according struct instance.
 // The naming is <a>-<b>-<c> for the flags. On unix, camel case is discouraged, so we have only the alternatives
-// of . _ or - and we decided for now, to use -.
+// of . _ or - and we decided for now, to use -. An empty prefix results in just <c>, e.g. for the flags of a
+// subcommand.
 func AddParseFlagFunc(fieldPrefix string, node *ast.Struct) (*ast.Func, error) {
 	fun := ast.NewFunc("ConfigureFlags").
 		SetPtrReceiver(true).
@@ -42,6 +43,10 @@ func AddParseFlagFunc(fieldPrefix string, node *ast.Struct) (*ast.Func, error) {
 		numFlags++
 
 		flagName := strings.ToLower(envNamePrefix + sep + field.FieldName)
+		if envNamePrefix == "" {
+			flagName = strings.ToLower(field.FieldName)
+		}
+
 		comment += " * " + field.FieldName + " is parsed from flag '" + flagName + "' if it has been set.\n"
 
 		stereotype.FieldFrom(field).SetProgramFlagVariable(flagName)
@@ -72,7 +77,11 @@ func AddParseFlagFunc(fieldPrefix string, node *ast.Struct) (*ast.Func, error) {
 				parseBody = lang.CallIdent("flags", "Float64Var", dst, ast.NewStrLit(flagName), def, ast.NewStrLit(comment))
 
 			default:
-				return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct parse flag function")
+				if _, _, ok := enumOf(node, t.SimpleName); !ok {
+					return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct parse flag function")
+				}
+
+				parseBody = lang.CallIdent("flags", "Var", dst, ast.NewStrLit(flagName), ast.NewStrLit(comment))
 			}
 		default:
 			return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct parse flag function")
@@ -63,6 +63,11 @@ func SimulateDefaultJson(node *ast.Struct) (interface{}, error) {
 
 				obj[field.FieldName] = v
 			default:
+				if cases, _, ok := enumOf(node, t.SimpleName); ok {
+					obj[field.FieldName] = cases[0]
+					continue
+				}
+
 				// try to resolve nested structs
 				node := astutil.Resolve(node, t.String())
 				if node == nil {
@@ -162,7 +167,13 @@ func AddResetFunc(node *ast.Struct) (*ast.Func, error) {
 
 				body.Add(ast.NewSym(ast.SymNewline))
 			default:
-				return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct reset function")
+				cases, consts, ok := enumOf(node, t.SimpleName)
+				if !ok {
+					return fun, token.NewPosError(astutil.WrapNode(field), field.FieldName+" "+field.FieldType.String()+": unsupported field type for struct reset function")
+				}
+
+				rawLiteral = cases[0]
+				body.Add(ast.NewTpl(fun.RecName() + "." + field.FieldName + " = " + consts[0] + "\n"))
 			}
 
 		default:
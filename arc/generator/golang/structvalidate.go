@@ -0,0 +1,197 @@
+package golang
+
+import (
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/astutil"
+	"github.com/golangee/architecture/arc/generator/stereotype"
+	"github.com/golangee/architecture/arc/token"
+	"github.com/golangee/src/ast"
+	"github.com/golangee/src/stdlib"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HasConstraints returns true, if any of the fields declares a constraint.
+func HasConstraints(fields []*adl.Field) bool {
+	for _, field := range fields {
+		if len(field.Constraints) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasPredicates returns true, if any of the fields declares a custom predicate.
+func HasPredicates(fields []*adl.Field) bool {
+	for _, field := range fields {
+		for _, c := range field.Constraints {
+			if c.Kind.String() == adl.ConstraintPredicate {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// AddValidateFunc appends a Validate method to the node, which checks all field constraints and returns either nil
+// or an *Error of the modules validation package, which lists all violations. The fieldName func maps the
+// declared field name to the name of the according struct field. Each custom predicate is declared as a method
+// of the given stub type, which is embedded by the node and should be shadowed by a concrete implementation.
+func AddValidateFunc(node *ast.Struct, fields []*adl.Field, fieldName func(name string) string, stub *ast.Struct) (*ast.Func, error) {
+	validationPkg := stereotype.ModFrom(astutil.Mod(node)).ValidationPkg()
+	if validationPkg == "" {
+		return nil, token.NewPosError(astutil.WrapNode(node), node.TypeName+": the module has no validation package")
+	}
+
+	if node.DefaultRecName == "" {
+		node.DefaultRecName = strings.ToLower(node.TypeName[:1])
+	}
+
+	rec := node.DefaultRecName
+	violation := `{{.Use "` + validationPkg + `.Violation"}}`
+	comment := "...checks the constraints of all fields and returns all violations at once.\n"
+	tmp := &strings.Builder{}
+	tmp.WriteString("var violations []" + violation + "\n\n")
+
+	for _, field := range fields {
+		name := field.Name.String()
+		attr := rec + "." + fieldName(name)
+		typeName := field.Type.Name.String()
+		collection := typeName == stdlib.Map || typeName == stdlib.List || field.Type.IsSlice()
+
+		for _, c := range field.Constraints {
+			var cond, msg string
+			switch c.Kind.String() {
+			case adl.ConstraintRequired:
+				msg = "is required"
+				switch {
+				case typeName == stdlib.String:
+					cond = attr + ` == ""`
+				case collection:
+					cond = "len(" + attr + ") == 0"
+				case field.Type.IsPtr():
+					cond = attr + " == nil"
+				case isNumber(typeName) || typeName == stdlib.Duration:
+					cond = attr + " == 0"
+				default:
+					cond = `{{.Use "reflect.ValueOf"}}(` + attr + ").IsZero()"
+				}
+			case adl.ConstraintMin, adl.ConstraintMax:
+				lit, err := numberLiteral(typeName, c.Value.String())
+				if err != nil {
+					return nil, token.NewPosError(c.Value, "invalid "+c.Kind.String()+" literal").SetCause(err)
+				}
+
+				if c.Kind.String() == adl.ConstraintMin {
+					cond = attr + " < " + lit
+					msg = "must be at least " + c.Value.String()
+				} else {
+					cond = attr + " > " + lit
+					msg = "must be at most " + c.Value.String()
+				}
+			case adl.ConstraintMinLength, adl.ConstraintMaxLength:
+				length := "len(" + attr + ")"
+				unit := "elements"
+				if typeName == stdlib.String {
+					length = `{{.Use "unicode/utf8.RuneCountInString"}}(` + attr + ")"
+					unit = "characters"
+				}
+
+				if c.Kind.String() == adl.ConstraintMinLength {
+					cond = length + " < " + c.Value.String()
+					msg = "must have at least " + c.Value.String() + " " + unit
+				} else {
+					cond = length + " > " + c.Value.String()
+					msg = "must have at most " + c.Value.String() + " " + unit
+				}
+			case adl.ConstraintPattern:
+				pattern := MakePrivate(node.TypeName) + MakePublic(name) + "Pattern"
+				astutil.File(node).AddNodes(ast.NewTpl("// " + pattern + " is the compiled pattern constraint of " + node.TypeName + "." + name + ".\n" +
+					"var " + pattern + " = {{.Use \"regexp.MustCompile\"}}(" + strconv.Quote(c.Value.String()) + ")\n"))
+				cond = "!" + pattern + ".MatchString(" + attr + ")"
+				msg = "must match " + c.Value.String()
+			case adl.ConstraintOneOf:
+				var conds, values []string
+				for _, value := range c.Values {
+					lit := strconv.Quote(value.String())
+					if typeName != stdlib.String {
+						l, err := numberLiteral(typeName, value.String())
+						if err != nil {
+							return nil, token.NewPosError(value, "invalid one-of literal").SetCause(err)
+						}
+
+						lit = l
+					}
+
+					conds = append(conds, attr+" != "+lit)
+					values = append(values, value.String())
+				}
+
+				cond = strings.Join(conds, " && ")
+				msg = "must be one of " + strings.Join(values, ", ")
+			case adl.ConstraintPredicate:
+				predicate := MakePrivate(c.Value.String())
+				stub.AddMethods(ast.NewFunc(predicate).
+					SetComment(c.Comment.String() + "\nShadow this method as required.").
+					SetVisibility(ast.Private).
+					AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+					SetBody(ast.NewBlock(ast.NewReturnStmt(ast.NewIdentLit("nil")))))
+
+				comment += " * " + name + " is checked by the custom predicate " + predicate + ".\n"
+				tmp.WriteString("if err := " + rec + "." + predicate + "(); err != nil {\n" +
+					"violations = append(violations, " + violation + "{Field: " + strconv.Quote(name) + ", Message: err.Error()})\n}\n\n")
+				continue
+			default:
+				return nil, token.NewPosError(c.Kind, "unknown constraint '"+c.Kind.String()+"'")
+			}
+
+			comment += " * " + name + " " + msg + ".\n"
+			tmp.WriteString("if " + cond + " {\n" +
+				"violations = append(violations, " + violation + "{Field: " + strconv.Quote(name) + ", Message: " + strconv.Quote(msg) + "})\n}\n\n")
+		}
+	}
+
+	tmp.WriteString("if len(violations) > 0 {\nreturn &{{.Use \"" + validationPkg + ".Error\"}}{Type: " + strconv.Quote(node.TypeName) + ", Violations: violations}\n}\n\nreturn nil\n")
+
+	fun := ast.NewFunc("Validate").
+		SetComment(comment).
+		SetPtrReceiver(true).
+		SetRecName(rec).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.Error))).
+		SetBody(ast.NewBlock(ast.NewTpl(tmp.String())))
+
+	node.AddMethods(fun)
+
+	return fun, nil
+}
+
+// isNumber returns true, if the type is a standard library integer or floating point type.
+func isNumber(typeName string) bool {
+	switch typeName {
+	case stdlib.Int, stdlib.Int16, stdlib.Int32, stdlib.Int64, stdlib.Byte, stdlib.Float32, stdlib.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// numberLiteral returns the Go literal for a constraint literal. Durations are converted into nanoseconds.
+func numberLiteral(typeName, literal string) (string, error) {
+	if typeName != stdlib.Duration {
+		if _, err := strconv.ParseFloat(literal, 64); err != nil {
+			return "", err
+		}
+
+		return literal, nil
+	}
+
+	d, err := time.ParseDuration(literal)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(int64(d), 10), nil
+}
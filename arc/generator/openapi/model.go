@@ -0,0 +1,156 @@
+// Package openapi contains a minimal model of an OpenAPI 3 document, which is sufficient to describe the
+// generated http driver adapters. Maps are encoded with sorted keys, so that the documents are stable.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the OpenAPI specification version of the generated documents.
+const Version = "3.0.3"
+
+// Document is the root object of an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi"`
+	Info       Info                 `json:"info" yaml:"info"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components Components           `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// NewDocument allocates an empty Document.
+func NewDocument(title, description, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       title,
+			Description: description,
+			Version:     version,
+		},
+		Paths: map[string]*PathItem{},
+		Components: Components{
+			Schemas:   map[string]*Schema{},
+			Responses: map[string]*Response{},
+		},
+	}
+}
+
+// Path returns the PathItem of the path template and allocates it if required.
+func (d *Document) Path(path string) *PathItem {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	return item
+}
+
+// JSON returns the indented json encoding of the document.
+func (d *Document) JSON() ([]byte, error) {
+	buf, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, '\n'), nil
+}
+
+// YAML returns the yaml encoding of the document.
+func (d *Document) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// Components contains the reusable schemas and responses.
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Responses map[string]*Response `json:"responses,omitempty" yaml:"responses,omitempty"`
+}
+
+// PathItem contains the operations of a single path template.
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty" yaml:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty" yaml:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+// Operation describes a single http method of a path.
+type Operation struct {
+	OperationID string               `json:"operationId" yaml:"operationId"`
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+}
+
+// Parameter is either a path or a query value.
+type Parameter struct {
+	Name        string  `json:"name" yaml:"name"`
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *Schema `json:"schema" yaml:"schema"`
+}
+
+// RequestBody describes the json request body.
+type RequestBody struct {
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content" yaml:"content"`
+}
+
+// Response describes a response by its status code or refers to a reusable response.
+type Response struct {
+	Ref         string                `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType contains the schema of a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema" yaml:"schema"`
+}
+
+// Schema is the subset of the OpenAPI schema object, which is required to describe the ADL types.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+}
+
+// Ref creates a Schema which refers to the named component schema.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
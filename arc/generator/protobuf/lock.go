@@ -0,0 +1,132 @@
+// Package protobuf contains a minimal model of proto3 files and the lock, which keeps the numbers of the
+// generated fields and enum values stable across generations.
+package protobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Lock records the numbers of all fields and enum values, which have ever been generated. A number is never
+// reassigned, even if its field has been removed, because that would break the wire compatibility.
+type Lock struct {
+	Messages map[string]*Numbers `json:"messages"` // by full qualified name of the message or enum, e.g. tickets.Ticket
+}
+
+// Numbers contains the numbers of the fields of a message or the values of an enum.
+type Numbers struct {
+	Fields   map[string]int `json:"fields"`             // by field or value name
+	Reserved map[string]int `json:"reserved,omitempty"` // removed fields, whose numbers must not be used again
+}
+
+// NewLock allocates an empty Lock.
+func NewLock() *Lock {
+	return &Lock{Messages: map[string]*Numbers{}}
+}
+
+// ReadLock reads the json encoded lock file. A missing file is an error, which wraps os.ErrNotExist, because
+// silently starting over would renumber all fields.
+func ReadLock(filename string) (*Lock, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read protobuf lock: %w", err)
+	}
+
+	lock, err := ParseLock(buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode protobuf lock '%s': %w", filename, err)
+	}
+
+	return lock, nil
+}
+
+// ParseLock decodes the json encoded lock.
+func ParseLock(buf []byte) (*Lock, error) {
+	lock := NewLock()
+	if err := json.Unmarshal(buf, lock); err != nil {
+		return nil, err
+	}
+
+	if lock.Messages == nil {
+		lock.Messages = map[string]*Numbers{}
+	}
+
+	return lock, nil
+}
+
+// Number returns the number of the field of the message. An unknown field gets the next number, which has never
+// been used by the message. A removed field, which is declared again, gets its former number back.
+func (l *Lock) Number(msg, field string) int {
+	numbers := l.numbers(msg)
+	if n, ok := numbers.Fields[field]; ok {
+		return n
+	}
+
+	if n, ok := numbers.Reserved[field]; ok {
+		delete(numbers.Reserved, field)
+		numbers.Fields[field] = n
+
+		return n
+	}
+
+	max := 0
+	for _, m := range []map[string]int{numbers.Fields, numbers.Reserved} {
+		for _, n := range m {
+			if n > max {
+				max = n
+			}
+		}
+	}
+
+	numbers.Fields[field] = max + 1
+
+	return max + 1
+}
+
+// Retain reserves the numbers of all fields of the message, which are not declared anymore, and returns all
+// reserved fields.
+func (l *Lock) Retain(msg string, fields []string) map[string]int {
+	numbers := l.numbers(msg)
+	declared := map[string]bool{}
+	for _, field := range fields {
+		declared[field] = true
+	}
+
+	for field, n := range numbers.Fields {
+		if !declared[field] {
+			delete(numbers.Fields, field)
+			numbers.Reserved[field] = n
+		}
+	}
+
+	return numbers.Reserved
+}
+
+// JSON returns the indented json encoding with sorted keys, so that it can be kept under version control.
+func (l *Lock) JSON() ([]byte, error) {
+	buf, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(buf, '\n'), nil
+}
+
+func (l *Lock) numbers(msg string) *Numbers {
+	numbers, ok := l.Messages[msg]
+	if !ok {
+		numbers = &Numbers{}
+		l.Messages[msg] = numbers
+	}
+
+	if numbers.Fields == nil {
+		numbers.Fields = map[string]int{}
+	}
+
+	if numbers.Reserved == nil {
+		numbers.Reserved = map[string]int{}
+	}
+
+	return numbers
+}
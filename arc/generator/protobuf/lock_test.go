@@ -0,0 +1,75 @@
+package protobuf
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockNumbers(t *testing.T) {
+	lock := NewLock()
+	if got := lock.Number("tickets.Ticket", "id"); got != 1 {
+		t.Fatalf("expected first number 1 but got %d", got)
+	}
+
+	if got := lock.Number("tickets.Ticket", "when"); got != 2 {
+		t.Fatalf("expected next number 2 but got %d", got)
+	}
+
+	if got := lock.Number("tickets.Ticket", "id"); got != 1 {
+		t.Fatalf("expected stable number 1 but got %d", got)
+	}
+
+	reserved := lock.Retain("tickets.Ticket", []string{"when"})
+	if !reflect.DeepEqual(reserved, map[string]int{"id": 1}) {
+		t.Fatalf("expected removed field to be reserved but got %v", reserved)
+	}
+
+	if got := lock.Number("tickets.Ticket", "other"); got != 3 {
+		t.Fatalf("expected reserved numbers to be skipped but got %d", got)
+	}
+
+	if got := lock.Number("tickets.Ticket", "id"); got != 1 {
+		t.Fatalf("expected former number 1 of a returning field but got %d", got)
+	}
+}
+
+func TestReadLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protolock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "lock.json")
+	if _, err := ReadLock(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing file but got %v", err)
+	}
+
+	lock := NewLock()
+	lock.Number("tickets.Ticket", "id")
+	lock.Number("tickets.Ticket", "when")
+	lock.Retain("tickets.Ticket", []string{"when"})
+
+	buf, err := lock.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filename, buf, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadLock(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, lock) {
+		t.Fatalf("expected %v but got %v", lock.Messages["tickets.Ticket"], got.Messages["tickets.Ticket"])
+	}
+}
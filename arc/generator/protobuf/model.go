@@ -0,0 +1,244 @@
+package protobuf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File is a proto3 source file.
+type File struct {
+	Preamble  string // emitted as line comments before the syntax declaration.
+	Package   string // the proto package, e.g. tickets.
+	GoPackage string // the full qualified path of the generated go package.
+	Imports   []string
+	Services  []*Service
+	Messages  []*Message
+	Enums     []*Enum
+}
+
+// Import adds the path of another proto file, if not yet imported.
+func (f *File) Import(path string) {
+	for _, imp := range f.Imports {
+		if imp == path {
+			return
+		}
+	}
+
+	f.Imports = append(f.Imports, path)
+}
+
+// Message returns the declared message of the given name or nil.
+func (f *File) Message(name string) *Message {
+	for _, msg := range f.Messages {
+		if msg.Name == name {
+			return msg
+		}
+	}
+
+	return nil
+}
+
+// Service is a set of rpcs.
+type Service struct {
+	Comment string
+	Name    string
+	Rpcs    []*Rpc
+}
+
+// Rpc is an unary method of a service.
+type Rpc struct {
+	Comment  string
+	Name     string
+	Request  string // the name of the request message.
+	Response string // the name of the response message.
+}
+
+// Message is a record of numbered fields.
+type Message struct {
+	Comment  string
+	Name     string
+	Fields   []*Field
+	Oneofs   []*Oneof
+	Reserved map[string]int // the numbers of removed fields by their former names.
+}
+
+// Oneof is a set of fields of a message, of which at most one is set.
+type Oneof struct {
+	Comment string
+	Name    string
+	Fields  []*Field
+}
+
+// Field is a numbered field of a message.
+type Field struct {
+	Comment  string
+	Name     string
+	Type     string // e.g. int64, google.protobuf.Timestamp or map<string, Ticket>.
+	Number   int
+	Repeated bool
+	Optional bool // explicit presence of a scalar.
+}
+
+// Enum is a closed set of numbered values. The first value must be the unspecified value with the number 0.
+type Enum struct {
+	Comment  string
+	Name     string
+	Values   []*EnumValue
+	Reserved map[string]int // the numbers of removed values by their former names.
+}
+
+// EnumValue is a single named number of an enum.
+type EnumValue struct {
+	Comment string
+	Name    string
+	Number  int
+}
+
+// String returns the source of the file.
+func (f *File) String() string {
+	w := &writer{}
+	for _, line := range strings.Split(f.Preamble, "\n") {
+		w.line(strings.TrimRight("// "+line, " "))
+	}
+
+	w.line("")
+	w.line(`syntax = "proto3";`)
+	w.line("")
+	w.line("package " + f.Package + ";")
+	w.line("")
+
+	if len(f.Imports) > 0 {
+		imports := append([]string{}, f.Imports...)
+		sort.Strings(imports)
+		for _, imp := range imports {
+			w.line("import " + strconv.Quote(imp) + ";")
+		}
+
+		w.line("")
+	}
+
+	w.line("option go_package = " + strconv.Quote(f.GoPackage) + ";")
+
+	for _, service := range f.Services {
+		w.line("")
+		w.comment(service.Comment)
+		w.line("service " + service.Name + " {")
+		w.indent++
+		for i, rpc := range service.Rpcs {
+			if i > 0 {
+				w.line("")
+			}
+
+			w.comment(rpc.Comment)
+			w.line("rpc " + rpc.Name + "(" + rpc.Request + ") returns (" + rpc.Response + ");")
+		}
+
+		w.indent--
+		w.line("}")
+	}
+
+	for _, msg := range f.Messages {
+		w.line("")
+		w.comment(msg.Comment)
+		w.line("message " + msg.Name + " {")
+		w.indent++
+		w.reserved(msg.Reserved)
+		for _, field := range msg.Fields {
+			w.field(field)
+		}
+
+		for _, oneof := range msg.Oneofs {
+			w.comment(oneof.Comment)
+			w.line("oneof " + oneof.Name + " {")
+			w.indent++
+			for _, field := range oneof.Fields {
+				w.field(field)
+			}
+
+			w.indent--
+			w.line("}")
+		}
+
+		w.indent--
+		w.line("}")
+	}
+
+	for _, enum := range f.Enums {
+		w.line("")
+		w.comment(enum.Comment)
+		w.line("enum " + enum.Name + " {")
+		w.indent++
+		w.reserved(enum.Reserved)
+		for _, value := range enum.Values {
+			w.comment(value.Comment)
+			w.line(value.Name + " = " + strconv.Itoa(value.Number) + ";")
+		}
+
+		w.indent--
+		w.line("}")
+	}
+
+	return w.String()
+}
+
+type writer struct {
+	strings.Builder
+	indent int
+}
+
+func (w *writer) line(s string) {
+	if s != "" {
+		w.WriteString(strings.Repeat("  ", w.indent))
+	}
+
+	w.WriteString(s + "\n")
+}
+
+func (w *writer) comment(s string) {
+	if s == "" {
+		return
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		w.line(strings.TrimRight("// "+line, " "))
+	}
+}
+
+func (w *writer) field(field *Field) {
+	w.comment(field.Comment)
+	decl := field.Type + " " + field.Name + " = " + strconv.Itoa(field.Number) + ";"
+	switch {
+	case field.Repeated:
+		decl = "repeated " + decl
+	case field.Optional:
+		decl = "optional " + decl
+	}
+
+	w.line(decl)
+}
+
+// reserved declares the numbers and names of removed fields, so that protoc rejects their reuse.
+func (w *writer) reserved(reserved map[string]int) {
+	if len(reserved) == 0 {
+		return
+	}
+
+	var names []string
+	var numbers []int
+	for name, n := range reserved {
+		names = append(names, strconv.Quote(name))
+		numbers = append(numbers, n)
+	}
+
+	sort.Strings(names)
+	sort.Ints(numbers)
+
+	var tmp []string
+	for _, n := range numbers {
+		tmp = append(tmp, strconv.Itoa(n))
+	}
+
+	w.line("reserved " + strings.Join(tmp, ", ") + ";")
+	w.line("reserved " + strings.Join(names, ", ") + ";")
+}
@@ -0,0 +1,45 @@
+package stereotype
+
+import "github.com/golangee/src/ast"
+
+// Interface contains all stereotype annotations for an interface instance.
+type Interface struct {
+	obj *ast.Interface
+}
+
+func InterfaceFrom(obj *ast.Interface) Interface {
+	return Interface{obj: obj}
+}
+
+// SetIsEventPublisher marks this interface as the port to publish the domain events of its package.
+func (s Interface) SetIsEventPublisher(isPublisher bool) Interface {
+	s.obj.PutValue(kEventPublisher, isPublisher)
+	return s
+}
+
+// IsEventPublisher returns only true, if this interface is an event publisher port.
+func (s Interface) IsEventPublisher() bool {
+	v := s.obj.Value(kEventPublisher)
+	if f, ok := v.(bool); ok {
+		return f
+	}
+
+	return false
+}
+
+// SetIsDatabase marks this interface as the connection to a database. The package declares the functions
+// Open(Options) and Migrate(DBTX) to connect to the database and to apply the pending migrations.
+func (s Interface) SetIsDatabase(isDatabase bool) Interface {
+	s.obj.PutValue(kDatabase, isDatabase)
+	return s
+}
+
+// IsDatabase returns only true, if this interface is a database connection.
+func (s Interface) IsDatabase() bool {
+	v := s.obj.Value(kDatabase)
+	if f, ok := v.(bool); ok {
+		return f
+	}
+
+	return false
+}
@@ -51,6 +51,20 @@ func (c Mod) SetProjectIdent(ident string) {
 	c.obj.PutValue(kPrjShortName, ident)
 }
 
+// ValidationPkg returns the path of the package which declares the violation types or the empty string.
+func (c Mod) ValidationPkg() string {
+	v := c.obj.Value(kValidationPkg)
+	if v == nil {
+		return ""
+	}
+
+	return v.(string)
+}
+
+func (c Mod) SetValidationPkg(path string) {
+	c.obj.PutValue(kValidationPkg, path)
+}
+
 func (c Mod) Docs() *Docs {
 	v := c.obj.Value(kModuleDocs)
 	if f, ok := v.(*Docs); ok {
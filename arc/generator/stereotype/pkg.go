@@ -31,6 +31,41 @@ func (c Pkg) IsCMDPkg() bool {
 	return false
 }
 
+// SetIsWasmPkg marks this main package as a js/wasm only entry point.
+func (c Pkg) SetIsWasmPkg(isWasm bool) Pkg {
+	c.obj.PutValue(kWasmPkg, isWasm)
+	return c
+}
+
+// IsWasmPkg returns only true, if the main package can only be build for js/wasm.
+func (c Pkg) IsWasmPkg() bool {
+	v := c.obj.Value(kWasmPkg)
+	if f, ok := v.(bool); ok {
+		return f
+	}
+
+	return false
+}
+
+// AddEnum declares that the package contains an enum type with the given cases in declaration order.
+func (c Pkg) AddEnum(typeName string, cases []string) Pkg {
+	enums, _ := c.obj.Value(kEnums).(map[string][]string)
+	if enums == nil {
+		enums = map[string][]string{}
+		c.obj.PutValue(kEnums, enums)
+	}
+
+	enums[typeName] = cases
+	return c
+}
+
+// EnumCases returns the case names of the named enum type or false, if the package contains no such enum.
+func (c Pkg) EnumCases(typeName string) ([]string, bool) {
+	enums, _ := c.obj.Value(kEnums).(map[string][]string)
+	cases, ok := enums[typeName]
+	return cases, ok
+}
+
 // FindStructs applies the predicate on each contained struct. However,
 // only non-raw types can be inspected.
 func (c Pkg) FindStructs(predicate func(s Struct) bool) []*ast.Struct {
@@ -48,6 +83,22 @@ func (c Pkg) FindStructs(predicate func(s Struct) bool) []*ast.Struct {
 	return r
 }
 
+// FindInterfaces applies the predicate on each contained interface.
+func (c Pkg) FindInterfaces(predicate func(s Interface) bool) []*ast.Interface {
+	var r []*ast.Interface
+	for _, file := range c.obj.PkgFiles {
+		for _, namedType := range file.Types() {
+			if s, ok := namedType.(*ast.Interface); ok {
+				if predicate(InterfaceFrom(s)) {
+					r = append(r, s)
+				}
+			}
+		}
+	}
+
+	return r
+}
+
 // WithPkg visits recursively all Pkg elements.
 func WithPkg(n ast.Node, f func(pkg Pkg) error) error {
 	return ast.ForEach(n, func(n ast.Node) error {
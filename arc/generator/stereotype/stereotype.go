@@ -21,6 +21,24 @@ const (
 	// kService declares a struct as an application wide (singleton) service.
 	kService secretKey = "kService"
 
+	// kIdentityAccessor denotes the name of the method which returns the identity of an entity.
+	kIdentityAccessor secretKey = "kIdentityAccessor"
+
+	// kEventPublisher declares an interface as the port to publish the domain events of a package.
+	kEventPublisher secretKey = "kEventPublisher"
+
+	// kEventSubscriptions denotes the full qualified names of all events, which are handled by a service.
+	kEventSubscriptions secretKey = "kEventSubscriptions"
+
+	// kLifecycle declares that a service is started and stopped together with the application.
+	kLifecycle secretKey = "kLifecycle"
+
+	// kEnums is attached to a package and maps the names of all contained enum types to their case names.
+	kEnums secretKey = "kEnums"
+
+	// kDatabase declares an interface as the connection to a database, which is opened and migrated by the application.
+	kDatabase secretKey = "kDatabase"
+
 	// denotes if is mysql related.
 	kMySQLRelated secretKey = "kMySQLRelated"
 
@@ -36,6 +54,9 @@ const (
 	// kCMDPkg declares a package as a main package entry point. Package must be a "main" package.
 	kCMDPkg secretKey = "kCMDPkg"
 
+	// kWasmPkg declares a main package, which can only be build for the js/wasm platform.
+	kWasmPkg secretKey = "kWasmPkg"
+
 	// kModuleDocs declares the root of all available documentations about a module.
 	kModuleDocs secretKey = "kModuleDocs"
 
@@ -43,6 +64,10 @@ const (
 	// memorable identifier for the entire module.
 	kModShortName secretKey = "kModShortName"
 
+	// kValidationPkg is attached to a module and contains the path of the package, which declares the
+	// violation types of all generated Validate methods.
+	kValidationPkg secretKey = "kValidationPkg"
+
 	// kPrjShortName is attached to a module and contains a short and
 	// memorable identifier for the entire project (containing this and other modules).
 	kPrjShortName secretKey = "kPrjShortName"
@@ -42,6 +42,52 @@ func (s Struct) IsService() bool {
 	return false
 }
 
+// SetIdentityAccessor marks this struct as an entity, whose identity is returned by the named method.
+func (s Struct) SetIdentityAccessor(methodName string) Struct {
+	s.obj.PutValue(kIdentityAccessor, methodName)
+	return s
+}
+
+// IdentityAccessor returns the method name which returns the identity of an entity.
+func (s Struct) IdentityAccessor() (string, bool) {
+	val := s.obj.Value(kIdentityAccessor)
+	if val == nil {
+		return "", false
+	}
+
+	return val.(string), true
+}
+
+// SetEventSubscriptions declares the full qualified names of the events, which are handled by this service.
+func (s Struct) SetEventSubscriptions(events []string) Struct {
+	s.obj.PutValue(kEventSubscriptions, events)
+	return s
+}
+
+// EventSubscriptions returns the full qualified names of the handled events.
+func (s Struct) EventSubscriptions() []string {
+	if v, ok := s.obj.Value(kEventSubscriptions).([]string); ok {
+		return v
+	}
+
+	return nil
+}
+
+// SetLifecycle marks this service to provide the Start and Stop hooks of the application lifecycle.
+func (s Struct) SetLifecycle(lifecycle bool) Struct {
+	s.obj.PutValue(kLifecycle, lifecycle)
+	return s
+}
+
+// IsLifecycle returns only true, if this service is started and stopped together with the application.
+func (s Struct) IsLifecycle() bool {
+	if v, ok := s.obj.Value(kLifecycle).(bool); ok {
+		return v
+	}
+
+	return false
+}
+
 // SetIsDatabaseConfiguration marks this struct as a public configuration object. It provides environmental and program flags.
 func (s Struct) SetIsDatabaseConfiguration(isDbConfig bool) Struct {
 	s.obj.PutValue(kDBConfiguration, isDbConfig)
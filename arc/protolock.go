@@ -0,0 +1,47 @@
+package arc
+
+import (
+	"errors"
+	"github.com/golangee/architecture/arc/adl"
+	"github.com/golangee/architecture/arc/generator/protobuf"
+	"github.com/golangee/architecture/arc/token"
+	"os"
+	"path/filepath"
+)
+
+// LoadProtoLocks reads the protobuf lock of each go module from the given directory, which is the directory the
+// artifact of Render is written into. A module without a lock file starts with an empty lock, but only as long
+// as there are no proto files from a former generation. Otherwise the lock has been lost and all field numbers
+// would change silently.
+func LoadProtoLocks(prj *adl.Project, dir string) (ProtoLocks, error) {
+	locks := ProtoLocks{}
+	for _, module := range prj.Modules {
+		if module.Generator == nil || module.Generator.Go == nil || module.Generator.Go.ProtoLock.String() == "" {
+			continue
+		}
+
+		filename := filepath.Join(dir, filepath.FromSlash(module.Generator.Go.ProtoLock.String()))
+		lock, err := protobuf.ReadLock(filename)
+		if err == nil {
+			locks[module.Name.String()] = lock
+			continue
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, token.NewPosError(module.Generator.Go.ProtoLock, "unable to load protobuf lock").SetCause(err)
+		}
+
+		protos, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(module.Generator.OutDir.String()), "internal", "rpc", "*", "*.proto"))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(protos) > 0 {
+			return nil, token.NewPosError(module.Generator.Go.ProtoLock, "protobuf lock is missing but the module contains generated proto files like "+protos[0]+", restore the lock to keep the field numbers stable")
+		}
+
+		locks[module.Name.String()] = protobuf.NewLock()
+	}
+
+	return locks, nil
+}
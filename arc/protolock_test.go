@@ -0,0 +1,80 @@
+package arc
+
+import (
+	"github.com/golangee/architecture/arc/adl/parser"
+	"github.com/golangee/architecture/arc/generator/protobuf"
+	"github.com/golangee/architecture/arc/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadProtoLocks(t *testing.T) {
+	prj, err := parser.Parse("demo.adl", []byte(`project demo "...is a test project."
+
+module demo-srv "...is a test module." {
+    generator {
+        out "server"
+        go {
+            module "example.com/demo"
+            protolock "demo.protolock.json"
+        }
+    }
+}`))
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	dir, err := ioutil.TempDir("", "protolock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	// the first generation starts without a lock
+	locks, err := LoadProtoLocks(prj, dir)
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	if lock := locks["demo-srv"]; lock == nil || len(lock.Messages) != 0 {
+		t.Fatalf("expected an empty lock but got %v", locks)
+	}
+
+	// a former generation has been written but its lock is gone
+	pbDir := filepath.Join(dir, "server", "internal", "rpc", "ticketspb")
+	if err := os.MkdirAll(pbDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(pbDir, "tickets.proto"), []byte(`syntax = "proto3";`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadProtoLocks(prj, dir); err == nil || !strings.Contains(token.Explain(err), "protobuf lock is missing") {
+		t.Fatalf("expected a missing lock but got %v", err)
+	}
+
+	lock := protobuf.NewLock()
+	lock.Number("tickets.Ticket", "id")
+	buf, err := lock.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "demo.protolock.json"), buf, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	locks, err = LoadProtoLocks(prj, dir)
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	if n := locks["demo-srv"].Number("tickets.Ticket", "id"); n != 1 {
+		t.Fatalf("expected the locked number 1 but got %d", n)
+	}
+}
@@ -5,13 +5,32 @@ import (
 	"github.com/golangee/architecture/arc/adl"
 	"github.com/golangee/architecture/arc/ddd/generator/golang"
 	"github.com/golangee/architecture/arc/ddd/generator/markdown"
+	"github.com/golangee/architecture/arc/ddd/generator/typescript"
+	"github.com/golangee/architecture/arc/generator/protobuf"
 	"github.com/golangee/architecture/arc/token"
 	"github.com/golangee/src/ast"
 	golang2 "github.com/golangee/src/golang"
 	"github.com/golangee/src/render"
 )
 
-func Render(prj *adl.Project) (render.Artifact, error) {
+// ProtoLocks contains the protobuf lock of each module by its name.
+type ProtoLocks map[string]*protobuf.Lock
+
+// Render validates and generates the project. If the project opts in, its identifiers are linted against the
+// ubiquitous language as well, see adl.Lint. Rendering never touches the file system, so the protobuf lock of
+// each module which exposes grpc methods must be loaded beforehand, see LoadProtoLocks. The locks are updated
+// with the numbers of new fields and are emitted as part of the artifact.
+func Render(prj *adl.Project, locks ProtoLocks) (render.Artifact, error) {
+	if err := adl.Validate(prj); err != nil {
+		return nil, err
+	}
+
+	if prj.Lint {
+		if err := adl.Lint(prj); err != nil {
+			return nil, err
+		}
+	}
+
 	astPrj := ast.NewPrj(prj.Name.String())
 
 	for _, module := range prj.Modules {
@@ -22,11 +41,15 @@ func Render(prj *adl.Project) (render.Artifact, error) {
 		noGenSettings := true
 		if module.Generator.Go != nil {
 			noGenSettings = false
-			if err := golang.RenderModule(astPrj, prj, module); err != nil {
+			if err := golang.RenderModule(astPrj, prj, module, locks[module.Name.String()]); err != nil {
 				return nil, token.NewPosError(module.Name, "unable to render golang module").SetCause(err)
 			}
 		}
 
+		if module.Generator.TypeScript != nil {
+			noGenSettings = false
+		}
+
 		if noGenSettings {
 			return nil, token.NewPosError(module.Name, "module has no generator settings details")
 		}
@@ -42,5 +65,25 @@ func Render(prj *adl.Project) (render.Artifact, error) {
 		return a, fmt.Errorf("unable to render prj %v: %w", astPrj.Name, err)
 	}
 
+	for _, module := range prj.Modules {
+		if module.Generator.Go == nil {
+			continue
+		}
+
+		if err := golang.RenderProtoLock(a.(*render.Dir), module, locks[module.Name.String()]); err != nil {
+			return a, token.NewPosError(module.Name, "unable to render protobuf lock").SetCause(err)
+		}
+	}
+
+	for _, module := range prj.Modules {
+		if module.Generator.TypeScript == nil {
+			continue
+		}
+
+		if err := typescript.RenderModule(a.(*render.Dir), module); err != nil {
+			return a, token.NewPosError(module.Name, "unable to render typescript module").SetCause(err)
+		}
+	}
+
 	return a, nil
 }
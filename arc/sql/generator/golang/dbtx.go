@@ -2,6 +2,7 @@ package golang
 
 import (
 	"github.com/golangee/architecture/arc/generator/golang"
+	"github.com/golangee/architecture/arc/generator/stereotype"
 	"github.com/golangee/architecture/arc/sql"
 	"github.com/golangee/src/ast"
 	"github.com/golangee/src/stdlib"
@@ -18,8 +19,9 @@ func RenderDBTX(dst *ast.Prj, src *sql.Ctx) error {
 	pkgName := src.Pkg.String()
 	file := golang.MkFile(dst, modName, pkgName, filenameDBTX)
 
+	dbtx := ast.NewInterface("DBTX")
 	file.AddTypes(
-		ast.NewInterface("DBTX").
+		dbtx.
 			SetComment("...abstracts from a concrete sql.DB or sql.Tx dependency.").
 			AddMethods(
 				ast.NewFunc("ExecContext").
@@ -52,6 +54,8 @@ func RenderDBTX(dst *ast.Prj, src *sql.Ctx) error {
 
 	)
 
+	stereotype.InterfaceFrom(dbtx).SetIsDatabase(true)
+
 	if err := renderOpen(file, src); err != nil {
 		return err
 	}
@@ -59,10 +63,9 @@ func RenderDBTX(dst *ast.Prj, src *sql.Ctx) error {
 	return nil
 }
 
-
+// renderOpen emits the Open function. The mysql driver is not imported, because registering a driver is up to
+// the application, which opens the database.
 func renderOpen(file *ast.File, src *sql.Ctx) error {
-	file.AddNodes(ast.NewImport("_", "github.com/go-sql-driver/mysql").SetComment("side-effect-only import to load mysql driver"))
-
 	file.AddFuncs(
 		ast.NewFunc("Open").
 			SetComment("...tries to connect to a mysql compatible database.").
@@ -77,7 +80,7 @@ func renderOpen(file *ast.File, src *sql.Ctx) error {
 				ast.NewBlock(
 					lang.TryDefine(
 						ast.NewIdent("db"),
-						lang.CallStatic("database/sql.Open", lang.CallIdent("opts", "DSN")),
+						lang.CallStatic("database/sql.Open", ast.NewStrLit("mysql"), lang.CallIdent("opts", "DSN")),
 						"cannot open mysql database",
 					),
 
@@ -110,7 +110,7 @@ func renderMigrationFunc(dst *ast.File, src *sql.Ctx, tableName string) error {
 				ast.NewBlock(
 					ast.NewTpl(`
 						// if we can start a transaction on our own, do so and invoke recursively
-						if db,ok := db.(*sql.DB);ok{
+						if db,ok := db.(*{{.Use "database/sql.DB"}});ok{
 							tx, err := db.BeginTx(context.Background(),nil)
 							if err != nil{
 								return {{.Use "fmt.Errorf"}}("cannot begin transaction: %w",err)
@@ -180,7 +180,7 @@ func renderMigrationFunc(dst *ast.File, src *sql.Ctx, tableName string) error {
 							}
 		
 							if !alreadyApplied {
-								start := time.Now()
+								start := {{.Use "time.Now"}}()
 		
 								entry := migrationEntry{
 									Version:           m.Version,
@@ -196,7 +196,7 @@ func renderMigrationFunc(dst *ast.File, src *sql.Ctx, tableName string) error {
 									return fmt.Errorf("unable to insert migration state %s: %w", m.String(), err)
 								}
 		
-								err := m.Apply(db)
+								err := m.apply(db)
 								if err != nil {
 									return fmt.Errorf("unable to apply migration %s: %w", m.String(), err)
 								}
@@ -238,7 +238,7 @@ func renderMigrationStruct(dst *ast.File, src *sql.Ctx, tableName string) error
 					SetComment("...is the file path indicating the origin of the statements."),
 				ast.NewField("Line", ast.NewSimpleTypeDecl(stdlib.Int32)).
 					SetComment("...is the line number indicating the origin of the statements."),
-				ast.NewField("Checksum", ast.NewSimpleTypeDecl(stdlib.Int32)).
+				ast.NewField("Checksum", ast.NewSimpleTypeDecl(stdlib.String)).
 					SetComment("...is the hex encoded 28 byte sha3-224 checksum of all trimmed statements."),
 			).
 			AddMethods(
@@ -256,13 +256,15 @@ func renderMigrationStruct(dst *ast.File, src *sql.Ctx, tableName string) error
 							ast.NewBlock(
 								lang.TryDefine(
 									ast.NewIdentLit("_"),
-									lang.CallIdent("db", "ExecContext", lang.CallStatic("context.Background")),
+									lang.CallIdent("db", "ExecContext", lang.CallStatic("context.Background"), ast.NewIdent("s")),
 									"cannot execute statement",
 								),
 							),
 						),
 						lang.Term(),
+						ast.NewReturnStmt(ast.NewIdent("nil")),
 					)),
+				stringFunc("m", "Version", "Description"),
 			),
 	)
 
@@ -320,6 +322,8 @@ func renderMigrationEntity(dst *ast.File, src *sql.Ctx, tableName string) (*ast.
 
 			).
 			AddMethods(
+				stringFunc(recName, "Version", "Description"),
+
 				ast.NewFunc("insert").
 					SetVisibility(ast.PackagePrivate).
 					SetComment("...writes a migrationEntry into the history table.").
@@ -395,7 +399,7 @@ func sqlCreateTableMigrationHistory(tableName string, dialect sql.Dialect) strin
     "version"            BIGINT       NOT NULL,
     "file"               VARCHAR(255) NOT NULL,
     "line"               INT          NOT NULL,
-    "checksum"           CHAR(32)     NOT NULL,
+    "checksum"           CHAR(56)     NOT NULL,
     "applied_at"         BIGINT       NOT NULL,
     "execution_duration" BIGINT       NOT NULL,
 	"description"		 TEXT         NOT NULL,
@@ -410,7 +414,7 @@ func sqlCreateTableMigrationHistory(tableName string, dialect sql.Dialect) strin
 
 func sqlArgs(idents ...string) []ast.Expr {
 	var r []ast.Expr
-	r = append(r, lang.CallStatic("context.Background"))
+	r = append(r, lang.CallStatic("context.Background"), ast.NewIdent("q"))
 
 	for _, ident := range idents {
 		r = append(r, ast.NewIdent(ident))
@@ -418,3 +422,12 @@ func sqlArgs(idents ...string) []ast.Expr {
 
 	return r
 }
+
+// stringFunc returns a String method, which identifies a migration by its version and description in error messages.
+func stringFunc(recName, version, description string) *ast.Func {
+	return ast.NewFunc("String").
+		SetComment("...returns the version and the description.").
+		SetRecName(recName).
+		AddResults(ast.NewParam("", ast.NewSimpleTypeDecl(stdlib.String))).
+		SetBody(ast.NewBlock(ast.NewTpl(`return {{.Use "fmt.Sprintf"}}("%d (%s)", ` + recName + `.` + version + `, ` + recName + `.` + description + `)`)))
+}
@@ -74,7 +74,7 @@ func renderMySQLOptions(file *ast.File, dialect sql.Dialect, defaultDBName strin
 				SetDefault(ast.NewIdentLit("30s")),
 			ast.NewField("Tls", ast.NewSimpleTypeDecl(stdlib.String)).
 				SetComment("...configures connection security. Valid values are true, false, skip-verify or preferred.").
-				SetDefault(ast.NewBoolLit(false)),
+				SetDefault(ast.NewStrLit("false")),
 			ast.NewField("SqlMode", ast.NewSimpleTypeDecl(stdlib.String)).
 				SetComment("...is a flag which influences the sql parser.").
 				SetDefault(ast.NewStrLit("ANSI")),
@@ -87,8 +87,6 @@ func renderMySQLOptions(file *ast.File, dialect sql.Dialect, defaultDBName strin
 			ast.NewField("MaxIdleConns", ast.NewSimpleTypeDecl(stdlib.Int)).
 				SetComment("...is the amount of how many open connections can be idle.").
 				SetDefault(ast.NewIntLit(25)),
-			ast.NewField("Test", ast.NewSimpleTypeDecl(stdlib.Bool)).SetDefault(ast.NewBoolLit(true)),
-			ast.NewField("Test2", ast.NewSimpleTypeDecl(stdlib.Float64)).SetDefault(ast.NewBasicLit(ast.TokenFloat, "3.41")),
 		)
 
 	file.AddNodes(opt) // add it early, functions may need contextual information like package path
@@ -146,19 +144,23 @@ func addDSNFunc(opt *ast.Struct) {
 					urlEscape("Collation"),
 
 					ast.NewStrLit("&maxAllowedPacket="),
-					lang.Itoa(ast.NewIdent("MaxAllowedPacket")),
+					lang.ToString(lang.Attr("MaxAllowedPacket")),
 
 					ast.NewStrLit("&sql_mode="),
 					lang.Attr("SqlMode"),
 
 					ast.NewStrLit("&tls="),
-					lang.ToString(ast.NewIdent("tls")),
+					lang.Attr("Tls"),
 
+					// durations are formatted like 30s, which is the expected format of the driver
 					ast.NewStrLit("&timeout="),
-					lang.Itoa(ast.NewIdent("Timeout")),
+					lang.ToString(lang.Attr("Timeout")),
 
 					ast.NewStrLit("&writeTimeout="),
-					lang.Itoa(ast.NewIdent("WriteTimeout")),
+					lang.ToString(lang.Attr("WriteTimeout")),
+
+					// time columns are scanned into time.Time and not into raw bytes
+					ast.NewStrLit("&parseTime=true"),
 
 				),
 				ast.NewReturnStmt(ast.NewCallExpr(ast.NewSelExpr(ast.NewIdent("sb"), ast.NewIdent("String")))),
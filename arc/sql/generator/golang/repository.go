@@ -27,11 +27,12 @@ func renderRepositories(dst *ast.Prj, src *sql.Ctx) error {
 
 		file.Name = simpleLowerCaseName(repo.TypeName) + ".go"
 
-		implTypeName := golang.MakePublic(string(src.Dialect) + repo.TypeName + "Impl")
+		implTypeName := ImplTypeName(src.Dialect, repo.TypeName)
 
 		stub := ast.NewStruct(golang.MakePrivate("Abstract" + repo.TypeName)).
 			SetVisibility(ast.PackagePrivate).
-			SetComment("...provides function stubs for the interface\n" + repoTypeName.String() + ".")
+			SetComment("...provides function stubs for the interface\n" + repoTypeName.String() + ".").
+			AddFields(ast.NewField("db", ast.NewSimpleTypeDecl("DBTX")).SetVisibility(ast.PackagePrivate))
 
 		stub.AddMethods(
 			ast.NewFunc("init").
@@ -44,6 +45,7 @@ func renderRepositories(dst *ast.Prj, src *sql.Ctx) error {
 				SetComment("...is called to get the significant context.\n"+
 					"This is not idiomatic, but it is not fine to clutter domain APIs with I/O details either.\n"+
 					"This is a compromise to actually customize timeouts a bit, but yes, this could be better.").
+				AddResults(ast.NewParam("", ast.NewSimpleTypeDecl("context.Context"))).
 				SetBody(ast.NewBlock(ast.NewTpl(`return {{.Use "context.Background"}}()`))),
 		)
 		golang.ImplementFunctions(repo, stub)
@@ -73,7 +75,7 @@ func renderRepositories(dst *ast.Prj, src *sql.Ctx) error {
 
 		file.AddNodes(
 			ast.NewTpl("// document and assert interface compatibility.\n// Entities must be imported anyway, so we won't loose modularity.\n"),
-			ast.NewTpl(`var _ {{.Use (.Get "repoTypeName")}} = ({{.Get "iface"}})(nil)`).
+			ast.NewTpl(`var _ {{.Use (.Get "repoTypeName")}} = (*{{.Get "iface"}})(nil)`).
 				Put("repoTypeName", repoTypeName.String()).
 				Put("iface", implTypeName),
 			lang.Term(),
@@ -117,6 +119,12 @@ func renderRepositories(dst *ast.Prj, src *sql.Ctx) error {
 	return nil
 }
 
+// ImplTypeName returns the name of the generated implementation of the repository interface. Its constructor is
+// named New<ImplTypeName> and expects a DBTX.
+func ImplTypeName(dialect sql.Dialect, iface string) string {
+	return golang.MakePublic(string(dialect) + iface + "Impl")
+}
+
 func implementBody(fun *ast.Func, method sql.Method) error {
 	switch m := method.Mapping.(type) {
 	case sql.ExecMany:
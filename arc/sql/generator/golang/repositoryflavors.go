@@ -102,13 +102,13 @@ func implementFindOne(fun *ast.Func, sql token.String, mapping sql.QueryOne) err
 					}
 			
 					defer w.Close()
-					for r.Next() {
+					for w.Next() {
 						if err:= w.Scan({{.Get "out"}}); err!=nil {
 							return i, {{.Use "fmt.Errorf"}}("scan of '%s' failed: %w",q, err)
 						}
 					}
 
-					if err := rows.Err(); err!=nil{
+					if err := w.Err(); err!=nil{
 						return i, {{.Use "fmt.Errorf"}}("query of '%s' failed: %w",q, err)
 					}
 			
@@ -153,7 +153,7 @@ func implementFindMany(fun *ast.Func, sql token.String, mapping sql.QueryMany) e
 					}
 			
 					defer w.Close()
-					for r.Next() {
+					for w.Next() {
 						var t {{.Use (.Get "returnType")}}
 						if err:= w.Scan({{.Get "out"}}); err!=nil {
 							return i, {{.Use "fmt.Errorf"}}("scan of '%s' failed: %w",q, err)
@@ -162,7 +162,7 @@ func implementFindMany(fun *ast.Func, sql token.String, mapping sql.QueryMany) e
 						i = append(i, t)
 					}
 
-					if err := rows.Err(); err!=nil{
+					if err := w.Err(); err!=nil{
 						return i, {{.Use "fmt.Errorf"}}("query of '%s' failed: %w",q, err)
 					}
 			
@@ -202,7 +202,8 @@ func (p PosError) Explain() string {
 	return sb.String()
 }
 
-// Explain takes the given wrapped error chain and explains it, if it can.
+// Explain takes the given wrapped error chain and explains it, if it can. Each positional error of the chain is
+// explained, so that the root cause of a nested error is located as well.
 func Explain(err error) string {
 	var posErr *PosError
 	if errors.As(err, &posErr) {
@@ -211,7 +212,15 @@ func Explain(err error) string {
 		sb.WriteString(err.Error())
 		sb.WriteString("\n")
 
-		sb.WriteString(posErr.Explain())
+		for posErr != nil {
+			sb.WriteString(posErr.Explain())
+
+			cause := posErr.Cause
+			posErr = nil
+			if cause != nil {
+				errors.As(cause, &posErr)
+			}
+		}
 
 		return sb.String()
 	}
@@ -34,6 +34,9 @@ func createWorkspace() *Project {
 							Require("github.com/golangee/uuid latest").
 							AddDist("darwin", "amd64").
 							AddDist("linux", "amd64"),
+						).
+						SetTypeScript(NewTypeScript().
+							SetOutDir("../../testdata/workspace/web"),
 						),
 				).
 				AddExecutables(
@@ -100,13 +103,14 @@ func createWorkspace() *Project {
 									AddMethods(NewMethod("SayHelloTicket", "...says hello to tickets.")).
 									AddInjections(
 										NewInjection("myCfg", "", Cfg, NewTypeDecl("$BC/usecase.MyConfig")),
-										NewInjection("tickets", "... is the other tickets stuff", ServiceComponent, NewTypeDecl("$BC/core.Tickets")),
+										NewInjection("tickets", "... is the other tickets stuff", ServiceComponent, NewTypeDecl("$BC/core.TicketRepo")),
 									),
 							).AddStructs(
 								NewStruct("MyConfig", "...is use case feature flag configuration.", Cfg).
 									AddFields(NewField("FancyFeature", "... is the fancy feature toggle.", NewTypeDecl(stdlib.Bool))),
 							),
-						),
+						).
+						Bind("$BC/core.TicketRepo", "$BC/core.InMemoryTicketRepo"),
 				),
 		)
 }
@@ -3,6 +3,7 @@ package eearc
 import (
 	"fmt"
 	"github.com/golangee/architecture/arc"
+	"github.com/golangee/architecture/arc/adl/parser"
 	"github.com/golangee/architecture/arc/token"
 	"github.com/golangee/src/render"
 	"os"
@@ -23,9 +24,31 @@ func TestADLMarshalling(t *testing.T) {
 	}
 }
 
+// TestADLTextFormat ensures that the textual representation of the dummy project renders exactly the same.
+func TestADLTextFormat(t *testing.T) {
+	prj, err := parser.ParseFile("../../testdata/supportiety.adl")
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	got, err := arc.Render(prj, nil)
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	want, err := arc.Render(createWorkspace(), nil)
+	if err != nil {
+		t.Fatal(token.Explain(err))
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected equal artifacts but got:\n%v", got)
+	}
+}
+
 func TestDummyProject(t *testing.T) {
 	ws := createWorkspace()
-	a, err := arc.Render(ws)
+	a, err := arc.Render(ws, nil)
 	//fmt.Println(a)
 
 	if err != nil {
@@ -5,4 +5,5 @@ go 1.16
 require (
 	github.com/golangee/sql v0.0.0-20210531101020-33021aed64c2
 	github.com/golangee/src v0.0.0-20210716153939-e436847e8c6b
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -24,6 +24,7 @@ import (
 	logging "github.com/golangee/architecture/testdata/workspace/server/internal/logging"
 	log "github.com/golangee/log"
 	ecs "github.com/golangee/log/ecs"
+	os "os"
 	signal "os/signal"
 	syscall "syscall"
 )
@@ -45,9 +46,11 @@ func main() {
 
 	if err != nil {
 		logger.Println(ecs.Fatal(), err)
+		os.Exit(1)
 	}
 
 	logger.Println(ecs.Info(), "successful shutdown")
+
 }
 func realMain(ctx context.Context) error {
 	a, err := supportietyserver.NewApplication(ctx)
@@ -21,6 +21,7 @@ import (
 	errors "errors"
 	flag "flag"
 	fmt "fmt"
+	application "github.com/golangee/architecture/testdata/workspace/server/internal/application"
 	core "github.com/golangee/architecture/testdata/workspace/server/internal/tickets/core"
 	usecase "github.com/golangee/architecture/testdata/workspace/server/internal/tickets/usecase"
 	os "os"
@@ -52,15 +53,18 @@ type defaultApplication struct {
 	// one level of a quasi-vtable calling indirection for simple method 'overriding'.
 	self *Application
 
-	ticketsUsecaseTickets *usecase.Tickets
+	ticketsUsecaseTickets         *usecase.Tickets
+	ticketsCoreInMemoryTicketRepo *core.InMemoryTicketRepo
+	// services contains the constructed lifecycle services in the order of their dependencies.
+	services application.Services
 }
 
-// configure resets, prepares and parses the configuration. The priority of evaluation is:
+// configure resets, prepares, parses and validates the configuration. The priority of evaluation is:
 //
-//   0. hardcoded defaults
-//   1. values from configuration file
-//   2. values from environment variables
-//   3. values from command flags
+//  0. hardcoded defaults
+//  1. values from configuration file
+//  2. values from environment variables
+//  3. values from command flags
 func (d *defaultApplication) configure() error {
 	const (
 		appName      = "supportiety-server"
@@ -106,6 +110,10 @@ func (d *defaultApplication) configure() error {
 		return fmt.Errorf("invalid arguments: %w", err)
 	}
 
+	if err := d.cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	return nil
 }
 
@@ -117,16 +125,19 @@ func (d *defaultApplication) init(ctx context.Context) error {
 	return nil
 }
 
-func (_ defaultApplication) Run(ctx context.Context) error {
-	return nil
-}
+// Run starts the services in the order of their dependencies, serves the driver adapters until the
+// context is cancelled or a server fails and finally stops the services in reverse order.
+func (d *defaultApplication) Run(ctx context.Context) error {
+	res := d.self.startServices(ctx)
+	if res == nil {
+		res = d.self.serve(ctx)
+	}
 
-func (d *defaultApplication) getTicketsUsecaseMyConfig() (usecase.MyConfig, error) {
-	return d.cfg.Tickets.Usecase.MyConfig, nil
-}
+	if err := d.services.Stop(d.cfg.Lifecycle.ShutdownTimeout); err != nil && res == nil {
+		res = err
+	}
 
-func (d *defaultApplication) getTicketsCoreTickets() (core.Tickets, error) {
-	panic("no implementation available")
+	return res
 }
 
 func (d *defaultApplication) getTicketsUsecaseTickets() (*usecase.Tickets, error) {
@@ -139,7 +150,7 @@ func (d *defaultApplication) getTicketsUsecaseTickets() (*usecase.Tickets, error
 		return nil, fmt.Errorf("cannot get parameter 'myCfg': %w", err)
 	}
 
-	tickets, err := d.self.getTicketsCoreTickets()
+	tickets, err := d.self.getTicketsCoreTicketRepo()
 	if err != nil {
 		return nil, fmt.Errorf("cannot get parameter 'tickets': %w", err)
 	}
@@ -153,3 +164,56 @@ func (d *defaultApplication) getTicketsUsecaseTickets() (*usecase.Tickets, error
 
 	return s, nil
 }
+
+func (d *defaultApplication) getTicketsUsecaseMyConfig() (usecase.MyConfig, error) {
+	return d.cfg.Tickets.Usecase.MyConfig, nil
+}
+
+// getTicketsCoreTicketRepo returns the bound implementation 'github.com/golangee/architecture/testdata/workspace/server/internal/tickets/core.InMemoryTicketRepo'.
+func (d *defaultApplication) getTicketsCoreTicketRepo() (core.TicketRepo, error) {
+	return d.self.getTicketsCoreInMemoryTicketRepo()
+}
+
+func (d *defaultApplication) getTicketsCoreInMemoryTicketRepo() (*core.InMemoryTicketRepo, error) {
+	if d.ticketsCoreInMemoryTicketRepo != nil {
+		return d.ticketsCoreInMemoryTicketRepo, nil
+	}
+
+	s := core.NewInMemoryTicketRepo()
+
+	d.ticketsCoreInMemoryTicketRepo = s
+
+	return s, nil
+}
+
+// resolveSingletons eagerly constructs all services and implementations in the order of their dependencies.
+// It is invoked by startServices, if the Lifecycle.Eager option is set.
+func (d *defaultApplication) resolveSingletons() error {
+	if _, err := d.self.getTicketsCoreInMemoryTicketRepo(); err != nil {
+		return fmt.Errorf("cannot resolve 'tickets/core.InMemoryTicketRepo': %w", err)
+	}
+
+	if _, err := d.self.getTicketsUsecaseTickets(); err != nil {
+		return fmt.Errorf("cannot resolve 'tickets/usecase.Tickets': %w", err)
+	}
+
+	return nil
+}
+
+// startServices constructs all lifecycle services and starts them in the order of their dependencies.
+func (d *defaultApplication) startServices(ctx context.Context) error {
+	if d.cfg.Lifecycle.Eager {
+		if err := d.self.resolveSingletons(); err != nil {
+			return err
+		}
+	}
+
+	return d.services.Start(ctx)
+}
+
+// serve blocks until the context is cancelled, because there is no driver adapter to serve.
+func (d *defaultApplication) serve(ctx context.Context) error {
+	<-ctx.Done()
+
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	json "encoding/json"
 	flag "flag"
 	fmt "fmt"
+	application "github.com/golangee/architecture/testdata/workspace/server/internal/application"
 	chat "github.com/golangee/architecture/testdata/workspace/server/internal/tickets/core/chat"
 	usecase "github.com/golangee/architecture/testdata/workspace/server/internal/tickets/usecase"
 	os "os"
@@ -28,16 +29,20 @@ import (
 // Configuration contains all aggregated configurations for the entire application and all contained bounded contexts.
 type Configuration struct {
 	Tickets TicketsConfig
+	// Lifecycle contains the options to start and stop the services.
+	Lifecycle application.LifecycleConfig
 }
 
 // Reset restores this instance to the default state.
 func (c *Configuration) Reset() {
 	c.Tickets.Reset()
+	c.Lifecycle.Reset()
 }
 
 // ConfigureFlags configures the flags to be ready to get evaluated.
 func (c *Configuration) ConfigureFlags(flags *flag.FlagSet) {
 	c.Tickets.ConfigureFlags(flags)
+	c.Lifecycle.ConfigureFlags(flags)
 }
 
 // ParseEnv tries to parse the environment variables into this instance.
@@ -45,6 +50,18 @@ func (c *Configuration) ParseEnv() error {
 	if err := c.Tickets.ParseEnv(); err != nil {
 		return fmt.Errorf("cannot parse 'Tickets': %w", err)
 	}
+	if err := c.Lifecycle.ParseEnv(); err != nil {
+		return fmt.Errorf("cannot parse 'Lifecycle': %w", err)
+	}
+
+	return nil
+}
+
+// Validate checks the constraints of all configurations and returns the first invalid one.
+func (c *Configuration) Validate() error {
+	if err := c.Tickets.Validate(); err != nil {
+		return fmt.Errorf("invalid 'Tickets': %w", err)
+	}
 
 	return nil
 }
@@ -53,20 +70,24 @@ func (c *Configuration) ParseEnv() error {
 //
 // Example JSON
 //
-//   {
-//     "Tickets": {
-//       "Core": {
-//         "AnotherConfig": {
-//           "BlaFeature": false
-//         }
-//       },
-//       "Usecase": {
-//         "MyConfig": {
-//           "FancyFeature": false
-//         }
-//       }
-//     }
-//   }
+//	{
+//	  "Lifecycle": {
+//	    "Eager": true,
+//	    "ShutdownTimeout": 10000000000
+//	  },
+//	  "Tickets": {
+//	    "Core": {
+//	      "AnotherConfig": {
+//	        "BlaFeature": false
+//	      }
+//	    },
+//	    "Usecase": {
+//	      "MyConfig": {
+//	        "FancyFeature": false
+//	      }
+//	    }
+//	  }
+//	}
 func (c *Configuration) ParseFile(filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -113,6 +134,18 @@ func (t *TicketsConfig) ParseEnv() error {
 
 }
 
+// Validate checks the constraints of all configurations and returns the first invalid one.
+func (t *TicketsConfig) Validate() error {
+	if err := t.Core.Validate(); err != nil {
+		return fmt.Errorf("invalid 'Core': %w", err)
+	}
+	if err := t.Usecase.Validate(); err != nil {
+		return fmt.Errorf("invalid 'Usecase': %w", err)
+	}
+	return nil
+
+}
+
 // TicketsCoreConfig contains all configurations for the 'core' layer of 'tickets'.
 type TicketsCoreConfig struct {
 	AnotherConfig chat.AnotherConfig
@@ -132,6 +165,12 @@ func (t *TicketsCoreConfig) ParseEnv() error {
 	return nil
 }
 
+// Validate checks the constraints of all configurations and returns the first invalid one.
+func (t *TicketsCoreConfig) Validate() error {
+
+	return nil
+}
+
 // ConfigureFlags configures the flags to be ready to get evaluated.
 func (t *TicketsCoreConfig) ConfigureFlags(flags *flag.FlagSet) {
 	t.AnotherConfig.ConfigureFlags(flags)
@@ -156,6 +195,12 @@ func (t *TicketsUsecaseConfig) ParseEnv() error {
 	return nil
 }
 
+// Validate checks the constraints of all configurations and returns the first invalid one.
+func (t *TicketsUsecaseConfig) Validate() error {
+
+	return nil
+}
+
 // ConfigureFlags configures the flags to be ready to get evaluated.
 func (t *TicketsUsecaseConfig) ConfigureFlags(flags *flag.FlagSet) {
 	t.MyConfig.ConfigureFlags(flags)
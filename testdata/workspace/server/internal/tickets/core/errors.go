@@ -262,3 +262,125 @@ func (e ticketsOtherError) Unwrap() error {
 func (e ticketsOtherError) Error() string {
 	return "Other"
 }
+
+// TicketsTicketNotFoundError indicates that no Ticket with the according id exists.
+type TicketsTicketNotFoundError interface {
+	// ID returns the value of id.
+	// ID is the identity of the entity.
+	ID() uuid.UUID
+
+	// TicketNotFound returns true, if it represents a TicketNotFound case.
+	TicketNotFound() bool
+
+	TicketsError
+}
+
+// AsTicketsTicketNotFoundError finds the first error in err's chain that matches anyTicketsTicketNotFoundError behavior.
+// Returns nil if no such error is found.
+func AsTicketsTicketNotFoundError(err error) TicketsTicketNotFoundError {
+	var match TicketsTicketNotFoundError
+	if errors.As(err, &match) && match.Tickets() && match.TicketNotFound() {
+		return match
+	}
+
+	return nil
+}
+
+// ticketsTicketNotFoundError indicates that no Ticket with the according id exists.
+// ticketsTicketNotFoundError is also a TicketsError.
+type ticketsTicketNotFoundError struct {
+	// id is the identity of the entity.
+	id uuid.UUID
+
+	// cause refers to a causing error or nil.
+	cause error
+}
+
+// ID returns the value of id.
+// ID is the identity of the entity.
+func (e ticketsTicketNotFoundError) ID() uuid.UUID {
+	return e.id
+}
+
+// Tickets returns true, if the error belongs to the sum type of Tickets.
+// This implementation always returns true.
+func (_ ticketsTicketNotFoundError) Tickets() bool {
+	return true
+}
+
+// TicketNotFound returns true, if it represents a TicketNotFound case.
+// This implementation always returns true.
+func (_ ticketsTicketNotFoundError) TicketNotFound() bool {
+	return true
+}
+
+// Unwrap unpacks the cause or returns nil.
+func (e ticketsTicketNotFoundError) Unwrap() error {
+	return e.cause
+}
+
+// Error returns the conventional description of this error.
+func (e ticketsTicketNotFoundError) Error() string {
+	return fmt.Sprintf("TicketNotFound: id=%v", e.id)
+}
+
+// TicketsTicketAlreadyExistsError indicates that a Ticket with the according id exists already.
+type TicketsTicketAlreadyExistsError interface {
+	// ID returns the value of id.
+	// ID is the identity of the entity.
+	ID() uuid.UUID
+
+	// TicketAlreadyExists returns true, if it represents a TicketAlreadyExists case.
+	TicketAlreadyExists() bool
+
+	TicketsError
+}
+
+// AsTicketsTicketAlreadyExistsError finds the first error in err's chain that matches anyTicketsTicketAlreadyExistsError behavior.
+// Returns nil if no such error is found.
+func AsTicketsTicketAlreadyExistsError(err error) TicketsTicketAlreadyExistsError {
+	var match TicketsTicketAlreadyExistsError
+	if errors.As(err, &match) && match.Tickets() && match.TicketAlreadyExists() {
+		return match
+	}
+
+	return nil
+}
+
+// ticketsTicketAlreadyExistsError indicates that a Ticket with the according id exists already.
+// ticketsTicketAlreadyExistsError is also a TicketsError.
+type ticketsTicketAlreadyExistsError struct {
+	// id is the identity of the entity.
+	id uuid.UUID
+
+	// cause refers to a causing error or nil.
+	cause error
+}
+
+// ID returns the value of id.
+// ID is the identity of the entity.
+func (e ticketsTicketAlreadyExistsError) ID() uuid.UUID {
+	return e.id
+}
+
+// Tickets returns true, if the error belongs to the sum type of Tickets.
+// This implementation always returns true.
+func (_ ticketsTicketAlreadyExistsError) Tickets() bool {
+	return true
+}
+
+// TicketAlreadyExists returns true, if it represents a TicketAlreadyExists case.
+// This implementation always returns true.
+func (_ ticketsTicketAlreadyExistsError) TicketAlreadyExists() bool {
+	return true
+}
+
+// Unwrap unpacks the cause or returns nil.
+func (e ticketsTicketAlreadyExistsError) Unwrap() error {
+	return e.cause
+}
+
+// Error returns the conventional description of this error.
+func (e ticketsTicketAlreadyExistsError) Error() string {
+	return fmt.Sprintf("TicketAlreadyExists: id=%v", e.id)
+}
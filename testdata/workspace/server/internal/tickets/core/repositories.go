@@ -17,8 +17,10 @@
 package core
 
 import (
+	bytes "bytes"
+	fmt "fmt"
 	uuid "github.com/golangee/uuid"
-	fs "io/fs"
+	sort "sort"
 	sync "sync"
 )
 
@@ -33,57 +35,145 @@ type Tickets interface {
 
 // TicketRepo autogenerated repo
 type TicketRepo interface {
+	// InsertOne inserts the entity or fails with TicketsTicketAlreadyExistsError, if an entity with the same
+	// identity exists already.
+	InsertOne(entity Ticket) error
+
+	// FindOne returns the entity with the given id or fails with TicketsTicketNotFoundError.
+	FindOne(id uuid.UUID) (Ticket, error)
+
+	// UpdateOne replaces the entity with the same identity or fails with TicketsTicketNotFoundError.
+	UpdateOne(entity Ticket) error
+
+	// DeleteOne deletes the entity with the given id. Is a no-op if no such entity exists.
+	DeleteOne(id uuid.UUID) error
+
+	// CountAll returns the amount of all entities.
+	CountAll() (int64, error)
+
+	// FindAll returns the page of the sorted entities, which is selected by the query.
+	FindAll(query TicketQuery) ([]Ticket, error)
+
+	// IterateAll invokes the visitor for each entity in the order of their identity, until it returns false.
+	// The visitor may modify the repository.
+	IterateAll(visitor TicketVisitor) error
 }
 
-// InMemoryTicketRepo implements a hashmap based in-memory implementation for Ticket entities.
-type InMemoryTicketRepo struct {
-	store map[uuid.UUID]Ticket
-	mutex sync.RWMutex
+// TicketQuery selects a sorted page of Ticket entities. A page is either selected by an offset or by
+// the last entity of the previous page, which is stable even if entities are inserted or deleted concurrently.
+type TicketQuery struct {
+	// OrderBy selects the field, by which the entities are sorted.
+	OrderBy TicketOrder
+
+	// Descending reverses the order.
+	Descending bool
+
+	// Offset skips the first entities of the sorted result. It is ignored, if After is set.
+	Offset int
+
+	// Limit is the maximum amount of entities. Zero means no limit.
+	Limit int
+
+	// After is the last entity of the previous page. If set, only the entities, which are sorted after it,
+	// are returned.
+	After *Ticket
 }
 
-// InsertOne inserts the entity or fails if already exists.
-func (r InMemoryTicketRepo) InsertOne(entity Ticket) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// TicketOrder selects the field, by which Ticket entities are sorted.
+type TicketOrder int
+
+const (
+	// TicketByIdentity sorts the entities only by their identity.
+	TicketByIdentity TicketOrder = iota
+	// TicketByWhen sorts the entities by their When field.
+	TicketByWhen
+)
+
+// TicketVisitor is invoked for each Ticket entity and returns false to stop the iteration.
+type TicketVisitor func(entity Ticket) bool
 
-	if _, ok := r.store[entity.ID]; ok {
-		return fs.ErrExist
+// Less reports whether the entity a is sorted before b. Entities, which are equal in the selected
+// field, are ordered by their identity, so that the order is total and the pages are stable.
+func (q TicketQuery) Less(a, b Ticket) bool {
+	switch q.OrderBy {
+	case TicketByWhen:
+		if a.When.Before(b.When) {
+			return !q.Descending
+		}
+
+		if b.When.Before(a.When) {
+			return q.Descending
+		}
 	}
 
-	r.store[entity.ID] = entity
+	if q.Descending {
+		return bytes.Compare(b.ID[:], a.ID[:]) < 0
+	}
+
+	return bytes.Compare(a.ID[:], b.ID[:]) < 0
+}
+
+// Validate returns an error, if the offset or the limit is negative.
+func (q TicketQuery) Validate() error {
+	if q.Offset < 0 || q.Limit < 0 {
+		return fmt.Errorf("invalid TicketQuery: negative offset %d or limit %d", q.Offset, q.Limit)
+	}
 
 	return nil
 }
 
-// UpdateOne updates the entity or fails if does not exist.
-func (r InMemoryTicketRepo) UpdateOne(entity Ticket) error {
+// InMemoryTicketRepo implements a hashmap based in-memory implementation for Ticket entities.
+type InMemoryTicketRepo struct {
+	store map[uuid.UUID]Ticket
+	mutex sync.RWMutex
+}
+
+// InsertOne inserts the entity or fails with TicketsTicketAlreadyExistsError, if an entity with the same
+// identity exists already.
+func (r *InMemoryTicketRepo) InsertOne(entity Ticket) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, ok := r.store[entity.ID]; !ok {
-		return fs.ErrNotExist
+	id := entity.ID
+	if _, ok := r.store[id]; ok {
+		return ticketsTicketAlreadyExistsError{id: id}
 	}
 
-	r.store[entity.ID] = entity
+	r.store[id] = entity
 
 	return nil
 }
 
-// FindOne finds the entity or fails if does not exist.
-func (r InMemoryTicketRepo) FindOne(id uuid.UUID) (Ticket, error) {
+// FindOne returns the entity with the given id or fails with TicketsTicketNotFoundError.
+func (r *InMemoryTicketRepo) FindOne(id uuid.UUID) (Ticket, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	v, ok := r.store[id]
 	if !ok {
-		return v, fs.ErrNotExist
+		return v, ticketsTicketNotFoundError{id: id}
 	}
 
 	return v, nil
 }
 
+// UpdateOne replaces the entity with the same identity or fails with TicketsTicketNotFoundError.
+func (r *InMemoryTicketRepo) UpdateOne(entity Ticket) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := entity.ID
+	if _, ok := r.store[id]; !ok {
+		return ticketsTicketNotFoundError{id: id}
+	}
+
+	r.store[id] = entity
+
+	return nil
+}
+
 // DeleteOne deletes the entity with the given id. Is a no-op if no such entity exists.
-func (r InMemoryTicketRepo) DeleteOne(id uuid.UUID) error {
+func (r *InMemoryTicketRepo) DeleteOne(id uuid.UUID) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
@@ -91,3 +181,81 @@ func (r InMemoryTicketRepo) DeleteOne(id uuid.UUID) error {
 
 	return nil
 }
+
+// CountAll returns the amount of all entities.
+func (r *InMemoryTicketRepo) CountAll() (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return int64(len(r.store)), nil
+}
+
+// FindAll returns the page of the sorted entities, which is selected by the query.
+func (r *InMemoryTicketRepo) FindAll(query TicketQuery) ([]Ticket, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	res := make([]Ticket, 0, len(r.store))
+	for _, entity := range r.store {
+		if query.After == nil || query.Less(*query.After, entity) {
+			res = append(res, entity)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return query.Less(res[i], res[j])
+	})
+
+	if query.After == nil {
+		if query.Offset >= len(res) {
+			return res[:0], nil
+		}
+
+		res = res[query.Offset:]
+	}
+
+	if query.Limit > 0 && query.Limit < len(res) {
+		res = res[:query.Limit]
+	}
+
+	return res, nil
+}
+
+// IterateAll invokes the visitor for each entity in the order of their identity, until it returns false.
+// The visitor may modify the repository.
+func (r *InMemoryTicketRepo) IterateAll(visitor TicketVisitor) error {
+	r.mutex.RLock()
+	snapshot := make([]Ticket, 0, len(r.store))
+	for _, entity := range r.store {
+		snapshot = append(snapshot, entity)
+	}
+	r.mutex.RUnlock()
+
+	order := TicketQuery{}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return order.Less(snapshot[i], snapshot[j])
+	})
+
+	for _, entity := range snapshot {
+		if !visitor(entity) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// NewInMemoryTicketRepo allocates an empty InMemoryTicketRepo.
+func NewInMemoryTicketRepo() *InMemoryTicketRepo {
+	r := &InMemoryTicketRepo{}
+	r.store = make(map[uuid.UUID]Ticket)
+
+	return r
+}
+
+// InMemoryTicketRepo must implement TicketRepo.
+var _ TicketRepo = (*InMemoryTicketRepo)(nil)
@@ -30,7 +30,7 @@ type Tickets struct {
 	myCfg MyConfig
 
 	// tickets is the other tickets stuff
-	tickets core.Tickets
+	tickets core.TicketRepo
 
 	// mutex ensures that internal state is thread safe.
 	mutex sync.Mutex
@@ -41,7 +41,7 @@ type Tickets struct {
 // NewTickets allocates and initializes a new Tickets instance.
 //
 // The parameter tickets is the other tickets stuff
-func NewTickets(myCfg MyConfig, tickets core.Tickets) (*Tickets, error) {
+func NewTickets(myCfg MyConfig, tickets core.TicketRepo) (*Tickets, error) {
 	t := &Tickets{}
 	t.myCfg = myCfg
 	t.tickets = tickets